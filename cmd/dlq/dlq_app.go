@@ -0,0 +1,145 @@
+package dlq
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+)
+
+const usage = "usage: dlq list [limit] | show <sequence> | replay <sequence>... | replay all"
+
+const defaultListLimit = 20
+
+// replayAllLimit bounds "replay all" so that a message failing again while replaying is not replayed twice
+const replayAllLimit = 10000
+
+// StartApp runs the dlq command, args are the ones following "dlq" in the command line
+func StartApp(args []string) {
+	if len(args) == 0 {
+		log.Fatal(usage)
+	}
+
+	appConfig := config.GetAppConfig("")
+	logService := logger.NewService()
+
+	natsService, err := nats.NewService(appConfig, logService)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer natsService.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "list":
+		limit := defaultListLimit
+		if len(args) > 1 {
+			limit, err = strconv.Atoi(args[1])
+			if err != nil || limit < 1 {
+				log.Fatalf("invalid limit '%s', %s", args[1], usage)
+			}
+		}
+
+		deadLetters, err := natsService.ListDeadLetters(ctx, limit)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printDeadLetters(deadLetters)
+	case "show":
+		if len(args) != 2 {
+			log.Fatal(usage)
+		}
+
+		deadLetter, err := findDeadLetter(ctx, natsService, parseSequence(args[1]))
+		if err != nil {
+			log.Fatal(err)
+		}
+		printDeadLetter(deadLetter)
+	case "replay":
+		if len(args) < 2 {
+			log.Fatal(usage)
+		}
+
+		sequences := []uint64{}
+		if args[1] == "all" {
+			deadLetters, err := natsService.ListDeadLetters(ctx, replayAllLimit)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, deadLetter := range deadLetters {
+				sequences = append(sequences, deadLetter.Sequence)
+			}
+		} else {
+			for _, arg := range args[1:] {
+				sequences = append(sequences, parseSequence(arg))
+			}
+		}
+
+		for _, sequence := range sequences {
+			err = natsService.ReplayDeadLetter(ctx, sequence)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		fmt.Printf("replayed %d dead letter(s)\n", len(sequences))
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func parseSequence(arg string) uint64 {
+	sequence, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil || sequence == 0 {
+		log.Fatalf("invalid sequence '%s', %s", arg, usage)
+	}
+
+	return sequence
+}
+
+func findDeadLetter(ctx context.Context, natsService nats.Service, sequence uint64) (nats.DeadLetter, error) {
+	deadLetters, err := natsService.ListDeadLetters(ctx, replayAllLimit)
+	if err != nil {
+		return nats.DeadLetter{}, err
+	}
+
+	for _, deadLetter := range deadLetters {
+		if deadLetter.Sequence == sequence {
+			return deadLetter, nil
+		}
+	}
+
+	return nats.DeadLetter{}, fmt.Errorf("dead letter %d does not exist", sequence)
+}
+
+func printDeadLetters(deadLetters []nats.DeadLetter) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SEQUENCE\tSUBJECT\tDURABLE\tDELIVERIES\tFAILED AT\tERROR")
+
+	for _, deadLetter := range deadLetters {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%d\t%s\t%s\n", deadLetter.Sequence, deadLetter.Subject, deadLetter.Durable, deadLetter.NumDelivered, deadLetter.FailedAt.Format(time.RFC3339), deadLetter.Error)
+	}
+
+	writer.Flush()
+}
+
+func printDeadLetter(deadLetter nats.DeadLetter) {
+	fmt.Printf("sequence:   %d\n", deadLetter.Sequence)
+	fmt.Printf("subject:    %s\n", deadLetter.Subject)
+	fmt.Printf("durable:    %s\n", deadLetter.Durable)
+	fmt.Printf("deliveries: %d\n", deadLetter.NumDelivered)
+	fmt.Printf("failed at:  %s\n", deadLetter.FailedAt.Format(time.RFC3339))
+	fmt.Printf("error:      %s\n", deadLetter.Error)
+	for key, values := range deadLetter.Headers {
+		fmt.Printf("header:     %s: %v\n", key, values)
+	}
+	fmt.Printf("data:\n%s\n", deadLetter.Data)
+}
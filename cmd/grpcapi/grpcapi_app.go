@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/internal/service/outbox"
+	"github.com/pjmessi/golang-practice/internal/service/security"
+	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/netutil"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+)
+
+// StartApp runs the grpcapi, it serves the same database as the restapi which relays the outbox and runs the workers
+func StartApp(args []string) {
+	flags := flag.NewFlagSet("grpcapi", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	appConfig := config.GetAppConfig("")
+
+	// initialize database connection
+	db, err := database.NewDb(appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = db.CheckHealth()
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	defer db.CloseConnection()
+
+	// initialize common services
+	logService := logger.NewService()
+	validationHandler, err := validation.NewHandler()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// initialize core services
+	jwtHandler, err := jwt.NewHandler(logService, appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	eventRegistry, err := eventtype.NewRegistry()
+	if err != nil {
+		log.Fatal(err)
+	}
+	outboxService := outbox.NewService(logService, db, eventRegistry)
+	inviteService := invite.NewService(logService, db)
+	userService := user.NewService(appConfig, logService, db, inviteService, outboxService)
+	securityService := security.NewService(appConfig, logService, db, outboxService)
+	authService := auth.NewService(logService, jwtHandler, db, securityService)
+
+	// initialize facades
+	userFacade := user.NewFacade(logService, userService, validationHandler)
+	authFacade := auth.NewFacade(logService, authService, validationHandler)
+
+	// start gRPC server
+	trustedProxies, err := netutil.ParseTrustedProxies(appConfig.TRUSTED_PROXIES)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := NewServer(logService, db, authFacade, userFacade, trustedProxies)
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", appConfig.GRPC_PORT))
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		logService.Debug(fmt.Sprintf("🚀 starting gRPC server on port: %s", appConfig.GRPC_PORT))
+		err := server.Serve(listener)
+		if err != nil {
+			logService.Debug(fmt.Sprintf("error while starting gRPC server: %v", err))
+		}
+	}()
+
+	// stop gRPC server gracefully, the calls in progress are completed
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	<-signalChan
+	server.GracefulStop()
+	logService.Debug("gRPC server closed")
+}
@@ -0,0 +1,49 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthServer implements the gRPC health checking protocol, a service is serving while the database is up. The empty
+// service name is the health of the whole server.
+type HealthServer struct {
+	healthpb.UnimplementedHealthServer
+	db         database.Db
+	logService logger.Service
+	services   map[string]bool
+}
+
+func NewHealthServer(logService logger.Service, db database.Db, services []string) *HealthServer {
+	healthServer := &HealthServer{
+		db:         db,
+		logService: logService,
+		services:   map[string]bool{"": true},
+	}
+	for _, service := range services {
+		healthServer.services[service] = true
+	}
+
+	return healthServer
+}
+
+func (s *HealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.services[req.GetService()] {
+		return nil, status.Errorf(codes.NotFound, "unknown service '%s'", req.GetService())
+	}
+
+	err := s.db.CheckHealth()
+	if err != nil {
+		// the error is only logged as it may describe the infrastructure
+		s.logService.ErrorCtx(ctx, fmt.Sprintf("grpcapi.HealthServer.Check(): %s", err))
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
@@ -0,0 +1,222 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/netutil"
+	"github.com/pjmessi/golang-practice/pkg/strutil"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// TraceIdHeader is the response header carrying the trace id of the call, like the X-Trace-ID header of the restapi
+const TraceIdHeader = "x-trace-id"
+
+// ErrorDomain is the domain of the ErrorInfo detail of every error, its reason is the type of the exception
+const ErrorDomain = "golang-practice"
+
+type Interceptors struct {
+	authFacade auth.Facade
+	logService logger.Service
+	// authMethods are the full names of the methods which require a jwt
+	authMethods    map[string]bool
+	trustedProxies []*net.IPNet
+}
+
+func NewInterceptors(logService logger.Service, authFacade auth.Facade, authMethods []string, trustedProxies []*net.IPNet) *Interceptors {
+	interceptors := &Interceptors{
+		authFacade:     authFacade,
+		logService:     logService,
+		authMethods:    map[string]bool{},
+		trustedProxies: trustedProxies,
+	}
+	for _, method := range authMethods {
+		interceptors.authMethods[method] = true
+	}
+
+	return interceptors
+}
+
+// Chain returns the interceptors in the order they handle a call, the outermost first
+func (i *Interceptors) Chain() grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(i.ctxInterceptor, i.reqLoggerInterceptor, i.panicHandlerInterceptor, i.errInterceptor, i.authInterceptor)
+}
+
+// ctxInterceptor adds the trace id, the client ip and the user agent to the context like the ctxMiddleware of the
+// restapi, the trace id is sent back in the x-trace-id header
+func (i *Interceptors) ctxInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	traceId, err := uuidutil.GenUuidV4()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "error while generating traceId")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	newCtx := ctxutil.WithTraceId(ctx, traceId)
+	newCtx = ctxutil.AddValue(newCtx, "clientIp", i.extractClientIp(ctx, md))
+	newCtx = ctxutil.AddValue(newCtx, "userAgent", strings.Join(md.Get("user-agent"), " "))
+	// reads after a write of the call must not be answered by a lagging read replica
+	newCtx = database.WithReadYourWrites(newCtx)
+
+	err = grpc.SetHeader(ctx, metadata.Pairs(TraceIdHeader, traceId))
+	if err != nil {
+		i.logService.ErrorCtx(newCtx, fmt.Sprintf("error setting the trace id header: %s", err))
+	}
+
+	return handler(newCtx, req)
+}
+
+func (i *Interceptors) reqLoggerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	startTime := time.Now()
+	i.logService.DebugCtx(ctx, fmt.Sprintf("new call: %s", info.FullMethod))
+
+	res, err := handler(ctx, req)
+
+	difference := time.Since(startTime)
+	i.logService.DebugCtx(ctx, fmt.Sprintf("call completed with %s, took %d ms", status.Code(err), difference.Milliseconds()))
+	return res, err
+}
+
+// panicHandlerInterceptor prints the stack trace on panic and returns an internal error
+func (i *Interceptors) panicHandlerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+	defer func() {
+		if recoverRes := recover(); recoverRes != nil {
+			stack := make([]byte, 1024)
+			runtime.Stack(stack, false)
+			i.logService.ErrorCtx(ctx, fmt.Sprintf("recovered from panic: %v\n%s", recoverRes, stack))
+			res, err = nil, newInternalErr()
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// errInterceptor converts the exceptions to statuses, see toStatus
+func (i *Interceptors) errInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	res, err := handler(ctx, req)
+	if err == nil {
+		return res, nil
+	}
+
+	st, isException := toStatus(err)
+	if !isException {
+		i.logService.ErrorCtx(ctx, fmt.Sprintf("unexpected error: %s", err.Error()))
+	}
+
+	return nil, st.Err()
+}
+
+// authInterceptor verifies the jwt sent as "Bearer <jwt>" in the authorization metadata of the calls to authMethods,
+// and adds its payload to the context like the authMiddleware of the restapi
+func (i *Interceptors) authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !i.authMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	jwtPayload, err := i.authFacade.VerifyJwt(ctx, i.extractBearerToken(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctxutil.AddValue(ctx, "jwtPayload", jwtPayload), req)
+}
+
+func (i *Interceptors) extractBearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authHeaders := md.Get("authorization")
+	if len(authHeaders) == 0 {
+		i.logService.DebugCtx(ctx, "empty authorization metadata")
+		return ""
+	}
+
+	jwt, prefixExists := strings.CutPrefix(authHeaders[0], "Bearer ")
+	if !prefixExists {
+		i.logService.DebugCtx(ctx, "token does not start with 'Bearer '")
+		return ""
+	}
+
+	return jwt
+}
+
+// extractClientIp returns the address of the peer, or the client address of the x-forwarded-for metadata when the peer
+// is a trusted proxy
+func (i *Interceptors) extractClientIp(ctx context.Context, md metadata.MD) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return netutil.GetClientIp(p.Addr.String(), strings.Join(md.Get("x-forwarded-for"), ","), i.trustedProxies)
+}
+
+// toStatus maps the exceptions to the codes the restapi maps them to status codes, the type and details of the
+// exception are sent in an ErrorInfo detail. isException is false for unexpected errors, which are hidden behind an
+// internal error.
+func toStatus(err error) (st *status.Status, isException bool) {
+	switch e := err.(type) {
+	case exception.InvalidReq:
+		return newStatus(codes.InvalidArgument, e.Base), true
+	case exception.NotFound:
+		return newStatus(codes.NotFound, e.Base), true
+	case exception.Unauthenticated:
+		return newStatus(codes.Unauthenticated, e.Base), true
+	case exception.Unauthorized:
+		return newStatus(codes.PermissionDenied, e.Base), true
+	case exception.AlreadyExists:
+		return newStatus(codes.AlreadyExists, e.Base), true
+	case exception.FailedPrecondition:
+		return newStatus(codes.FailedPrecondition, e.Base), true
+	}
+
+	// statuses returned by the interceptors are already final
+	if st, ok := status.FromError(err); ok {
+		return st, true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return newStatus(codes.DeadlineExceeded, &exception.Base{Type: "DEADLINE_EXCEEDED", Message: "request timed out"}), true
+	}
+	if errors.Is(err, context.Canceled) {
+		return newStatus(codes.Canceled, &exception.Base{Type: "CANCELED", Message: "request canceled"}), true
+	}
+
+	return status.Convert(newInternalErr()), false
+}
+
+func newStatus(code codes.Code, base *exception.Base) *status.Status {
+	st := status.New(code, base.Message)
+	errInfo := &errdetails.ErrorInfo{Reason: base.Type, Domain: ErrorDomain}
+	if base.Details != nil {
+		errInfo.Metadata = map[string]string{}
+		for key, val := range *base.Details {
+			// the invalid fields are named like their JSON keys, the restapi does the same
+			errInfo.Metadata[strutil.PascalCaseToCamelCase(key)] = val
+		}
+	}
+
+	withDetails, err := st.WithDetails(errInfo)
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// newInternalErr hides the unexpected errors from the client, like the 500 responses of the restapi
+func newInternalErr() error {
+	return newStatus(codes.Internal, &exception.Base{Type: "INTERNAL", Message: "internal server error"}).Err()
+}
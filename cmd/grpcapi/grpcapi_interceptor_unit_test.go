@@ -0,0 +1,99 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_ToStatus(t *testing.T) {
+	details := map[string]string{"Email": "validation failed for tag: 'email'"}
+	testCases := []struct {
+		err         error
+		code        codes.Code
+		reason      string
+		isException bool
+	}{
+		{exception.NewInvalidReqFromBase(exception.Base{Details: &details}), codes.InvalidArgument, "REQUEST_DATA.INVALID", true},
+		{exception.NewNotFound(), codes.NotFound, "RESOURCE.NOT_FOUND", true},
+		{exception.NewUnauthenticated(), codes.Unauthenticated, "UNAUTHENTICATED", true},
+		{exception.NewUnauthorized(exception.Base{}), codes.PermissionDenied, "UNAUTHORIZED", true},
+		{exception.NewAlreadyExistsFromBase(exception.Base{}), codes.AlreadyExists, "RESOURCE.ALREADY_EXISTS", true},
+		{exception.NewFailedPreconditionFromBase(exception.Base{}), codes.FailedPrecondition, "FAILED_PRECONDITION", true},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), codes.DeadlineExceeded, "DEADLINE_EXCEEDED", true},
+		{fmt.Errorf("connection refused"), codes.Internal, "INTERNAL", false},
+	}
+
+	for _, testCase := range testCases {
+		// ACT
+		res, isExceptionRes := toStatus(testCase.err)
+
+		// ASSERT
+		assert.Equal(t, testCase.code, res.Code())
+		assert.Equal(t, testCase.isException, isExceptionRes)
+		assert.Equal(t, testCase.reason, res.Details()[0].(*errdetails.ErrorInfo).GetReason())
+	}
+}
+
+func Test_ToStatus_Should_Camelcase_Details(t *testing.T) {
+	// ARRANGE
+	details := map[string]string{"Password": "password not strong"}
+
+	// ACT
+	res, _ := toStatus(exception.NewInvalidReqFromBase(exception.Base{Details: &details}))
+
+	// ASSERT
+	assert.Equal(t, map[string]string{"password": "password not strong"}, res.Details()[0].(*errdetails.ErrorInfo).GetMetadata())
+}
+
+func Test_Interceptors_Should_Recover_From_Panic(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	logServiceMock.On("ErrorCtx", mock.Anything, mock.Anything)
+	interceptors := NewInterceptors(logServiceMock, new(auth.FacadeMock), nil, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"}
+
+	// ACT
+	res, errRes := interceptors.panicHandlerInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+
+	// ASSERT
+	assert.Nil(t, res)
+	assert.Equal(t, codes.Internal, status.Code(errRes))
+	assert.Equal(t, "internal server error", status.Convert(errRes).Message())
+	logServiceMock.AssertCalled(t, "ErrorCtx", mock.Anything, mock.Anything)
+}
+
+func Test_Interceptors_Should_Only_Authenticate_Auth_Methods(t *testing.T) {
+	// ARRANGE
+	authFacadeMock := new(auth.FacadeMock)
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	interceptors := NewInterceptors(logServiceMock, authFacadeMock, []string{"/user.v1.UserService/GetProfile"}, nil)
+	handler := func(ctx context.Context, req any) (any, error) { return "res", nil }
+
+	authFacadeMock.On("VerifyJwt", mock.Anything, "").Return(jwt.JwtPayload{}, exception.NewUnauthenticated())
+
+	// ACT
+	publicRes, errPublicRes := interceptors.authInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/RegisterUser"}, handler)
+	_, errAuthRes := interceptors.authInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"}, handler)
+
+	// ASSERT
+	assert.Nil(t, errPublicRes)
+	assert.Equal(t, "res", publicRes)
+	assert.Equal(t, exception.NewUnauthenticated(), errAuthRes)
+	authFacadeMock.AssertNumberOfCalls(t, "VerifyJwt", 1)
+}
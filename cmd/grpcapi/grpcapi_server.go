@@ -0,0 +1,36 @@
+package grpcapi
+
+import (
+	"net"
+
+	authv1 "github.com/pjmessi/golang-practice/internal/pb/auth/v1"
+	userv1 "github.com/pjmessi/golang-practice/internal/pb/user/v1"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// authMethods are the methods which require a jwt, like the routes of the restapi with authentication
+var authMethods = []string{
+	userv1.UserService_GetProfile_FullMethodName,
+}
+
+// NewServer returns the gRPC server of the auth and user services along with the health service, reflection lets
+// clients like grpcurl discover them. The x-forwarded-for metadata is only trusted when the peer is one of trustedProxies.
+func NewServer(logService logger.Service, db database.Db, authFacade auth.Facade, userFacade user.Facade, trustedProxies []*net.IPNet) *grpc.Server {
+	server := grpc.NewServer(NewInterceptors(logService, authFacade, authMethods, trustedProxies).Chain())
+
+	authv1.RegisterAuthServiceServer(server, NewAuthServer(authFacade))
+	userv1.RegisterUserServiceServer(server, NewUserServer(userFacade))
+	healthpb.RegisterHealthServer(server, NewHealthServer(logService, db, []string{
+		authv1.AuthService_ServiceDesc.ServiceName,
+		userv1.UserService_ServiceDesc.ServiceName,
+	}))
+	reflection.Register(server)
+
+	return server
+}
@@ -0,0 +1,106 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	authv1 "github.com/pjmessi/golang-practice/internal/pb/auth/v1"
+	userv1 "github.com/pjmessi/golang-practice/internal/pb/user/v1"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/structutil"
+)
+
+// the services convert the messages to the JSON requests of the facades and their JSON responses back, so that the
+// validation, the exceptions and the responses stay the same as the ones of the restapi
+
+type AuthServer struct {
+	authv1.UnimplementedAuthServiceServer
+	authFacade auth.Facade
+}
+
+func NewAuthServer(authFacade auth.Facade) *AuthServer {
+	return &AuthServer{authFacade: authFacade}
+}
+
+func (s *AuthServer) Login(ctx context.Context, req *authv1.LoginRequest) (*authv1.LoginResponse, error) {
+	var res model.LoginApiRes
+	err := callFacade(ctx, s.authFacade.Login, model.LoginApiReq{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		OrgId:    req.OrgId,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authv1.LoginResponse{User: toPbUser(res.User), Jwt: res.Jwt}, nil
+}
+
+type UserServer struct {
+	userv1.UnimplementedUserServiceServer
+	userFacade user.Facade
+}
+
+func NewUserServer(userFacade user.Facade) *UserServer {
+	return &UserServer{userFacade: userFacade}
+}
+
+func (s *UserServer) RegisterUser(ctx context.Context, req *userv1.RegisterUserRequest) (*userv1.RegisterUserResponse, error) {
+	var res model.UserRegApiRes
+	err := callFacade(ctx, s.userFacade.RegisterUser, model.UserRegApiReq{
+		Email:      req.GetEmail(),
+		Password:   req.GetPassword(),
+		InviteCode: req.InviteCode,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.RegisterUserResponse{User: toPbUser(res.User)}, nil
+}
+
+func (s *UserServer) GetProfile(ctx context.Context, req *userv1.GetProfileRequest) (*userv1.GetProfileResponse, error) {
+	jwtPayload, ok := ctxutil.GetValue(ctx, "jwtPayload").(jwt.JwtPayload)
+	if !ok {
+		return nil, exception.NewUnauthenticated()
+	}
+
+	var res model.GetProfileApiRes
+	err := callFacade(ctx, func(ctx context.Context, reqBytes []byte) ([]byte, error) {
+		return s.userFacade.GetProfile(ctx, reqBytes, jwtPayload)
+	}, struct{}{}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.GetProfileResponse{User: toPbUser(res.User)}, nil
+}
+
+// callFacade calls facadeFunc with req encoded to JSON and decodes its response into res
+func callFacade(ctx context.Context, facadeFunc func(ctx context.Context, reqBytes []byte) ([]byte, error), req any, res any) error {
+	reqBytes, err := structutil.ConvertToBytes(req)
+	if err != nil {
+		return err
+	}
+
+	resBytes, err := facadeFunc(ctx, reqBytes)
+	if err != nil {
+		return err
+	}
+
+	return structutil.ConvertFromBytes(resBytes, res)
+}
+
+func toPbUser(user model.UserRes) *userv1.User {
+	return &userv1.User{
+		Id:        user.Id,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		CreatedAt: user.CreatedAt,
+	}
+}
@@ -0,0 +1,93 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/migration"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+)
+
+const usage = "usage: migrate up | down [steps] | status"
+
+// StartApp runs the migrate command, args are the ones following "migrate" in the command line
+func StartApp(args []string) {
+	if len(args) == 0 {
+		log.Fatal(usage)
+	}
+
+	appConfig := config.GetAppConfig("")
+	logService := logger.NewService()
+
+	dbCon, err := database.Open(appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer dbCon.Close()
+
+	runner, err := migration.NewEmbeddedRunner(logService, dbCon, database.GetDriver(appConfig))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("applied %d migration(s)\n", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps '%s', %s", args[1], usage)
+			}
+		}
+
+		reverted, err := runner.Down(ctx, steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("reverted %d migration(s)\n", len(reverted))
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printStatus(statuses)
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func printStatus(statuses []migration.Status) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+
+	for _, status := range statuses {
+		state := "pending"
+		appliedAt := "-"
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.ChecksumMismatch {
+			state = "applied (checksum mismatch)"
+		}
+
+		fmt.Fprintf(writer, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+
+	writer.Flush()
+}
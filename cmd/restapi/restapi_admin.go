@@ -0,0 +1,20 @@
+package restapi
+
+import (
+	"expvar"
+	"net/http"
+)
+
+const defaultAdminPort = "6060"
+
+// adminHost is the only interface the admin listener is bound to, the variables include the command line of the
+// process and must not be reachable from the network
+const adminHost = "127.0.0.1"
+
+// NewAdminHandler serves the expvar variables, the statistics of the database pools are published as "database" and
+// the metrics of the outbox relay as "outbox"
+func NewAdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
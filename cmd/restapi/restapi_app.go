@@ -1,28 +1,54 @@
 package restapi
 
 import (
+	"context"
 	"errors"
+	"expvar"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/pjmessi/golang-practice/config"
 	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
 	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/pkg/migration"
 	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/internal/service/idempotency"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/internal/service/organization"
+	"github.com/pjmessi/golang-practice/internal/service/outbox"
+	"github.com/pjmessi/golang-practice/internal/service/security"
 	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/internal/service/webhook"
 	"github.com/pjmessi/golang-practice/pkg/logger"
 	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/netutil"
 	"github.com/pjmessi/golang-practice/pkg/validation"
 )
 
-func StartApp() {
+// natsDrainTimeout is how long the NATS handlers may take to finish their messages on shutdown
+const natsDrainTimeout = 30 * time.Second
+
+// StartApp runs the restapi, args are the ones following the program name in the command line
+func StartApp(args []string) {
+	flags := flag.NewFlagSet("restapi", flag.ExitOnError)
+	dev := flags.Bool("dev", false, "run without external services, on an in memory sqlite database and an embedded NATS server")
+	_ = flags.Parse(args)
+
 	appConfig := config.GetAppConfig("")
+	if *dev {
+		applyDevConfig(appConfig)
+	}
 
-	// initialize database connection
+	// initialize database connection, it is opened before migrating so that an in memory sqlite database outlives the
+	// connection of the migration runner
 	db, err := database.NewDb(appConfig)
 	if err != nil {
 		log.Fatal(err)
@@ -32,6 +58,17 @@ func StartApp() {
 		log.Fatalf(err.Error())
 	}
 	defer db.CloseConnection()
+	expvar.Publish("database", expvar.Func(func() any {
+		return db.PoolStats()
+	}))
+
+	// apply pending migrations before serving requests
+	if appConfig.DB_AUTO_MIGRATE == "true" {
+		err = runMigrations(appConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// initialize common services
 	logService := logger.NewService()
@@ -45,20 +82,60 @@ func StartApp() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	userService := user.NewService(logService, db)
-	authService := auth.NewService(logService, jwtHandler, db)
-	natsService, err := nats.NewPubService(appConfig, logService)
+	if appConfig.NATS_EMBEDDED == "true" {
+		embeddedServer, err := nats.StartEmbeddedServer(logService)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer embeddedServer.Shutdown()
+		appConfig.NATS_URL = embeddedServer.Url()
+	}
+	natsService, err := nats.NewService(appConfig, logService)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer natsService.Close()
+	eventRegistry, err := eventtype.NewRegistry()
+	if err != nil {
+		log.Fatal(err)
+	}
+	outboxService := outbox.NewService(logService, db, eventRegistry)
+	outboxRelay, err := outbox.NewRelay(appConfig, logService, db, natsService)
+	if err != nil {
+		log.Fatal(err)
+	}
+	expvar.Publish("outbox", expvar.Func(func() any {
+		return outboxRelay.Metrics()
+	}))
+	idempotencyService, err := idempotency.NewService(appConfig, logService, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	inviteService := invite.NewService(logService, db)
+	userService := user.NewService(appConfig, logService, db, inviteService, outboxService)
+	orgService := organization.NewService(logService, db, inviteService)
+	securityService := security.NewService(appConfig, logService, db, outboxService)
+	authService := auth.NewService(logService, jwtHandler, db, securityService)
+	webhookService := webhook.NewService(appConfig, logService, db, eventRegistry)
+	webhookWorker, err := webhook.NewWorker(appConfig, logService, db)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// initialize facades
-	userFacade := user.NewFacade(appConfig, logService, userService, validationHandler, natsService)
+	userFacade := user.NewFacade(logService, userService, validationHandler)
 	authFacade := auth.NewFacade(logService, authService, validationHandler)
+	inviteFacade := invite.NewFacade(logService, inviteService, validationHandler)
+	orgFacade := organization.NewFacade(logService, orgService, validationHandler)
+	securityFacade := security.NewFacade(logService, securityService, validationHandler)
+	webhookFacade := webhook.NewFacade(logService, webhookService, validationHandler)
 
 	// register REST API routes
-	router := RegisterRoutes(logService, authFacade, userFacade)
+	trustedProxies, err := netutil.ParseTrustedProxies(appConfig.TRUSTED_PROXIES)
+	if err != nil {
+		log.Fatal(err)
+	}
+	router := RegisterRoutes(logService, authFacade, userFacade, inviteFacade, orgFacade, securityFacade, webhookFacade, NewHealthHandler(logService, db, natsService), trustedProxies)
 
 	// start HTTP server
 	port := appConfig.APP_PORT
@@ -75,14 +152,52 @@ func StartApp() {
 		}
 	}()
 
-	// start NATS consumer
+	// start the admin server, it is only reachable from the host
+	adminPort := appConfig.ADMIN_PORT
+	if adminPort == "" {
+		adminPort = defaultAdminPort
+	}
+	adminServer := &http.Server{Addr: net.JoinHostPort(adminHost, adminPort), Handler: NewAdminHandler()}
 	go func() {
-		err := natsService.Subscribe(appConfig.NATS_STREAM)
+		logService.Debug(fmt.Sprintf("starting admin HTTP server on %s", adminServer.Addr))
+		err := adminServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logService.Error(fmt.Sprintf("error while starting admin HTTP server: %v", err))
+		}
+	}()
+
+	// start publishing the outbox to NATS, deleting the expired processed messages and delivering the webhooks
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go outboxRelay.Run(workerCtx)
+	go idempotencyService.Run(workerCtx)
+	go webhookWorker.Run(workerCtx)
+
+	// start NATS consumers
+	handlerRegistry := nats.NewRegistry()
+	handlers := user.NewEventHandlers(appConfig, logService, eventRegistry, idempotencyService)
+	handlers = append(handlers, webhook.NewEventHandlers(appConfig, eventRegistry, webhookService)...)
+	for _, handler := range handlers {
+		err = handlerRegistry.Register(handler)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	go func() {
+		err := natsService.Subscribe(appConfig.NATS_STREAM, handlerRegistry)
 		if err != nil {
 			logService.Error(err.Error())
 		}
 	}()
 
+	// answer the user lookups of other services
+	for _, responder := range user.NewRpcResponders(appConfig, logService, validationHandler, userService) {
+		err = natsService.Respond(responder)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// stop http server gracefully
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
@@ -91,4 +206,32 @@ func StartApp() {
 	if err != nil {
 		log.Fatal(fmt.Errorf("error closing HTTP server: %w", err))
 	}
+	err = adminServer.Close()
+	if err != nil {
+		logService.Error(fmt.Sprintf("error closing admin HTTP server: %v", err))
+	}
+
+	// let the NATS handlers finish the messages and requests they are processing
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), natsDrainTimeout)
+	defer cancelDrain()
+	err = natsService.Drain(drainCtx)
+	if err != nil {
+		logService.Error(err.Error())
+	}
+}
+
+func runMigrations(appConfig *config.AppConfig) error {
+	dbCon, err := database.Open(appConfig)
+	if err != nil {
+		return err
+	}
+	defer dbCon.Close()
+
+	runner, err := migration.NewEmbeddedRunner(logger.NewService(), dbCon, database.GetDriver(appConfig))
+	if err != nil {
+		return err
+	}
+
+	_, err = runner.Up(context.Background())
+	return err
 }
@@ -0,0 +1,18 @@
+package restapi
+
+import (
+	"github.com/pjmessi/golang-practice/config"
+)
+
+// applyDevConfig makes the restapi run without external services for "--dev". The database is an in memory sqlite
+// database, unless sqlite is already configured, migrated on start and NATS is an embedded server, so the data only
+// lives as long as the process. Webhooks may target local endpoints.
+func applyDevConfig(appConfig *config.AppConfig) {
+	if appConfig.DB_DRIVER != "sqlite" {
+		appConfig.DB_DRIVER = "sqlite"
+		appConfig.DB_DATABASE = ":memory:"
+	}
+	appConfig.DB_AUTO_MIGRATE = "true"
+	appConfig.NATS_EMBEDDED = "true"
+	appConfig.WEBHOOK_ALLOW_PRIVATE_NETWORKS = "true"
+}
@@ -4,17 +4,22 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
 	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
 	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/internal/service/organization"
 	"github.com/pjmessi/golang-practice/internal/service/user"
 	"github.com/pjmessi/golang-practice/pkg/ctxutil"
 	"github.com/pjmessi/golang-practice/pkg/exception"
 	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/netutil"
 	"github.com/pjmessi/golang-practice/pkg/structutil"
 	"github.com/pjmessi/golang-practice/pkg/strutil"
 	"github.com/pjmessi/golang-practice/pkg/uuidutil"
@@ -22,19 +27,24 @@ import (
 
 type FacadeApiFunc func(ctx context.Context, reqBytes []byte) ([]byte, error)
 type FacadeApiFuncWithAuth func(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error)
+type FacadeApiFuncWithOrg func(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
 type ErrRes exception.Base
 
 type RouteHandler struct {
-	authFacade auth.Facade
-	userFacade user.Facade
-	logService logger.Service
+	authFacade     auth.Facade
+	userFacade     user.Facade
+	orgFacade      organization.Facade
+	logService     logger.Service
+	trustedProxies []*net.IPNet
 }
 
-func NewRouteHandler(logService logger.Service, authFacade auth.Facade, userFacade user.Facade) *RouteHandler {
+func NewRouteHandler(logService logger.Service, authFacade auth.Facade, userFacade user.Facade, orgFacade organization.Facade, trustedProxies []*net.IPNet) *RouteHandler {
 	return &RouteHandler{
-		authFacade: authFacade,
-		userFacade: userFacade,
-		logService: logService,
+		authFacade:     authFacade,
+		userFacade:     userFacade,
+		orgFacade:      orgFacade,
+		logService:     logService,
+		trustedProxies: trustedProxies,
 	}
 }
 
@@ -42,7 +52,7 @@ func (rh *RouteHandler) handlePublicApi(facadeFunc FacadeApiFunc) http.HandlerFu
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		reqBytes, err := io.ReadAll(r.Body)
+		reqBytes, err := rh.readReqBytes(r)
 		if err != nil {
 			rh.writeHttpResFromErr(ctx, w, err)
 			return
@@ -81,7 +91,7 @@ func (rh *RouteHandler) handlePrivateApi(facadeFunc FacadeApiFuncWithAuth) http.
 			return
 		}
 
-		reqBytes, err := io.ReadAll(r.Body)
+		reqBytes, err := rh.readReqBytes(r)
 		if err != nil {
 			rh.writeHttpResFromErr(ctx, w, err)
 			return
@@ -109,9 +119,27 @@ func (rh *RouteHandler) handlePrivateApi(facadeFunc FacadeApiFuncWithAuth) http.
 	}
 }
 
-func (rh *RouteHandler) attachMiddlewares(handlerFunc http.HandlerFunc, authenticate bool) http.HandlerFunc {
+func (rh *RouteHandler) handleOrgApi(facadeFunc FacadeApiFuncWithOrg) http.HandlerFunc {
+	return rh.handlePrivateApi(func(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+		membership, ok := ctxutil.GetValue(ctx, "membership").(model.Membership)
+		if !ok {
+			rh.logService.DebugCtx(ctx, "restapi.RouteHandler.handleOrgApi(): membership not set in context")
+			return nil, exception.NewUnauthenticated()
+		}
+
+		return facadeFunc(ctx, reqBytes, jwtPayload, membership)
+	})
+}
+
+// attachMiddlewares wraps the handler with the common middlewares, scopeToOrg requires authenticate to be true as the
+// active organization is read from the jwt
+func (rh *RouteHandler) attachMiddlewares(handlerFunc http.HandlerFunc, authenticate bool, scopeToOrg bool) http.HandlerFunc {
 	handler := http.Handler(handlerFunc)
 
+	if scopeToOrg {
+		handler = rh.orgMiddleware(handler)
+	}
+
 	if authenticate {
 		handler = rh.authMiddleware(handler)
 	}
@@ -132,6 +160,10 @@ func (rh *RouteHandler) ctxMiddleware(next http.Handler) http.Handler {
 			return
 		}
 		ctx := ctxutil.NewCtxWithTraceId(traceId)
+		ctx = ctxutil.AddValue(ctx, "clientIp", rh.extractClientIp(r))
+		ctx = ctxutil.AddValue(ctx, "userAgent", r.UserAgent())
+		// reads after a write of the request must not be answered by a lagging read replica
+		ctx = database.WithReadYourWrites(ctx)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -189,6 +221,31 @@ func (rh *RouteHandler) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// orgMiddleware scopes the request to the organization selected in the jwt, it makes sure the user is still a member of
+// the organization and adds the membership to the context
+func (rh *RouteHandler) orgMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		jwtPayload, ok := ctxutil.GetValue(ctx, "jwtPayload").(jwt.JwtPayload)
+		if !ok {
+			rh.logService.DebugCtx(ctx, "restapi.RouteHandler.orgMiddleware(): jwtPayload not set in context")
+			rh.writeHttpResFromErr(ctx, w, exception.NewUnauthenticated())
+			return
+		}
+
+		membership, err := rh.orgFacade.VerifyMembership(ctx, jwtPayload)
+		if err != nil {
+			rh.writeHttpResFromErr(ctx, w, err)
+			return
+		}
+
+		ctx = ctxutil.AddValue(ctx, "membership", membership)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (rh *RouteHandler) writeHttpResFromErr(ctx context.Context, w http.ResponseWriter, err error) {
 	switch e := err.(type) {
 	case exception.InvalidReq:
@@ -266,6 +323,29 @@ func (rh *RouteHandler) extractBearerToken(ctx context.Context, r *http.Request)
 	return jwt
 }
 
+// readReqBytes returns the request body, GET and DELETE requests have no body so their query params are converted to a
+// JSON object of strings instead
+func (rh *RouteHandler) readReqBytes(r *http.Request) ([]byte, error) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		return io.ReadAll(r.Body)
+	}
+
+	queryParams := map[string]string{}
+	for key, values := range r.URL.Query() {
+		if len(values) > 0 {
+			queryParams[key] = values[0]
+		}
+	}
+
+	return structutil.ConvertToBytes(queryParams)
+}
+
+// extractClientIp returns the remote address of the connection, or the client address of the X-Forwarded-For header
+// when the connection comes from a trusted proxy
+func (rh *RouteHandler) extractClientIp(r *http.Request) string {
+	return netutil.GetClientIp(r.RemoteAddr, strings.Join(r.Header.Values("X-Forwarded-For"), ","), rh.trustedProxies)
+}
+
 func (rh *RouteHandler) handleRouteNotFound() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
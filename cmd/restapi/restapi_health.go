@@ -0,0 +1,74 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/structutil"
+)
+
+const healthUp = "UP"
+const healthDown = "DOWN"
+
+type HealthRes struct {
+	// Status is DOWN when the database or NATS is down
+	Status   string     `json:"status"`
+	Database DbHealth   `json:"database"`
+	Nats     NatsHealth `json:"nats"`
+}
+
+type DbHealth struct {
+	Status string `json:"status"`
+}
+
+type NatsHealth struct {
+	Status string `json:"status"`
+}
+
+// NewHealthHandler reports the state of the database and of the NATS connection, the status code is 503 when one of
+// them is down so that the instance can be taken out of rotation
+func NewHealthHandler(logService logger.Service, db database.Db, natsService nats.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		res := HealthRes{
+			Status:   healthUp,
+			Database: DbHealth{Status: healthUp},
+			Nats:     NatsHealth{Status: healthUp},
+		}
+
+		err := db.CheckHealth()
+		if err != nil {
+			// the error is only logged as it may describe the infrastructure
+			logService.ErrorCtx(ctx, fmt.Sprintf("restapi.NewHealthHandler(): %s", err))
+			res.Database.Status = healthDown
+			res.Status = healthDown
+		}
+		natsStatus := natsService.Status()
+		if !natsStatus.Connected {
+			// the url and the last error of the connection are only logged for the same reason
+			logService.ErrorCtx(ctx, fmt.Sprintf("restapi.NewHealthHandler(): NATS connection is %s, last error: %s", natsStatus.Status, natsStatus.LastError))
+			res.Nats.Status = healthDown
+			res.Status = healthDown
+		}
+
+		resBytes, err := structutil.ConvertToBytes(res)
+		if err != nil {
+			logService.ErrorCtx(ctx, fmt.Sprintf("restapi.NewHealthHandler(): %s", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if res.Status != healthUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, err = w.Write(resBytes)
+		if err != nil {
+			logService.ErrorCtx(ctx, fmt.Sprintf("restapi.NewHealthHandler(): %s", err))
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/openapi"
+	"github.com/pjmessi/golang-practice/pkg/structutil"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const openApiTitle = "golang-practice"
+const openApiVersion = "1.0.0"
+const bearerSecurityScheme = "bearerAuth"
+
+// NewOpenApiDocument documents the routes of the registry, the schemas are generated from the request and response
+// models. Besides the errors of its facade func, a route documents
+//   - 422 when it takes a request, for the validation errors
+//   - 401 when it is authenticated
+//   - 400 and 403 when it is scoped to the organization of the jwt
+//   - 500 for the unexpected errors
+//
+// all of them with the ErrRes schema.
+func NewOpenApiDocument(routes []route) *openapi.Document {
+	generator := openapi.NewGenerator()
+	errResSchema := generator.ResponseSchema(ErrRes{})
+
+	document := &openapi.Document{
+		OpenApi: openapi.Version,
+		Info:    openapi.Info{Title: openApiTitle, Version: openApiVersion},
+		Paths:   map[string]openapi.PathItem{},
+		Components: openapi.Components{
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				bearerSecurityScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, r := range routes {
+		operation := &openapi.Operation{
+			Tags:        []string{r.tag},
+			Summary:     r.summary,
+			OperationId: getOperationId(r.method, r.path),
+			Responses: map[string]*openapi.Response{
+				strconv.Itoa(http.StatusOK): newOpenApiResponse(http.StatusOK, generator.ResponseSchema(r.res)),
+			},
+		}
+
+		errStatusCodes := append([]int{http.StatusInternalServerError}, r.errStatusCodes...)
+		if r.req != nil {
+			if r.method == http.MethodGet || r.method == http.MethodDelete {
+				operation.Parameters = generator.QueryParameters(r.req)
+			} else {
+				operation.RequestBody = &openapi.RequestBody{
+					Required: true,
+					Content:  map[string]openapi.MediaType{openapi.ContentType: {Schema: generator.RequestSchema(r.req)}},
+				}
+			}
+			errStatusCodes = append(errStatusCodes, http.StatusUnprocessableEntity)
+		}
+		if r.authenticate() {
+			operation.Security = []map[string][]string{{bearerSecurityScheme: {}}}
+			errStatusCodes = append(errStatusCodes, http.StatusUnauthorized)
+		}
+		if r.scopeToOrg() {
+			errStatusCodes = append(errStatusCodes, http.StatusBadRequest, http.StatusForbidden)
+		}
+		for _, statusCode := range errStatusCodes {
+			operation.Responses[strconv.Itoa(statusCode)] = newOpenApiResponse(statusCode, errResSchema)
+		}
+
+		if document.Paths[r.path] == nil {
+			document.Paths[r.path] = openapi.PathItem{}
+		}
+		document.Paths[r.path][strings.ToLower(r.method)] = operation
+	}
+
+	document.Components.Schemas = generator.Schemas()
+	return document
+}
+
+func newOpenApiResponse(statusCode int, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{
+		Description: http.StatusText(statusCode),
+		Content:     map[string]openapi.MediaType{openapi.ContentType: {Schema: schema}},
+	}
+}
+
+// getOperationId joins the method and the segments of the path, e.g. postAuthLogin for POST /auth/login
+func getOperationId(method string, path string) string {
+	operationId := strings.ToLower(method)
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' }) {
+		operationId += strings.ToUpper(segment[:1]) + segment[1:]
+	}
+	return operationId
+}
+
+// NewOpenApiHandler serves the document, it is converted once as it does not change while the app runs
+func NewOpenApiHandler(logService logger.Service, document *openapi.Document) http.HandlerFunc {
+	documentBytes, err := structutil.ConvertToBytes(document)
+	if err != nil {
+		logService.Error(fmt.Sprintf("err while converting the OpenAPI document to bytes: %v", err))
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", openapi.ContentType)
+		_, writeErr := w.Write(documentBytes)
+		if writeErr != nil {
+			logService.Error(fmt.Sprintf("err while writing the OpenAPI document: %v", writeErr))
+		}
+	}
+}
+
+// docsAssets are the Swagger UI files the docs page loads, they are embedded in the binary and served from the origin
+// of the restapi
+var docsAssets = map[string]bool{
+	"swagger-ui.css":       true,
+	"swagger-ui-bundle.js": true,
+	"favicon-16x16.png":    true,
+	"favicon-32x32.png":    true,
+}
+
+// docsCsp only lets the docs page run the scripts and styles served by the restapi
+const docsCsp = "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; script-src 'self'; object-src 'none'; base-uri 'none'; frame-ancestors 'none'"
+
+// NewDocsHandler serves Swagger UI rendering /openapi.json at /docs, the assets are served under /docs/ from the
+// embedded Swagger UI distribution so the page runs no third party script
+func NewDocsHandler() http.Handler {
+	page := []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%s API</title>
+  <link rel="icon" type="image/png" href="/docs/favicon-32x32.png" sizes="32x32">
+  <link rel="stylesheet" href="/docs/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/docs/swagger-ui-bundle.js"></script>
+  <script src="/docs/swagger-initializer.js"></script>
+</body>
+</html>
+`, openApiTitle))
+	initializer := []byte(`window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});` + "\n")
+	assetServer := http.StripPrefix("/docs/", http.FileServer(http.FS(swaggerFiles.FS)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", docsCsp)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		switch asset := strings.TrimPrefix(r.URL.Path, "/docs/"); {
+		case r.URL.Path == "/docs":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write(page)
+		case asset == "swagger-initializer.js":
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			_, _ = w.Write(initializer)
+		case docsAssets[asset]:
+			assetServer.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
@@ -1,26 +1,107 @@
 package restapi
 
 import (
+	"net"
 	"net/http"
 
+	"github.com/pjmessi/golang-practice/internal/model"
 	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/internal/service/organization"
+	"github.com/pjmessi/golang-practice/internal/service/security"
 	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/internal/service/webhook"
 	"github.com/pjmessi/golang-practice/pkg/logger"
 
 	"github.com/gorilla/mux"
 )
 
-func RegisterRoutes(logService logger.Service, authFacade auth.Facade, userFacade user.Facade) http.Handler {
+// route is an entry of the route registry, the routes are registered on the router and documented in the OpenAPI
+// document from the same entries. Exactly one of the facade funcs is set, it decides the middlewares of the route.
+type route struct {
+	method  string
+	path    string
+	tag     string
+	summary string
+	// req is the request model, it is read from the query params for GET and DELETE routes, nil when the route takes no
+	// request
+	req any
+	res any
+	// errStatusCodes are the status codes of the errors of the facade func, the errors of the middlewares and of the
+	// request validation are documented from the kind of route
+	errStatusCodes []int
+
+	publicFunc  FacadeApiFunc
+	privateFunc FacadeApiFuncWithAuth
+	orgFunc     FacadeApiFuncWithOrg
+}
+
+func (r route) authenticate() bool {
+	return r.privateFunc != nil || r.orgFunc != nil
+}
+
+func (r route) scopeToOrg() bool {
+	return r.orgFunc != nil
+}
+
+func newRoutes(authFacade auth.Facade, userFacade user.Facade, inviteFacade invite.Facade, orgFacade organization.Facade, securityFacade security.Facade, webhookFacade webhook.Facade) []route {
+	return []route{
+		// auth routes
+		{method: "POST", path: "/auth/login", tag: "auth", summary: "Log in with email and password", req: model.LoginApiReq{}, res: model.LoginApiRes{}, errStatusCodes: []int{http.StatusUnauthorized, http.StatusForbidden}, publicFunc: authFacade.Login},
+
+		// user routes
+		{method: "POST", path: "/users/registration", tag: "users", summary: "Register a user", req: model.UserRegApiReq{}, res: model.UserRegApiRes{}, errStatusCodes: []int{http.StatusBadRequest}, publicFunc: userFacade.RegisterUser},
+		{method: "GET", path: "/users/profile", tag: "users", summary: "Get the profile of the logged in user", res: model.GetProfileApiRes{}, privateFunc: userFacade.GetProfile},
+		{method: "GET", path: "/users/me/security-events", tag: "users", summary: "List the login attempts of the logged in user", req: model.GetSecurityEventsApiReq{}, res: model.GetSecurityEventsApiRes{}, privateFunc: securityFacade.GetSecurityEvents},
+
+		// invite routes
+		{method: "POST", path: "/invites", tag: "invites", summary: "Create a registration invite", req: model.CreateInviteApiReq{}, res: model.CreateInviteApiRes{}, errStatusCodes: []int{http.StatusBadRequest}, privateFunc: inviteFacade.CreateInvite},
+
+		// organization routes
+		{method: "POST", path: "/organizations", tag: "organizations", summary: "Create an organization", req: model.CreateOrgApiReq{}, res: model.CreateOrgApiRes{}, privateFunc: orgFacade.CreateOrganization},
+		{method: "GET", path: "/organizations", tag: "organizations", summary: "List the organizations of the logged in user", res: model.ListOrgsApiRes{}, privateFunc: orgFacade.ListOrganizations},
+		{method: "POST", path: "/organizations/invitations/accept", tag: "organizations", summary: "Join an organization with an invite code", req: model.AcceptOrgInviteApiReq{}, res: model.AcceptOrgInviteApiRes{}, errStatusCodes: []int{http.StatusBadRequest}, privateFunc: orgFacade.AcceptInvite},
+
+		// routes scoped to the organization selected during login
+		{method: "GET", path: "/organizations/current/members", tag: "organizations", summary: "List the members of the organization", res: model.ListOrgMembersApiRes{}, orgFunc: orgFacade.ListMembers},
+		{method: "POST", path: "/organizations/current/invitations", tag: "organizations", summary: "Invite a member to the organization", req: model.InviteOrgMemberApiReq{}, res: model.InviteOrgMemberApiRes{}, orgFunc: orgFacade.InviteMember},
+		{method: "POST", path: "/organizations/current/webhooks", tag: "webhooks", summary: "Create a webhook", req: model.CreateWebhookApiReq{}, res: model.CreateWebhookApiRes{}, orgFunc: webhookFacade.CreateWebhook},
+		{method: "GET", path: "/organizations/current/webhooks", tag: "webhooks", summary: "List the webhooks", res: model.ListWebhooksApiRes{}, orgFunc: webhookFacade.ListWebhooks},
+		{method: "DELETE", path: "/organizations/current/webhooks", tag: "webhooks", summary: "Delete a webhook", req: model.DeleteWebhookApiReq{}, res: model.DeleteWebhookApiRes{}, errStatusCodes: []int{http.StatusNotFound}, orgFunc: webhookFacade.DeleteWebhook},
+		{method: "GET", path: "/organizations/current/webhooks/deliveries", tag: "webhooks", summary: "List the deliveries of a webhook", req: model.ListWebhookDeliveriesApiReq{}, res: model.ListWebhookDeliveriesApiRes{}, errStatusCodes: []int{http.StatusNotFound}, orgFunc: webhookFacade.ListDeliveries},
+		{method: "GET", path: "/organizations/current/webhooks/deliveries/attempts", tag: "webhooks", summary: "List the attempts of a delivery", req: model.ListWebhookDeliveryAttemptsApiReq{}, res: model.ListWebhookDeliveryAttemptsApiRes{}, errStatusCodes: []int{http.StatusNotFound}, orgFunc: webhookFacade.ListDeliveryAttempts},
+		{method: "POST", path: "/organizations/current/webhooks/deliveries/redeliver", tag: "webhooks", summary: "Send a delivery again", req: model.RedeliverWebhookApiReq{}, res: model.RedeliverWebhookApiRes{}, errStatusCodes: []int{http.StatusNotFound}, orgFunc: webhookFacade.Redeliver},
+	}
+}
+
+func RegisterRoutes(logService logger.Service, authFacade auth.Facade, userFacade user.Facade, inviteFacade invite.Facade, orgFacade organization.Facade, securityFacade security.Facade, webhookFacade webhook.Facade, healthHandler http.Handler, trustedProxies []*net.IPNet) http.Handler {
 	router := mux.NewRouter().StrictSlash(true)
-	rHandler := NewRouteHandler(logService, authFacade, userFacade)
+	rHandler := NewRouteHandler(logService, authFacade, userFacade, orgFacade, trustedProxies)
+
+	routes := newRoutes(authFacade, userFacade, inviteFacade, orgFacade, securityFacade, webhookFacade)
+	for _, r := range routes {
+		var handlerFunc http.HandlerFunc
+		switch {
+		case r.orgFunc != nil:
+			handlerFunc = rHandler.handleOrgApi(r.orgFunc)
+		case r.privateFunc != nil:
+			handlerFunc = rHandler.handlePrivateApi(r.privateFunc)
+		default:
+			handlerFunc = rHandler.handlePublicApi(r.publicFunc)
+		}
+
+		router.HandleFunc(r.path, rHandler.attachMiddlewares(handlerFunc, r.authenticate(), r.scopeToOrg())).Methods(r.method)
+	}
 
-	// auth routes
-	router.HandleFunc("/auth/login", rHandler.attachMiddlewares(rHandler.handlePublicApi(authFacade.Login), false)).Methods("POST")
+	// documentation generated from the route registry
+	router.Handle("/openapi.json", NewOpenApiHandler(logService, NewOpenApiDocument(routes))).Methods("GET")
+	docsHandler := NewDocsHandler()
+	router.Handle("/docs", docsHandler).Methods("GET")
+	router.PathPrefix("/docs/").Handler(docsHandler).Methods("GET")
 
-	// user routes
-	router.HandleFunc("/users/registration", rHandler.attachMiddlewares(rHandler.handlePublicApi(userFacade.RegisterUser), false)).Methods("POST")
-	router.HandleFunc("/users/profile", rHandler.attachMiddlewares(rHandler.handlePrivateApi(userFacade.GetProfile), true)).Methods("GET")
+	// monitoring, the expvar variables are served by the admin listener
+	router.Handle("/health", healthHandler).Methods("GET")
 
-	router.NotFoundHandler = rHandler.attachMiddlewares(rHandler.handleRouteNotFound(), false)
+	router.NotFoundHandler = rHandler.attachMiddlewares(rHandler.handleRouteNotFound(), false, false)
 	return router
 }
@@ -0,0 +1,240 @@
+package stream
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/service/idempotency"
+	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/internal/service/webhook"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+)
+
+const usage = `usage: stream list | consumers [stream] | purge <stream> <subject>
+       stream replay <stream> [--subject <subject>] [--from-seq <seq>] [--to-seq <seq>] [--since <time>] [--until <time>] (--to <subject> | --handler <durable>)
+times are RFC3339 or durations before now like "2h"`
+
+// adminTimeout bounds the commands other than replay, which runs until it is done or interrupted
+const adminTimeout = time.Minute
+
+// StartApp runs the stream command, args are the ones following "stream" in the command line
+func StartApp(args []string) {
+	if len(args) == 0 {
+		log.Fatal(usage)
+	}
+
+	appConfig := config.GetAppConfig("")
+	logService := logger.NewService()
+
+	natsService, err := nats.NewService(appConfig, logService)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer natsService.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args[0] {
+	case "list":
+		ctx, cancel := context.WithTimeout(ctx, adminTimeout)
+		defer cancel()
+
+		streams, err := natsService.ListStreams(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printStreams(streams)
+	case "consumers":
+		stream := appConfig.NATS_STREAM
+		if len(args) > 1 {
+			stream = args[1]
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, adminTimeout)
+		defer cancel()
+
+		consumers, err := natsService.ListConsumers(ctx, stream)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printConsumers(consumers)
+	case "replay":
+		if len(args) < 2 {
+			log.Fatal(usage)
+		}
+
+		replay(ctx, appConfig, logService, natsService, args[1], args[2:])
+	case "purge":
+		if len(args) != 3 {
+			log.Fatal(usage)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, adminTimeout)
+		defer cancel()
+
+		err = natsService.PurgeSubject(ctx, args[1], args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("purged '%s' from '%s'\n", args[2], args[1])
+	default:
+		log.Fatal(usage)
+	}
+}
+
+// replay replays the messages of the stream to a subject, or to a handler of the app without going through its
+// consumer
+func replay(ctx context.Context, appConfig *config.AppConfig, logService logger.Service, natsService nats.Service, stream string, args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	subject := flags.String("subject", "", "only replay the messages of the subject")
+	fromSeq := flags.Uint64("from-seq", 0, "first stream sequence to replay")
+	toSeq := flags.Uint64("to-seq", 0, "last stream sequence to replay")
+	since := flags.String("since", "", "replay the messages stored from this time")
+	until := flags.String("until", "", "replay the messages stored up to this time")
+	target := flags.String("to", "", "subject the messages are published to")
+	durable := flags.String("handler", "", "durable of the handler the messages are handled by")
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	_ = flags.Parse(args)
+
+	if (*target == "") == (*durable == "") || flags.NArg() != 0 {
+		log.Fatal(usage)
+	}
+
+	replayRange := nats.ReplayRange{
+		Subject: *subject,
+		FromSeq: *fromSeq,
+		ToSeq:   *toSeq,
+		Since:   parseTime(*since),
+		Until:   parseTime(*until),
+	}
+
+	var handle nats.HandlerFunc
+	if *target != "" {
+		handle = republishTo(natsService, *target)
+	} else {
+		db, err := database.NewDb(appConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.CloseConnection()
+
+		handler, err := findHandler(appConfig, logService, db, *durable)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if replayRange.Subject == "" {
+			replayRange.Subject = handler.Subject
+		}
+		handle = handler.Handle
+		*target = *durable
+	}
+
+	replayed, err := natsService.Replay(ctx, stream, replayRange, handle)
+	if err != nil {
+		log.Fatalf("replayed %d message(s) before failing: %s", replayed, err)
+	}
+	fmt.Printf("replayed %d message(s) of '%s' to '%s'\n", replayed, stream, *target)
+}
+
+// republishTo publishes the messages to the subject with their headers. Nats-Msg-Id is removed since the stream
+// would drop a message replayed to itself within its duplicate window, the Ce-Id header still lets idempotent
+// handlers recognize the events they have already processed.
+func republishTo(natsService nats.Service, subject string) nats.HandlerFunc {
+	return func(ctx context.Context, msg nats.Msg) error {
+		header := natsgo.Header{}
+		for key, values := range msg.Headers {
+			header[key] = values
+		}
+		header.Del(jetstream.MsgIDHeader)
+
+		return natsService.PublishToStream(ctx, subject, msg.Data, header)
+	}
+}
+
+// findHandler returns the handler of the app consuming with the durable
+func findHandler(appConfig *config.AppConfig, logService logger.Service, db database.Db, durable string) (nats.Handler, error) {
+	eventRegistry, err := eventtype.NewRegistry()
+	if err != nil {
+		return nats.Handler{}, err
+	}
+	idempotencyService, err := idempotency.NewService(appConfig, logService, db)
+	if err != nil {
+		return nats.Handler{}, err
+	}
+
+	handlers := user.NewEventHandlers(appConfig, logService, eventRegistry, idempotencyService)
+	handlers = append(handlers, webhook.NewEventHandlers(appConfig, eventRegistry, webhook.NewService(appConfig, logService, db, eventRegistry))...)
+
+	durables := []string{}
+	for _, handler := range handlers {
+		if handler.Durable == durable {
+			return handler, nil
+		}
+		durables = append(durables, handler.Durable)
+	}
+
+	return nats.Handler{}, fmt.Errorf("handler '%s' does not exist, handlers are %s", durable, strings.Join(durables, ", "))
+}
+
+// parseTime parses an RFC3339 time, or a duration before now
+func parseTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return parsed
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Fatalf("invalid time '%s', %s", value, usage)
+	}
+
+	return time.Now().Add(-duration)
+}
+
+func printStreams(streams []nats.StreamInfo) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "STREAM\tSUBJECTS\tMESSAGES\tBYTES\tFIRST SEQ\tLAST SEQ\tLAST MESSAGE AT\tCONSUMERS")
+
+	for _, stream := range streams {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t%d\n", stream.Name, strings.Join(stream.Subjects, ","), stream.Msgs, stream.Bytes, stream.FirstSeq, stream.LastSeq, formatTime(stream.LastTime), stream.Consumers)
+	}
+
+	writer.Flush()
+}
+
+func printConsumers(consumers []nats.ConsumerInfo) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CONSUMER\tSUBJECT\tLAG\tACK PENDING\tREDELIVERED\tDELIVERED SEQ\tACK FLOOR\tLAST ACTIVE")
+
+	for _, consumer := range consumers {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n", consumer.Name, consumer.FilterSubject, consumer.NumPending, consumer.NumAckPending, consumer.NumRedelivered, consumer.Delivered, consumer.AckFloor, formatTime(consumer.LastActive))
+	}
+
+	writer.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
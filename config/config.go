@@ -8,17 +8,54 @@ import (
 )
 
 type AppConfig struct {
-	APP_PORT                     string
-	DB_HOST                      string
-	DB_PORT                      string
-	DB_DATABASE                  string
-	DB_USER                      string
-	DB_PASSWORD                  string
-	JWT_SECRET                   string
-	JWT_EXPIRATION_TIME          string
-	NATS_URL                     string
-	NATS_STREAM                  string
-	NATS_EVENT_USER_REGISTRATION string
+	APP_PORT                       string
+	GRPC_PORT                      string
+	TRUSTED_PROXIES                string
+	ADMIN_PORT                     string
+	DB_HOST                        string
+	DB_PORT                        string
+	DB_DATABASE                    string
+	DB_USER                        string
+	DB_PASSWORD                    string
+	DB_DRIVER                      string
+	DB_SSL_MODE                    string
+	DB_AUTO_MIGRATE                string
+	DB_QUERY_TIMEOUT               string
+	DB_MAX_OPEN_CONNS              string
+	DB_MAX_IDLE_CONNS              string
+	DB_CONN_MAX_LIFETIME           string
+	DB_CONN_MAX_IDLE_TIME          string
+	DB_REPLICA_DSNS                string
+	JWT_SECRET                     string
+	JWT_EXPIRATION_TIME            string
+	NATS_URL                       string
+	NATS_EMBEDDED                  string
+	NATS_DRIVER                    string
+	NATS_MEMORY_FILE               string
+	NATS_RECONNECT_WAIT            string
+	NATS_CREDS_FILE                string
+	NATS_NKEY_SEED_FILE            string
+	NATS_TLS_CA_FILE               string
+	NATS_TLS_CERT_FILE             string
+	NATS_TLS_KEY_FILE              string
+	NATS_MAX_RECONNECTS            string
+	NATS_STREAM                    string
+	NATS_DLQ_STREAM                string
+	NATS_EVENT_USER_REGISTRATION   string
+	NATS_EVENT_USER_NEW_DEVICE     string
+	NATS_MAX_DELIVER               string
+	NATS_PUBLISH_MAX_PENDING       string
+	NATS_RPC_TIMEOUT               string
+	NATS_RPC_USER_GET              string
+	NATS_RPC_USER_BATCH_GET        string
+	OUTBOX_POLL_INTERVAL           string
+	OUTBOX_BATCH_SIZE              string
+	PROCESSED_MESSAGE_TTL          string
+	WEBHOOK_POLL_INTERVAL          string
+	WEBHOOK_TIMEOUT                string
+	WEBHOOK_MAX_ATTEMPTS           string
+	WEBHOOK_ALLOW_PRIVATE_NETWORKS string
+	REGISTRATION_INVITE_ONLY       string
 }
 
 func GetAppConfig(env string) *AppConfig {
@@ -28,17 +65,54 @@ func GetAppConfig(env string) *AppConfig {
 	}
 
 	return &AppConfig{
-		APP_PORT:                     os.Getenv("APP_PORT"),
-		DB_HOST:                      os.Getenv("DB_HOST"),
-		DB_PORT:                      os.Getenv("DB_PORT"),
-		DB_DATABASE:                  os.Getenv("DB_DATABASE"),
-		DB_USER:                      os.Getenv("DB_USER"),
-		DB_PASSWORD:                  os.Getenv("DB_PASSWORD"),
-		JWT_SECRET:                   os.Getenv("JWT_SECRET"),
-		JWT_EXPIRATION_TIME:          os.Getenv("JWT_EXPIRATION_TIME"),
-		NATS_URL:                     os.Getenv("NATS_URL"),
-		NATS_STREAM:                  os.Getenv("NATS_STREAM"),
-		NATS_EVENT_USER_REGISTRATION: os.Getenv("NATS_EVENT_USER_REGISTRATION"),
+		APP_PORT:                       os.Getenv("APP_PORT"),
+		GRPC_PORT:                      os.Getenv("GRPC_PORT"),
+		TRUSTED_PROXIES:                os.Getenv("TRUSTED_PROXIES"),
+		ADMIN_PORT:                     os.Getenv("ADMIN_PORT"),
+		DB_HOST:                        os.Getenv("DB_HOST"),
+		DB_PORT:                        os.Getenv("DB_PORT"),
+		DB_DATABASE:                    os.Getenv("DB_DATABASE"),
+		DB_USER:                        os.Getenv("DB_USER"),
+		DB_PASSWORD:                    os.Getenv("DB_PASSWORD"),
+		DB_DRIVER:                      os.Getenv("DB_DRIVER"),
+		DB_SSL_MODE:                    os.Getenv("DB_SSL_MODE"),
+		DB_AUTO_MIGRATE:                os.Getenv("DB_AUTO_MIGRATE"),
+		DB_QUERY_TIMEOUT:               os.Getenv("DB_QUERY_TIMEOUT"),
+		DB_MAX_OPEN_CONNS:              os.Getenv("DB_MAX_OPEN_CONNS"),
+		DB_MAX_IDLE_CONNS:              os.Getenv("DB_MAX_IDLE_CONNS"),
+		DB_CONN_MAX_LIFETIME:           os.Getenv("DB_CONN_MAX_LIFETIME"),
+		DB_CONN_MAX_IDLE_TIME:          os.Getenv("DB_CONN_MAX_IDLE_TIME"),
+		DB_REPLICA_DSNS:                os.Getenv("DB_REPLICA_DSNS"),
+		JWT_SECRET:                     os.Getenv("JWT_SECRET"),
+		JWT_EXPIRATION_TIME:            os.Getenv("JWT_EXPIRATION_TIME"),
+		NATS_URL:                       os.Getenv("NATS_URL"),
+		NATS_EMBEDDED:                  os.Getenv("NATS_EMBEDDED"),
+		NATS_DRIVER:                    os.Getenv("NATS_DRIVER"),
+		NATS_MEMORY_FILE:               os.Getenv("NATS_MEMORY_FILE"),
+		NATS_RECONNECT_WAIT:            os.Getenv("NATS_RECONNECT_WAIT"),
+		NATS_CREDS_FILE:                os.Getenv("NATS_CREDS_FILE"),
+		NATS_NKEY_SEED_FILE:            os.Getenv("NATS_NKEY_SEED_FILE"),
+		NATS_TLS_CA_FILE:               os.Getenv("NATS_TLS_CA_FILE"),
+		NATS_TLS_CERT_FILE:             os.Getenv("NATS_TLS_CERT_FILE"),
+		NATS_TLS_KEY_FILE:              os.Getenv("NATS_TLS_KEY_FILE"),
+		NATS_MAX_RECONNECTS:            os.Getenv("NATS_MAX_RECONNECTS"),
+		NATS_STREAM:                    os.Getenv("NATS_STREAM"),
+		NATS_DLQ_STREAM:                os.Getenv("NATS_DLQ_STREAM"),
+		NATS_EVENT_USER_REGISTRATION:   os.Getenv("NATS_EVENT_USER_REGISTRATION"),
+		NATS_EVENT_USER_NEW_DEVICE:     os.Getenv("NATS_EVENT_USER_NEW_DEVICE"),
+		NATS_MAX_DELIVER:               os.Getenv("NATS_MAX_DELIVER"),
+		NATS_PUBLISH_MAX_PENDING:       os.Getenv("NATS_PUBLISH_MAX_PENDING"),
+		NATS_RPC_TIMEOUT:               os.Getenv("NATS_RPC_TIMEOUT"),
+		NATS_RPC_USER_GET:              os.Getenv("NATS_RPC_USER_GET"),
+		NATS_RPC_USER_BATCH_GET:        os.Getenv("NATS_RPC_USER_BATCH_GET"),
+		OUTBOX_POLL_INTERVAL:           os.Getenv("OUTBOX_POLL_INTERVAL"),
+		OUTBOX_BATCH_SIZE:              os.Getenv("OUTBOX_BATCH_SIZE"),
+		PROCESSED_MESSAGE_TTL:          os.Getenv("PROCESSED_MESSAGE_TTL"),
+		WEBHOOK_POLL_INTERVAL:          os.Getenv("WEBHOOK_POLL_INTERVAL"),
+		WEBHOOK_TIMEOUT:                os.Getenv("WEBHOOK_TIMEOUT"),
+		WEBHOOK_MAX_ATTEMPTS:           os.Getenv("WEBHOOK_MAX_ATTEMPTS"),
+		WEBHOOK_ALLOW_PRIVATE_NETWORKS: os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS"),
+		REGISTRATION_INVITE_ONLY:       os.Getenv("REGISTRATION_INVITE_ONLY"),
 	}
 }
 
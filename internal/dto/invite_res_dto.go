@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+)
+
+func InviteToInviteRes(invite *model.Invite) model.InviteRes {
+	var expiresAt *string
+	if invite.ExpiresAt != nil {
+		expiresAtStr := invite.ExpiresAt.Format(time.RFC3339)
+		expiresAt = &expiresAtStr
+	}
+
+	return model.InviteRes{
+		Id:        invite.Id,
+		Code:      invite.Code,
+		Email:     invite.Email,
+		OrgId:     invite.OrgId,
+		Role:      invite.Role,
+		MaxUses:   invite.MaxUses,
+		UsedCount: invite.UsedCount,
+		ExpiresAt: expiresAt,
+		CreatedAt: invite.CreatedAt.Format(time.RFC3339),
+	}
+}
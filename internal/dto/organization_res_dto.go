@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+)
+
+func OrgToOrgRes(org *model.Organization, role string) model.OrganizationRes {
+	return model.OrganizationRes{
+		Id:        org.Id,
+		Name:      org.Name,
+		Role:      role,
+		CreatedAt: org.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func OrgMemberToOrgMemberRes(member *model.OrgMember) model.OrgMemberRes {
+	return model.OrgMemberRes{
+		UserId:   member.UserId,
+		Email:    member.Email,
+		Role:     member.Role,
+		JoinedAt: member.JoinedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+)
+
+func LoginEventToSecurityEventRes(event *model.LoginEvent) model.SecurityEventRes {
+	return model.SecurityEventRes{
+		Id:            event.Id,
+		Success:       event.Success,
+		FailureReason: event.FailureReason,
+		Ip:            event.Ip,
+		UserAgent:     event.UserAgent,
+		MfaUsed:       event.MfaUsed,
+		NewDevice:     event.NewDevice,
+		CreatedAt:     event.CreatedAt.Format(time.RFC3339),
+	}
+}
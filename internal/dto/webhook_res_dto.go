@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+)
+
+func WebhookToWebhookRes(webhook *model.Webhook) model.WebhookRes {
+	return model.WebhookRes{
+		Id:         webhook.Id,
+		Url:        webhook.Url,
+		EventTypes: webhook.EventTypes,
+		CreatedBy:  webhook.CreatedBy,
+		CreatedAt:  webhook.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// WebhookDeliveryToWebhookDeliveryRes leaves out the payload, it is the event the endpoint receives
+func WebhookDeliveryToWebhookDeliveryRes(delivery *model.WebhookDelivery) model.WebhookDeliveryRes {
+	res := model.WebhookDeliveryRes{
+		Id:             delivery.Id,
+		WebhookId:      delivery.WebhookId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+	}
+
+	// only pending deliveries have a next attempt
+	if delivery.Status == model.WebhookDeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt.Format(time.RFC3339)
+		res.NextAttemptAt = &nextAttemptAt
+	}
+
+	if delivery.DeliveredAt != nil {
+		deliveredAt := delivery.DeliveredAt.Format(time.RFC3339)
+		res.DeliveredAt = &deliveredAt
+	}
+
+	return res
+}
+
+func WebhookDeliveryAttemptToWebhookDeliveryAttemptRes(attempt *model.WebhookDeliveryAttempt) model.WebhookDeliveryAttemptRes {
+	return model.WebhookDeliveryAttemptRes{
+		Id:           attempt.Id,
+		StatusCode:   attempt.StatusCode,
+		Error:        attempt.Error,
+		ResponseBody: attempt.ResponseBody,
+		DurationMs:   attempt.DurationMs,
+		CreatedAt:    attempt.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -4,4 +4,22 @@ const (
 	ReqDataMissing   = "REQUEST.MISSING"
 	UserPwNotSet     = "USER.PASSWORD_NOT_SET"
 	UserAlreadyExist = "USER.ALREADY_EXISTS"
+	UserNotFound     = "USER.NOT_FOUND"
+
+	InviteRequired      = "INVITE.REQUIRED"
+	InviteNotFound      = "INVITE.NOT_FOUND"
+	InviteExpired       = "INVITE.EXPIRED"
+	InviteExhausted     = "INVITE.EXHAUSTED"
+	InviteEmailMismatch = "INVITE.EMAIL_MISMATCH"
+	InviteNotForOrg     = "INVITE.NOT_FOR_ORGANIZATION"
+
+	OrgNotMember        = "ORGANIZATION.NOT_MEMBER"
+	OrgNotSelected      = "ORGANIZATION.NOT_SELECTED"
+	OrgPermissionDenied = "ORGANIZATION.PERMISSION_DENIED"
+	OrgAlreadyMember    = "ORGANIZATION.ALREADY_MEMBER"
+
+	WebhookNotFound         = "WEBHOOK.NOT_FOUND"
+	WebhookDeliveryNotFound = "WEBHOOK.DELIVERY_NOT_FOUND"
+	WebhookInvalidUrl       = "WEBHOOK.INVALID_URL"
+	WebhookInvalidEventType = "WEBHOOK.INVALID_EVENT_TYPE"
 )
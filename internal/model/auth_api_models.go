@@ -1,8 +1,9 @@
 package model
 
 type LoginApiReq struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email    string  `json:"email" validate:"required,email,max=100"`
+	Password string  `json:"password" validate:"required"`
+	OrgId    *string `json:"orgId"`
 }
 
 type LoginApiRes struct {
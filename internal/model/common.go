@@ -7,3 +7,9 @@ type UserRes struct {
 	LastName  *string `json:"lastName"`
 	CreatedAt string  `json:"createdAt"`
 }
+
+type PaginationRes struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
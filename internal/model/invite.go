@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type Invite struct {
+	Id        string
+	Code      string
+	Email     *string
+	CreatedBy string
+	OrgId     *string
+	Role      *string
+	MaxUses   int
+	UsedCount int
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,23 @@
+package model
+
+type CreateInviteApiReq struct {
+	Email     *string `json:"email" validate:"omitempty,email"`
+	MaxUses   int     `json:"maxUses" validate:"omitempty,min=1"`
+	ExpiresIn string  `json:"expiresIn"` // s = seconds, m = minute, h = hour, d = day, M = month, y = year
+}
+
+type InviteRes struct {
+	Id        string  `json:"id"`
+	Code      string  `json:"code"`
+	Email     *string `json:"email"`
+	OrgId     *string `json:"orgId"`
+	Role      *string `json:"role"`
+	MaxUses   int     `json:"maxUses"`
+	UsedCount int     `json:"usedCount"`
+	ExpiresAt *string `json:"expiresAt"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type CreateInviteApiRes struct {
+	Invite InviteRes `json:"invite"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	LoginFailureUserNotFound    = "USER_NOT_FOUND"
+	LoginFailurePwNotSet        = "PASSWORD_NOT_SET"
+	LoginFailureInvalidPassword = "INVALID_PASSWORD"
+	LoginFailureNotOrgMember    = "NOT_ORGANIZATION_MEMBER"
+)
+
+type LoginEvent struct {
+	Id            string
+	UserId        *string
+	Email         string
+	Success       bool
+	FailureReason *string
+	Ip            string
+	UserAgent     string
+	MfaUsed       bool
+	NewDevice     bool
+	CreatedAt     time.Time
+}
+
+// LoginAttempt holds the details of a login attempt known to the auth service, request metadata like ip and user agent
+// are read from the context while recording the attempt.
+type LoginAttempt struct {
+	Email         string
+	UserId        *string
+	FailureReason *string
+	MfaUsed       bool
+}
+
+type LoginHistoryStats struct {
+	SuccessfulLogins int
+	FromSameIp       int
+	FromSameDevice   int
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+type Organization struct {
+	Id        string
+	Name      string
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt *time.Time
+}
+
+type Membership struct {
+	OrgId     string
+	UserId    string
+	Role      string
+	CreatedAt time.Time
+}
+
+type UserOrganization struct {
+	Organization
+	Role string
+}
+
+type OrgMember struct {
+	UserId   string
+	Email    string
+	Role     string
+	JoinedAt time.Time
+}
@@ -0,0 +1,49 @@
+package model
+
+type CreateOrgApiReq struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type OrganizationRes struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type CreateOrgApiRes struct {
+	Organization OrganizationRes `json:"organization"`
+}
+
+type ListOrgsApiRes struct {
+	Organizations []OrganizationRes `json:"organizations"`
+}
+
+type OrgMemberRes struct {
+	UserId   string `json:"userId"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joinedAt"`
+}
+
+type ListOrgMembersApiRes struct {
+	Members []OrgMemberRes `json:"members"`
+}
+
+type InviteOrgMemberApiReq struct {
+	Email     string `json:"email" validate:"required,email"`
+	Role      string `json:"role" validate:"required,oneof=owner admin member"`
+	ExpiresIn string `json:"expiresIn"` // s = seconds, m = minute, h = hour, d = day, M = month, y = year
+}
+
+type InviteOrgMemberApiRes struct {
+	Invite InviteRes `json:"invite"`
+}
+
+type AcceptOrgInviteApiReq struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type AcceptOrgInviteApiRes struct {
+	Organization OrganizationRes `json:"organization"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// OutboxEvent is an event waiting to be published to NATS, it is saved in the transaction of the data it describes so
+// that the event is published if and only if the data is committed
+type OutboxEvent struct {
+	Id            string
+	Topic         string
+	Payload       []byte
+	Attempts      int
+	LastError     *string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+type OutboxStats struct {
+	Pending int
+	// OldestPendingAt is the creation time of the oldest event which has not been published yet
+	OldestPendingAt *time.Time
+}
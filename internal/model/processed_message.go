@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ProcessedMessage records that a consumer has handled a message, so that redeliveries of the message are skipped
+// until it expires
+type ProcessedMessage struct {
+	Consumer    string
+	MessageId   string
+	ProcessedAt time.Time
+	ExpiresAt   time.Time
+}
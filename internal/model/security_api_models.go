@@ -0,0 +1,22 @@
+package model
+
+type GetSecurityEventsApiReq struct {
+	Page  int `json:"page,string" validate:"omitempty,min=1,max=10000"`
+	Limit int `json:"limit,string" validate:"omitempty,min=1,max=100"`
+}
+
+type SecurityEventRes struct {
+	Id            string  `json:"id"`
+	Success       bool    `json:"success"`
+	FailureReason *string `json:"failureReason"`
+	Ip            string  `json:"ip"`
+	UserAgent     string  `json:"userAgent"`
+	MfaUsed       bool    `json:"mfaUsed"`
+	NewDevice     bool    `json:"newDevice"`
+	CreatedAt     string  `json:"createdAt"`
+}
+
+type GetSecurityEventsApiRes struct {
+	Events     []SecurityEventRes `json:"events"`
+	Pagination PaginationRes      `json:"pagination"`
+}
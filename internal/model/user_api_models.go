@@ -1,8 +1,9 @@
 package model
 
 type UserRegApiReq struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string  `json:"email" validate:"required,email"`
+	Password   string  `json:"password" validate:"required"`
+	InviteCode *string `json:"inviteCode"`
 }
 
 type UserRegApiRes struct {
@@ -0,0 +1,21 @@
+package model
+
+// UserGetRpcReq is the request of NATS_RPC_USER_GET
+type UserGetRpcReq struct {
+	Id string `json:"id" validate:"required"`
+}
+
+type UserGetRpcRes struct {
+	User UserRes `json:"user"`
+}
+
+// UserBatchGetRpcReq is the request of NATS_RPC_USER_BATCH_GET
+type UserBatchGetRpcReq struct {
+	Ids []string `json:"ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// UserBatchGetRpcRes lists the users found in the order of the requested ids, the ids without a user are in MissingIds
+type UserBatchGetRpcRes struct {
+	Users      []UserRes `json:"users"`
+	MissingIds []string  `json:"missingIds"`
+}
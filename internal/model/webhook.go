@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// AllEventTypes subscribes a webhook to every event type, including the ones added later
+const AllEventTypes = "*"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is an HTTP endpoint of an organization which receives the events of the types it is subscribed to, Secret
+// signs the payloads so that the endpoint can authenticate them
+type Webhook struct {
+	Id         string
+	OrgId      string
+	Url        string
+	Secret     string
+	EventTypes []string
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+// WebhookDelivery is an event to deliver to a webhook, it is retried until the endpoint accepts it or the attempts
+// are used up
+type WebhookDelivery struct {
+	Id             string
+	WebhookId      string
+	OrgId          string
+	EventId        string
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	LastStatusCode *int
+	LastError      *string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookDeliveryAttempt logs one request made to deliver an event, StatusCode is nil when no response was received
+type WebhookDeliveryAttempt struct {
+	Id           string
+	DeliveryId   string
+	StatusCode   *int
+	Error        *string
+	ResponseBody *string
+	DurationMs   int
+	CreatedAt    time.Time
+}
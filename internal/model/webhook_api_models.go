@@ -0,0 +1,82 @@
+package model
+
+type CreateWebhookApiReq struct {
+	Url        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"eventTypes" validate:"required,min=1,max=20,dive,required,max=100"`
+}
+
+type WebhookRes struct {
+	Id         string   `json:"id"`
+	Url        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	CreatedBy  string   `json:"createdBy"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+type CreateWebhookApiRes struct {
+	Webhook WebhookRes `json:"webhook"`
+	// Secret signs the deliveries of the webhook, it is only returned when the webhook is created
+	Secret string `json:"secret"`
+}
+
+type ListWebhooksApiRes struct {
+	Webhooks []WebhookRes `json:"webhooks"`
+}
+
+type DeleteWebhookApiReq struct {
+	Id string `json:"id" validate:"required"`
+}
+
+type DeleteWebhookApiRes struct {
+	Id string `json:"id"`
+}
+
+type ListWebhookDeliveriesApiReq struct {
+	WebhookId string `json:"webhookId" validate:"required"`
+	Page      int    `json:"page,string" validate:"omitempty,min=1,max=10000"`
+	Limit     int    `json:"limit,string" validate:"omitempty,min=1,max=100"`
+}
+
+type WebhookDeliveryRes struct {
+	Id             string  `json:"id"`
+	WebhookId      string  `json:"webhookId"`
+	EventId        string  `json:"eventId"`
+	EventType      string  `json:"eventType"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	LastStatusCode *int    `json:"lastStatusCode"`
+	LastError      *string `json:"lastError"`
+	NextAttemptAt  *string `json:"nextAttemptAt"`
+	CreatedAt      string  `json:"createdAt"`
+	DeliveredAt    *string `json:"deliveredAt"`
+}
+
+type ListWebhookDeliveriesApiRes struct {
+	Deliveries []WebhookDeliveryRes `json:"deliveries"`
+	Pagination PaginationRes        `json:"pagination"`
+}
+
+type ListWebhookDeliveryAttemptsApiReq struct {
+	DeliveryId string `json:"deliveryId" validate:"required"`
+}
+
+type WebhookDeliveryAttemptRes struct {
+	Id           string  `json:"id"`
+	StatusCode   *int    `json:"statusCode"`
+	Error        *string `json:"error"`
+	ResponseBody *string `json:"responseBody"`
+	DurationMs   int     `json:"durationMs"`
+	CreatedAt    string  `json:"createdAt"`
+}
+
+type ListWebhookDeliveryAttemptsApiRes struct {
+	Attempts []WebhookDeliveryAttemptRes `json:"attempts"`
+}
+
+type RedeliverWebhookApiReq struct {
+	DeliveryId string `json:"deliveryId" validate:"required"`
+}
+
+type RedeliverWebhookApiRes struct {
+	Delivery WebhookDeliveryRes `json:"delivery"`
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pjmessi/golang-practice/config"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	DriverMySql    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+// mysql error numbers
+const (
+	errDuplicateEntry  = 1062
+	errLockWaitTimeout = 1205
+	errDeadlock        = 1213
+)
+
+// postgres error codes
+const (
+	pqErrUniqueViolation      = "23505"
+	pqErrSerializationFailure = "40001"
+	pqErrDeadlockDetected     = "40P01"
+)
+
+// dialect hides the differences between the supported databases, queries are written with "?" placeholders and
+// rebound for the databases using another syntax
+type dialect interface {
+	driverName() string
+	dsn(appConf *config.AppConfig) string
+	rebind(query string) string
+	isUniqueViolation(err error) bool
+	// isRetryable returns true for errors of transactions rolled back by the server which succeed when retried
+	isRetryable(err error) bool
+}
+
+// GetDriver returns the database driver selected in the config, mysql is used when it is not set
+func GetDriver(appConf *config.AppConfig) string {
+	if appConf.DB_DRIVER == "" {
+		return DriverMySql
+	}
+
+	return appConf.DB_DRIVER
+}
+
+// Rebind converts a query written with "?" placeholders to the placeholder syntax of the driver
+func Rebind(driver string, query string) string {
+	dialect, err := newDialect(driver)
+	if err != nil {
+		return query
+	}
+
+	return dialect.rebind(query)
+}
+
+func newDialect(driver string) (dialect, error) {
+	switch driver {
+	case DriverMySql:
+		return mysqlDialect{}, nil
+	case DriverPostgres:
+		return postgresDialect{}, nil
+	case DriverSqlite:
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("database.newDialect(): unsupported driver '%s'", driver)
+	}
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) driverName() string {
+	return "mysql"
+}
+
+func (mysqlDialect) dsn(appConf *config.AppConfig) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", appConf.DB_USER, appConf.DB_PASSWORD, appConf.DB_HOST, appConf.DB_PORT, appConf.DB_DATABASE)
+}
+
+func (mysqlDialect) rebind(query string) string {
+	return query
+}
+
+func (mysqlDialect) isUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry
+}
+
+func (mysqlDialect) isRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+
+	return mysqlErr.Number == errDeadlock || mysqlErr.Number == errLockWaitTimeout
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) driverName() string {
+	return "postgres"
+}
+
+func (postgresDialect) dsn(appConf *config.AppConfig) string {
+	sslMode := appConf.DB_SSL_MODE
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(appConf.DB_USER, appConf.DB_PASSWORD),
+		Host:     fmt.Sprintf("%s:%s", appConf.DB_HOST, appConf.DB_PORT),
+		Path:     appConf.DB_DATABASE,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+// rebind replaces the "?" placeholders with "$1", "$2"... skipping the ones inside string literals
+func (postgresDialect) rebind(query string) string {
+	var builder strings.Builder
+	builder.Grow(len(query) + 10)
+
+	position := 0
+	inLiteral := false
+	for _, char := range query {
+		switch {
+		case char == '\'':
+			inLiteral = !inLiteral
+			builder.WriteRune(char)
+		case char == '?' && !inLiteral:
+			position++
+			builder.WriteString("$")
+			builder.WriteString(strconv.Itoa(position))
+		default:
+			builder.WriteRune(char)
+		}
+	}
+
+	return builder.String()
+}
+
+func (postgresDialect) isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqErrUniqueViolation
+}
+
+func (postgresDialect) isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == pqErrSerializationFailure || pqErr.Code == pqErrDeadlockDetected
+}
+
+// sqliteDialect is meant for local development and hermetic tests, DB_DATABASE is the path of the database file or
+// ":memory:" for a database which is shared by the connection pools of the process and lives as long as one of them
+// holds a connection
+type sqliteDialect struct{}
+
+func (sqliteDialect) driverName() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) dsn(appConf *config.AppConfig) string {
+	params := "_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_time_format=sqlite"
+	if appConf.DB_DATABASE == ":memory:" {
+		return "file:memdb?mode=memory&cache=shared&" + params
+	}
+
+	return fmt.Sprintf("file:%s?%s&_pragma=journal_mode(WAL)", appConf.DB_DATABASE, params)
+}
+
+func (sqliteDialect) rebind(query string) string {
+	return query
+}
+
+func (sqliteDialect) isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+func (sqliteDialect) isRetryable(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	// extended result codes keep the primary code in the lowest byte
+	primaryCode := sqliteErr.Code() & 0xff
+	return primaryCode == sqlite3.SQLITE_BUSY || primaryCode == sqlite3.SQLITE_LOCKED
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// QueryError is returned when a query fails, it keeps the method and the query so that the failing statement can be
+// found from the logs. Driver errors and context errors are still reachable with errors.Is and errors.As.
+type QueryError struct {
+	Method string
+	Query  string
+	Err    error
+}
+
+func newQueryError(method string, query string, err error) *QueryError {
+	return &QueryError{Method: method, Query: query, Err: err}
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("database.%s(): %s", e.Method, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// IsTimeout returns true if the query has been cancelled because it ran longer than DB_QUERY_TIMEOUT
+func (e *QueryError) IsTimeout() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
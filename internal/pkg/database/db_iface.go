@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/pjmessi/golang-practice/internal/model"
 )
@@ -9,9 +10,73 @@ import (
 type Db interface {
 	CloseConnection()
 	CheckHealth() error
+	// PoolStats returns the statistics of the connection pools of the primary and of every read replica
+	PoolStats() []PoolStats
+	// WithTx runs fn inside a transaction and commits it if fn returns nil, every write made through txDb is rolled
+	// back otherwise. Calling WithTx on txDb creates a savepoint so nested units of work can fail on their own. The
+	// whole transaction is retried on deadlocks and lock wait timeouts, so fn must not have side effects outside txDb.
+	WithTx(ctx context.Context, fn func(txDb Db) error) error
 
 	SaveUser(ctx context.Context, user *model.User) error
 	IsUserEmailTaken(ctx context.Context, email string) (isTaken bool, err error)
 	GetUserByEmail(ctx context.Context, email string) (exists bool, user model.User, err error)
 	GetUserById(ctx context.Context, userId string) (exists bool, user model.User, err error)
+	// GetUsersByIds returns the users which exist among the ids, in the order of the ids and without duplicates
+	GetUsersByIds(ctx context.Context, userIds []string) ([]model.User, error)
+
+	SaveInvite(ctx context.Context, invite *model.Invite) error
+	GetInviteByCode(ctx context.Context, code string) (exists bool, invite model.Invite, err error)
+	// IncrementInviteUsage atomically consumes one use of the invite, it returns false if the invite has already been
+	// used up or has expired by the time of the update.
+	IncrementInviteUsage(ctx context.Context, inviteId string, now time.Time) (incremented bool, err error)
+
+	// organization scoped queries always take the organization id so that data never leaks across tenants
+	SaveOrganization(ctx context.Context, org *model.Organization) error
+	GetOrganizationById(ctx context.Context, orgId string) (exists bool, org model.Organization, err error)
+	SaveMembership(ctx context.Context, membership *model.Membership) error
+	GetMembership(ctx context.Context, orgId string, userId string) (exists bool, membership model.Membership, err error)
+	GetOrgMembers(ctx context.Context, orgId string) ([]model.OrgMember, error)
+	GetUserOrganizations(ctx context.Context, userId string) ([]model.UserOrganization, error)
+
+	SaveLoginEvent(ctx context.Context, event *model.LoginEvent) error
+	GetLoginEvents(ctx context.Context, userId string, limit int, offset int) ([]model.LoginEvent, error)
+	CountLoginEvents(ctx context.Context, userId string) (int, error)
+	GetLoginHistoryStats(ctx context.Context, userId string, ip string, userAgent string) (model.LoginHistoryStats, error)
+
+	// outbox events are saved inside the transaction of the data they describe and published later by the relay
+	SaveOutboxEvent(ctx context.Context, event *model.OutboxEvent) error
+	GetPendingOutboxEvents(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error)
+	// ClaimOutboxEvent leases a due event to the caller until leaseUntil by moving its next attempt, false is returned
+	// when another relay has claimed or sent it in the meantime
+	ClaimOutboxEvent(ctx context.Context, eventId string, now time.Time, leaseUntil time.Time) (claimed bool, err error)
+	MarkOutboxEventSent(ctx context.Context, eventId string, sentAt time.Time) error
+	MarkOutboxEventFailed(ctx context.Context, eventId string, lastError string, nextAttemptAt time.Time) error
+	GetOutboxStats(ctx context.Context) (model.OutboxStats, error)
+
+	// SaveProcessedMessage returns false without saving when the consumer has already processed the message, it is
+	// called in the transaction of the handler so that the message is only recorded when the handler succeeds
+	SaveProcessedMessage(ctx context.Context, msg *model.ProcessedMessage) (saved bool, err error)
+	DeleteExpiredProcessedMessages(ctx context.Context, now time.Time) (deleted int, err error)
+
+	// webhooks are organization scoped, GetWebhooksOfUserOrgs returns the ones of the organizations the user is a member
+	// of to fan the events of the user out to them
+	SaveWebhook(ctx context.Context, webhook *model.Webhook) error
+	GetWebhook(ctx context.Context, orgId string, webhookId string) (exists bool, webhook model.Webhook, err error)
+	GetOrgWebhooks(ctx context.Context, orgId string) ([]model.Webhook, error)
+	GetWebhooksOfUserOrgs(ctx context.Context, userId string) ([]model.Webhook, error)
+	// DeleteWebhook deletes the webhook along with its deliveries and their attempts in one transaction
+	DeleteWebhook(ctx context.Context, orgId string, webhookId string) (deleted bool, err error)
+	// SaveWebhookDelivery returns false without saving when the event has already been queued for the webhook
+	SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) (saved bool, err error)
+	GetWebhookDelivery(ctx context.Context, orgId string, deliveryId string) (exists bool, delivery model.WebhookDelivery, err error)
+	GetWebhookDeliveries(ctx context.Context, orgId string, webhookId string, limit int, offset int) ([]model.WebhookDelivery, error)
+	CountWebhookDeliveries(ctx context.Context, orgId string, webhookId string) (int, error)
+	GetPendingWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	// ClaimWebhookDelivery leases a due delivery to the caller until leaseUntil by moving its next attempt, false is
+	// returned when another worker has claimed it or it is no longer pending
+	ClaimWebhookDelivery(ctx context.Context, deliveryId string, now time.Time, leaseUntil time.Time) (claimed bool, err error)
+	// UpdateWebhookDelivery saves the status, the attempts, the last result and the next attempt of the delivery
+	UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	SaveWebhookDeliveryAttempt(ctx context.Context, attempt *model.WebhookDeliveryAttempt) error
+	GetWebhookDeliveryAttempts(ctx context.Context, orgId string, deliveryId string) ([]model.WebhookDeliveryAttempt, error)
 }
@@ -3,137 +3,887 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
 	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+)
+
+const maxTxAttempts = 3
+const txRetryBackoff = 20 * time.Millisecond
+const defaultQueryTimeout = 5 * time.Second
+
+// column lists of the tables, rows are always scanned in this order
+const (
+	userColumns       = "id, email, password, first_name, last_name, created_at, updated_at"
+	inviteColumns     = "id, code, email, created_by, org_id, role, max_uses, used_count, expires_at, created_at"
+	orgColumns        = "id, name, created_by, created_at, updated_at"
+	membershipColumns = "org_id, user_id, role, created_at"
+	loginEventColumns = "id, user_id, email, success, failure_reason, ip, user_agent, mfa_used, new_device, created_at"
+	outboxColumns     = "id, topic, payload, attempts, last_error, next_attempt_at, created_at, sent_at"
+	processedColumns  = "consumer, message_id, processed_at, expires_at"
+	webhookColumns    = "id, org_id, url, secret, event_types, created_by, created_at"
+	deliveryColumns   = "id, webhook_id, org_id, event_id, event_type, payload, status, attempts, last_status_code, last_error, next_attempt_at, created_at, delivered_at"
+	attemptColumns    = "id, delivery_id, status_code, error, response_body, duration_ms, created_at"
 )
 
 type RawDbImpl struct {
-	db *sql.DB
+	pool    *sql.DB
+	stmts   *stmtCache
+	dialect dialect
+	// queryTimeout bounds every single query, zero disables it
+	queryTimeout time.Duration
+	// replicas answer the reads which tolerate replication lag, replicaIndex rotates between them
+	replicas     []*RawDbImpl
+	replicaIndex *atomic.Uint64
+	// tx is set when the instance is bound to a transaction, txDepth counts the savepoints of nested WithTx calls
+	tx      *sql.Tx
+	txDepth int
 }
 
 func NewDb(appConf *config.AppConfig) (Db, error) {
-	dns := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", appConf.DB_USER, appConf.DB_PASSWORD, appConf.DB_HOST, appConf.DB_PORT, appConf.DB_DATABASE)
-	db, err := sql.Open("mysql", dns)
+	dialect, err := newDialect(GetDriver(appConf))
 	if err != nil {
 		return nil, fmt.Errorf("database.NewDbImpl: %w", err)
 	}
 
-	db.SetConnMaxLifetime(time.Minute * 3)
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(10)
+	queryTimeout, err := getQueryTimeout(appConf)
+	if err != nil {
+		return nil, fmt.Errorf("database.NewDbImpl: %w", err)
+	}
+
+	db, err := Open(appConf)
+	if err != nil {
+		return nil, fmt.Errorf("database.NewDbImpl: %w", err)
+	}
+
+	replicas, err := openReplicas(appConf, dialect, queryTimeout)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("database.NewDbImpl: %w", err)
+	}
 
 	impl := &RawDbImpl{
-		db: db,
+		pool:         db,
+		stmts:        newStmtCache(db),
+		dialect:      dialect,
+		queryTimeout: queryTimeout,
+		replicas:     replicas,
+		replicaIndex: &atomic.Uint64{},
 	}
 
 	return impl, nil
 }
 
-func (r *RawDbImpl) CheckHealth() error {
-	var total int
-	res, err := r.db.Query("SELECT 2 + 2;")
+func getQueryTimeout(appConf *config.AppConfig) (time.Duration, error) {
+	if appConf.DB_QUERY_TIMEOUT == "" {
+		return defaultQueryTimeout, nil
+	}
+
+	queryTimeout, err := time.ParseDuration(appConf.DB_QUERY_TIMEOUT)
 	if err != nil {
-		return fmt.Errorf("database.CheckHealth(): %w", err)
+		return 0, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %w", err)
 	}
 
-	defer res.Close()
+	return queryTimeout, nil
+}
 
-	if res.Next() {
-		err := res.Scan(&total)
-		if err != nil {
-			return fmt.Errorf("database.CheckHealth(): %w", err)
+// Open returns the connection pool for the database driver selected in the config, it is shared by the Db
+// implementation and tools like the migration runner which need the raw connection
+func Open(appConf *config.AppConfig) (*sql.DB, error) {
+	dialect, err := newDialect(GetDriver(appConf))
+	if err != nil {
+		return nil, fmt.Errorf("database.Open(): %w", err)
+	}
+
+	poolConf, err := getPoolConfig(appConf)
+	if err != nil {
+		return nil, fmt.Errorf("database.Open(): %w", err)
+	}
+
+	db, err := sql.Open(dialect.driverName(), dialect.dsn(appConf))
+	if err != nil {
+		return nil, fmt.Errorf("database.Open(): %w", err)
+	}
+
+	poolConf.apply(db)
+
+	// sqlite allows a single writer and an in memory database only lives as long as its connection
+	if GetDriver(appConf) == DriverSqlite {
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+	}
+
+	return db, nil
+}
+
+func (r *RawDbImpl) WithTx(ctx context.Context, fn func(txDb Db) error) error {
+	if r.tx != nil {
+		return r.withSavepoint(ctx, fn)
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = r.runTx(ctx, fn)
+		if err == nil || !r.dialect.isRetryable(err) || attempt == maxTxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(txRetryBackoff * time.Duration(attempt)):
 		}
 	}
 
-	if total != 4 {
-		return fmt.Errorf("database.CheckHealth(): expected result 4 but received %d", total)
+	return err
+}
+
+func (r *RawDbImpl) runTx(ctx context.Context, fn func(txDb Db) error) error {
+	tx, err := r.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database.WithTx(): %w", err)
+	}
+
+	err = fn(r.bindTx(tx, 0))
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("database.WithTx(): rollback failed: %v, after: %w", rollbackErr, err)
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("database.WithTx(): %w", err)
 	}
 
 	return nil
 }
 
-func (r *RawDbImpl) SaveUser(ctx context.Context, user *model.User) error {
-	stmt, err := r.db.Prepare("INSERT INTO users (id, email, password, first_name, last_name, created_at, updated_at) VALUE (?, ?, ?, ?, ?, ?, ?)")
+// withSavepoint runs a nested unit of work, a failure only rolls back the changes made by fn and leaves the outer
+// transaction usable
+func (r *RawDbImpl) withSavepoint(ctx context.Context, fn func(txDb Db) error) error {
+	savepoint := fmt.Sprintf("sp_%d", r.txDepth+1)
+
+	_, err := r.tx.ExecContext(ctx, "SAVEPOINT "+savepoint)
 	if err != nil {
-		return fmt.Errorf("database.SaveUser(): %w", err)
+		return fmt.Errorf("database.WithTx(): %w", err)
 	}
 
-	defer func() {
-		if cerr := stmt.Close(); cerr != nil && err == nil {
-			err = fmt.Errorf("database.SaveUser(): %w", cerr)
+	err = fn(r.bindTx(r.tx, r.txDepth+1))
+	if err != nil {
+		if _, rollbackErr := r.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+			return fmt.Errorf("database.WithTx(): rollback to savepoint failed: %v, after: %w", rollbackErr, err)
 		}
-	}()
+		return err
+	}
 
-	_, err = stmt.Exec(user.Id, user.Email, user.Password, user.FirstName, user.LastName, user.CreatedAt, user.UpdatedAt)
+	_, err = r.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
 	if err != nil {
-		return fmt.Errorf("database.SaveUser(): %w", err)
+		return fmt.Errorf("database.WithTx(): %w", err)
 	}
 
 	return nil
 }
 
-func (r *RawDbImpl) IsUserEmailTaken(ctx context.Context, email string) (bool, error) {
-	var isTaken bool
-	query := fmt.Sprintf("SELECT EXISTS(SELECT * FROM users WHERE email = \"%s\");", email)
-	res, err := r.db.Query(query)
+// bindTx returns a copy of the db which runs every query inside the transaction
+func (r *RawDbImpl) bindTx(tx *sql.Tx, txDepth int) *RawDbImpl {
+	return &RawDbImpl{
+		pool:         r.pool,
+		stmts:        r.stmts,
+		dialect:      r.dialect,
+		queryTimeout: r.queryTimeout,
+		replicas:     r.replicas,
+		replicaIndex: r.replicaIndex,
+		tx:           tx,
+		txDepth:      txDepth,
+	}
+}
+
+// wrapWriteErr converts unique constraint violations of an insert or update to exception.AlreadyExists so that they
+// reach the client as a bad request
+func (r *RawDbImpl) wrapWriteErr(err error) error {
+	if r.dialect.isUniqueViolation(err) {
+		return exception.NewAlreadyExists()
+	}
+
+	return err
+}
+
+func (r *RawDbImpl) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, r.queryTimeout)
+}
+
+// prepare returns the prepared statement of the query, inside a transaction the statement of the pool is bound to the
+// transaction and closed with it
+func (r *RawDbImpl) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	if r.tx == nil {
+		return r.stmts.get(ctx, query)
+	}
+
+	if stmt, isCached := r.stmts.lookup(query); isCached {
+		return r.tx.StmtContext(ctx, stmt), nil
+	}
+
+	// the pool cannot prepare while the transaction holds its connection, which is the only one with sqlite
+	return r.tx.PrepareContext(ctx, query)
+}
+
+func (r *RawDbImpl) exec(ctx context.Context, method string, query string, args ...any) (sql.Result, error) {
+	query = r.dialect.rebind(query)
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	stmt, err := r.prepare(ctx, query)
+	if err != nil {
+		return nil, newQueryError(method, query, err)
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return nil, newQueryError(method, query, err)
+	}
+
+	markWritten(ctx)
+	return res, nil
+}
+
+// queryRow scans the first row returned by the query into dest, exists is false when there are no rows
+func (r *RawDbImpl) queryRow(ctx context.Context, method string, query string, args []any, dest ...any) (exists bool, err error) {
+	query = r.dialect.rebind(query)
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	stmt, err := r.prepare(ctx, query)
+	if err != nil {
+		return false, newQueryError(method, query, err)
+	}
+
+	err = stmt.QueryRowContext(ctx, args...).Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
 	if err != nil {
-		return false, fmt.Errorf("database.IsUserEmailTaken: %w", err)
+		return false, newQueryError(method, query, err)
 	}
 
-	defer res.Close()
+	return true, nil
+}
+
+// queryRows calls scanRow for every row returned by the query
+func (r *RawDbImpl) queryRows(ctx context.Context, method string, query string, args []any, scanRow func(rows *sql.Rows) error) error {
+	query = r.dialect.rebind(query)
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	stmt, err := r.prepare(ctx, query)
+	if err != nil {
+		return newQueryError(method, query, err)
+	}
 
-	if res.Next() {
-		err := res.Scan(&isTaken)
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return newQueryError(method, query, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = scanRow(rows)
 		if err != nil {
-			return false, fmt.Errorf("database.IsUserEmailTaken: %w", err)
+			return newQueryError(method, query, err)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return newQueryError(method, query, err)
+	}
+
+	return nil
+}
+
+func (r *RawDbImpl) CheckHealth() error {
+	var total int
+	_, err := r.queryRow(context.Background(), "CheckHealth", "SELECT 2 + 2;", nil, &total)
+	if err != nil {
+		return err
+	}
+
+	if total != 4 {
+		return fmt.Errorf("database.CheckHealth(): expected result 4 but received %d", total)
+	}
+
+	return nil
+}
+
+func (r *RawDbImpl) CloseConnection() {
+	closeReplicas(r.replicas)
+	r.stmts.close()
+	r.pool.Close()
+}
+
+func (r *RawDbImpl) PoolStats() []PoolStats {
+	stats := []PoolStats{{Name: "primary", DBStats: r.pool.Stats()}}
+	for i, replica := range r.replicas {
+		stats = append(stats, PoolStats{Name: fmt.Sprintf("replica-%d", i+1), DBStats: replica.pool.Stats()})
+	}
+
+	return stats
+}
+
+func userFields(user *model.User) []any {
+	return []any{&user.Id, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt}
+}
+
+func (r *RawDbImpl) SaveUser(ctx context.Context, user *model.User) error {
+	_, err := r.exec(ctx, "SaveUser", "INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?, ?);",
+		user.Id, user.Email, user.Password, user.FirstName, user.LastName, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		// the unique email index catches concurrent registrations which all passed the IsUserEmailTaken check
+		if r.dialect.isUniqueViolation(err) {
+			return exception.NewAlreadyExistsFromBase(exception.Base{
+				Message: fmt.Sprintf("user with the email '%s' already exists", user.Email),
+				Type:    errorcode.UserAlreadyExist,
+			})
 		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *RawDbImpl) IsUserEmailTaken(ctx context.Context, email string) (bool, error) {
+	var isTaken bool
+	_, err := r.queryRow(ctx, "IsUserEmailTaken", "SELECT EXISTS(SELECT 1 FROM users WHERE email = ?);", []any{email}, &isTaken)
+	if err != nil {
+		return false, err
 	}
 
 	return isTaken, nil
 }
 
 func (r *RawDbImpl) GetUserByEmail(ctx context.Context, email string) (bool, model.User, error) {
-	rows, err := r.db.Query("SELECT * FROM users WHERE email = ?;", email)
+	var user model.User
+	exists, err := r.queryReplicaRow(ctx, "GetUserByEmail", "SELECT "+userColumns+" FROM users WHERE email = ?;", []any{email}, userFields(&user)...)
+	if err != nil || !exists {
+		return false, model.User{}, err
+	}
+
+	return true, user, nil
+}
+
+func (r *RawDbImpl) GetUserById(ctx context.Context, userId string) (bool, model.User, error) {
+	var user model.User
+	exists, err := r.queryReplicaRow(ctx, "GetUserById", "SELECT "+userColumns+" FROM users WHERE id = ?;", []any{userId}, userFields(&user)...)
+	if err != nil || !exists {
+		return false, model.User{}, err
+	}
+
+	return true, user, nil
+}
+
+// GetUsersByIds returns the users which exist among the ids, in the order of the ids. The number of placeholders is
+// rounded up to a power of two by repeating the last id, so that lists of any length share a few prepared statements.
+func (r *RawDbImpl) GetUsersByIds(ctx context.Context, userIds []string) ([]model.User, error) {
+	if len(userIds) == 0 {
+		return []model.User{}, nil
+	}
+
+	numPlaceholders := 1
+	for numPlaceholders < len(userIds) {
+		numPlaceholders *= 2
+	}
+	args := make([]any, numPlaceholders)
+	for i := range args {
+		args[i] = userIds[min(i, len(userIds)-1)]
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", numPlaceholders), ", ")
+
+	// the rows are keyed by id, so the rows of a replica which failed part way are replaced by the ones of the primary
+	usersById := map[string]model.User{}
+	err := r.queryReplicaRows(ctx, "GetUsersByIds", "SELECT "+userColumns+" FROM users WHERE id IN ("+placeholders+");", args,
+		func(rows *sql.Rows) error {
+			var user model.User
+			err := rows.Scan(userFields(&user)...)
+			usersById[user.Id] = user
+			return err
+		})
 	if err != nil {
-		return false, model.User{}, fmt.Errorf("database.GetUserByEmail(): %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	if rows.Next() {
-		var user model.User
-		err := rows.Scan(&user.Id, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
-		if err != nil {
-			return false, model.User{}, fmt.Errorf("database.GetUserByEmail(): %w", err)
+	users := []model.User{}
+	for _, userId := range userIds {
+		if user, ok := usersById[userId]; ok {
+			users = append(users, user)
+			delete(usersById, userId)
 		}
-		return true, user, nil
-	} else {
-		return false, model.User{}, nil
 	}
+
+	return users, nil
 }
 
-func (r *RawDbImpl) CloseConnection() {
-	r.db.Close()
+func (r *RawDbImpl) SaveInvite(ctx context.Context, invite *model.Invite) error {
+	_, err := r.exec(ctx, "SaveInvite", "INSERT INTO invites ("+inviteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		invite.Id, invite.Code, invite.Email, invite.CreatedBy, invite.OrgId, invite.Role, invite.MaxUses, invite.UsedCount, invite.ExpiresAt, invite.CreatedAt)
+	if err != nil {
+		return r.wrapWriteErr(err)
+	}
+
+	return nil
+}
+
+func (r *RawDbImpl) GetInviteByCode(ctx context.Context, code string) (bool, model.Invite, error) {
+	var invite model.Invite
+	exists, err := r.queryRow(ctx, "GetInviteByCode", "SELECT "+inviteColumns+" FROM invites WHERE code = ?;", []any{code},
+		&invite.Id, &invite.Code, &invite.Email, &invite.CreatedBy, &invite.OrgId, &invite.Role, &invite.MaxUses, &invite.UsedCount, &invite.ExpiresAt, &invite.CreatedAt)
+	if err != nil || !exists {
+		return false, model.Invite{}, err
+	}
+
+	return true, invite, nil
 }
 
-func (r *RawDbImpl) GetUserById(ctx context.Context, userId string) (exists bool, user model.User, err error) {
-	rows, err := r.db.Query("SELECT * FROM users WHERE id = ?;", userId)
+func (r *RawDbImpl) IncrementInviteUsage(ctx context.Context, inviteId string, now time.Time) (bool, error) {
+	// the conditions are evaluated by the database while holding the row lock, so concurrent redemptions can never
+	// push used_count beyond max_uses
+	res, err := r.exec(ctx, "IncrementInviteUsage", "UPDATE invites SET used_count = used_count + 1 WHERE id = ? AND used_count < max_uses AND (expires_at IS NULL OR expires_at > ?);", inviteId, now)
 	if err != nil {
-		return false, model.User{}, fmt.Errorf("database.GetUserById(): %w", err)
+		return false, err
 	}
-	defer rows.Close()
 
-	if rows.Next() {
-		var user model.User
-		err := rows.Scan(&user.Id, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("database.IncrementInviteUsage(): %w", err)
+	}
+
+	return affectedRows == 1, nil
+}
+
+func (r *RawDbImpl) SaveOrganization(ctx context.Context, org *model.Organization) error {
+	_, err := r.exec(ctx, "SaveOrganization", "INSERT INTO organizations ("+orgColumns+") VALUES (?, ?, ?, ?, ?);",
+		org.Id, org.Name, org.CreatedBy, org.CreatedAt, org.UpdatedAt)
+	if err != nil {
+		return r.wrapWriteErr(err)
+	}
+
+	return nil
+}
+
+func (r *RawDbImpl) GetOrganizationById(ctx context.Context, orgId string) (bool, model.Organization, error) {
+	var org model.Organization
+	exists, err := r.queryRow(ctx, "GetOrganizationById", "SELECT "+orgColumns+" FROM organizations WHERE id = ?;", []any{orgId},
+		&org.Id, &org.Name, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil || !exists {
+		return false, model.Organization{}, err
+	}
+
+	return true, org, nil
+}
+
+func (r *RawDbImpl) SaveMembership(ctx context.Context, membership *model.Membership) error {
+	_, err := r.exec(ctx, "SaveMembership", "INSERT INTO memberships ("+membershipColumns+") VALUES (?, ?, ?, ?);",
+		membership.OrgId, membership.UserId, membership.Role, membership.CreatedAt)
+	if err != nil {
+		return r.wrapWriteErr(err)
+	}
+
+	return nil
+}
+
+func (r *RawDbImpl) GetMembership(ctx context.Context, orgId string, userId string) (bool, model.Membership, error) {
+	var membership model.Membership
+	exists, err := r.queryRow(ctx, "GetMembership", "SELECT "+membershipColumns+" FROM memberships WHERE org_id = ? AND user_id = ?;", []any{orgId, userId},
+		&membership.OrgId, &membership.UserId, &membership.Role, &membership.CreatedAt)
+	if err != nil || !exists {
+		return false, model.Membership{}, err
+	}
+
+	return true, membership, nil
+}
+
+func (r *RawDbImpl) GetOrgMembers(ctx context.Context, orgId string) ([]model.OrgMember, error) {
+	members := []model.OrgMember{}
+	err := r.queryRows(ctx, "GetOrgMembers", "SELECT m.user_id, u.email, m.role, m.created_at FROM memberships m INNER JOIN users u ON u.id = m.user_id WHERE m.org_id = ? ORDER BY m.created_at;", []any{orgId},
+		func(rows *sql.Rows) error {
+			var member model.OrgMember
+			err := rows.Scan(&member.UserId, &member.Email, &member.Role, &member.JoinedAt)
+			members = append(members, member)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *RawDbImpl) GetUserOrganizations(ctx context.Context, userId string) ([]model.UserOrganization, error) {
+	orgs := []model.UserOrganization{}
+	err := r.queryRows(ctx, "GetUserOrganizations", "SELECT o.id, o.name, o.created_by, o.created_at, o.updated_at, m.role FROM organizations o INNER JOIN memberships m ON m.org_id = o.id WHERE m.user_id = ? ORDER BY o.created_at;", []any{userId},
+		func(rows *sql.Rows) error {
+			var org model.UserOrganization
+			err := rows.Scan(&org.Id, &org.Name, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt, &org.Role)
+			orgs = append(orgs, org)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (r *RawDbImpl) SaveLoginEvent(ctx context.Context, event *model.LoginEvent) error {
+	_, err := r.exec(ctx, "SaveLoginEvent", "INSERT INTO login_events ("+loginEventColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		event.Id, event.UserId, event.Email, event.Success, event.FailureReason, event.Ip, event.UserAgent, event.MfaUsed, event.NewDevice, event.CreatedAt)
+	return err
+}
+
+func (r *RawDbImpl) GetLoginEvents(ctx context.Context, userId string, limit int, offset int) ([]model.LoginEvent, error) {
+	events := []model.LoginEvent{}
+	err := r.queryRows(ctx, "GetLoginEvents", "SELECT "+loginEventColumns+" FROM login_events WHERE user_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?;", []any{userId, limit, offset},
+		func(rows *sql.Rows) error {
+			var event model.LoginEvent
+			err := rows.Scan(&event.Id, &event.UserId, &event.Email, &event.Success, &event.FailureReason, &event.Ip, &event.UserAgent, &event.MfaUsed, &event.NewDevice, &event.CreatedAt)
+			events = append(events, event)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *RawDbImpl) CountLoginEvents(ctx context.Context, userId string) (int, error) {
+	var total int
+	_, err := r.queryRow(ctx, "CountLoginEvents", "SELECT COUNT(*) FROM login_events WHERE user_id = ?;", []any{userId}, &total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *RawDbImpl) GetLoginHistoryStats(ctx context.Context, userId string, ip string, userAgent string) (model.LoginHistoryStats, error) {
+	var stats model.LoginHistoryStats
+	_, err := r.queryRow(ctx, "GetLoginHistoryStats", "SELECT COUNT(*), COALESCE(SUM(CASE WHEN ip = ? THEN 1 ELSE 0 END), 0), COALESCE(SUM(CASE WHEN user_agent = ? THEN 1 ELSE 0 END), 0) FROM login_events WHERE user_id = ? AND success = TRUE;",
+		[]any{ip, userAgent, userId}, &stats.SuccessfulLogins, &stats.FromSameIp, &stats.FromSameDevice)
+	if err != nil {
+		return model.LoginHistoryStats{}, err
+	}
+
+	return stats, nil
+}
+
+func (r *RawDbImpl) SaveOutboxEvent(ctx context.Context, event *model.OutboxEvent) error {
+	_, err := r.exec(ctx, "SaveOutboxEvent", "INSERT INTO outbox_events ("+outboxColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?);",
+		event.Id, event.Topic, event.Payload, event.Attempts, event.LastError, event.NextAttemptAt, event.CreatedAt, event.SentAt)
+	return err
+}
+
+func (r *RawDbImpl) GetPendingOutboxEvents(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	events := []model.OutboxEvent{}
+	err := r.queryRows(ctx, "GetPendingOutboxEvents", "SELECT "+outboxColumns+" FROM outbox_events WHERE sent_at IS NULL AND next_attempt_at <= ? ORDER BY created_at LIMIT ?;", []any{now, limit},
+		func(rows *sql.Rows) error {
+			var event model.OutboxEvent
+			err := rows.Scan(&event.Id, &event.Topic, &event.Payload, &event.Attempts, &event.LastError, &event.NextAttemptAt, &event.CreatedAt, &event.SentAt)
+			events = append(events, event)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *RawDbImpl) ClaimOutboxEvent(ctx context.Context, eventId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	// the conditions are evaluated by the database while holding the row lock, so only one relay moves a due event
+	// into the future, the others see it as not due and skip it
+	res, err := r.exec(ctx, "ClaimOutboxEvent", "UPDATE outbox_events SET next_attempt_at = ? WHERE id = ? AND sent_at IS NULL AND next_attempt_at <= ?;", leaseUntil, eventId, now)
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("database.ClaimOutboxEvent(): %w", err)
+	}
+
+	return affectedRows == 1, nil
+}
+
+func (r *RawDbImpl) MarkOutboxEventSent(ctx context.Context, eventId string, sentAt time.Time) error {
+	_, err := r.exec(ctx, "MarkOutboxEventSent", "UPDATE outbox_events SET sent_at = ?, attempts = attempts + 1, last_error = NULL WHERE id = ?;", sentAt, eventId)
+	return err
+}
+
+func (r *RawDbImpl) MarkOutboxEventFailed(ctx context.Context, eventId string, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.exec(ctx, "MarkOutboxEventFailed", "UPDATE outbox_events SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?;", lastError, nextAttemptAt, eventId)
+	return err
+}
+
+func (r *RawDbImpl) GetOutboxStats(ctx context.Context) (model.OutboxStats, error) {
+	var stats model.OutboxStats
+	_, err := r.queryRow(ctx, "GetOutboxStats", "SELECT COUNT(*) FROM outbox_events WHERE sent_at IS NULL;", nil, &stats.Pending)
+	if err != nil {
+		return model.OutboxStats{}, err
+	}
+
+	// the oldest event is read on its own as some drivers return aggregates of timestamps as strings
+	var oldestPendingAt time.Time
+	exists, err := r.queryRow(ctx, "GetOutboxStats", "SELECT created_at FROM outbox_events WHERE sent_at IS NULL ORDER BY created_at LIMIT 1;", nil, &oldestPendingAt)
+	if err != nil {
+		return model.OutboxStats{}, err
+	}
+	if exists {
+		stats.OldestPendingAt = &oldestPendingAt
+	}
+
+	return stats, nil
+}
+
+func (r *RawDbImpl) SaveProcessedMessage(ctx context.Context, msg *model.ProcessedMessage) (bool, error) {
+	_, err := r.exec(ctx, "SaveProcessedMessage", "INSERT INTO processed_messages ("+processedColumns+") VALUES (?, ?, ?, ?);",
+		msg.Consumer, msg.MessageId, msg.ProcessedAt, msg.ExpiresAt)
+	if err != nil {
+		// the primary key is violated by a redelivery, postgres aborts the transaction which is rolled back anyway
+		if r.dialect.isUniqueViolation(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *RawDbImpl) DeleteExpiredProcessedMessages(ctx context.Context, now time.Time) (int, error) {
+	res, err := r.exec(ctx, "DeleteExpiredProcessedMessages", "DELETE FROM processed_messages WHERE expires_at <= ?;", now)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+// the event types of a webhook are stored as a comma separated list, event type names never contain commas
+const eventTypesSep = ","
+
+func (r *RawDbImpl) SaveWebhook(ctx context.Context, webhook *model.Webhook) error {
+	_, err := r.exec(ctx, "SaveWebhook", "INSERT INTO webhooks ("+webhookColumns+") VALUES (?, ?, ?, ?, ?, ?, ?);",
+		webhook.Id, webhook.OrgId, webhook.Url, webhook.Secret, strings.Join(webhook.EventTypes, eventTypesSep), webhook.CreatedBy, webhook.CreatedAt)
+	if err != nil {
+		return r.wrapWriteErr(err)
+	}
+
+	return nil
+}
+
+// webhookFields returns the destinations of a row of webhookColumns, the event types are split once the row is scanned
+func webhookFields(webhook *model.Webhook, eventTypes *string) []any {
+	return []any{&webhook.Id, &webhook.OrgId, &webhook.Url, &webhook.Secret, eventTypes, &webhook.CreatedBy, &webhook.CreatedAt}
+}
+
+func (r *RawDbImpl) GetWebhook(ctx context.Context, orgId string, webhookId string) (bool, model.Webhook, error) {
+	var webhook model.Webhook
+	var eventTypes string
+	exists, err := r.queryRow(ctx, "GetWebhook", "SELECT "+webhookColumns+" FROM webhooks WHERE id = ? AND org_id = ?;", []any{webhookId, orgId},
+		webhookFields(&webhook, &eventTypes)...)
+	if err != nil || !exists {
+		return false, model.Webhook{}, err
+	}
+
+	webhook.EventTypes = strings.Split(eventTypes, eventTypesSep)
+	return true, webhook, nil
+}
+
+func (r *RawDbImpl) GetOrgWebhooks(ctx context.Context, orgId string) ([]model.Webhook, error) {
+	return r.getWebhooks(ctx, "GetOrgWebhooks", "SELECT "+webhookColumns+" FROM webhooks WHERE org_id = ? ORDER BY created_at;", orgId)
+}
+
+func (r *RawDbImpl) GetWebhooksOfUserOrgs(ctx context.Context, userId string) ([]model.Webhook, error) {
+	return r.getWebhooks(ctx, "GetWebhooksOfUserOrgs", "SELECT w.id, w.org_id, w.url, w.secret, w.event_types, w.created_by, w.created_at FROM webhooks w INNER JOIN memberships m ON m.org_id = w.org_id WHERE m.user_id = ? ORDER BY w.created_at;", userId)
+}
+
+func (r *RawDbImpl) getWebhooks(ctx context.Context, method string, query string, args ...any) ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+	err := r.queryRows(ctx, method, query, args,
+		func(rows *sql.Rows) error {
+			var webhook model.Webhook
+			var eventTypes string
+			err := rows.Scan(webhookFields(&webhook, &eventTypes)...)
+			webhook.EventTypes = strings.Split(eventTypes, eventTypesSep)
+			webhooks = append(webhooks, webhook)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *RawDbImpl) DeleteWebhook(ctx context.Context, orgId string, webhookId string) (bool, error) {
+	deleted := false
+	err := r.WithTx(ctx, func(txDb Db) error {
+		tx := txDb.(*RawDbImpl)
+		_, err := tx.exec(ctx, "DeleteWebhook", "DELETE FROM webhook_delivery_attempts WHERE delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id = ? AND org_id = ?);", webhookId, orgId)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.exec(ctx, "DeleteWebhook", "DELETE FROM webhook_deliveries WHERE webhook_id = ? AND org_id = ?;", webhookId, orgId)
 		if err != nil {
-			return false, model.User{}, fmt.Errorf("database.GetUserById(): %w", err)
+			return err
 		}
-		return true, user, nil
-	} else {
-		return false, model.User{}, nil
+
+		res, err := tx.exec(ctx, "DeleteWebhook", "DELETE FROM webhooks WHERE id = ? AND org_id = ?;", webhookId, orgId)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("database.DeleteWebhook(): %w", err)
+		}
+
+		deleted = affectedRows == 1
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (r *RawDbImpl) SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	_, err := r.exec(ctx, "SaveWebhookDelivery", "INSERT INTO webhook_deliveries ("+deliveryColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		delivery.Id, delivery.WebhookId, delivery.OrgId, delivery.EventId, delivery.EventType, delivery.Payload, delivery.Status, delivery.Attempts,
+		delivery.LastStatusCode, delivery.LastError, delivery.NextAttemptAt, delivery.CreatedAt, delivery.DeliveredAt)
+	if err != nil {
+		// the event has already been queued for the webhook when its message is redelivered
+		if r.dialect.isUniqueViolation(err) {
+			return false, nil
+		}
+		return false, err
 	}
+
+	return true, nil
+}
+
+func deliveryFields(delivery *model.WebhookDelivery) []any {
+	return []any{&delivery.Id, &delivery.WebhookId, &delivery.OrgId, &delivery.EventId, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.Attempts,
+		&delivery.LastStatusCode, &delivery.LastError, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.DeliveredAt}
+}
+
+func (r *RawDbImpl) GetWebhookDelivery(ctx context.Context, orgId string, deliveryId string) (bool, model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	exists, err := r.queryRow(ctx, "GetWebhookDelivery", "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id = ? AND org_id = ?;", []any{deliveryId, orgId},
+		deliveryFields(&delivery)...)
+	if err != nil || !exists {
+		return false, model.WebhookDelivery{}, err
+	}
+
+	return true, delivery, nil
+}
+
+func (r *RawDbImpl) GetWebhookDeliveries(ctx context.Context, orgId string, webhookId string, limit int, offset int) ([]model.WebhookDelivery, error) {
+	return r.getWebhookDeliveries(ctx, "GetWebhookDeliveries", "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE webhook_id = ? AND org_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?;",
+		webhookId, orgId, limit, offset)
+}
+
+func (r *RawDbImpl) CountWebhookDeliveries(ctx context.Context, orgId string, webhookId string) (int, error) {
+	var total int
+	_, err := r.queryRow(ctx, "CountWebhookDeliveries", "SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ? AND org_id = ?;", []any{webhookId, orgId}, &total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *RawDbImpl) GetPendingWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	return r.getWebhookDeliveries(ctx, "GetPendingWebhookDeliveries", "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?;",
+		model.WebhookDeliveryPending, now, limit)
+}
+
+func (r *RawDbImpl) ClaimWebhookDelivery(ctx context.Context, deliveryId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	// the conditions are evaluated by the database while holding the row lock, so only one worker moves a due delivery
+	// into the future, the others see it as not due and skip it
+	res, err := r.exec(ctx, "ClaimWebhookDelivery", "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?;",
+		leaseUntil, deliveryId, model.WebhookDeliveryPending, now)
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("database.ClaimWebhookDelivery(): %w", err)
+	}
+
+	return affectedRows == 1, nil
+}
+
+func (r *RawDbImpl) getWebhookDeliveries(ctx context.Context, method string, query string, args ...any) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	err := r.queryRows(ctx, method, query, args,
+		func(rows *sql.Rows) error {
+			var delivery model.WebhookDelivery
+			err := rows.Scan(deliveryFields(&delivery)...)
+			deliveries = append(deliveries, delivery)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *RawDbImpl) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, err := r.exec(ctx, "UpdateWebhookDelivery", "UPDATE webhook_deliveries SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, next_attempt_at = ?, delivered_at = ? WHERE id = ?;",
+		delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt, delivery.Id)
+	return err
+}
+
+func (r *RawDbImpl) SaveWebhookDeliveryAttempt(ctx context.Context, attempt *model.WebhookDeliveryAttempt) error {
+	_, err := r.exec(ctx, "SaveWebhookDeliveryAttempt", "INSERT INTO webhook_delivery_attempts ("+attemptColumns+") VALUES (?, ?, ?, ?, ?, ?, ?);",
+		attempt.Id, attempt.DeliveryId, attempt.StatusCode, attempt.Error, attempt.ResponseBody, attempt.DurationMs, attempt.CreatedAt)
+	return err
+}
+
+func (r *RawDbImpl) GetWebhookDeliveryAttempts(ctx context.Context, orgId string, deliveryId string) ([]model.WebhookDeliveryAttempt, error) {
+	attempts := []model.WebhookDeliveryAttempt{}
+	err := r.queryRows(ctx, "GetWebhookDeliveryAttempts", "SELECT a.id, a.delivery_id, a.status_code, a.error, a.response_body, a.duration_ms, a.created_at FROM webhook_delivery_attempts a INNER JOIN webhook_deliveries d ON d.id = a.delivery_id WHERE a.delivery_id = ? AND d.org_id = ? ORDER BY a.created_at;", []any{deliveryId, orgId},
+		func(rows *sql.Rows) error {
+			var attempt model.WebhookDeliveryAttempt
+			err := rows.Scan(&attempt.Id, &attempt.DeliveryId, &attempt.StatusCode, &attempt.Error, &attempt.ResponseBody, &attempt.DurationMs, &attempt.CreatedAt)
+			attempts = append(attempts, attempt)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
 }
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetQueryTimeout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "default when not set", value: "", expected: defaultQueryTimeout},
+		{name: "seconds", value: "3s", expected: 3 * time.Second},
+		{name: "milliseconds", value: "250ms", expected: 250 * time.Millisecond},
+		{name: "disabled", value: "0", expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// ACT
+			timeoutRes, errRes := getQueryTimeout(&config.AppConfig{DB_QUERY_TIMEOUT: testCase.value})
+
+			// ASSERT
+			assert.Nil(t, errRes)
+			assert.Equal(t, testCase.expected, timeoutRes)
+		})
+	}
+}
+
+func Test_GetQueryTimeout_Should_Fail_On_Invalid_Value(t *testing.T) {
+	// ACT
+	_, errRes := getQueryTimeout(&config.AppConfig{DB_QUERY_TIMEOUT: "5 seconds"})
+
+	// ASSERT
+	assert.ErrorContains(t, errRes, "invalid DB_QUERY_TIMEOUT")
+}
+
+func Test_GetPoolConfig(t *testing.T) {
+	// ACT
+	defaultConfRes, defaultErrRes := getPoolConfig(&config.AppConfig{})
+	confRes, errRes := getPoolConfig(&config.AppConfig{
+		DB_MAX_OPEN_CONNS:     "25",
+		DB_MAX_IDLE_CONNS:     "5",
+		DB_CONN_MAX_LIFETIME:  "10m",
+		DB_CONN_MAX_IDLE_TIME: "30s",
+	})
+
+	// ASSERT
+	assert.Nil(t, defaultErrRes)
+	assert.Equal(t, poolConfig{maxOpenConns: 10, maxIdleConns: 10, connMaxLifetime: 3 * time.Minute}, defaultConfRes)
+	assert.Nil(t, errRes)
+	assert.Equal(t, poolConfig{maxOpenConns: 25, maxIdleConns: 5, connMaxLifetime: 10 * time.Minute, connMaxIdleTime: 30 * time.Second}, confRes)
+}
+
+func Test_GetPoolConfig_Should_Fail_On_Invalid_Value(t *testing.T) {
+	// ACT
+	_, errRes := getPoolConfig(&config.AppConfig{DB_MAX_OPEN_CONNS: "ten"})
+
+	// ASSERT
+	assert.ErrorContains(t, errRes, "invalid DB_MAX_OPEN_CONNS")
+}
+
+func Test_GetReplicaDsns(t *testing.T) {
+	// ACT
+	dsnsRes := getReplicaDsns(&config.AppConfig{DB_REPLICA_DSNS: "user:pw@tcp(replica-1:3306)/db, user:pw@tcp(replica-2:3306)/db,"})
+
+	// ASSERT
+	assert.Equal(t, []string{"user:pw@tcp(replica-1:3306)/db", "user:pw@tcp(replica-2:3306)/db"}, dsnsRes)
+	assert.Empty(t, getReplicaDsns(&config.AppConfig{}))
+}
+
+func Test_NewDb_Should_Reject_Replicas_With_Sqlite(t *testing.T) {
+	// ACT
+	dbRes, errRes := NewDb(&config.AppConfig{DB_DRIVER: DriverSqlite, DB_DATABASE: ":memory:", DB_REPLICA_DSNS: "file:replica.db"})
+
+	// ASSERT
+	assert.Nil(t, dbRes)
+	assert.EqualError(t, errRes, "database.NewDbImpl: read replicas are not supported with sqlite")
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/pjmessi/golang-practice/internal/model"
 	"github.com/stretchr/testify/mock"
@@ -16,6 +17,16 @@ func (r *DbMock) CheckHealth() error {
 	return args.Error(0)
 }
 
+func (r *DbMock) PoolStats() []PoolStats {
+	args := r.Called()
+	return args.Get(0).([]PoolStats)
+}
+
+// WithTx runs fn against the mock itself so that the calls made inside the transaction can be asserted as usual
+func (r *DbMock) WithTx(ctx context.Context, fn func(txDb Db) error) error {
+	return fn(r)
+}
+
 func (r *DbMock) SaveUser(ctx context.Context, user *model.User) error {
 	args := r.Called(ctx, user)
 	return args.Error(0)
@@ -36,6 +47,186 @@ func (r *DbMock) GetUserById(ctx context.Context, userId string) (bool, model.Us
 	return args.Bool(0), args.Get(1).(model.User), args.Error(2)
 }
 
+func (r *DbMock) GetUsersByIds(ctx context.Context, userIds []string) ([]model.User, error) {
+	args := r.Called(ctx, userIds)
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (r *DbMock) SaveInvite(ctx context.Context, invite *model.Invite) error {
+	args := r.Called(ctx, invite)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetInviteByCode(ctx context.Context, code string) (bool, model.Invite, error) {
+	args := r.Called(ctx, code)
+	return args.Bool(0), args.Get(1).(model.Invite), args.Error(2)
+}
+
+func (r *DbMock) IncrementInviteUsage(ctx context.Context, inviteId string, now time.Time) (bool, error) {
+	args := r.Called(ctx, inviteId, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *DbMock) SaveOrganization(ctx context.Context, org *model.Organization) error {
+	args := r.Called(ctx, org)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetOrganizationById(ctx context.Context, orgId string) (bool, model.Organization, error) {
+	args := r.Called(ctx, orgId)
+	return args.Bool(0), args.Get(1).(model.Organization), args.Error(2)
+}
+
+func (r *DbMock) SaveMembership(ctx context.Context, membership *model.Membership) error {
+	args := r.Called(ctx, membership)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetMembership(ctx context.Context, orgId string, userId string) (bool, model.Membership, error) {
+	args := r.Called(ctx, orgId, userId)
+	return args.Bool(0), args.Get(1).(model.Membership), args.Error(2)
+}
+
+func (r *DbMock) GetOrgMembers(ctx context.Context, orgId string) ([]model.OrgMember, error) {
+	args := r.Called(ctx, orgId)
+	return args.Get(0).([]model.OrgMember), args.Error(1)
+}
+
+func (r *DbMock) GetUserOrganizations(ctx context.Context, userId string) ([]model.UserOrganization, error) {
+	args := r.Called(ctx, userId)
+	return args.Get(0).([]model.UserOrganization), args.Error(1)
+}
+
+func (r *DbMock) SaveLoginEvent(ctx context.Context, event *model.LoginEvent) error {
+	args := r.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetLoginEvents(ctx context.Context, userId string, limit int, offset int) ([]model.LoginEvent, error) {
+	args := r.Called(ctx, userId, limit, offset)
+	return args.Get(0).([]model.LoginEvent), args.Error(1)
+}
+
+func (r *DbMock) CountLoginEvents(ctx context.Context, userId string) (int, error) {
+	args := r.Called(ctx, userId)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *DbMock) GetLoginHistoryStats(ctx context.Context, userId string, ip string, userAgent string) (model.LoginHistoryStats, error) {
+	args := r.Called(ctx, userId, ip, userAgent)
+	return args.Get(0).(model.LoginHistoryStats), args.Error(1)
+}
+
 func (r *DbMock) CloseConnection() {
 	r.Called()
 }
+
+func (r *DbMock) SaveOutboxEvent(ctx context.Context, event *model.OutboxEvent) error {
+	args := r.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetPendingOutboxEvents(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	args := r.Called(ctx, now, limit)
+	return args.Get(0).([]model.OutboxEvent), args.Error(1)
+}
+
+func (r *DbMock) ClaimOutboxEvent(ctx context.Context, eventId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	args := r.Called(ctx, eventId, now, leaseUntil)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *DbMock) MarkOutboxEventSent(ctx context.Context, eventId string, sentAt time.Time) error {
+	args := r.Called(ctx, eventId, sentAt)
+	return args.Error(0)
+}
+
+func (r *DbMock) MarkOutboxEventFailed(ctx context.Context, eventId string, lastError string, nextAttemptAt time.Time) error {
+	args := r.Called(ctx, eventId, lastError, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetOutboxStats(ctx context.Context) (model.OutboxStats, error) {
+	args := r.Called(ctx)
+	return args.Get(0).(model.OutboxStats), args.Error(1)
+}
+
+func (r *DbMock) SaveProcessedMessage(ctx context.Context, msg *model.ProcessedMessage) (bool, error) {
+	args := r.Called(ctx, msg)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *DbMock) DeleteExpiredProcessedMessages(ctx context.Context, now time.Time) (int, error) {
+	args := r.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *DbMock) SaveWebhook(ctx context.Context, webhook *model.Webhook) error {
+	args := r.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetWebhook(ctx context.Context, orgId string, webhookId string) (bool, model.Webhook, error) {
+	args := r.Called(ctx, orgId, webhookId)
+	return args.Bool(0), args.Get(1).(model.Webhook), args.Error(2)
+}
+
+func (r *DbMock) GetOrgWebhooks(ctx context.Context, orgId string) ([]model.Webhook, error) {
+	args := r.Called(ctx, orgId)
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (r *DbMock) GetWebhooksOfUserOrgs(ctx context.Context, userId string) ([]model.Webhook, error) {
+	args := r.Called(ctx, userId)
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (r *DbMock) DeleteWebhook(ctx context.Context, orgId string, webhookId string) (bool, error) {
+	args := r.Called(ctx, orgId, webhookId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *DbMock) SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	args := r.Called(ctx, delivery)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *DbMock) GetWebhookDelivery(ctx context.Context, orgId string, deliveryId string) (bool, model.WebhookDelivery, error) {
+	args := r.Called(ctx, orgId, deliveryId)
+	return args.Bool(0), args.Get(1).(model.WebhookDelivery), args.Error(2)
+}
+
+func (r *DbMock) GetWebhookDeliveries(ctx context.Context, orgId string, webhookId string, limit int, offset int) ([]model.WebhookDelivery, error) {
+	args := r.Called(ctx, orgId, webhookId, limit, offset)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (r *DbMock) CountWebhookDeliveries(ctx context.Context, orgId string, webhookId string) (int, error) {
+	args := r.Called(ctx, orgId, webhookId)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *DbMock) GetPendingWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	args := r.Called(ctx, now, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (r *DbMock) ClaimWebhookDelivery(ctx context.Context, deliveryId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	args := r.Called(ctx, deliveryId, now, leaseUntil)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *DbMock) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	args := r.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (r *DbMock) SaveWebhookDeliveryAttempt(ctx context.Context, attempt *model.WebhookDeliveryAttempt) error {
+	args := r.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (r *DbMock) GetWebhookDeliveryAttempts(ctx context.Context, orgId string, deliveryId string) ([]model.WebhookDeliveryAttempt, error) {
+	args := r.Called(ctx, orgId, deliveryId)
+	return args.Get(0).([]model.WebhookDeliveryAttempt), args.Error(1)
+}
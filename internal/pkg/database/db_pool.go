@@ -0,0 +1,125 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+)
+
+const defaultMaxOpenConns = 10
+const defaultMaxIdleConns = 10
+const defaultConnMaxLifetime = 3 * time.Minute
+
+type poolConfig struct {
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	connMaxIdleTime time.Duration
+}
+
+// PoolStats are the statistics of one connection pool, the primary pool is named "primary" and the replica pools
+// "replica-1", "replica-2"...
+type PoolStats struct {
+	Name string `json:"name"`
+	sql.DBStats
+}
+
+func getPoolConfig(appConf *config.AppConfig) (poolConfig, error) {
+	conf := poolConfig{
+		maxOpenConns:    defaultMaxOpenConns,
+		maxIdleConns:    defaultMaxIdleConns,
+		connMaxLifetime: defaultConnMaxLifetime,
+	}
+
+	var err error
+	if appConf.DB_MAX_OPEN_CONNS != "" {
+		conf.maxOpenConns, err = strconv.Atoi(appConf.DB_MAX_OPEN_CONNS)
+		if err != nil {
+			return poolConfig{}, fmt.Errorf("invalid DB_MAX_OPEN_CONNS: %w", err)
+		}
+	}
+
+	if appConf.DB_MAX_IDLE_CONNS != "" {
+		conf.maxIdleConns, err = strconv.Atoi(appConf.DB_MAX_IDLE_CONNS)
+		if err != nil {
+			return poolConfig{}, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: %w", err)
+		}
+	}
+
+	if appConf.DB_CONN_MAX_LIFETIME != "" {
+		conf.connMaxLifetime, err = time.ParseDuration(appConf.DB_CONN_MAX_LIFETIME)
+		if err != nil {
+			return poolConfig{}, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %w", err)
+		}
+	}
+
+	if appConf.DB_CONN_MAX_IDLE_TIME != "" {
+		conf.connMaxIdleTime, err = time.ParseDuration(appConf.DB_CONN_MAX_IDLE_TIME)
+		if err != nil {
+			return poolConfig{}, fmt.Errorf("invalid DB_CONN_MAX_IDLE_TIME: %w", err)
+		}
+	}
+
+	return conf, nil
+}
+
+func (c poolConfig) apply(db *sql.DB) {
+	db.SetMaxOpenConns(c.maxOpenConns)
+	db.SetMaxIdleConns(c.maxIdleConns)
+	db.SetConnMaxLifetime(c.connMaxLifetime)
+	db.SetConnMaxIdleTime(c.connMaxIdleTime)
+}
+
+// getReplicaDsns returns the dsns of the read replicas, they are written in the format of the driver
+func getReplicaDsns(appConf *config.AppConfig) []string {
+	dsns := []string{}
+	for _, dsn := range strings.Split(appConf.DB_REPLICA_DSNS, ",") {
+		if dsn = strings.TrimSpace(dsn); dsn != "" {
+			dsns = append(dsns, dsn)
+		}
+	}
+
+	return dsns
+}
+
+// openReplicas returns a Db for every read replica, they share the settings of the primary
+func openReplicas(appConf *config.AppConfig, dialect dialect, queryTimeout time.Duration) ([]*RawDbImpl, error) {
+	dsns := getReplicaDsns(appConf)
+	if len(dsns) > 0 && GetDriver(appConf) == DriverSqlite {
+		return nil, fmt.Errorf("read replicas are not supported with sqlite")
+	}
+
+	poolConf, err := getPoolConfig(appConf)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := []*RawDbImpl{}
+	for _, dsn := range dsns {
+		pool, err := sql.Open(dialect.driverName(), dsn)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, err
+		}
+		poolConf.apply(pool)
+
+		replicas = append(replicas, &RawDbImpl{
+			pool:         pool,
+			stmts:        newStmtCache(pool),
+			dialect:      dialect,
+			queryTimeout: queryTimeout,
+		})
+	}
+
+	return replicas, nil
+}
+
+func closeReplicas(replicas []*RawDbImpl) {
+	for _, replica := range replicas {
+		replica.CloseConnection()
+	}
+}
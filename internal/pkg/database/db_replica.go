@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+)
+
+const writeTrackerCtxKey = "dbWriteTracker"
+
+// writeTracker remembers that a request has written to the primary, replicas may lag behind the primary so the
+// following reads of the request are answered by the primary to see its own writes
+type writeTracker struct {
+	hasWritten atomic.Bool
+}
+
+// WithReadYourWrites returns a context whose reads go to the primary once a write has been made with it, it is meant
+// to wrap the context of a whole request
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return ctxutil.AddValue(ctx, writeTrackerCtxKey, &writeTracker{})
+}
+
+func markWritten(ctx context.Context) {
+	if tracker, ok := ctxutil.GetValue(ctx, writeTrackerCtxKey).(*writeTracker); ok {
+		tracker.hasWritten.Store(true)
+	}
+}
+
+func hasWritten(ctx context.Context) bool {
+	tracker, ok := ctxutil.GetValue(ctx, writeTrackerCtxKey).(*writeTracker)
+	return ok && tracker.hasWritten.Load()
+}
+
+// nextReplica returns the replicas in round robin, nil is returned when none is configured
+func (r *RawDbImpl) nextReplica() *RawDbImpl {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+
+	index := r.replicaIndex.Add(1) % uint64(len(r.replicas))
+	return r.replicas[index]
+}
+
+// queryReplicaRow works like queryRow but runs the query on a read replica when one is configured. The primary answers
+// the query inside transactions, after the request has written and when the replica fails.
+func (r *RawDbImpl) queryReplicaRow(ctx context.Context, method string, query string, args []any, dest ...any) (bool, error) {
+	replica := r.nextReplica()
+	if replica == nil || r.tx != nil || hasWritten(ctx) {
+		return r.queryRow(ctx, method, query, args, dest...)
+	}
+
+	exists, err := replica.queryRow(ctx, method, query, args, dest...)
+	if err != nil && ctx.Err() == nil {
+		return r.queryRow(ctx, method, query, args, dest...)
+	}
+
+	return exists, err
+}
+
+// queryReplicaRows works like queryRows but runs the query on a read replica like queryReplicaRow. scanRow is called
+// again for the rows of the primary when the replica fails part way, so it must not keep the rows of a failed attempt.
+func (r *RawDbImpl) queryReplicaRows(ctx context.Context, method string, query string, args []any, scanRow func(rows *sql.Rows) error) error {
+	replica := r.nextReplica()
+	if replica == nil || r.tx != nil || hasWritten(ctx) {
+		return r.queryRows(ctx, method, query, args, scanRow)
+	}
+
+	err := replica.queryRows(ctx, method, query, args, scanRow)
+	if err != nil && ctx.Err() == nil {
+		return r.queryRows(ctx, method, query, args, scanRow)
+	}
+
+	return err
+}
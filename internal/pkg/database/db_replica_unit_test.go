@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupDbWithReplica returns a primary and its read replica, they are separate sqlite databases so that the tests can
+// tell which one answered a query
+func setupDbWithReplica(t *testing.T) (*RawDbImpl, *RawDbImpl) {
+	t.Helper()
+
+	primary := setupSqliteDbAt(t, filepath.Join(t.TempDir(), "primary.db")).(*RawDbImpl)
+	replica := setupSqliteDbAt(t, filepath.Join(t.TempDir(), "replica.db")).(*RawDbImpl)
+	primary.replicas = []*RawDbImpl{replica}
+
+	return primary, replica
+}
+
+func genReplicaTestUser(id string) model.User {
+	return model.User{Id: id, Email: id + "@example.com", CreatedAt: time.Now().UTC()}
+}
+
+func Test_Replica_GetUserById_Should_Read_From_Replica(t *testing.T) {
+	// ARRANGE
+	primary, replica := setupDbWithReplica(t)
+	ctx := context.Background()
+	user := genReplicaTestUser("replica-only")
+	err := replica.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+
+	// ACT
+	existsRes, userRes, errRes := primary.GetUserById(ctx, user.Id)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.True(t, existsRes)
+	assert.Equal(t, user.Email, userRes.Email)
+}
+
+func Test_Replica_Should_Read_From_Primary_After_Write_Of_The_Request(t *testing.T) {
+	// ARRANGE
+	primary, _ := setupDbWithReplica(t)
+	ctx := WithReadYourWrites(context.Background())
+	user := genReplicaTestUser("primary-only")
+
+	// ACT
+	saveErr := primary.SaveUser(ctx, &user)
+	existsRes, _, errRes := primary.GetUserByEmail(ctx, user.Email)
+	otherReqExistsRes, _, _ := primary.GetUserByEmail(context.Background(), user.Email)
+
+	// ASSERT
+	assert.Nil(t, saveErr)
+	assert.Nil(t, errRes)
+	assert.True(t, existsRes, "the request should see its own write")
+	assert.False(t, otherReqExistsRes, "other requests should read from the replica")
+}
+
+func Test_Replica_Should_Read_From_Primary_Inside_Transactions(t *testing.T) {
+	// ARRANGE
+	primary, replica := setupDbWithReplica(t)
+	ctx := context.Background()
+	user := genReplicaTestUser("replica-only")
+	err := replica.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+
+	// ACT
+	var existsRes bool
+	errRes := primary.WithTx(ctx, func(txDb Db) error {
+		var err error
+		existsRes, _, err = txDb.GetUserById(ctx, user.Id)
+		return err
+	})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.False(t, existsRes)
+}
+
+func Test_Replica_Should_Fall_Back_To_Primary_When_Replica_Fails(t *testing.T) {
+	// ARRANGE
+	primary, replica := setupDbWithReplica(t)
+	ctx := context.Background()
+	user := genReplicaTestUser("primary-only")
+	err := primary.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+	replica.CloseConnection()
+
+	// ACT
+	existsRes, _, errRes := primary.GetUserById(ctx, user.Id)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.True(t, existsRes)
+}
+
+func Test_PoolStats_Should_Return_Stats_Of_Every_Pool(t *testing.T) {
+	// ARRANGE
+	primary, _ := setupDbWithReplica(t)
+
+	// ACT
+	statsRes := primary.PoolStats()
+
+	// ASSERT
+	assert.Len(t, statsRes, 2)
+	assert.Equal(t, "primary", statsRes[0].Name)
+	assert.Equal(t, 1, statsRes[0].MaxOpenConnections)
+	assert.Equal(t, "replica-1", statsRes[1].Name)
+}
@@ -0,0 +1,454 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/migrations"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupSqliteDb returns a Db backed by an in memory sqlite database with the schema of the embedded migrations
+func setupSqliteDb(t *testing.T) Db {
+	t.Helper()
+	return setupSqliteDbAt(t, ":memory:")
+}
+
+// setupSqliteDbAt works like setupSqliteDb with the database file at the path
+func setupSqliteDbAt(t *testing.T, path string) Db {
+	t.Helper()
+
+	db, err := NewDb(&config.AppConfig{DB_DRIVER: DriverSqlite, DB_DATABASE: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.CloseConnection)
+
+	paths, err := fs.Glob(migrations.FS, "sqlite/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		script, err := migrations.FS.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = db.(*RawDbImpl).pool.Exec(string(script))
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+	}
+
+	return db
+}
+
+func Test_Sqlite_CheckHealth(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+
+	// ACT
+	errRes := db.CheckHealth()
+
+	// ASSERT
+	assert.Nil(t, errRes)
+}
+
+func Test_Sqlite_SaveUser_Should_Save_User(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	password := "hashed"
+	user := model.User{
+		Id:        "d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0",
+		Email:     "john@example.com",
+		Password:  &password,
+		CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+	}
+
+	// ACT
+	errRes := db.SaveUser(ctx, &user)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+
+	exists, userRes, err := db.GetUserByEmail(ctx, user.Email)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, user.Id, userRes.Id)
+	assert.Equal(t, password, *userRes.Password)
+	assert.True(t, user.CreatedAt.Equal(userRes.CreatedAt))
+
+	isTaken, err := db.IsUserEmailTaken(ctx, user.Email)
+	assert.Nil(t, err)
+	assert.True(t, isTaken)
+}
+
+func Test_Sqlite_SaveUser_Should_Return_AlreadyExists_On_Duplicate_Id(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	user := model.User{Id: "d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0", Email: "john@example.com", CreatedAt: time.Now().UTC()}
+	err := db.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+
+	// ACT
+	errRes := db.SaveUser(ctx, &user)
+
+	// ASSERT
+	var alreadyExists exception.AlreadyExists
+	assert.True(t, errors.As(errRes, &alreadyExists))
+}
+
+func Test_Sqlite_WithTx_Should_Rollback_On_Error(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	expectedErr := errors.New("some error")
+
+	// ACT
+	errRes := db.WithTx(ctx, func(txDb Db) error {
+		err := txDb.SaveUser(ctx, &model.User{Id: "d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0", Email: "john@example.com", CreatedAt: time.Now().UTC()})
+		if err != nil {
+			return err
+		}
+
+		// the savepoint of the nested transaction is rolled back alone
+		nestedErr := txDb.WithTx(ctx, func(nestedDb Db) error {
+			err := nestedDb.SaveUser(ctx, &model.User{Id: "8f0e2a51-2bd9-4cc8-9a57-4c3e6f0f4d11", Email: "jane@example.com", CreatedAt: time.Now().UTC()})
+			if err != nil {
+				return err
+			}
+			return expectedErr
+		})
+		assert.ErrorIs(t, nestedErr, expectedErr)
+
+		isTaken, err := txDb.IsUserEmailTaken(ctx, "jane@example.com")
+		assert.Nil(t, err)
+		assert.False(t, isTaken)
+
+		return expectedErr
+	})
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, expectedErr)
+
+	isTaken, err := db.IsUserEmailTaken(ctx, "john@example.com")
+	assert.Nil(t, err)
+	assert.False(t, isTaken)
+}
+
+func Test_Sqlite_GetUserOrganizations(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	org := model.Organization{Id: "0b6c0f7e-8d47-4b8a-9f0e-7d1d2c3b4a59", Name: "Acme", CreatedBy: "d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0", CreatedAt: now}
+	err := db.SaveOrganization(ctx, &org)
+	assert.Nil(t, err)
+	err = db.SaveMembership(ctx, &model.Membership{OrgId: org.Id, UserId: org.CreatedBy, Role: "owner", CreatedAt: now})
+	assert.Nil(t, err)
+
+	// ACT
+	orgsRes, errRes := db.GetUserOrganizations(ctx, org.CreatedBy)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Len(t, orgsRes, 1)
+	assert.Equal(t, org.Id, orgsRes[0].Id)
+	assert.Equal(t, "Acme", orgsRes[0].Name)
+	assert.Equal(t, "owner", orgsRes[0].Role)
+}
+
+func Test_Sqlite_GetUsersByIds_Should_Return_Existing_Users_In_Order_Of_Ids(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	ids := []string{"d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0", "8f0e2a51-2bd9-4cc8-9a57-4c3e6f0f4d11", "0b6c0f7e-8d47-4b8a-9f0e-7d1d2c3b4a59"}
+	for i, id := range ids {
+		err := db.SaveUser(ctx, &model.User{Id: id, Email: fmt.Sprintf("user%d@example.com", i), CreatedAt: time.Now().UTC()})
+		assert.Nil(t, err)
+	}
+
+	// ACT
+	usersRes, errRes := db.GetUsersByIds(ctx, []string{ids[2], "missing", ids[0], ids[2]})
+	emptyRes, emptyErrRes := db.GetUsersByIds(ctx, []string{})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Len(t, usersRes, 2)
+	assert.Equal(t, ids[2], usersRes[0].Id)
+	assert.Equal(t, ids[0], usersRes[1].Id)
+	assert.Nil(t, emptyErrRes)
+	assert.Empty(t, emptyRes)
+	_, isCached := db.(*RawDbImpl).stmts.lookup("SELECT " + userColumns + " FROM users WHERE id IN (?, ?, ?, ?);")
+	assert.True(t, isCached, "should round the placeholders up to a power of two")
+}
+
+func Test_Sqlite_SaveUser_Should_Return_AlreadyExists_On_Duplicate_Email_With_Other_Case(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	err := db.SaveUser(ctx, &model.User{Id: "d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0", Email: "john@example.com", CreatedAt: time.Now().UTC()})
+	assert.Nil(t, err)
+
+	// ACT
+	errRes := db.SaveUser(ctx, &model.User{Id: "8f0e2a51-2bd9-4cc8-9a57-4c3e6f0f4d11", Email: "John@Example.com", CreatedAt: time.Now().UTC()})
+
+	// ASSERT
+	assert.Equal(t, exception.NewAlreadyExistsFromBase(exception.Base{
+		Message: "user with the email 'John@Example.com' already exists",
+		Type:    errorcode.UserAlreadyExist,
+	}), errRes)
+}
+
+func Test_Sqlite_Should_Cache_Prepared_Statements(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	stmts := db.(*RawDbImpl).stmts
+
+	// ACT
+	_, _, errRes := db.GetUserById(ctx, "d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0")
+	_, _, secondErrRes := db.GetUserById(ctx, "8f0e2a51-2bd9-4cc8-9a57-4c3e6f0f4d11")
+	txErrRes := db.WithTx(ctx, func(txDb Db) error {
+		_, _, err := txDb.GetUserById(ctx, "d2c1a7a4-6f38-4c26-a8a4-1e1b0d7cf1a0")
+		return err
+	})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Nil(t, secondErrRes)
+	assert.Nil(t, txErrRes)
+	assert.Len(t, stmts.stmts, 1)
+	_, isCached := stmts.lookup("SELECT " + userColumns + " FROM users WHERE id = ?;")
+	assert.True(t, isCached)
+}
+
+func Test_Sqlite_Should_Return_QueryError_On_Timeout(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	db.(*RawDbImpl).queryTimeout = time.Nanosecond
+
+	// ACT
+	_, errRes := db.IsUserEmailTaken(context.Background(), "john@example.com")
+
+	// ASSERT
+	var queryErr *QueryError
+	assert.True(t, errors.As(errRes, &queryErr))
+	assert.Equal(t, "IsUserEmailTaken", queryErr.Method)
+	assert.Equal(t, "SELECT EXISTS(SELECT 1 FROM users WHERE email = ?);", queryErr.Query)
+	assert.True(t, queryErr.IsTimeout())
+	assert.ErrorIs(t, errRes, context.DeadlineExceeded)
+	assert.EqualError(t, errRes, "database.IsUserEmailTaken(): context deadline exceeded")
+}
+
+func Test_Sqlite_Outbox_Should_Return_Due_Events_Until_Sent(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	due := model.OutboxEvent{Id: "event-1", Topic: "EVENT.USER.NEW", Payload: []byte(`{"id":"1"}`), NextAttemptAt: now.Add(-time.Minute), CreatedAt: now.Add(-time.Minute)}
+	notDue := model.OutboxEvent{Id: "event-2", Topic: "EVENT.USER.NEW", Payload: []byte(`{"id":"2"}`), NextAttemptAt: now.Add(time.Minute), CreatedAt: now}
+	assert.Nil(t, db.SaveOutboxEvent(ctx, &due))
+	assert.Nil(t, db.SaveOutboxEvent(ctx, &notDue))
+
+	// ACT
+	pendingRes, errPendingRes := db.GetPendingOutboxEvents(ctx, now, 10)
+	errFailedRes := db.MarkOutboxEventFailed(ctx, due.Id, "no responders", now.Add(2*time.Minute))
+	pendingAfterFailureRes, _ := db.GetPendingOutboxEvents(ctx, now.Add(3*time.Minute), 10)
+	errSentRes := db.MarkOutboxEventSent(ctx, notDue.Id, now)
+	statsRes, errStatsRes := db.GetOutboxStats(ctx)
+
+	// ASSERT
+	assert.Nil(t, errPendingRes)
+	if assert.Len(t, pendingRes, 1) {
+		assert.Equal(t, due.Id, pendingRes[0].Id)
+		assert.Equal(t, due.Payload, pendingRes[0].Payload)
+		assert.Equal(t, 0, pendingRes[0].Attempts)
+	}
+
+	assert.Nil(t, errFailedRes)
+	if assert.Len(t, pendingAfterFailureRes, 2) {
+		assert.Equal(t, due.Id, pendingAfterFailureRes[0].Id)
+		assert.Equal(t, 1, pendingAfterFailureRes[0].Attempts)
+		assert.Equal(t, "no responders", *pendingAfterFailureRes[0].LastError)
+	}
+
+	assert.Nil(t, errSentRes)
+	assert.Nil(t, errStatsRes)
+	assert.Equal(t, 1, statsRes.Pending)
+	if assert.NotNil(t, statsRes.OldestPendingAt) {
+		assert.True(t, due.CreatedAt.Equal(*statsRes.OldestPendingAt))
+	}
+}
+
+func Test_Sqlite_Outbox_Should_Claim_Due_Event_Once(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	due := model.OutboxEvent{Id: "event-1", Topic: "EVENT.USER.NEW", Payload: []byte(`{"id":"1"}`), NextAttemptAt: now.Add(-time.Minute), CreatedAt: now.Add(-time.Minute)}
+	assert.Nil(t, db.SaveOutboxEvent(ctx, &due))
+
+	// ACT
+	claimedRes, errClaimedRes := db.ClaimOutboxEvent(ctx, due.Id, now, now.Add(time.Minute))
+	claimedAgainRes, errClaimedAgainRes := db.ClaimOutboxEvent(ctx, due.Id, now, now.Add(time.Minute))
+	pendingRes, _ := db.GetPendingOutboxEvents(ctx, now, 10)
+	claimedAfterLeaseRes, _ := db.ClaimOutboxEvent(ctx, due.Id, now.Add(time.Minute), now.Add(2*time.Minute))
+	_ = db.MarkOutboxEventSent(ctx, due.Id, now)
+	claimedAfterSentRes, _ := db.ClaimOutboxEvent(ctx, due.Id, now.Add(3*time.Minute), now.Add(4*time.Minute))
+
+	// ASSERT
+	assert.Nil(t, errClaimedRes)
+	assert.True(t, claimedRes)
+	assert.Nil(t, errClaimedAgainRes)
+	assert.False(t, claimedAgainRes, "a claimed event should not be claimed by another relay")
+	assert.Empty(t, pendingRes, "a claimed event should not be pending until its lease expires")
+	assert.True(t, claimedAfterLeaseRes, "an event should be claimed again once its lease expires")
+	assert.False(t, claimedAfterSentRes, "a sent event should not be claimed")
+}
+
+func Test_Sqlite_ProcessedMessages_Should_Be_Saved_Once_Until_Expired(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := model.ProcessedMessage{Consumer: "consumer", MessageId: "event-1", ProcessedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	recent := model.ProcessedMessage{Consumer: "consumer", MessageId: "event-2", ProcessedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	// ACT
+	savedRes, errSaveRes := db.SaveProcessedMessage(ctx, &expired)
+	_, _ = db.SaveProcessedMessage(ctx, &recent)
+	savedAgainRes, errSaveAgainRes := db.SaveProcessedMessage(ctx, &recent)
+	savedOtherConsumerRes, _ := db.SaveProcessedMessage(ctx, &model.ProcessedMessage{Consumer: "other_consumer", MessageId: "event-2", ProcessedAt: now, ExpiresAt: now.Add(time.Hour)})
+	deletedRes, errDeleteRes := db.DeleteExpiredProcessedMessages(ctx, now)
+
+	// ASSERT
+	assert.Nil(t, errSaveRes)
+	assert.True(t, savedRes)
+	assert.Nil(t, errSaveAgainRes)
+	assert.False(t, savedAgainRes)
+	assert.True(t, savedOtherConsumerRes)
+	assert.Nil(t, errDeleteRes)
+	assert.Equal(t, 1, deletedRes)
+
+	savedExpiredRes, err := db.SaveProcessedMessage(ctx, &expired)
+	assert.Nil(t, err)
+	assert.True(t, savedExpiredRes)
+}
+
+func Test_Sqlite_Webhook_Delivery_Should_Be_Claimed_Once(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	delivery := model.WebhookDelivery{Id: "delivery-1", WebhookId: "webhook-1", OrgId: "org-1", EventId: "event-1", EventType: "user.registered", Payload: []byte(`{"id":"event-1"}`),
+		Status: model.WebhookDeliveryPending, NextAttemptAt: now.Add(-time.Minute), CreatedAt: now.Add(-time.Minute)}
+	_, err := db.SaveWebhookDelivery(ctx, &delivery)
+	assert.Nil(t, err)
+
+	// ACT
+	claimedRes, errClaimedRes := db.ClaimWebhookDelivery(ctx, delivery.Id, now, now.Add(time.Minute))
+	claimedAgainRes, errClaimedAgainRes := db.ClaimWebhookDelivery(ctx, delivery.Id, now, now.Add(time.Minute))
+	pendingRes, _ := db.GetPendingWebhookDeliveries(ctx, now, 10)
+	claimedAfterLeaseRes, _ := db.ClaimWebhookDelivery(ctx, delivery.Id, now.Add(time.Minute), now.Add(2*time.Minute))
+	delivery.Status = model.WebhookDeliverySucceeded
+	_ = db.UpdateWebhookDelivery(ctx, &delivery)
+	claimedAfterSuccessRes, _ := db.ClaimWebhookDelivery(ctx, delivery.Id, now.Add(3*time.Minute), now.Add(4*time.Minute))
+
+	// ASSERT
+	assert.Nil(t, errClaimedRes)
+	assert.True(t, claimedRes)
+	assert.Nil(t, errClaimedAgainRes)
+	assert.False(t, claimedAgainRes, "a claimed delivery should not be claimed by another worker")
+	assert.Empty(t, pendingRes, "a claimed delivery should not be pending until its lease expires")
+	assert.True(t, claimedAfterLeaseRes, "a delivery should be claimed again once its lease expires")
+	assert.False(t, claimedAfterSuccessRes, "a delivery which is no longer pending should not be claimed")
+}
+
+func Test_Sqlite_GetWebhooksOfUserOrgs_Should_Only_Return_Webhooks_Of_The_Orgs_Of_The_User(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	orgWebhook := model.Webhook{Id: "webhook-1", OrgId: "org-1", Url: "https://example.com/hooks", Secret: "secret", EventTypes: []string{"*"}, CreatedBy: "user-1", CreatedAt: now}
+	otherOrgWebhook := model.Webhook{Id: "webhook-2", OrgId: "org-2", Url: "https://example.com/other", Secret: "secret", EventTypes: []string{"*"}, CreatedBy: "user-2", CreatedAt: now}
+	assert.Nil(t, db.SaveWebhook(ctx, &orgWebhook))
+	assert.Nil(t, db.SaveWebhook(ctx, &otherOrgWebhook))
+	assert.Nil(t, db.SaveMembership(ctx, &model.Membership{OrgId: "org-1", UserId: "user-3", Role: "member", CreatedAt: now}))
+
+	// ACT
+	memberRes, errMemberRes := db.GetWebhooksOfUserOrgs(ctx, "user-3")
+	strangerRes, errStrangerRes := db.GetWebhooksOfUserOrgs(ctx, "user-4")
+
+	// ASSERT
+	assert.Nil(t, errMemberRes)
+	if assert.Len(t, memberRes, 1) {
+		assert.Equal(t, orgWebhook.Id, memberRes[0].Id)
+		assert.Equal(t, orgWebhook.EventTypes, memberRes[0].EventTypes)
+	}
+	assert.Nil(t, errStrangerRes)
+	assert.Empty(t, strangerRes)
+}
+
+func Test_Sqlite_Webhooks_Should_Queue_Deliveries_Once_And_Be_Deleted_With_Them(t *testing.T) {
+	// ARRANGE
+	db := setupSqliteDb(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	webhook := model.Webhook{Id: "webhook-1", OrgId: "org-1", Url: "https://example.com/hooks", Secret: "secret", EventTypes: []string{"user.registered", "user.new_device_login"}, CreatedBy: "user-1", CreatedAt: now}
+	delivery := model.WebhookDelivery{Id: "delivery-1", WebhookId: webhook.Id, OrgId: webhook.OrgId, EventId: "event-1", EventType: "user.registered", Payload: []byte(`{"id":"event-1"}`),
+		Status: model.WebhookDeliveryPending, NextAttemptAt: now, CreatedAt: now}
+	duplicate := delivery
+	duplicate.Id = "delivery-2"
+	assert.Nil(t, db.SaveWebhook(ctx, &webhook))
+
+	// ACT
+	savedRes, errSaveRes := db.SaveWebhookDelivery(ctx, &delivery)
+	savedAgainRes, errSaveAgainRes := db.SaveWebhookDelivery(ctx, &duplicate)
+	statusCode := 500
+	delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.NextAttemptAt = model.WebhookDeliveryPending, 1, &statusCode, now.Add(time.Minute)
+	errUpdateRes := db.UpdateWebhookDelivery(ctx, &delivery)
+	errAttemptRes := db.SaveWebhookDeliveryAttempt(ctx, &model.WebhookDeliveryAttempt{Id: "attempt-1", DeliveryId: delivery.Id, StatusCode: &statusCode, DurationMs: 12, CreatedAt: now})
+	pendingRes, _ := db.GetPendingWebhookDeliveries(ctx, now, 10)
+	pendingLaterRes, _ := db.GetPendingWebhookDeliveries(ctx, now.Add(time.Minute), 10)
+	_, webhookRes, _ := db.GetWebhook(ctx, webhook.OrgId, webhook.Id)
+	existsInOtherOrgRes, _, _ := db.GetWebhook(ctx, "org-2", webhook.Id)
+	attemptsBeforeDeleteRes, errAttemptsRes := db.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	attemptsInOtherOrgRes, _ := db.GetWebhookDeliveryAttempts(ctx, "org-2", delivery.Id)
+	deletedRes, errDeleteRes := db.DeleteWebhook(ctx, webhook.OrgId, webhook.Id)
+
+	// ASSERT
+	assert.Nil(t, errSaveRes)
+	assert.True(t, savedRes)
+	assert.Nil(t, errSaveAgainRes)
+	assert.False(t, savedAgainRes, "should queue an event once per webhook")
+	assert.Nil(t, errUpdateRes)
+	assert.Nil(t, errAttemptRes)
+	assert.Empty(t, pendingRes)
+	if assert.Len(t, pendingLaterRes, 1) {
+		assert.Equal(t, 1, pendingLaterRes[0].Attempts)
+		assert.Equal(t, 500, *pendingLaterRes[0].LastStatusCode)
+		assert.Equal(t, delivery.Payload, pendingLaterRes[0].Payload)
+	}
+	assert.Equal(t, webhook.EventTypes, webhookRes.EventTypes)
+	assert.False(t, existsInOtherOrgRes)
+	assert.Nil(t, errAttemptsRes)
+	assert.Len(t, attemptsBeforeDeleteRes, 1)
+	assert.Empty(t, attemptsInOtherOrgRes, "should not return the attempts of the deliveries of another organization")
+	assert.Nil(t, errDeleteRes)
+	assert.True(t, deletedRes)
+
+	totalRes, _ := db.CountWebhookDeliveries(ctx, webhook.OrgId, webhook.Id)
+	attemptsRes, _ := db.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	assert.Equal(t, 0, totalRes)
+	assert.Empty(t, attemptsRes)
+}
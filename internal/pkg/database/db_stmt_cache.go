@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// stmtCache keeps the prepared statements of the pool so that every query is only parsed once per connection. The
+// lock is never held while preparing because preparing waits for a free connection, which may be held by a transaction
+// that needs the cache as well.
+type stmtCache struct {
+	pool  *sql.DB
+	mu    sync.RWMutex
+	stmts map[string]*sql.Stmt
+}
+
+func newStmtCache(pool *sql.DB) *stmtCache {
+	return &stmtCache{
+		pool:  pool,
+		stmts: map[string]*sql.Stmt{},
+	}
+}
+
+// get returns the prepared statement of the query, the statement is prepared and cached on first use
+func (c *stmtCache) get(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mu.RLock()
+	stmt, isCached := c.stmts[query]
+	c.mu.RUnlock()
+	if isCached {
+		return stmt, nil
+	}
+
+	stmt, err := c.pool.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// another goroutine may have prepared the same query in the meantime
+	if cachedStmt, isCached := c.stmts[query]; isCached {
+		stmt.Close()
+		return cachedStmt, nil
+	}
+	c.stmts[query] = stmt
+
+	return stmt, nil
+}
+
+// lookup returns the prepared statement of the query without preparing it
+func (c *stmtCache) lookup(query string) (*sql.Stmt, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stmt, isCached := c.stmts[query]
+	return stmt, isCached
+}
+
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for query, stmt := range c.stmts {
+		errs = append(errs, stmt.Close())
+		delete(c.stmts, query)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package eventtype
+
+import (
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/pkg/event"
+)
+
+// Source is the producer written in the envelopes of the events published by the app
+const Source = "golang-practice"
+
+const UserRegistered = "user.registered"
+const UserNewDeviceLogin = "user.new_device_login"
+
+// UserData is implemented by the data of the events about a user, they belong to the organizations of the user and
+// are only delivered to their webhooks
+type UserData interface {
+	GetUserId() string
+}
+
+// UserRegisteredData is the data of UserRegistered, published when a user has registered
+type UserRegisteredData struct {
+	Id    string `json:"id"`
+	Email string `json:"email"`
+}
+
+func (d UserRegisteredData) GetUserId() string {
+	return d.Id
+}
+
+func (d UserRegisteredData) Validate() error {
+	if d.Id == "" || d.Email == "" {
+		return fmt.Errorf("id and email are required")
+	}
+
+	return nil
+}
+
+// UserNewDeviceLoginData is the data of UserNewDeviceLogin, published when a user has logged in from an ip or with a
+// user agent never seen before
+type UserNewDeviceLoginData struct {
+	UserId    string `json:"userId"`
+	Email     string `json:"email"`
+	Ip        string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+}
+
+func (d UserNewDeviceLoginData) GetUserId() string {
+	return d.UserId
+}
+
+func (d UserNewDeviceLoginData) Validate() error {
+	if d.UserId == "" || d.Email == "" {
+		return fmt.Errorf("userId and email are required")
+	}
+
+	return nil
+}
+
+// NewRegistry returns the registry of every event type of the app with its current version
+func NewRegistry() (*event.Registry, error) {
+	registry := event.NewRegistry(Source)
+
+	err := event.Register[UserRegisteredData](registry, UserRegistered, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = event.Register[UserNewDeviceLoginData](registry, UserNewDeviceLogin, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
@@ -3,6 +3,8 @@ package jwt
 type JwtPayload struct {
 	UserId    string
 	UserEmail string
+	// OrgId is the organization selected during login, it is empty if no organization was selected
+	OrgId string
 }
 
 type Handler interface {
@@ -45,12 +45,18 @@ func (h *HandlerImpl) Generate(payload JwtPayload) (jwtString string, err error)
 }
 
 func (h *HandlerImpl) createClaims(payload JwtPayload) jwtgo.MapClaims {
-	return jwtgo.MapClaims{
+	claims := jwtgo.MapClaims{
 		"user_id":    payload.UserId,
 		"user_email": payload.UserEmail,
 		"exp":        timeutil.GetTimestampAfterNSec(h.jwtExpTimeInSec),
 		"issuer":     h.issuer,
 	}
+
+	if payload.OrgId != "" {
+		claims["org_id"] = payload.OrgId
+	}
+
+	return claims
 }
 
 func (h *HandlerImpl) Verify(jwtStr string) (valid bool, payload JwtPayload, err error) {
@@ -81,7 +87,10 @@ func (h *HandlerImpl) Verify(jwtStr string) (valid bool, payload JwtPayload, err
 			return false, JwtPayload{}, fmt.Errorf("jwt.HandlerImpl.Verify(): User ID or User Email not found in claims")
 		}
 
-		return true, JwtPayload{UserId: userId, UserEmail: userEmail}, nil
+		// org_id is optional, it is only present when an organization was selected during login
+		orgId, _ := claims["org_id"].(string)
+
+		return true, JwtPayload{UserId: userId, UserEmail: userEmail, OrgId: orgId}, nil
 	} else {
 		return false, JwtPayload{}, nil
 	}
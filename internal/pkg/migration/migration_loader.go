@@ -0,0 +1,124 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pjmessi/golang-practice/migrations"
+)
+
+var fileNameRegex = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	UpSql    string
+	DownSql  string
+	Checksum string
+}
+
+// LoadEmbeddedMigrations returns the migrations shipped with the binary for the given driver
+func LoadEmbeddedMigrations(driver string) ([]Migration, error) {
+	return LoadMigrations(migrations.FS, driver)
+}
+
+// LoadMigrations reads the up and down scripts inside dir and returns the migrations ordered by version, every version
+// must have both scripts
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migration.LoadMigrations(): %w", err)
+	}
+
+	migrationsByVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := fileNameRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("migration.LoadMigrations(): invalid migration file name '%s'", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration.LoadMigrations(): %w", err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("migration.LoadMigrations(): %w", err)
+		}
+
+		migration, exists := migrationsByVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: matches[2]}
+			migrationsByVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration.LoadMigrations(): version %d is used by '%s' and '%s'", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.UpSql = string(content)
+		} else {
+			migration.DownSql = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(migrationsByVersion))
+	for _, migration := range migrationsByVersion {
+		if strings.TrimSpace(migration.UpSql) == "" || strings.TrimSpace(migration.DownSql) == "" {
+			return nil, fmt.Errorf("migration.LoadMigrations(): migration %d_%s requires non empty up and down scripts", migration.Version, migration.Name)
+		}
+
+		migration.Checksum = computeChecksum(migration.UpSql)
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+func computeChecksum(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
+
+// splitStatements splits a script into single statements as the driver does not run multiple statements at once,
+// statements must end with a semicolon at the end of a line and lines starting with "--" are ignored
+func splitStatements(script string) []string {
+	statements := []string{}
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmedLine := strings.TrimSpace(line)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmedLine, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	if strings.TrimSpace(current.String()) != "" {
+		statements = append(statements, strings.TrimSpace(current.String()))
+	}
+
+	return statements
+}
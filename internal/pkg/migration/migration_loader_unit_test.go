@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadMigrations_Should_Return_Ordered_Migrations(t *testing.T) {
+	// ARRANGE
+	fsys := fstest.MapFS{
+		"mysql/0002_create_orders.up.sql":   {Data: []byte("CREATE TABLE orders (id int);")},
+		"mysql/0002_create_orders.down.sql": {Data: []byte("DROP TABLE orders;")},
+		"mysql/0001_create_users.up.sql":    {Data: []byte("CREATE TABLE users (id int);")},
+		"mysql/0001_create_users.down.sql":  {Data: []byte("DROP TABLE users;")},
+	}
+
+	// ACT
+	migrationsRes, errRes := LoadMigrations(fsys, "mysql")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Len(t, migrationsRes, 2)
+	assert.Equal(t, int64(1), migrationsRes[0].Version)
+	assert.Equal(t, "create_users", migrationsRes[0].Name)
+	assert.Equal(t, "CREATE TABLE users (id int);", migrationsRes[0].UpSql)
+	assert.Equal(t, "DROP TABLE users;", migrationsRes[0].DownSql)
+	assert.Equal(t, computeChecksum("CREATE TABLE users (id int);"), migrationsRes[0].Checksum)
+	assert.Equal(t, int64(2), migrationsRes[1].Version)
+}
+
+func Test_LoadMigrations_Should_Fail_Without_Down_Script(t *testing.T) {
+	// ARRANGE
+	fsys := fstest.MapFS{
+		"mysql/0001_create_users.up.sql": {Data: []byte("CREATE TABLE users (id int);")},
+	}
+
+	// ACT
+	migrationsRes, errRes := LoadMigrations(fsys, "mysql")
+
+	// ASSERT
+	assert.EqualError(t, errRes, "migration.LoadMigrations(): migration 1_create_users requires non empty up and down scripts")
+	assert.Nil(t, migrationsRes)
+}
+
+func Test_LoadMigrations_Should_Fail_On_Invalid_File_Name(t *testing.T) {
+	// ARRANGE
+	fsys := fstest.MapFS{
+		"mysql/create_users.sql": {Data: []byte("CREATE TABLE users (id int);")},
+	}
+
+	// ACT
+	_, errRes := LoadMigrations(fsys, "mysql")
+
+	// ASSERT
+	assert.EqualError(t, errRes, "migration.LoadMigrations(): invalid migration file name 'create_users.sql'")
+}
+
+func Test_LoadMigrations_Should_Fail_On_Duplicate_Version(t *testing.T) {
+	// ARRANGE
+	fsys := fstest.MapFS{
+		"mysql/0001_create_users.up.sql":  {Data: []byte("CREATE TABLE users (id int);")},
+		"mysql/0001_create_orders.up.sql": {Data: []byte("CREATE TABLE orders (id int);")},
+	}
+
+	// ACT
+	_, errRes := LoadMigrations(fsys, "mysql")
+
+	// ASSERT
+	assert.ErrorContains(t, errRes, "version 1 is used by")
+}
+
+func Test_LoadEmbeddedMigrations_Should_Load_Migrations_Of_Every_Driver(t *testing.T) {
+	// ACT
+	mysqlMigrations, mysqlErr := LoadEmbeddedMigrations("mysql")
+	postgresMigrations, postgresErr := LoadEmbeddedMigrations("postgres")
+	sqliteMigrations, sqliteErr := LoadEmbeddedMigrations("sqlite")
+
+	// ASSERT
+	assert.Nil(t, mysqlErr)
+	assert.Nil(t, postgresErr)
+	assert.Nil(t, sqliteErr)
+	assert.NotEmpty(t, mysqlMigrations)
+	assert.Equal(t, len(mysqlMigrations), len(postgresMigrations), "every driver should have the same migrations")
+	assert.Equal(t, len(mysqlMigrations), len(sqliteMigrations), "every driver should have the same migrations")
+	for i, migration := range mysqlMigrations {
+		assert.Equal(t, int64(i+1), migration.Version, "versions should be sequential")
+		assert.Equal(t, migration.Name, postgresMigrations[i].Name, "every driver should have the same migrations")
+		assert.Equal(t, migration.Name, sqliteMigrations[i].Name, "every driver should have the same migrations")
+	}
+}
+
+func Test_SplitStatements(t *testing.T) {
+	// ARRANGE
+	script := `-- creates tables
+CREATE TABLE users (
+  id int
+);
+
+CREATE TABLE orders (id int);
+DROP TABLE tmp`
+
+	// ACT
+	statementsRes := splitStatements(script)
+
+	// ASSERT
+	expectedStatements := []string{
+		"CREATE TABLE users (\n  id int\n);",
+		"CREATE TABLE orders (id int);",
+		"DROP TABLE tmp",
+	}
+
+	assert.Equal(t, expectedStatements, statementsRes)
+}
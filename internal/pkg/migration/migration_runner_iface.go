@@ -0,0 +1,21 @@
+package migration
+
+import (
+	"context"
+	"time"
+)
+
+type Status struct {
+	Migration
+	Applied          bool
+	AppliedAt        *time.Time
+	ChecksumMismatch bool
+}
+
+type Runner interface {
+	// Up applies every pending migration in order and returns the applied ones
+	Up(ctx context.Context) ([]Migration, error)
+	// Down reverts the given number of most recently applied migrations and returns the reverted ones
+	Down(ctx context.Context, steps int) ([]Migration, error)
+	Status(ctx context.Context) ([]Status, error)
+}
@@ -0,0 +1,348 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+)
+
+const lockName = "schema_migrations"
+const lockTimeoutSec = 30
+const lockPollInterval = 500 * time.Millisecond
+
+// advisoryLockId identifies the postgres advisory lock, postgres only supports numeric lock keys
+const advisoryLockId int64 = 7262633
+
+var ErrLocked = errors.New("another migration runner holds the lock")
+
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+type RunnerImpl struct {
+	db         *sql.DB
+	driver     string
+	logService logger.Service
+	migrations []Migration
+}
+
+func NewRunner(logService logger.Service, db *sql.DB, driver string, migrations []Migration) Runner {
+	return &RunnerImpl{
+		db:         db,
+		driver:     driver,
+		logService: logService,
+		migrations: migrations,
+	}
+}
+
+// NewEmbeddedRunner returns a runner for the migrations embedded in the binary for the driver
+func NewEmbeddedRunner(logService logger.Service, db *sql.DB, driver string) (Runner, error) {
+	migrations, err := LoadEmbeddedMigrations(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRunner(logService, db, driver, migrations), nil
+}
+
+func (r *RunnerImpl) Up(ctx context.Context) ([]Migration, error) {
+	applied := []Migration{}
+
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		appliedByVersion, err := r.getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range r.migrations {
+			existing, isApplied := appliedByVersion[migration.Version]
+			if isApplied && existing.checksum != migration.Checksum {
+				return fmt.Errorf("migration.Up(): checksum of applied migration %d_%s does not match the file", migration.Version, migration.Name)
+			}
+		}
+
+		for _, migration := range r.migrations {
+			if _, isApplied := appliedByVersion[migration.Version]; isApplied {
+				continue
+			}
+
+			r.logService.Debug(fmt.Sprintf("applying migration %d_%s", migration.Version, migration.Name))
+			err = r.execMigration(ctx, conn, migration.UpSql, "INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?);",
+				migration.Version, migration.Name, migration.Checksum, timeutil.GetCurrentTime())
+			if err != nil {
+				return fmt.Errorf("migration.Up(): migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+func (r *RunnerImpl) Down(ctx context.Context, steps int) ([]Migration, error) {
+	reverted := []Migration{}
+
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		appliedByVersion, err := r.getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(appliedByVersion))
+		for version := range appliedByVersion {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i] > versions[j]
+		})
+
+		migrationsByVersion := map[int64]Migration{}
+		for _, migration := range r.migrations {
+			migrationsByVersion[migration.Version] = migration
+		}
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			migration, exists := migrationsByVersion[versions[i]]
+			if !exists {
+				return fmt.Errorf("migration.Down(): no down script found for applied migration %d_%s", versions[i], appliedByVersion[versions[i]].name)
+			}
+
+			r.logService.Debug(fmt.Sprintf("reverting migration %d_%s", migration.Version, migration.Name))
+			err = r.execMigration(ctx, conn, migration.DownSql, "DELETE FROM schema_migrations WHERE version = ?;", migration.Version)
+			if err != nil {
+				return fmt.Errorf("migration.Down(): migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+func (r *RunnerImpl) Status(ctx context.Context) ([]Status, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration.Status(): %w", err)
+	}
+	defer conn.Close()
+
+	appliedByVersion, err := r.getAppliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []Status{}
+	for _, migration := range r.migrations {
+		status := Status{Migration: migration}
+		if existing, isApplied := appliedByVersion[migration.Version]; isApplied {
+			status.Applied = true
+			status.AppliedAt = &existing.appliedAt
+			status.ChecksumMismatch = existing.checksum != migration.Checksum
+			delete(appliedByVersion, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	// migrations applied by a newer version of the app are still reported
+	for _, existing := range appliedByVersion {
+		appliedAt := existing.appliedAt
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: existing.version, Name: existing.name, Checksum: existing.checksum},
+			Applied:   true,
+			AppliedAt: &appliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// withLock runs fn while holding a named lock so that multiple app instances starting at the same time do not apply
+// the same migration twice, the lock belongs to the connection so fn must use the given connection
+func (r *RunnerImpl) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migration.withLock(): %w", err)
+	}
+	defer conn.Close()
+
+	err = r.acquireLock(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err := r.releaseLock(conn)
+		if err != nil {
+			r.logService.Error(fmt.Sprintf("error releasing migration lock: %s", err))
+		}
+	}()
+
+	return fn(conn)
+}
+
+func (r *RunnerImpl) acquireLock(ctx context.Context, conn *sql.Conn) error {
+	switch r.driver {
+	case database.DriverPostgres:
+		return r.acquireAdvisoryLock(ctx, conn)
+	case database.DriverSqlite:
+		// sqlite databases are local to a single process which uses a single connection
+		return nil
+	}
+
+	var acquired sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?);", lockName, lockTimeoutSec).Scan(&acquired)
+	if err != nil {
+		return fmt.Errorf("migration.acquireLock(): %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("migration.acquireLock(): %w", ErrLocked)
+	}
+
+	return nil
+}
+
+// acquireAdvisoryLock polls the postgres advisory lock as pg_advisory_lock would wait forever
+func (r *RunnerImpl) acquireAdvisoryLock(ctx context.Context, conn *sql.Conn) error {
+	deadline := time.Now().Add(lockTimeoutSec * time.Second)
+
+	for {
+		var acquired bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1);", advisoryLockId).Scan(&acquired)
+		if err != nil {
+			return fmt.Errorf("migration.acquireLock(): %w", err)
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("migration.acquireLock(): %w", ErrLocked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("migration.acquireLock(): %w", ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (r *RunnerImpl) releaseLock(conn *sql.Conn) error {
+	query := "SELECT RELEASE_LOCK(?) IS NOT NULL;"
+	args := []any{lockName}
+	switch r.driver {
+	case database.DriverPostgres:
+		query = "SELECT pg_advisory_unlock($1);"
+		args = []any{advisoryLockId}
+	case database.DriverSqlite:
+		return nil
+	}
+
+	var released bool
+	return conn.QueryRowContext(context.Background(), query, args...).Scan(&released)
+}
+
+func (r *RunnerImpl) rebind(query string) string {
+	return database.Rebind(r.driver, query)
+}
+
+func (r *RunnerImpl) getAppliedMigrations(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version bigint NOT NULL,
+  name varchar(255) NOT NULL,
+  checksum char(64) NOT NULL,
+  applied_at timestamp NOT NULL,
+  PRIMARY KEY (version)
+);`)
+	if err != nil {
+		return nil, fmt.Errorf("migration.getAppliedMigrations(): %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations;")
+	if err != nil {
+		return nil, fmt.Errorf("migration.getAppliedMigrations(): %w", err)
+	}
+	defer rows.Close()
+
+	appliedByVersion := map[int64]appliedMigration{}
+	for rows.Next() {
+		var applied appliedMigration
+		err = rows.Scan(&applied.version, &applied.name, &applied.checksum, &applied.appliedAt)
+		if err != nil {
+			return nil, fmt.Errorf("migration.getAppliedMigrations(): %w", err)
+		}
+		appliedByVersion[applied.version] = applied
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("migration.getAppliedMigrations(): %w", err)
+	}
+
+	return appliedByVersion, nil
+}
+
+// execMigration runs the statements of the script followed by the bookkeeping query of schema_migrations. Postgres and
+// sqlite run them in one transaction, so a migration failing in any statement leaves neither the schema nor
+// schema_migrations changed. MySQL commits every DDL statement implicitly, so its statements run one by one and the
+// statements which succeeded before a failing one stay applied and must be reverted by hand before running it again.
+func (r *RunnerImpl) execMigration(ctx context.Context, conn *sql.Conn, script string, query string, args ...any) error {
+	if r.driver == database.DriverMySql {
+		err := execScript(ctx, conn, script)
+		if err != nil {
+			return err
+		}
+
+		_, err = conn.ExecContext(ctx, r.rebind(query), args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = execScript(ctx, tx, script)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, r.rebind(query), args...)
+	}
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
+
+// execer is implemented by both *sql.Conn and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func execScript(ctx context.Context, db execer, script string) error {
+	for _, statement := range splitStatements(script) {
+		_, err := db.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
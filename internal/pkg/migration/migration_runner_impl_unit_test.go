@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	_ "modernc.org/sqlite"
+)
+
+// setupSqliteRunnerTest creates RunnerImpl on an in memory sqlite database, which lives as long as its only connection
+func setupSqliteRunnerTest(t *testing.T, migrations []Migration) (*RunnerImpl, *sql.DB) {
+	t.Helper()
+
+	db, err := sql.Open(database.DriverSqlite, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("Debug", mock.Anything)
+
+	return NewRunner(logServiceMock, db, database.DriverSqlite, migrations).(*RunnerImpl), db
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?;", table).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count == 1
+}
+
+func genMigration(version int64, name string, upSql string, downSql string) Migration {
+	return Migration{Version: version, Name: name, UpSql: upSql, DownSql: downSql, Checksum: computeChecksum(upSql)}
+}
+
+func Test_Up_Should_Not_Apply_Migration_Failing_In_Second_Statement(t *testing.T) {
+	// ARRANGE
+	users := genMigration(1, "create_users", "CREATE TABLE users (id int);", "DROP TABLE users;")
+	orders := genMigration(2, "create_orders", "CREATE TABLE orders (id int);\nINSERT INTO missing (id) VALUES (1);", "DROP TABLE orders;")
+	runner, db := setupSqliteRunnerTest(t, []Migration{users, orders})
+	ctx := context.Background()
+
+	// ACT
+	appliedRes, errRes := runner.Up(ctx)
+	statusesRes, _ := runner.Status(ctx)
+
+	// ASSERT
+	assert.ErrorContains(t, errRes, "migration 2_create_orders failed")
+	if assert.Len(t, appliedRes, 1) {
+		assert.Equal(t, users.Version, appliedRes[0].Version)
+	}
+	assert.True(t, tableExists(t, db, "users"))
+	assert.False(t, tableExists(t, db, "orders"), "the statements of the failed migration should be rolled back")
+	if assert.Len(t, statusesRes, 2) {
+		assert.True(t, statusesRes[0].Applied)
+		assert.False(t, statusesRes[1].Applied, "the failed migration should not be recorded")
+	}
+
+	// the fixed migration applies from scratch
+	runner.migrations[1] = genMigration(2, "create_orders", "CREATE TABLE orders (id int);\nINSERT INTO orders (id) VALUES (1);", "DROP TABLE orders;")
+	appliedAgainRes, errAgainRes := runner.Up(ctx)
+	assert.Nil(t, errAgainRes)
+	assert.Len(t, appliedAgainRes, 1)
+	assert.True(t, tableExists(t, db, "orders"))
+}
+
+func Test_Down_Should_Not_Revert_Migration_Failing_In_Second_Statement(t *testing.T) {
+	// ARRANGE
+	users := genMigration(1, "create_users", "CREATE TABLE users (id int);", "DROP TABLE users;\nDROP TABLE missing;")
+	runner, db := setupSqliteRunnerTest(t, []Migration{users})
+	ctx := context.Background()
+	_, err := runner.Up(ctx)
+	assert.Nil(t, err)
+
+	// ACT
+	revertedRes, errRes := runner.Down(ctx, 1)
+	statusesRes, _ := runner.Status(ctx)
+
+	// ASSERT
+	assert.ErrorContains(t, errRes, "migration 1_create_users failed")
+	assert.Empty(t, revertedRes)
+	assert.True(t, tableExists(t, db, "users"), "the statements of the failed migration should be rolled back")
+	if assert.Len(t, statusesRes, 1) {
+		assert.True(t, statusesRes[0].Applied, "the migration should still be recorded as applied")
+	}
+}
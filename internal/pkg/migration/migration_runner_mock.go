@@ -0,0 +1,26 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RunnerMock struct {
+	mock.Mock
+}
+
+func (r *RunnerMock) Up(ctx context.Context) ([]Migration, error) {
+	args := r.Called(ctx)
+	return args.Get(0).([]Migration), args.Error(1)
+}
+
+func (r *RunnerMock) Down(ctx context.Context, steps int) ([]Migration, error) {
+	args := r.Called(ctx, steps)
+	return args.Get(0).([]Migration), args.Error(1)
+}
+
+func (r *RunnerMock) Status(ctx context.Context) ([]Status, error) {
+	args := r.Called(ctx)
+	return args.Get(0).([]Status), args.Error(1)
+}
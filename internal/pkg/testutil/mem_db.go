@@ -0,0 +1,964 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+)
+
+var ErrMemDbClosed = errors.New("testutil.MemDb: connection is closed")
+
+// memState holds the rows of every table, rows are kept in insertion order so that ties in ORDER BY columns are
+// returned in a stable order
+type memState struct {
+	users       []model.User
+	invites     []model.Invite
+	orgs        []model.Organization
+	memberships []model.Membership
+	loginEvents []model.LoginEvent
+	outbox      []model.OutboxEvent
+	processed   []model.ProcessedMessage
+	webhooks    []model.Webhook
+	deliveries  []model.WebhookDelivery
+	attempts    []model.WebhookDeliveryAttempt
+}
+
+func (s *memState) clone() *memState {
+	return &memState{
+		users:       append([]model.User{}, s.users...),
+		invites:     append([]model.Invite{}, s.invites...),
+		orgs:        append([]model.Organization{}, s.orgs...),
+		memberships: append([]model.Membership{}, s.memberships...),
+		loginEvents: append([]model.LoginEvent{}, s.loginEvents...),
+		outbox:      append([]model.OutboxEvent{}, s.outbox...),
+		processed:   append([]model.ProcessedMessage{}, s.processed...),
+		webhooks:    append([]model.Webhook{}, s.webhooks...),
+		deliveries:  append([]model.WebhookDelivery{}, s.deliveries...),
+		attempts:    append([]model.WebhookDeliveryAttempt{}, s.attempts...),
+	}
+}
+
+// MemSnapshot is a copy of the content of a MemDb which can be restored later
+type MemSnapshot struct {
+	state *memState
+}
+
+// MemDb is an in memory implementation of database.Db for component tests. It enforces the same primary keys and
+// unique constraints as the migrations and supports nested transactions.
+//
+// Transactions are serializable: WithTx holds the lock of the database until fn returns, so fn must only use txDb,
+// using the outer MemDb inside fn deadlocks the same way as a single connection database would.
+type MemDb struct {
+	mu     *sync.Mutex
+	root   *MemDb
+	state  *memState
+	inTx   bool
+	closed bool
+}
+
+func NewMemDb() *MemDb {
+	db := &MemDb{
+		mu:    &sync.Mutex{},
+		state: &memState{},
+	}
+	db.root = db
+
+	return db
+}
+
+// run runs a statement against the current state, the lock is only taken outside of transactions as the transaction
+// already holds it
+func (m *MemDb) run(fn func(state *memState) error) error {
+	if !m.inTx {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+
+	if m.root.closed {
+		return ErrMemDbClosed
+	}
+
+	return fn(m.state)
+}
+
+// Snapshot returns a copy of the content of the database, inside a transaction the uncommitted changes are included
+func (m *MemDb) Snapshot() MemSnapshot {
+	if !m.inTx {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+
+	return MemSnapshot{state: m.state.clone()}
+}
+
+// Restore replaces the content of the database with the snapshot, the snapshot can be restored multiple times.
+// Restore and Reset must not be called inside a transaction.
+func (m *MemDb) Restore(snapshot MemSnapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.root.state = snapshot.state.clone()
+}
+
+// Reset removes every row and reopens the database if it has been closed
+func (m *MemDb) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.root.state = &memState{}
+	m.root.closed = false
+}
+
+func (m *MemDb) CloseConnection() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.root.closed = true
+}
+
+func (m *MemDb) CheckHealth() error {
+	return m.run(func(state *memState) error {
+		return nil
+	})
+}
+
+// PoolStats returns no statistics as there is no connection pool
+func (m *MemDb) PoolStats() []database.PoolStats {
+	return []database.PoolStats{}
+}
+
+func (m *MemDb) WithTx(ctx context.Context, fn func(txDb database.Db) error) error {
+	if !m.inTx {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+
+	if m.root.closed {
+		return ErrMemDbClosed
+	}
+
+	txDb := &MemDb{
+		mu:    m.mu,
+		root:  m.root,
+		state: m.state.clone(),
+		inTx:  true,
+	}
+
+	err := fn(txDb)
+	if err != nil {
+		return err
+	}
+
+	// committing a nested transaction publishes its changes to the outer transaction only
+	*m.state = *txDb.state
+
+	return nil
+}
+
+func (m *MemDb) SaveUser(ctx context.Context, user *model.User) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.users {
+			if existing.Id == user.Id || strings.EqualFold(existing.Email, user.Email) {
+				return exception.NewAlreadyExistsFromBase(exception.Base{
+					Message: fmt.Sprintf("user with the email '%s' already exists", user.Email),
+					Type:    errorcode.UserAlreadyExist,
+				})
+			}
+		}
+
+		state.users = append(state.users, copyUser(*user))
+		return nil
+	})
+}
+
+func (m *MemDb) IsUserEmailTaken(ctx context.Context, email string) (bool, error) {
+	exists, _, err := m.GetUserByEmail(ctx, email)
+	return exists, err
+}
+
+func (m *MemDb) GetUserByEmail(ctx context.Context, email string) (bool, model.User, error) {
+	return m.findUser(func(user model.User) bool {
+		return user.Email == email
+	})
+}
+
+func (m *MemDb) GetUserById(ctx context.Context, userId string) (bool, model.User, error) {
+	return m.findUser(func(user model.User) bool {
+		return user.Id == userId
+	})
+}
+
+func (m *MemDb) GetUsersByIds(ctx context.Context, userIds []string) ([]model.User, error) {
+	users := []model.User{}
+	err := m.run(func(state *memState) error {
+		seen := map[string]bool{}
+		for _, userId := range userIds {
+			for _, existing := range state.users {
+				if existing.Id == userId && !seen[userId] {
+					seen[userId] = true
+					users = append(users, copyUser(existing))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (m *MemDb) findUser(matches func(user model.User) bool) (exists bool, user model.User, err error) {
+	err = m.run(func(state *memState) error {
+		for _, existing := range state.users {
+			if matches(existing) {
+				exists, user = true, copyUser(existing)
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return exists, user, err
+}
+
+func (m *MemDb) SaveInvite(ctx context.Context, invite *model.Invite) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.invites {
+			if existing.Id == invite.Id || existing.Code == invite.Code {
+				return exception.NewAlreadyExists()
+			}
+		}
+
+		state.invites = append(state.invites, copyInvite(*invite))
+		return nil
+	})
+}
+
+func (m *MemDb) GetInviteByCode(ctx context.Context, code string) (exists bool, invite model.Invite, err error) {
+	err = m.run(func(state *memState) error {
+		for _, existing := range state.invites {
+			if existing.Code == code {
+				exists, invite = true, copyInvite(existing)
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return exists, invite, err
+}
+
+func (m *MemDb) IncrementInviteUsage(ctx context.Context, inviteId string, now time.Time) (incremented bool, err error) {
+	err = m.run(func(state *memState) error {
+		for i, existing := range state.invites {
+			if existing.Id != inviteId {
+				continue
+			}
+
+			isExpired := existing.ExpiresAt != nil && !existing.ExpiresAt.After(now)
+			if existing.UsedCount < existing.MaxUses && !isExpired {
+				state.invites[i].UsedCount++
+				incremented = true
+			}
+			return nil
+		}
+		return nil
+	})
+
+	return incremented, err
+}
+
+func (m *MemDb) SaveOrganization(ctx context.Context, org *model.Organization) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.orgs {
+			if existing.Id == org.Id {
+				return exception.NewAlreadyExists()
+			}
+		}
+
+		state.orgs = append(state.orgs, copyOrg(*org))
+		return nil
+	})
+}
+
+func (m *MemDb) GetOrganizationById(ctx context.Context, orgId string) (exists bool, org model.Organization, err error) {
+	err = m.run(func(state *memState) error {
+		for _, existing := range state.orgs {
+			if existing.Id == orgId {
+				exists, org = true, copyOrg(existing)
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return exists, org, err
+}
+
+func (m *MemDb) SaveMembership(ctx context.Context, membership *model.Membership) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.memberships {
+			if existing.OrgId == membership.OrgId && existing.UserId == membership.UserId {
+				return exception.NewAlreadyExists()
+			}
+		}
+
+		state.memberships = append(state.memberships, *membership)
+		return nil
+	})
+}
+
+func (m *MemDb) GetMembership(ctx context.Context, orgId string, userId string) (exists bool, membership model.Membership, err error) {
+	err = m.run(func(state *memState) error {
+		for _, existing := range state.memberships {
+			if existing.OrgId == orgId && existing.UserId == userId {
+				exists, membership = true, existing
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return exists, membership, err
+}
+
+func (m *MemDb) GetOrgMembers(ctx context.Context, orgId string) ([]model.OrgMember, error) {
+	members := []model.OrgMember{}
+	err := m.run(func(state *memState) error {
+		for _, membership := range state.memberships {
+			if membership.OrgId != orgId {
+				continue
+			}
+
+			// inner join, memberships of unknown users are skipped
+			for _, user := range state.users {
+				if user.Id == membership.UserId {
+					members = append(members, model.OrgMember{UserId: user.Id, Email: user.Email, Role: membership.Role, JoinedAt: membership.CreatedAt})
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+
+	return members, nil
+}
+
+func (m *MemDb) GetUserOrganizations(ctx context.Context, userId string) ([]model.UserOrganization, error) {
+	orgs := []model.UserOrganization{}
+	err := m.run(func(state *memState) error {
+		for _, org := range state.orgs {
+			for _, membership := range state.memberships {
+				if membership.OrgId == org.Id && membership.UserId == userId {
+					orgs = append(orgs, model.UserOrganization{Organization: copyOrg(org), Role: membership.Role})
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(orgs, func(i, j int) bool {
+		return orgs[i].CreatedAt.Before(orgs[j].CreatedAt)
+	})
+
+	return orgs, nil
+}
+
+func (m *MemDb) SaveLoginEvent(ctx context.Context, event *model.LoginEvent) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.loginEvents {
+			if existing.Id == event.Id {
+				return exception.NewAlreadyExists()
+			}
+		}
+
+		state.loginEvents = append(state.loginEvents, copyLoginEvent(*event))
+		return nil
+	})
+}
+
+func (m *MemDb) GetLoginEvents(ctx context.Context, userId string, limit int, offset int) ([]model.LoginEvent, error) {
+	events, err := m.getUserLoginEvents(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	if offset >= len(events) {
+		return []model.LoginEvent{}, nil
+	}
+	events = events[offset:]
+	if limit < len(events) {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+func (m *MemDb) CountLoginEvents(ctx context.Context, userId string) (int, error) {
+	events, err := m.getUserLoginEvents(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+func (m *MemDb) GetLoginHistoryStats(ctx context.Context, userId string, ip string, userAgent string) (model.LoginHistoryStats, error) {
+	events, err := m.getUserLoginEvents(userId)
+	if err != nil {
+		return model.LoginHistoryStats{}, err
+	}
+
+	var stats model.LoginHistoryStats
+	for _, event := range events {
+		if !event.Success {
+			continue
+		}
+
+		stats.SuccessfulLogins++
+		if event.Ip == ip {
+			stats.FromSameIp++
+		}
+		if event.UserAgent == userAgent {
+			stats.FromSameDevice++
+		}
+	}
+
+	return stats, nil
+}
+
+// getUserLoginEvents returns the login events of the user in insertion order
+func (m *MemDb) getUserLoginEvents(userId string) ([]model.LoginEvent, error) {
+	events := []model.LoginEvent{}
+	err := m.run(func(state *memState) error {
+		for _, event := range state.loginEvents {
+			if event.UserId != nil && *event.UserId == userId {
+				events = append(events, copyLoginEvent(event))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (m *MemDb) SaveOutboxEvent(ctx context.Context, event *model.OutboxEvent) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.outbox {
+			if existing.Id == event.Id {
+				return exception.NewAlreadyExists()
+			}
+		}
+
+		state.outbox = append(state.outbox, copyOutboxEvent(*event))
+		return nil
+	})
+}
+
+func (m *MemDb) GetPendingOutboxEvents(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	events := []model.OutboxEvent{}
+	err := m.run(func(state *memState) error {
+		for _, event := range state.outbox {
+			if event.SentAt == nil && !event.NextAttemptAt.After(now) {
+				events = append(events, copyOutboxEvent(event))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	if limit < len(events) {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+func (m *MemDb) ClaimOutboxEvent(ctx context.Context, eventId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	claimed := false
+	err := m.updateOutboxEvent(eventId, func(event *model.OutboxEvent) {
+		if event.SentAt == nil && !event.NextAttemptAt.After(now) {
+			event.NextAttemptAt = leaseUntil
+			claimed = true
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return claimed, nil
+}
+
+func (m *MemDb) MarkOutboxEventSent(ctx context.Context, eventId string, sentAt time.Time) error {
+	return m.updateOutboxEvent(eventId, func(event *model.OutboxEvent) {
+		event.SentAt = &sentAt
+		event.Attempts++
+		event.LastError = nil
+	})
+}
+
+func (m *MemDb) MarkOutboxEventFailed(ctx context.Context, eventId string, lastError string, nextAttemptAt time.Time) error {
+	return m.updateOutboxEvent(eventId, func(event *model.OutboxEvent) {
+		event.Attempts++
+		event.LastError = &lastError
+		event.NextAttemptAt = nextAttemptAt
+	})
+}
+
+func (m *MemDb) updateOutboxEvent(eventId string, update func(event *model.OutboxEvent)) error {
+	return m.run(func(state *memState) error {
+		for i := range state.outbox {
+			if state.outbox[i].Id == eventId {
+				update(&state.outbox[i])
+			}
+		}
+		return nil
+	})
+}
+
+func (m *MemDb) GetOutboxStats(ctx context.Context) (model.OutboxStats, error) {
+	var stats model.OutboxStats
+	err := m.run(func(state *memState) error {
+		for _, event := range state.outbox {
+			if event.SentAt != nil {
+				continue
+			}
+
+			stats.Pending++
+			if stats.OldestPendingAt == nil || event.CreatedAt.Before(*stats.OldestPendingAt) {
+				stats.OldestPendingAt = copyPtr(&event.CreatedAt)
+			}
+		}
+		return nil
+	})
+
+	return stats, err
+}
+
+func (m *MemDb) SaveProcessedMessage(ctx context.Context, msg *model.ProcessedMessage) (bool, error) {
+	saved := false
+	err := m.run(func(state *memState) error {
+		for _, existing := range state.processed {
+			if existing.Consumer == msg.Consumer && existing.MessageId == msg.MessageId {
+				return nil
+			}
+		}
+
+		state.processed = append(state.processed, *msg)
+		saved = true
+		return nil
+	})
+
+	return saved, err
+}
+
+func (m *MemDb) DeleteExpiredProcessedMessages(ctx context.Context, now time.Time) (int, error) {
+	deleted := 0
+	err := m.run(func(state *memState) error {
+		kept := []model.ProcessedMessage{}
+		for _, msg := range state.processed {
+			if msg.ExpiresAt.After(now) {
+				kept = append(kept, msg)
+			}
+		}
+
+		deleted = len(state.processed) - len(kept)
+		state.processed = kept
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (m *MemDb) SaveWebhook(ctx context.Context, webhook *model.Webhook) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.webhooks {
+			if existing.Id == webhook.Id {
+				return exception.NewAlreadyExists()
+			}
+		}
+
+		state.webhooks = append(state.webhooks, copyWebhook(*webhook))
+		return nil
+	})
+}
+
+func (m *MemDb) GetWebhook(ctx context.Context, orgId string, webhookId string) (exists bool, webhook model.Webhook, err error) {
+	err = m.run(func(state *memState) error {
+		for _, existing := range state.webhooks {
+			if existing.Id == webhookId && existing.OrgId == orgId {
+				exists, webhook = true, copyWebhook(existing)
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return exists, webhook, err
+}
+
+func (m *MemDb) GetOrgWebhooks(ctx context.Context, orgId string) ([]model.Webhook, error) {
+	return m.getWebhooks(func(webhook model.Webhook) bool {
+		return webhook.OrgId == orgId
+	})
+}
+
+func (m *MemDb) GetWebhooksOfUserOrgs(ctx context.Context, userId string) ([]model.Webhook, error) {
+	orgIds := map[string]bool{}
+	err := m.run(func(state *memState) error {
+		for _, membership := range state.memberships {
+			if membership.UserId == userId {
+				orgIds[membership.OrgId] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.getWebhooks(func(webhook model.Webhook) bool {
+		return orgIds[webhook.OrgId]
+	})
+}
+
+// getWebhooks returns the matching webhooks ordered by creation time
+func (m *MemDb) getWebhooks(matches func(webhook model.Webhook) bool) ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+	err := m.run(func(state *memState) error {
+		for _, webhook := range state.webhooks {
+			if matches(webhook) {
+				webhooks = append(webhooks, copyWebhook(webhook))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
+
+func (m *MemDb) DeleteWebhook(ctx context.Context, orgId string, webhookId string) (bool, error) {
+	deleted := false
+	err := m.run(func(state *memState) error {
+		deliveryIds := map[string]bool{}
+		deliveries := []model.WebhookDelivery{}
+		for _, delivery := range state.deliveries {
+			if delivery.WebhookId == webhookId && delivery.OrgId == orgId {
+				deliveryIds[delivery.Id] = true
+			} else {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+
+		attempts := []model.WebhookDeliveryAttempt{}
+		for _, attempt := range state.attempts {
+			if !deliveryIds[attempt.DeliveryId] {
+				attempts = append(attempts, attempt)
+			}
+		}
+
+		webhooks := []model.Webhook{}
+		for _, webhook := range state.webhooks {
+			if webhook.Id == webhookId && webhook.OrgId == orgId {
+				deleted = true
+			} else {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+
+		state.webhooks, state.deliveries, state.attempts = webhooks, deliveries, attempts
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (m *MemDb) SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	saved := false
+	err := m.run(func(state *memState) error {
+		for _, existing := range state.deliveries {
+			if existing.Id == delivery.Id {
+				return exception.NewAlreadyExists()
+			}
+			if existing.WebhookId == delivery.WebhookId && existing.EventId == delivery.EventId {
+				return nil
+			}
+		}
+
+		state.deliveries = append(state.deliveries, copyWebhookDelivery(*delivery))
+		saved = true
+		return nil
+	})
+
+	return saved, err
+}
+
+func (m *MemDb) GetWebhookDelivery(ctx context.Context, orgId string, deliveryId string) (exists bool, delivery model.WebhookDelivery, err error) {
+	err = m.run(func(state *memState) error {
+		for _, existing := range state.deliveries {
+			if existing.Id == deliveryId && existing.OrgId == orgId {
+				exists, delivery = true, copyWebhookDelivery(existing)
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return exists, delivery, err
+}
+
+func (m *MemDb) GetWebhookDeliveries(ctx context.Context, orgId string, webhookId string, limit int, offset int) ([]model.WebhookDelivery, error) {
+	deliveries, err := m.getWebhookDeliveries(func(delivery model.WebhookDelivery) bool {
+		return delivery.WebhookId == webhookId && delivery.OrgId == orgId
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	if offset >= len(deliveries) {
+		return []model.WebhookDelivery{}, nil
+	}
+	deliveries = deliveries[offset:]
+	if limit < len(deliveries) {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (m *MemDb) CountWebhookDeliveries(ctx context.Context, orgId string, webhookId string) (int, error) {
+	deliveries, err := m.getWebhookDeliveries(func(delivery model.WebhookDelivery) bool {
+		return delivery.WebhookId == webhookId && delivery.OrgId == orgId
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(deliveries), nil
+}
+
+func (m *MemDb) GetPendingWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	deliveries, err := m.getWebhookDeliveries(func(delivery model.WebhookDelivery) bool {
+		return delivery.Status == model.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+	})
+	if limit < len(deliveries) {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (m *MemDb) ClaimWebhookDelivery(ctx context.Context, deliveryId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	claimed := false
+	err := m.run(func(state *memState) error {
+		for i := range state.deliveries {
+			delivery := &state.deliveries[i]
+			if delivery.Id == deliveryId && delivery.Status == model.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+				delivery.NextAttemptAt = leaseUntil
+				claimed = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return claimed, nil
+}
+
+// getWebhookDeliveries returns the matching deliveries in insertion order
+func (m *MemDb) getWebhookDeliveries(matches func(delivery model.WebhookDelivery) bool) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	err := m.run(func(state *memState) error {
+		for _, delivery := range state.deliveries {
+			if matches(delivery) {
+				deliveries = append(deliveries, copyWebhookDelivery(delivery))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (m *MemDb) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return m.run(func(state *memState) error {
+		for i := range state.deliveries {
+			if state.deliveries[i].Id == delivery.Id {
+				updated := copyWebhookDelivery(*delivery)
+				state.deliveries[i].Status = updated.Status
+				state.deliveries[i].Attempts = updated.Attempts
+				state.deliveries[i].LastStatusCode = updated.LastStatusCode
+				state.deliveries[i].LastError = updated.LastError
+				state.deliveries[i].NextAttemptAt = updated.NextAttemptAt
+				state.deliveries[i].DeliveredAt = updated.DeliveredAt
+			}
+		}
+		return nil
+	})
+}
+
+func (m *MemDb) SaveWebhookDeliveryAttempt(ctx context.Context, attempt *model.WebhookDeliveryAttempt) error {
+	return m.run(func(state *memState) error {
+		for _, existing := range state.attempts {
+			if existing.Id == attempt.Id {
+				return exception.NewAlreadyExists()
+			}
+		}
+
+		state.attempts = append(state.attempts, copyWebhookDeliveryAttempt(*attempt))
+		return nil
+	})
+}
+
+func (m *MemDb) GetWebhookDeliveryAttempts(ctx context.Context, orgId string, deliveryId string) ([]model.WebhookDeliveryAttempt, error) {
+	attempts := []model.WebhookDeliveryAttempt{}
+	err := m.run(func(state *memState) error {
+		inOrg := false
+		for _, delivery := range state.deliveries {
+			inOrg = inOrg || (delivery.Id == deliveryId && delivery.OrgId == orgId)
+		}
+		if !inOrg {
+			return nil
+		}
+
+		for _, attempt := range state.attempts {
+			if attempt.DeliveryId == deliveryId {
+				attempts = append(attempts, copyWebhookDeliveryAttempt(attempt))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].CreatedAt.Before(attempts[j].CreatedAt)
+	})
+
+	return attempts, nil
+}
+
+// the copy helpers make sure that rows never share memory with the values of the callers, like a real database
+
+func copyUser(user model.User) model.User {
+	user.Password = copyPtr(user.Password)
+	user.FirstName = copyPtr(user.FirstName)
+	user.LastName = copyPtr(user.LastName)
+	user.UpdatedAt = copyPtr(user.UpdatedAt)
+	return user
+}
+
+func copyInvite(invite model.Invite) model.Invite {
+	invite.Email = copyPtr(invite.Email)
+	invite.OrgId = copyPtr(invite.OrgId)
+	invite.Role = copyPtr(invite.Role)
+	invite.ExpiresAt = copyPtr(invite.ExpiresAt)
+	return invite
+}
+
+func copyOrg(org model.Organization) model.Organization {
+	org.UpdatedAt = copyPtr(org.UpdatedAt)
+	return org
+}
+
+func copyLoginEvent(event model.LoginEvent) model.LoginEvent {
+	event.UserId = copyPtr(event.UserId)
+	event.FailureReason = copyPtr(event.FailureReason)
+	return event
+}
+
+func copyOutboxEvent(event model.OutboxEvent) model.OutboxEvent {
+	event.Payload = append([]byte{}, event.Payload...)
+	event.LastError = copyPtr(event.LastError)
+	event.SentAt = copyPtr(event.SentAt)
+	return event
+}
+
+func copyWebhook(webhook model.Webhook) model.Webhook {
+	webhook.EventTypes = append([]string{}, webhook.EventTypes...)
+	return webhook
+}
+
+func copyWebhookDelivery(delivery model.WebhookDelivery) model.WebhookDelivery {
+	delivery.Payload = append([]byte{}, delivery.Payload...)
+	delivery.LastStatusCode = copyPtr(delivery.LastStatusCode)
+	delivery.LastError = copyPtr(delivery.LastError)
+	delivery.DeliveredAt = copyPtr(delivery.DeliveredAt)
+	return delivery
+}
+
+func copyWebhookDeliveryAttempt(attempt model.WebhookDeliveryAttempt) model.WebhookDeliveryAttempt {
+	attempt.StatusCode = copyPtr(attempt.StatusCode)
+	attempt.Error = copyPtr(attempt.Error)
+	attempt.ResponseBody = copyPtr(attempt.ResponseBody)
+	return attempt
+}
+
+func copyPtr[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+
+	copied := *value
+	return &copied
+}
+
+var _ database.Db = (*MemDb)(nil)
@@ -0,0 +1,306 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MemDb_SaveUser_Should_Return_AlreadyExists_On_Duplicate_Id(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	user := GenMockUser(nil)
+	err := db.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+
+	// ACT
+	errRes := db.SaveUser(ctx, &user)
+
+	// ASSERT
+	assert.IsType(t, exception.AlreadyExists{}, errRes)
+
+	exists, userRes, err := db.GetUserByEmail(ctx, user.Email)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, user, userRes)
+}
+
+func Test_MemDb_SaveUser_Should_Return_AlreadyExists_On_Duplicate_Email_With_Other_Case(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	user := GenMockUser(&model.User{Email: "john@example.com"})
+	err := db.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+	otherUser := GenMockUser(&model.User{Email: "John@Example.com"})
+
+	// ACT
+	errRes := db.SaveUser(ctx, &otherUser)
+
+	// ASSERT
+	assert.Equal(t, exception.NewAlreadyExistsFromBase(exception.Base{
+		Message: "user with the email 'John@Example.com' already exists",
+		Type:    errorcode.UserAlreadyExist,
+	}), errRes)
+}
+
+func Test_MemDb_Should_Not_Share_Memory_With_Callers(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	user := GenMockUser(nil)
+	err := db.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+
+	// ACT
+	*user.FirstName = "changed"
+
+	// ASSERT
+	_, userRes, err := db.GetUserById(ctx, user.Id)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "changed", *userRes.FirstName)
+}
+
+func Test_MemDb_WithTx_Should_Rollback_On_Error(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	user := GenMockUser(nil)
+	nestedUser := GenMockUser(nil)
+	expectedErr := errors.New("some error")
+
+	// ACT
+	errRes := db.WithTx(ctx, func(txDb database.Db) error {
+		err := txDb.SaveUser(ctx, &user)
+		assert.Nil(t, err)
+
+		nestedErr := txDb.WithTx(ctx, func(nestedDb database.Db) error {
+			err := nestedDb.SaveUser(ctx, &nestedUser)
+			assert.Nil(t, err)
+			return expectedErr
+		})
+		assert.ErrorIs(t, nestedErr, expectedErr)
+
+		isTaken, err := txDb.IsUserEmailTaken(ctx, user.Email)
+		assert.Nil(t, err)
+		assert.True(t, isTaken, "the outer transaction should see its own writes")
+
+		isTaken, err = txDb.IsUserEmailTaken(ctx, nestedUser.Email)
+		assert.Nil(t, err)
+		assert.False(t, isTaken, "the failed nested transaction should be rolled back")
+
+		return expectedErr
+	})
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, expectedErr)
+
+	isTaken, err := db.IsUserEmailTaken(ctx, user.Email)
+	assert.Nil(t, err)
+	assert.False(t, isTaken)
+}
+
+func Test_MemDb_WithTx_Should_Commit(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	user := GenMockUser(nil)
+	nestedUser := GenMockUser(nil)
+
+	// ACT
+	errRes := db.WithTx(ctx, func(txDb database.Db) error {
+		err := txDb.SaveUser(ctx, &user)
+		if err != nil {
+			return err
+		}
+
+		return txDb.WithTx(ctx, func(nestedDb database.Db) error {
+			return nestedDb.SaveUser(ctx, &nestedUser)
+		})
+	})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+
+	for _, email := range []string{user.Email, nestedUser.Email} {
+		isTaken, err := db.IsUserEmailTaken(ctx, email)
+		assert.Nil(t, err)
+		assert.True(t, isTaken)
+	}
+}
+
+func Test_MemDb_IncrementInviteUsage_Should_Not_Exceed_Max_Uses_Concurrently(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	now := time.Now()
+	invite := model.Invite{Id: Fake.UUID().V4(), Code: "code", CreatedBy: Fake.UUID().V4(), MaxUses: 5, CreatedAt: now}
+	err := db.SaveInvite(ctx, &invite)
+	assert.Nil(t, err)
+
+	// ACT
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	incrementedCount := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			incremented, err := db.IncrementInviteUsage(ctx, invite.Id, now)
+			assert.Nil(t, err)
+			if incremented {
+				mu.Lock()
+				incrementedCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// ASSERT
+	assert.Equal(t, 5, incrementedCount)
+
+	_, inviteRes, err := db.GetInviteByCode(ctx, invite.Code)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, inviteRes.UsedCount)
+}
+
+func Test_MemDb_GetLoginEvents_Should_Paginate_Newest_First(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	userId := Fake.UUID().V4()
+	start := time.Now().UTC()
+	for i := 0; i < 3; i++ {
+		event := model.LoginEvent{Id: Fake.UUID().V4(), UserId: &userId, Success: true, Ip: "127.0.0.1", CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+		err := db.SaveLoginEvent(ctx, &event)
+		assert.Nil(t, err)
+	}
+
+	// ACT
+	eventsRes, errRes := db.GetLoginEvents(ctx, userId, 2, 1)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Len(t, eventsRes, 2)
+	assert.Equal(t, start.Add(time.Minute), eventsRes[0].CreatedAt)
+	assert.Equal(t, start, eventsRes[1].CreatedAt)
+
+	totalRes, err := db.CountLoginEvents(ctx, userId)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, totalRes)
+
+	statsRes, err := db.GetLoginHistoryStats(ctx, userId, "127.0.0.1", "")
+	assert.Nil(t, err)
+	assert.Equal(t, model.LoginHistoryStats{SuccessfulLogins: 3, FromSameIp: 3, FromSameDevice: 3}, statsRes)
+}
+
+func Test_MemDb_Snapshot_Restore_And_Reset(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	user := GenMockUser(nil)
+	err := db.SaveUser(ctx, &user)
+	assert.Nil(t, err)
+	snapshot := db.Snapshot()
+
+	otherUser := GenMockUser(nil)
+	err = db.SaveUser(ctx, &otherUser)
+	assert.Nil(t, err)
+
+	// ACT
+	db.Restore(snapshot)
+
+	// ASSERT
+	exists, _, err := db.GetUserById(ctx, user.Id)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	exists, _, err = db.GetUserById(ctx, otherUser.Id)
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	db.CloseConnection()
+	assert.ErrorIs(t, db.CheckHealth(), ErrMemDbClosed)
+
+	db.Reset()
+	assert.Nil(t, db.CheckHealth())
+	exists, _, err = db.GetUserById(ctx, user.Id)
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func Test_MemDb_Outbox_Should_Return_Due_Events_Until_Sent(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	now := time.Now()
+	due := model.OutboxEvent{Id: "event-1", Topic: "EVENT.USER.NEW", NextAttemptAt: now.Add(-time.Minute), CreatedAt: now.Add(-time.Minute)}
+	notDue := model.OutboxEvent{Id: "event-2", Topic: "EVENT.USER.NEW", NextAttemptAt: now.Add(time.Minute), CreatedAt: now}
+	assert.Nil(t, db.SaveOutboxEvent(ctx, &due))
+	assert.Nil(t, db.SaveOutboxEvent(ctx, &notDue))
+
+	// ACT
+	pendingRes, _ := db.GetPendingOutboxEvents(ctx, now, 10)
+	errFailedRes := db.MarkOutboxEventFailed(ctx, due.Id, "no responders", now.Add(2*time.Minute))
+	pendingAfterFailureRes, _ := db.GetPendingOutboxEvents(ctx, now, 10)
+	errSentRes := db.MarkOutboxEventSent(ctx, notDue.Id, now)
+	statsRes, _ := db.GetOutboxStats(ctx)
+
+	// ASSERT
+	if assert.Len(t, pendingRes, 1) {
+		assert.Equal(t, due.Id, pendingRes[0].Id)
+	}
+	assert.Nil(t, errFailedRes)
+	assert.Empty(t, pendingAfterFailureRes)
+	assert.Nil(t, errSentRes)
+	assert.Equal(t, 1, statsRes.Pending)
+	assert.Equal(t, due.CreatedAt, *statsRes.OldestPendingAt)
+}
+
+func Test_MemDb_Webhooks_Should_Queue_Deliveries_Once_And_Be_Deleted_With_Them(t *testing.T) {
+	// ARRANGE
+	db := NewMemDb()
+	ctx := context.Background()
+	now := time.Now()
+	webhook := model.Webhook{Id: "webhook-1", OrgId: "org-1", Url: "https://example.com/hooks", EventTypes: []string{"user.registered"}, CreatedAt: now}
+	otherWebhook := model.Webhook{Id: "webhook-2", OrgId: "org-1", Url: "https://example.com/other", EventTypes: []string{"*"}, CreatedAt: now}
+	delivery := model.WebhookDelivery{Id: "delivery-1", WebhookId: webhook.Id, OrgId: webhook.OrgId, EventId: "event-1", Status: model.WebhookDeliveryPending, NextAttemptAt: now, CreatedAt: now}
+	otherDelivery := model.WebhookDelivery{Id: "delivery-2", WebhookId: otherWebhook.Id, OrgId: otherWebhook.OrgId, EventId: "event-1", Payload: []byte("{}"), Status: model.WebhookDeliveryPending, NextAttemptAt: now, CreatedAt: now}
+	assert.Nil(t, db.SaveWebhook(ctx, &webhook))
+	assert.Nil(t, db.SaveWebhook(ctx, &otherWebhook))
+
+	// ACT
+	savedRes, _ := db.SaveWebhookDelivery(ctx, &delivery)
+	savedOtherRes, _ := db.SaveWebhookDelivery(ctx, &otherDelivery)
+	savedAgainRes, _ := db.SaveWebhookDelivery(ctx, &model.WebhookDelivery{Id: "delivery-3", WebhookId: webhook.Id, OrgId: webhook.OrgId, EventId: "event-1"})
+	_ = db.SaveWebhookDeliveryAttempt(ctx, &model.WebhookDeliveryAttempt{Id: "attempt-1", DeliveryId: delivery.Id, CreatedAt: now})
+	attemptsBeforeDeleteRes, _ := db.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	attemptsInOtherOrgRes, _ := db.GetWebhookDeliveryAttempts(ctx, "other-org", delivery.Id)
+	deletedRes, errDeleteRes := db.DeleteWebhook(ctx, webhook.OrgId, webhook.Id)
+
+	// ASSERT
+	assert.True(t, savedRes)
+	assert.True(t, savedOtherRes, "should queue the event for every webhook")
+	assert.False(t, savedAgainRes)
+	assert.Len(t, attemptsBeforeDeleteRes, 1)
+	assert.Empty(t, attemptsInOtherOrgRes, "should not return the attempts of the deliveries of another organization")
+	assert.Nil(t, errDeleteRes)
+	assert.True(t, deletedRes)
+
+	webhooksRes, _ := db.GetOrgWebhooks(ctx, webhook.OrgId)
+	pendingRes, _ := db.GetPendingWebhookDeliveries(ctx, now, 10)
+	attemptsRes, _ := db.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	assert.Equal(t, []model.Webhook{otherWebhook}, webhooksRes)
+	assert.Equal(t, []model.WebhookDelivery{otherDelivery}, pendingRes, "should only delete the deliveries of the webhook")
+	assert.Empty(t, attemptsRes)
+}
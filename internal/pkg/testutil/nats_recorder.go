@@ -0,0 +1,141 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+)
+
+// NatsRecorder records the messages published to subjects of a NATS server, so that tests can assert on what an action
+// has published. Messages published to JetStream are recorded as well, whether the stream stores them or not.
+type NatsRecorder struct {
+	nc      *natsgo.Conn
+	mu      sync.Mutex
+	msgs    []nats.Msg
+	updated chan struct{}
+}
+
+// NewNatsRecorder subscribes to the subjects, wildcards are allowed. Only the messages published after it returns are
+// recorded.
+func NewNatsRecorder(url string, subjects ...string) (*NatsRecorder, error) {
+	nc, err := natsgo.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("testutil.NewNatsRecorder(): %w", err)
+	}
+
+	recorder := &NatsRecorder{nc: nc, updated: make(chan struct{})}
+	for _, subject := range subjects {
+		_, err = nc.Subscribe(subject, recorder.record)
+		if err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("testutil.NewNatsRecorder(): %w", err)
+		}
+	}
+
+	// the subscriptions are only active once the server has processed them
+	err = nc.Flush()
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("testutil.NewNatsRecorder(): %w", err)
+	}
+
+	return recorder, nil
+}
+
+func (r *NatsRecorder) record(msg *natsgo.Msg) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.msgs = append(r.msgs, nats.Msg{Subject: msg.Subject, Data: msg.Data, Headers: msg.Header})
+	// waiters are woken up by closing the channel, the next ones wait on a new one
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+// Close stops recording
+func (r *NatsRecorder) Close() {
+	r.nc.Close()
+}
+
+// Msgs returns the messages recorded so far in the order they were received, of every subject when subject is empty
+func (r *NatsRecorder) Msgs(subject string) []nats.Msg {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	msgs := []nats.Msg{}
+	for _, msg := range r.msgs {
+		if subject == "" || msg.Subject == subject {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs
+}
+
+// WaitFor waits until a message of the subject for which match returns true has been recorded and returns it, match may
+// be nil to accept any message
+func (r *NatsRecorder) WaitFor(subject string, timeout time.Duration, match func(msg nats.Msg) bool) (nats.Msg, error) {
+	deadline := time.After(timeout)
+	for {
+		r.mu.Lock()
+		updated := r.updated
+		for _, msg := range r.msgs {
+			if msg.Subject == subject && (match == nil || match(msg)) {
+				r.mu.Unlock()
+				return msg, nil
+			}
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-updated:
+		case <-deadline:
+			return nats.Msg{}, fmt.Errorf("no matching message published to '%s' within %s", subject, timeout)
+		}
+	}
+}
+
+// AssertPublished asserts that a message of the subject for which match returns true is published within timeout
+func (r *NatsRecorder) AssertPublished(t assert.TestingT, subject string, timeout time.Duration, match func(msg nats.Msg) bool) bool {
+	_, err := r.WaitFor(subject, timeout, match)
+	return assert.NoError(t, err)
+}
+
+// AssertNotPublished asserts that no message of the subject for which match returns true is published within wait
+func (r *NatsRecorder) AssertNotPublished(t assert.TestingT, subject string, wait time.Duration, match func(msg nats.Msg) bool) bool {
+	msg, err := r.WaitFor(subject, wait, match)
+	if err == nil {
+		return assert.Fail(t, fmt.Sprintf("unexpected message published to '%s': %s", subject, msg.Data))
+	}
+
+	return true
+}
+
+// AssertEventPublished asserts that an event envelope of the type, whose data decoded into T satisfies match, is
+// published to the subject within timeout and returns the data
+func AssertEventPublished[T any](t assert.TestingT, r *NatsRecorder, subject string, eventType string, timeout time.Duration, match func(data T) bool) (T, bool) {
+	var found T
+	_, err := r.WaitFor(subject, timeout, func(msg nats.Msg) bool {
+		envelope, err := event.Parse(msg.Data)
+		if err != nil || envelope.Type != eventType {
+			return false
+		}
+
+		var data T
+		err = json.Unmarshal(envelope.Data, &data)
+		if err != nil || (match != nil && !match(data)) {
+			return false
+		}
+
+		found = data
+		return true
+	})
+
+	return found, assert.NoError(t, err, "event '%s' should be published", eventType)
+}
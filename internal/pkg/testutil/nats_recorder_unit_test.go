@@ -0,0 +1,100 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type recordedData struct {
+	Email string `json:"email"`
+}
+
+func setupNatsRecorderTest(t *testing.T) (*NatsRecorder, *natsgo.Conn) {
+	logServiceMock := &logger.ServiceMock{}
+	logServiceMock.On("Debug", mock.Anything).Return()
+
+	embeddedServer, err := nats.StartEmbeddedServer(logServiceMock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(embeddedServer.Shutdown)
+
+	recorder, err := NewNatsRecorder(embeddedServer.Url(), "EVENT.USER.>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(recorder.Close)
+
+	nc, err := natsgo.Connect(embeddedServer.Url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+
+	return recorder, nc
+}
+
+func Test_NatsRecorder_Should_Record_Messages_Of_Subjects(t *testing.T) {
+	// ARRANGE
+	recorder, nc := setupNatsRecorderTest(t)
+
+	// ACT
+	assert.Nil(t, nc.Publish("EVENT.USER.NEW", []byte("1")))
+	assert.Nil(t, nc.Publish("EVENT.ORG.NEW", []byte("2")))
+	assert.Nil(t, nc.Publish("EVENT.USER.NEW_DEVICE_LOGIN", []byte("3")))
+	msgRes, errRes := recorder.WaitFor("EVENT.USER.NEW_DEVICE_LOGIN", time.Second, nil)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, "3", string(msgRes.Data))
+	assert.Len(t, recorder.Msgs(""), 2, "should only record the subjects it subscribed to")
+	assert.Len(t, recorder.Msgs("EVENT.USER.NEW"), 1)
+}
+
+func Test_NatsRecorder_WaitFor_Should_Return_Error_After_Timeout(t *testing.T) {
+	// ARRANGE
+	recorder, nc := setupNatsRecorderTest(t)
+	assert.Nil(t, nc.Publish("EVENT.USER.NEW", []byte("1")))
+
+	// ACT
+	_, errRes := recorder.WaitFor("EVENT.USER.NEW", 100*time.Millisecond, func(msg nats.Msg) bool {
+		return string(msg.Data) == "2"
+	})
+
+	// ASSERT
+	assert.ErrorContains(t, errRes, "no matching message published to 'EVENT.USER.NEW'")
+}
+
+func Test_AssertEventPublished_Should_Return_Data_Of_Matching_Event(t *testing.T) {
+	// ARRANGE
+	recorder, nc := setupNatsRecorderTest(t)
+	registry := event.NewRegistry("test")
+	err := event.Register[recordedData](registry, "user.registered", 1)
+	assert.Nil(t, err)
+
+	for _, email := range []string{"john@example.com", "jane@example.com"} {
+		envelope, err := registry.New(context.Background(), "user.registered", recordedData{Email: email})
+		assert.Nil(t, err)
+		payload, err := json.Marshal(envelope)
+		assert.Nil(t, err)
+		assert.Nil(t, nc.Publish("EVENT.USER.NEW", payload))
+	}
+
+	// ACT
+	dataRes, okRes := AssertEventPublished(t, recorder, "EVENT.USER.NEW", "user.registered", time.Second, func(data recordedData) bool {
+		return data.Email == "jane@example.com"
+	})
+
+	// ASSERT
+	assert.True(t, okRes)
+	assert.Equal(t, "jane@example.com", dataRes.Email)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/jaswdr/faker"
 	"github.com/pjmessi/golang-practice/config"
 	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
 )
 
 var Fake faker.Faker = faker.New()
@@ -104,15 +105,11 @@ func GenMockRegUserApiReq(partialData *model.UserRegApiReq) model.UserRegApiReq
 }
 
 func GetTestDbCon(appConf *config.AppConfig) (*sql.DB, error) {
-	dns := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", appConf.DB_USER, appConf.DB_PASSWORD, appConf.DB_HOST, appConf.DB_PORT, appConf.DB_DATABASE)
-	db, err := sql.Open("mysql", dns)
+	db, err := database.Open(appConf)
 	if err != nil {
 		return nil, fmt.Errorf("testutil.GetTestDbCon(): %w", err)
 	}
 
-	db.SetConnMaxLifetime(time.Minute * 3)
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(10)
 	return db, nil
 }
 
@@ -137,23 +134,69 @@ func SetupTestUser(testServerUrl string) model.LoginApiRes {
 
 func GetMockAppConfig(appConf *config.AppConfig) config.AppConfig {
 	finalAppConfig := config.AppConfig{
-		APP_PORT:                     "3000",
-		DB_HOST:                      "localhost",
-		DB_PORT:                      "3006",
-		DB_DATABASE:                  "go_test",
-		DB_USER:                      Fake.Internet().User(),
-		DB_PASSWORD:                  Fake.Internet().Password(),
-		JWT_SECRET:                   Fake.RandomStringWithLength(10),
-		JWT_EXPIRATION_TIME:          "1d",
-		NATS_URL:                     "nats://127.0.0.1:4222",
-		NATS_STREAM:                  "GO_STREAM",
-		NATS_EVENT_USER_REGISTRATION: "EVENT.USER.NEW",
+		APP_PORT:                       "3000",
+		GRPC_PORT:                      "50051",
+		TRUSTED_PROXIES:                "",
+		ADMIN_PORT:                     "6060",
+		DB_HOST:                        "localhost",
+		DB_PORT:                        "3006",
+		DB_DATABASE:                    "go_test",
+		DB_USER:                        Fake.Internet().User(),
+		DB_PASSWORD:                    Fake.Internet().Password(),
+		DB_DRIVER:                      "mysql",
+		DB_SSL_MODE:                    "disable",
+		DB_AUTO_MIGRATE:                "false",
+		DB_QUERY_TIMEOUT:               "5s",
+		DB_MAX_OPEN_CONNS:              "10",
+		DB_MAX_IDLE_CONNS:              "10",
+		DB_CONN_MAX_LIFETIME:           "3m",
+		DB_CONN_MAX_IDLE_TIME:          "0",
+		DB_REPLICA_DSNS:                "",
+		JWT_SECRET:                     Fake.RandomStringWithLength(10),
+		JWT_EXPIRATION_TIME:            "1d",
+		NATS_URL:                       "nats://127.0.0.1:4222",
+		NATS_EMBEDDED:                  "false",
+		NATS_DRIVER:                    "nats",
+		NATS_MEMORY_FILE:               "",
+		NATS_RECONNECT_WAIT:            "1s",
+		NATS_CREDS_FILE:                "",
+		NATS_NKEY_SEED_FILE:            "",
+		NATS_TLS_CA_FILE:               "",
+		NATS_TLS_CERT_FILE:             "",
+		NATS_TLS_KEY_FILE:              "",
+		NATS_MAX_RECONNECTS:            "-1",
+		NATS_STREAM:                    "GO_STREAM",
+		NATS_DLQ_STREAM:                "GO_STREAM_DLQ",
+		NATS_EVENT_USER_REGISTRATION:   "EVENT.USER.NEW",
+		NATS_EVENT_USER_NEW_DEVICE:     "EVENT.USER.NEW_DEVICE_LOGIN",
+		NATS_MAX_DELIVER:               "5",
+		NATS_PUBLISH_MAX_PENDING:       "256",
+		NATS_RPC_TIMEOUT:               "5s",
+		NATS_RPC_USER_GET:              "svc.user.get",
+		NATS_RPC_USER_BATCH_GET:        "svc.user.batchGet",
+		OUTBOX_POLL_INTERVAL:           "1s",
+		OUTBOX_BATCH_SIZE:              "100",
+		PROCESSED_MESSAGE_TTL:          "168h",
+		WEBHOOK_POLL_INTERVAL:          "1s",
+		WEBHOOK_TIMEOUT:                "10s",
+		WEBHOOK_MAX_ATTEMPTS:           "8",
+		WEBHOOK_ALLOW_PRIVATE_NETWORKS: "false",
+		REGISTRATION_INVITE_ONLY:       "false",
 	}
 
 	if appConf != nil {
 		if appConf.APP_PORT != "" {
 			finalAppConfig.APP_PORT = appConf.APP_PORT
 		}
+		if appConf.GRPC_PORT != "" {
+			finalAppConfig.GRPC_PORT = appConf.GRPC_PORT
+		}
+		if appConf.TRUSTED_PROXIES != "" {
+			finalAppConfig.TRUSTED_PROXIES = appConf.TRUSTED_PROXIES
+		}
+		if appConf.ADMIN_PORT != "" {
+			finalAppConfig.ADMIN_PORT = appConf.ADMIN_PORT
+		}
 		if appConf.DB_HOST != "" {
 			finalAppConfig.DB_HOST = appConf.DB_HOST
 		}
@@ -169,6 +212,33 @@ func GetMockAppConfig(appConf *config.AppConfig) config.AppConfig {
 		if appConf.DB_PASSWORD != "" {
 			finalAppConfig.DB_PASSWORD = appConf.DB_PASSWORD
 		}
+		if appConf.DB_DRIVER != "" {
+			finalAppConfig.DB_DRIVER = appConf.DB_DRIVER
+		}
+		if appConf.DB_SSL_MODE != "" {
+			finalAppConfig.DB_SSL_MODE = appConf.DB_SSL_MODE
+		}
+		if appConf.DB_AUTO_MIGRATE != "" {
+			finalAppConfig.DB_AUTO_MIGRATE = appConf.DB_AUTO_MIGRATE
+		}
+		if appConf.DB_QUERY_TIMEOUT != "" {
+			finalAppConfig.DB_QUERY_TIMEOUT = appConf.DB_QUERY_TIMEOUT
+		}
+		if appConf.DB_MAX_OPEN_CONNS != "" {
+			finalAppConfig.DB_MAX_OPEN_CONNS = appConf.DB_MAX_OPEN_CONNS
+		}
+		if appConf.DB_MAX_IDLE_CONNS != "" {
+			finalAppConfig.DB_MAX_IDLE_CONNS = appConf.DB_MAX_IDLE_CONNS
+		}
+		if appConf.DB_CONN_MAX_LIFETIME != "" {
+			finalAppConfig.DB_CONN_MAX_LIFETIME = appConf.DB_CONN_MAX_LIFETIME
+		}
+		if appConf.DB_CONN_MAX_IDLE_TIME != "" {
+			finalAppConfig.DB_CONN_MAX_IDLE_TIME = appConf.DB_CONN_MAX_IDLE_TIME
+		}
+		if appConf.DB_REPLICA_DSNS != "" {
+			finalAppConfig.DB_REPLICA_DSNS = appConf.DB_REPLICA_DSNS
+		}
 		if appConf.JWT_SECRET != "" {
 			finalAppConfig.JWT_SECRET = appConf.JWT_SECRET
 		}
@@ -178,12 +248,87 @@ func GetMockAppConfig(appConf *config.AppConfig) config.AppConfig {
 		if appConf.NATS_URL != "" {
 			finalAppConfig.NATS_URL = appConf.NATS_URL
 		}
+		if appConf.NATS_EMBEDDED != "" {
+			finalAppConfig.NATS_EMBEDDED = appConf.NATS_EMBEDDED
+		}
+		if appConf.NATS_DRIVER != "" {
+			finalAppConfig.NATS_DRIVER = appConf.NATS_DRIVER
+		}
+		if appConf.NATS_MEMORY_FILE != "" {
+			finalAppConfig.NATS_MEMORY_FILE = appConf.NATS_MEMORY_FILE
+		}
+		if appConf.NATS_RECONNECT_WAIT != "" {
+			finalAppConfig.NATS_RECONNECT_WAIT = appConf.NATS_RECONNECT_WAIT
+		}
+		if appConf.NATS_CREDS_FILE != "" {
+			finalAppConfig.NATS_CREDS_FILE = appConf.NATS_CREDS_FILE
+		}
+		if appConf.NATS_NKEY_SEED_FILE != "" {
+			finalAppConfig.NATS_NKEY_SEED_FILE = appConf.NATS_NKEY_SEED_FILE
+		}
+		if appConf.NATS_TLS_CA_FILE != "" {
+			finalAppConfig.NATS_TLS_CA_FILE = appConf.NATS_TLS_CA_FILE
+		}
+		if appConf.NATS_TLS_CERT_FILE != "" {
+			finalAppConfig.NATS_TLS_CERT_FILE = appConf.NATS_TLS_CERT_FILE
+		}
+		if appConf.NATS_TLS_KEY_FILE != "" {
+			finalAppConfig.NATS_TLS_KEY_FILE = appConf.NATS_TLS_KEY_FILE
+		}
+		if appConf.NATS_MAX_RECONNECTS != "" {
+			finalAppConfig.NATS_MAX_RECONNECTS = appConf.NATS_MAX_RECONNECTS
+		}
 		if appConf.NATS_STREAM != "" {
 			finalAppConfig.NATS_STREAM = appConf.NATS_STREAM
 		}
+		if appConf.NATS_DLQ_STREAM != "" {
+			finalAppConfig.NATS_DLQ_STREAM = appConf.NATS_DLQ_STREAM
+		}
 		if appConf.NATS_EVENT_USER_REGISTRATION != "" {
 			finalAppConfig.NATS_EVENT_USER_REGISTRATION = appConf.NATS_EVENT_USER_REGISTRATION
 		}
+		if appConf.NATS_EVENT_USER_NEW_DEVICE != "" {
+			finalAppConfig.NATS_EVENT_USER_NEW_DEVICE = appConf.NATS_EVENT_USER_NEW_DEVICE
+		}
+		if appConf.NATS_MAX_DELIVER != "" {
+			finalAppConfig.NATS_MAX_DELIVER = appConf.NATS_MAX_DELIVER
+		}
+		if appConf.NATS_PUBLISH_MAX_PENDING != "" {
+			finalAppConfig.NATS_PUBLISH_MAX_PENDING = appConf.NATS_PUBLISH_MAX_PENDING
+		}
+		if appConf.NATS_RPC_TIMEOUT != "" {
+			finalAppConfig.NATS_RPC_TIMEOUT = appConf.NATS_RPC_TIMEOUT
+		}
+		if appConf.NATS_RPC_USER_GET != "" {
+			finalAppConfig.NATS_RPC_USER_GET = appConf.NATS_RPC_USER_GET
+		}
+		if appConf.NATS_RPC_USER_BATCH_GET != "" {
+			finalAppConfig.NATS_RPC_USER_BATCH_GET = appConf.NATS_RPC_USER_BATCH_GET
+		}
+		if appConf.OUTBOX_POLL_INTERVAL != "" {
+			finalAppConfig.OUTBOX_POLL_INTERVAL = appConf.OUTBOX_POLL_INTERVAL
+		}
+		if appConf.OUTBOX_BATCH_SIZE != "" {
+			finalAppConfig.OUTBOX_BATCH_SIZE = appConf.OUTBOX_BATCH_SIZE
+		}
+		if appConf.PROCESSED_MESSAGE_TTL != "" {
+			finalAppConfig.PROCESSED_MESSAGE_TTL = appConf.PROCESSED_MESSAGE_TTL
+		}
+		if appConf.WEBHOOK_POLL_INTERVAL != "" {
+			finalAppConfig.WEBHOOK_POLL_INTERVAL = appConf.WEBHOOK_POLL_INTERVAL
+		}
+		if appConf.WEBHOOK_TIMEOUT != "" {
+			finalAppConfig.WEBHOOK_TIMEOUT = appConf.WEBHOOK_TIMEOUT
+		}
+		if appConf.WEBHOOK_MAX_ATTEMPTS != "" {
+			finalAppConfig.WEBHOOK_MAX_ATTEMPTS = appConf.WEBHOOK_MAX_ATTEMPTS
+		}
+		if appConf.WEBHOOK_ALLOW_PRIVATE_NETWORKS != "" {
+			finalAppConfig.WEBHOOK_ALLOW_PRIVATE_NETWORKS = appConf.WEBHOOK_ALLOW_PRIVATE_NETWORKS
+		}
+		if appConf.REGISTRATION_INVITE_ONLY != "" {
+			finalAppConfig.REGISTRATION_INVITE_ONLY = appConf.REGISTRATION_INVITE_ONLY
+		}
 	}
 
 	return finalAppConfig
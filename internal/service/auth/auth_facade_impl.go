@@ -48,7 +48,7 @@ func (f *FacadeImpl) Login(ctx context.Context, reqBytes []byte) ([]byte, error)
 		}
 	}
 
-	user, jwtStr, err := f.authService.Login(ctx, req.Email, req.Password)
+	user, jwtStr, err := f.authService.Login(ctx, req.Email, req.Password, req.OrgId)
 	if err != nil {
 		return nil, err
 	}
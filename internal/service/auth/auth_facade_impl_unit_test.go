@@ -122,7 +122,7 @@ func Test_Facade_Login_Err_Logging_In(t *testing.T) {
 	loginErr := fmt.Errorf("error from Login")
 
 	validationUtilMock.On("ValidateStruct", loginApiReq).Return(nil)
-	service.On("Login", ctx, loginApiReq.Email, loginApiReq.Password).Return(model.User{}, "", loginErr)
+	service.On("Login", ctx, loginApiReq.Email, loginApiReq.Password, loginApiReq.OrgId).Return(model.User{}, "", loginErr)
 
 	// ACT
 	bytesRes, errRes := facade.Login(ctx, reqBytes)
@@ -146,7 +146,7 @@ func Test_Facade_Login_Success_Res(t *testing.T) {
 	jwtStr := testutil.Fake.RandomStringWithLength(255)
 
 	validationUtilMock.On("ValidateStruct", loginApiReq).Return(nil)
-	service.On("Login", ctx, loginApiReq.Email, loginApiReq.Password).Return(user, jwtStr, nil)
+	service.On("Login", ctx, loginApiReq.Email, loginApiReq.Password, loginApiReq.OrgId).Return(user, jwtStr, nil)
 
 	// ACT
 	bytesRes, errRes := facade.Login(ctx, reqBytes)
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -14,3 +15,8 @@ func (f *FacadeMock) Login(ctx context.Context, reqBytes []byte) ([]byte, error)
 	args := f.Called(ctx, reqBytes)
 	return args.Get(0).([]byte), args.Error(1)
 }
+
+func (f *FacadeMock) VerifyJwt(ctx context.Context, jwtStr string) (jwt.JwtPayload, error) {
+	args := f.Called(ctx, jwtStr)
+	return args.Get(0).(jwt.JwtPayload), args.Error(1)
+}
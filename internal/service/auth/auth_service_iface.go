@@ -8,6 +8,6 @@ import (
 )
 
 type Service interface {
-	Login(ctx context.Context, email string, password string) (user model.User, jwt string, err error)
+	Login(ctx context.Context, email string, password string, orgId *string) (user model.User, jwt string, err error)
 	VerifyJwt(ctx context.Context, jwtStr string) (jwt.JwtPayload, error)
 }
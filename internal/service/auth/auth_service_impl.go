@@ -3,63 +3,132 @@ package auth
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pjmessi/golang-practice/internal/errorcode"
 	"github.com/pjmessi/golang-practice/internal/model"
 	"github.com/pjmessi/golang-practice/internal/pkg/database"
 	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
 	"github.com/pjmessi/golang-practice/internal/pkg/passwordutil"
+	"github.com/pjmessi/golang-practice/internal/service/security"
 	"github.com/pjmessi/golang-practice/pkg/exception"
 	"github.com/pjmessi/golang-practice/pkg/logger"
 )
 
 type ServiceImpl struct {
 	Service
-	db         database.Db
-	jwtHandler jwt.Handler
-	logService logger.Service
+	db              database.Db
+	jwtHandler      jwt.Handler
+	logService      logger.Service
+	securityService security.Service
 }
 
-func NewService(logService logger.Service, jwtHandler jwt.Handler, db database.Db) Service {
+func NewService(logService logger.Service, jwtHandler jwt.Handler, db database.Db, securityService security.Service) Service {
 	return &ServiceImpl{
-		db:         db,
-		jwtHandler: jwtHandler,
-		logService: logService,
+		db:              db,
+		jwtHandler:      jwtHandler,
+		logService:      logService,
+		securityService: securityService,
 	}
 }
 
-func (s *ServiceImpl) Login(ctx context.Context, email string, password string) (model.User, string, error) {
-	userExists, user, err := (s.db).GetUserByEmail(ctx, email)
+func (s *ServiceImpl) Login(ctx context.Context, email string, password string, orgId *string) (model.User, string, error) {
+	// emails are saved in lower case during registration, the lookup and the recorded attempt use the same case
+	email = strings.ToLower(email)
+
+	user, jwtString, failureReason, err := s.login(ctx, email, password, orgId)
+
+	// unexpected errors are not login attempts made by the user, so they are not recorded
+	if err == nil || failureReason != "" {
+		s.recordLoginAttempt(ctx, email, user, failureReason)
+	}
+
 	if err != nil {
 		return model.User{}, "", err
 	}
+
+	return user, jwtString, nil
+}
+
+// login authenticates the user, the returned user is set whenever the user exists so that failed attempts can be
+// attributed to the user, failureReason is set when the user failed to authenticate
+func (s *ServiceImpl) login(ctx context.Context, email string, password string, orgId *string) (model.User, string, string, error) {
+	userExists, user, err := (s.db).GetUserByEmail(ctx, email)
+	if err != nil {
+		return model.User{}, "", "", err
+	}
 	if !userExists {
 		s.logService.DebugCtx(ctx, fmt.Sprintf("user with the email '%s' does not exist", email))
-		return model.User{}, "", exception.NewUnauthenticatedFromBase(exception.Base{
+		return model.User{}, "", model.LoginFailureUserNotFound, exception.NewUnauthenticatedFromBase(exception.Base{
 			Message: "invalid credentials",
 		})
 	}
 
 	if user.Password == nil {
 		s.logService.DebugCtx(ctx, fmt.Sprintf("user with the email '%s' hasn't setup his password", email))
-		return model.User{}, "", exception.NewUnauthenticatedFromBase(exception.Base{
+		return user, "", model.LoginFailurePwNotSet, exception.NewUnauthenticatedFromBase(exception.Base{
 			Type: errorcode.UserPwNotSet,
 		})
 	}
 
 	if isValidHash := passwordutil.IsHashCorrect(*user.Password, password); !isValidHash {
 		s.logService.DebugCtx(ctx, fmt.Sprintf("user with the email '%s' did not provide correct password", email))
-		return model.User{}, "", exception.NewUnauthenticatedFromBase(exception.Base{
+		return user, "", model.LoginFailureInvalidPassword, exception.NewUnauthenticatedFromBase(exception.Base{
 			Message: "invalid credentials",
 		})
 	}
 
-	jwtString, err := s.jwtHandler.Generate(jwt.JwtPayload{UserId: user.Id, UserEmail: user.Email})
+	jwtPayload := jwt.JwtPayload{UserId: user.Id, UserEmail: user.Email}
+
+	if orgId != nil && *orgId != "" {
+		isMember, err := s.isOrgMember(ctx, *orgId, user.Id)
+		if err != nil {
+			return user, "", "", err
+		}
+		if !isMember {
+			return user, "", model.LoginFailureNotOrgMember, exception.NewUnauthorized(exception.Base{
+				Type:    errorcode.OrgNotMember,
+				Message: "user is not a member of the organization",
+			})
+		}
+		jwtPayload.OrgId = *orgId
+	}
+
+	jwtString, err := s.jwtHandler.Generate(jwtPayload)
 	if err != nil {
-		return model.User{}, "", err
+		return user, "", "", err
 	}
 
-	return user, jwtString, nil
+	return user, jwtString, "", nil
+}
+
+func (s *ServiceImpl) isOrgMember(ctx context.Context, orgId string, userId string) (bool, error) {
+	isMember, _, err := s.db.GetMembership(ctx, orgId, userId)
+	if err != nil {
+		return false, err
+	}
+
+	if !isMember {
+		s.logService.DebugCtx(ctx, fmt.Sprintf("user '%s' tried to login into organization '%s' without being a member", userId, orgId))
+	}
+
+	return isMember, nil
+}
+
+// recordLoginAttempt stores the attempt in the login history, failing to record it is logged but does not fail the login
+func (s *ServiceImpl) recordLoginAttempt(ctx context.Context, email string, user model.User, failureReason string) {
+	attempt := model.LoginAttempt{Email: email}
+	if user.Id != "" {
+		attempt.UserId = &user.Id
+	}
+	if failureReason != "" {
+		attempt.FailureReason = &failureReason
+	}
+
+	err := s.securityService.RecordLoginAttempt(ctx, attempt)
+	if err != nil {
+		s.logService.ErrorCtx(ctx, fmt.Sprintf("error recording login attempt for email '%s': %s", email, err))
+	}
 }
 
 func (s *ServiceImpl) VerifyJwt(ctx context.Context, jwtStr string) (jwt.JwtPayload, error) {
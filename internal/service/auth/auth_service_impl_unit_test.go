@@ -11,6 +11,7 @@ import (
 	"github.com/pjmessi/golang-practice/internal/pkg/database"
 	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
 	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/internal/service/security"
 	"github.com/pjmessi/golang-practice/pkg/exception"
 	"github.com/pjmessi/golang-practice/pkg/logger"
 	"github.com/stretchr/testify/assert"
@@ -18,33 +19,43 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// setupMocksForServiceImplTest creates ServiceImpl with mocked dependencies
+// setupMocksForServiceImplTest creates ServiceImpl with mocked dependencies, login attempts are recorded successfully
 func setupMocksForServiceImplTest() (*ServiceImpl, *database.DbMock, *jwt.HandlerMock, *logger.ServiceMock) {
+	service, dbMock, jwtHandlerMock, logServiceMock, securityServiceMock := setupMocksForServiceImplTestWithSecurity()
+	securityServiceMock.On("RecordLoginAttempt", mock.Anything, mock.Anything).Return(nil)
+	return service, dbMock, jwtHandlerMock, logServiceMock
+}
+
+// setupMocksForServiceImplTestWithSecurity creates ServiceImpl with mocked dependencies including the security service
+func setupMocksForServiceImplTestWithSecurity() (*ServiceImpl, *database.DbMock, *jwt.HandlerMock, *logger.ServiceMock, *security.ServiceMock) {
 	dbMock := new(database.DbMock)
 	jwtHandlerMock := new(jwt.HandlerMock)
 	logServiceMock := new(logger.ServiceMock)
+	securityServiceMock := new(security.ServiceMock)
 	service := &ServiceImpl{
-		db:         dbMock,
-		jwtHandler: jwtHandlerMock,
-		logService: logServiceMock,
+		db:              dbMock,
+		jwtHandler:      jwtHandlerMock,
+		logService:      logServiceMock,
+		securityService: securityServiceMock,
 	}
-	return service, dbMock, jwtHandlerMock, logServiceMock
+	return service, dbMock, jwtHandlerMock, logServiceMock, securityServiceMock
 }
 
 // setupMocksForNewService returns mocked dependencies for NewService func
-func setupMocksForNewService() (*database.DbMock, *jwt.HandlerMock, *logger.ServiceMock) {
+func setupMocksForNewService() (*database.DbMock, *jwt.HandlerMock, *logger.ServiceMock, *security.ServiceMock) {
 	dbMock := new(database.DbMock)
 	jwtHandlerMock := new(jwt.HandlerMock)
 	logServiceMock := new(logger.ServiceMock)
-	return dbMock, jwtHandlerMock, logServiceMock
+	securityServiceMock := new(security.ServiceMock)
+	return dbMock, jwtHandlerMock, logServiceMock, securityServiceMock
 }
 
 func Test_NewService(t *testing.T) {
 	// ARRANGE
-	dbMock, jwtHandlerMock, logServiceMock := setupMocksForNewService()
+	dbMock, jwtHandlerMock, logServiceMock, securityServiceMock := setupMocksForNewService()
 
 	// ACT
-	res := NewService(logServiceMock, jwtHandlerMock, dbMock)
+	res := NewService(logServiceMock, jwtHandlerMock, dbMock, securityServiceMock)
 
 	// ARRANGE
 	resServiceImpl := res.(*ServiceImpl)
@@ -65,7 +76,7 @@ func Test_Service_Login_User_Doesnt_exist(t *testing.T) {
 	dbMock.On("GetUserByEmail", ctx, email).Return(false, model.User{}, nil)
 
 	// ACT
-	userRes, jwtStrRes, errRes := service.Login(ctx, email, password)
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := model.User{}
@@ -94,7 +105,7 @@ func Test_Service_Login_Err_Getting_User_By_Email(t *testing.T) {
 	dbMock.On("GetUserByEmail", ctx, email).Return(false, model.User{}, getUserByEmailErr)
 
 	// ACT
-	userRes, jwtStrRes, errRes := service.Login(ctx, email, password)
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := model.User{}
@@ -120,7 +131,7 @@ func Test_Service_Login_User_Has_not_Setup_Pw(t *testing.T) {
 	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
 
 	// ACT
-	userRes, jwtStrRes, errRes := service.Login(ctx, email, password)
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := model.User{}
@@ -145,7 +156,7 @@ func Test_Service_Login_Incorrect_Pw(t *testing.T) {
 	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
 
 	// ACT
-	userRes, jwtStrRes, errRes := service.Login(ctx, email, password)
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := model.User{}
@@ -164,19 +175,20 @@ func Test_Service_Login_Success_Res(t *testing.T) {
 	service, dbMock, jwtHandlerMock, logServiceMock := setupMocksForServiceImplTest()
 
 	ctx := context.Background()
+	// the email is saved in lower case during registration, the user may type it in any case
 	email := strings.ToUpper(testutil.Fake.Internet().Email())
 	password := "Password123!"
 	hashedPasswordByte, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	hashedPassword := string(hashedPasswordByte)
-	user := testutil.GenMockUser(&model.User{Email: email, Password: &hashedPassword})
+	user := testutil.GenMockUser(&model.User{Email: strings.ToLower(email), Password: &hashedPassword})
 	jwtStr := testutil.Fake.RandomStringWithLength(100)
 
 	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
-	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
+	dbMock.On("GetUserByEmail", ctx, strings.ToLower(email)).Return(true, user, nil)
 	jwtHandlerMock.On("Generate", jwt.JwtPayload{UserId: user.Id, UserEmail: user.Email}).Return(jwtStr, nil)
 
 	// ACT
-	userRes, jwtStrRes, errRes := service.Login(ctx, email, password)
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := user
@@ -204,7 +216,7 @@ func Test_Service_Login_Err_Generating_Jwt(t *testing.T) {
 	jwtHandlerMock.On("Generate", jwt.JwtPayload{UserId: user.Id, UserEmail: user.Email}).Return("", generateErr)
 
 	// ACT
-	userRes, jwtStrRes, errRes := service.Login(ctx, email, password)
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := model.User{}
@@ -215,3 +227,138 @@ func Test_Service_Login_Err_Generating_Jwt(t *testing.T) {
 	assert.Equal(t, jwtStrRes, expectedJwtStrRes)
 	assert.Equal(t, errRes, expectedErr)
 }
+
+func Test_Service_Login_Not_Member_Of_Selected_Org(t *testing.T) {
+	// ARRANGE
+	service, dbMock, jwtHandlerMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	hashedPasswordByte, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword := string(hashedPasswordByte)
+	user := testutil.GenMockUser(&model.User{Email: email, Password: &hashedPassword})
+	orgId := testutil.Fake.UUID().V4()
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
+	dbMock.On("GetMembership", ctx, orgId, user.Id).Return(false, model.Membership{}, nil)
+
+	// ACT
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, &orgId)
+
+	// ASSERT
+	expectedErr := exception.NewUnauthorized(exception.Base{
+		Type:    errorcode.OrgNotMember,
+		Message: "user is not a member of the organization",
+	})
+
+	assert.Equal(t, model.User{}, userRes)
+	assert.Equal(t, "", jwtStrRes)
+	assert.Equal(t, expectedErr, errRes)
+	jwtHandlerMock.AssertNotCalled(t, "Generate", mock.Anything)
+}
+
+func Test_Service_Login_With_Selected_Org_Success_Res(t *testing.T) {
+	// ARRANGE
+	service, dbMock, jwtHandlerMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	hashedPasswordByte, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword := string(hashedPasswordByte)
+	user := testutil.GenMockUser(&model.User{Email: email, Password: &hashedPassword})
+	orgId := testutil.Fake.UUID().V4()
+	jwtStr := testutil.Fake.RandomStringWithLength(100)
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
+	dbMock.On("GetMembership", ctx, orgId, user.Id).Return(true, model.Membership{OrgId: orgId, UserId: user.Id, Role: model.OrgRoleMember}, nil)
+	jwtHandlerMock.On("Generate", jwt.JwtPayload{UserId: user.Id, UserEmail: user.Email, OrgId: orgId}).Return(jwtStr, nil)
+
+	// ACT
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, &orgId)
+
+	// ASSERT
+	assert.Equal(t, user, userRes)
+	assert.Equal(t, jwtStr, jwtStrRes)
+	assert.Nil(t, errRes)
+}
+
+func Test_Service_Login_Should_Record_Failed_Attempt(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, logServiceMock, securityServiceMock := setupMocksForServiceImplTestWithSecurity()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := testutil.Fake.Internet().Password()
+	user := testutil.GenMockUser(&model.User{Email: email})
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
+	securityServiceMock.On("RecordLoginAttempt", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	service.Login(ctx, email, password, nil)
+
+	// ASSERT
+	securityServiceMock.AssertCalled(t, "RecordLoginAttempt", ctx, mock.MatchedBy(func(attempt model.LoginAttempt) bool {
+		return attempt.Email == email &&
+			*attempt.UserId == user.Id &&
+			*attempt.FailureReason == model.LoginFailureInvalidPassword
+	}))
+}
+
+func Test_Service_Login_Should_Record_Successful_Attempt(t *testing.T) {
+	// ARRANGE
+	service, dbMock, jwtHandlerMock, logServiceMock, securityServiceMock := setupMocksForServiceImplTestWithSecurity()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	hashedPasswordByte, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword := string(hashedPasswordByte)
+	user := testutil.GenMockUser(&model.User{Email: email, Password: &hashedPassword})
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
+	jwtHandlerMock.On("Generate", mock.Anything).Return("jwt", nil)
+	securityServiceMock.On("RecordLoginAttempt", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	service.Login(ctx, email, password, nil)
+
+	// ASSERT
+	securityServiceMock.AssertCalled(t, "RecordLoginAttempt", ctx, model.LoginAttempt{Email: email, UserId: &user.Id})
+}
+
+func Test_Service_Login_Should_Not_Fail_If_Recording_Attempt_Fails(t *testing.T) {
+	// ARRANGE
+	service, dbMock, jwtHandlerMock, logServiceMock, securityServiceMock := setupMocksForServiceImplTestWithSecurity()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	hashedPasswordByte, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword := string(hashedPasswordByte)
+	user := testutil.GenMockUser(&model.User{Email: email, Password: &hashedPassword})
+	recordErr := fmt.Errorf("error from RecordLoginAttempt")
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	logServiceMock.On("ErrorCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetUserByEmail", ctx, email).Return(true, user, nil)
+	jwtHandlerMock.On("Generate", mock.Anything).Return("jwt", nil)
+	securityServiceMock.On("RecordLoginAttempt", ctx, mock.Anything).Return(recordErr)
+
+	// ACT
+	userRes, jwtStrRes, errRes := service.Login(ctx, email, password, nil)
+
+	// ASSERT
+	expectedLogStr := fmt.Sprintf("error recording login attempt for email '%s': %s", email, recordErr)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, user, userRes)
+	assert.Equal(t, "jwt", jwtStrRes)
+	logServiceMock.AssertCalled(t, "ErrorCtx", ctx, expectedLogStr)
+}
@@ -12,8 +12,8 @@ type ServiceMock struct {
 	mock.Mock
 }
 
-func (s *ServiceMock) Login(ctx context.Context, email string, password string) (model.User, string, error) {
-	args := s.Called(ctx, email, password)
+func (s *ServiceMock) Login(ctx context.Context, email string, password string, orgId *string) (model.User, string, error) {
+	args := s.Called(ctx, email, password, orgId)
 	return args.Get(0).(model.User), args.String(1), args.Error(2)
 }
 
@@ -0,0 +1,23 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+)
+
+// NewHandlerFunc builds the handler of a message around the transaction in which the message is recorded as
+// processed, every write of the handler must go through txDb so that it is committed along with the record
+type NewHandlerFunc func(txDb database.Db) nats.HandlerFunc
+
+type Service interface {
+	// Handler returns a handler which handles a message once per consumer even when it is delivered several times.
+	// Messages are recognized by the id of their event, or their Nats-Msg-Id, for PROCESSED_MESSAGE_TTL. When the
+	// handler fails nothing is recorded, so the message is handled again on its redelivery.
+	Handler(consumer string, newHandler NewHandlerFunc) nats.HandlerFunc
+	// DeleteExpired deletes the processed messages older than PROCESSED_MESSAGE_TTL and returns how many were deleted
+	DeleteExpired(ctx context.Context) (int, error)
+	// Run deletes the expired processed messages every cleanup interval until ctx is cancelled
+	Run(ctx context.Context)
+}
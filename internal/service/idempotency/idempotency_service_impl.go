@@ -0,0 +1,125 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+)
+
+const defaultTtl = 7 * 24 * time.Hour
+const cleanupInterval = time.Hour
+
+// errAlreadyProcessed rolls back the transaction of a message which has already been processed
+var errAlreadyProcessed = errors.New("message already processed")
+
+type ServiceImpl struct {
+	db         database.Db
+	logService logger.Service
+	ttl        time.Duration
+}
+
+func NewService(appConfig *config.AppConfig, logService logger.Service, db database.Db) (Service, error) {
+	ttl := defaultTtl
+	if appConfig.PROCESSED_MESSAGE_TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(appConfig.PROCESSED_MESSAGE_TTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("idempotency.NewService(): invalid PROCESSED_MESSAGE_TTL '%s'", appConfig.PROCESSED_MESSAGE_TTL)
+		}
+	}
+
+	return &ServiceImpl{
+		db:         db,
+		logService: logService,
+		ttl:        ttl,
+	}, nil
+}
+
+func (s *ServiceImpl) Handler(consumer string, newHandler NewHandlerFunc) nats.HandlerFunc {
+	return func(ctx context.Context, msg nats.Msg) error {
+		messageId := getMessageId(msg)
+		if messageId == "" {
+			s.logService.DebugCtx(ctx, fmt.Sprintf("message of '%s' has no id, so '%s' handles it without deduplication", msg.Subject, consumer))
+			return newHandler(s.db)(ctx, msg)
+		}
+
+		err := s.db.WithTx(ctx, func(txDb database.Db) error {
+			currentTime := timeutil.GetCurrentTime()
+			saved, err := txDb.SaveProcessedMessage(ctx, &model.ProcessedMessage{
+				Consumer:    consumer,
+				MessageId:   messageId,
+				ProcessedAt: currentTime,
+				ExpiresAt:   currentTime.Add(s.ttl),
+			})
+			if err != nil {
+				return err
+			}
+			if !saved {
+				return errAlreadyProcessed
+			}
+
+			return newHandler(txDb)(ctx, msg)
+		})
+		if errors.Is(err, errAlreadyProcessed) {
+			s.logService.DebugCtx(ctx, fmt.Sprintf("skipped message '%s' of '%s' already processed by '%s'", messageId, msg.Subject, consumer))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("idempotency.Handler(): %w", err)
+		}
+
+		return nil
+	}
+}
+
+// getMessageId returns the id of the event of the message, which stays the same when a dead letter is replayed,
+// or the Nats-Msg-Id of other messages
+func getMessageId(msg nats.Msg) string {
+	messageId := msg.Headers.Get(event.IdHeader)
+	if messageId == "" {
+		messageId = msg.Headers.Get(jetstream.MsgIDHeader)
+	}
+
+	return messageId
+}
+
+func (s *ServiceImpl) DeleteExpired(ctx context.Context) (int, error) {
+	deleted, err := s.db.DeleteExpiredProcessedMessages(ctx, timeutil.GetCurrentTime())
+	if err != nil {
+		return 0, fmt.Errorf("idempotency.DeleteExpired(): %w", err)
+	}
+
+	if deleted > 0 {
+		s.logService.Debug(fmt.Sprintf("deleted %d expired processed messages", deleted))
+	}
+
+	return deleted, nil
+}
+
+func (s *ServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := s.DeleteExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logService.Error(err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupServiceForTest creates ServiceImpl which records the processed messages in memory
+func setupServiceForTest() (*ServiceImpl, *testutil.MemDb, *logger.ServiceMock) {
+	memDb := testutil.NewMemDb()
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("Debug", mock.Anything)
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	service := &ServiceImpl{
+		db:         memDb,
+		logService: logServiceMock,
+		ttl:        defaultTtl,
+	}
+	return service, memDb, logServiceMock
+}
+
+func genMsg(eventId string) nats.Msg {
+	return nats.Msg{
+		Subject:      "EVENT.USER.NEW",
+		Data:         []byte(`{}`),
+		Headers:      natsgo.Header{event.IdHeader: []string{eventId}},
+		NumDelivered: 1,
+	}
+}
+
+// countingHandler returns a handler which counts its calls and saves the user "<consumer>-<calls>@example.com" through
+// txDb on every call
+func countingHandler(consumer string, calls *int, handleErr error) NewHandlerFunc {
+	return func(txDb database.Db) nats.HandlerFunc {
+		return func(ctx context.Context, msg nats.Msg) error {
+			*calls++
+			email := fmt.Sprintf("%s-%d@example.com", consumer, *calls)
+			err := txDb.SaveUser(ctx, &model.User{Id: email, Email: email, CreatedAt: time.Now()})
+			if err != nil {
+				return err
+			}
+			return handleErr
+		}
+	}
+}
+
+func Test_NewService(t *testing.T) {
+	// ARRANGE
+	appConfig := testutil.GetMockAppConfig(&config.AppConfig{PROCESSED_MESSAGE_TTL: "24h"})
+
+	// ACT
+	res, errRes := NewService(&appConfig, new(logger.ServiceMock), new(database.DbMock))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 24*time.Hour, res.(*ServiceImpl).ttl)
+}
+
+func Test_NewService_Invalid_Ttl(t *testing.T) {
+	for _, ttl := range []string{"a week", "-1h", "0s"} {
+		// ARRANGE
+		appConfig := config.AppConfig{PROCESSED_MESSAGE_TTL: ttl}
+
+		// ACT
+		res, errRes := NewService(&appConfig, new(logger.ServiceMock), new(database.DbMock))
+
+		// ASSERT
+		assert.Nil(t, res)
+		assert.EqualError(t, errRes, fmt.Sprintf("idempotency.NewService(): invalid PROCESSED_MESSAGE_TTL '%s'", ttl))
+	}
+}
+
+func Test_Handler_Should_Handle_Message_Once_Per_Consumer(t *testing.T) {
+	// ARRANGE
+	service, memDb, _ := setupServiceForTest()
+	ctx := context.Background()
+	msg := genMsg("event-1")
+
+	calls, otherCalls := 0, 0
+	handle := service.Handler("consumer", countingHandler("consumer", &calls, nil))
+	otherHandle := service.Handler("other_consumer", countingHandler("other_consumer", &otherCalls, nil))
+
+	// ACT
+	errRes := handle(ctx, msg)
+	errRedeliveredRes := handle(ctx, msg)
+	errOtherRes := otherHandle(ctx, msg)
+	errNextRes := handle(ctx, genMsg("event-2"))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Nil(t, errRedeliveredRes)
+	assert.Nil(t, errOtherRes)
+	assert.Nil(t, errNextRes)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, otherCalls)
+
+	saved, err := memDb.SaveProcessedMessage(ctx, &model.ProcessedMessage{Consumer: "consumer", MessageId: "event-1"})
+	assert.Nil(t, err)
+	assert.False(t, saved, "should record the message as processed")
+}
+
+func Test_Handler_Should_Rollback_When_Handler_Fails(t *testing.T) {
+	// ARRANGE
+	service, memDb, _ := setupServiceForTest()
+	ctx := context.Background()
+	msg := genMsg("event-1")
+	handleErr := errors.New("error from handler")
+
+	calls := 0
+	failingHandle := service.Handler("consumer", countingHandler("consumer", &calls, handleErr))
+	handle := service.Handler("consumer", countingHandler("consumer", &calls, nil))
+
+	// ACT
+	errRes := failingHandle(ctx, msg)
+	errRedeliveredRes := handle(ctx, msg)
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, handleErr)
+	assert.Nil(t, errRedeliveredRes)
+	assert.Equal(t, 2, calls, "should handle the redelivery of a failed message")
+
+	isTaken, err := memDb.IsUserEmailTaken(ctx, "consumer-1@example.com")
+	assert.Nil(t, err)
+	assert.False(t, isTaken, "should roll back the writes of the failed handler")
+	isTaken, err = memDb.IsUserEmailTaken(ctx, "consumer-2@example.com")
+	assert.Nil(t, err)
+	assert.True(t, isTaken)
+}
+
+func Test_Handler_Should_Fall_Back_To_Nats_Msg_Id(t *testing.T) {
+	// ARRANGE
+	service, _, _ := setupServiceForTest()
+	ctx := context.Background()
+	msg := nats.Msg{Subject: "EVENT.USER.NEW", Headers: natsgo.Header{jetstream.MsgIDHeader: []string{"msg-1"}}}
+
+	calls := 0
+	handle := service.Handler("consumer", countingHandler("consumer", &calls, nil))
+
+	// ACT
+	_ = handle(ctx, msg)
+	_ = handle(ctx, msg)
+
+	// ASSERT
+	assert.Equal(t, 1, calls)
+}
+
+func Test_Handler_Should_Handle_Messages_Without_Id_Every_Time(t *testing.T) {
+	// ARRANGE
+	service, _, logServiceMock := setupServiceForTest()
+	ctx := context.Background()
+	msg := nats.Msg{Subject: "EVENT.USER.NEW"}
+
+	calls := 0
+	handle := func(txDb database.Db) nats.HandlerFunc {
+		return func(ctx context.Context, msg nats.Msg) error {
+			calls++
+			return nil
+		}
+	}
+
+	// ACT
+	errRes := service.Handler("consumer", handle)(ctx, msg)
+	errRedeliveredRes := service.Handler("consumer", handle)(ctx, msg)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Nil(t, errRedeliveredRes)
+	assert.Equal(t, 2, calls)
+	logServiceMock.AssertCalled(t, "DebugCtx", ctx, "message of 'EVENT.USER.NEW' has no id, so 'consumer' handles it without deduplication")
+}
+
+func Test_Handler_Error_Saving_Processed_Message(t *testing.T) {
+	// ARRANGE
+	dbMock := new(database.DbMock)
+	service := &ServiceImpl{db: dbMock, logService: new(logger.ServiceMock), ttl: defaultTtl}
+	ctx := context.Background()
+	dbErr := errors.New("error from SaveProcessedMessage")
+
+	dbMock.On("SaveProcessedMessage", ctx, mock.Anything).Return(false, dbErr)
+
+	calls := 0
+	handle := service.Handler("consumer", countingHandler("consumer", &calls, nil))
+
+	// ACT
+	errRes := handle(ctx, genMsg("event-1"))
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, dbErr)
+	assert.Equal(t, 0, calls)
+	dbMock.AssertCalled(t, "SaveProcessedMessage", ctx, mock.MatchedBy(func(msg *model.ProcessedMessage) bool {
+		return msg.Consumer == "consumer" && msg.MessageId == "event-1" && msg.ExpiresAt.Sub(msg.ProcessedAt) == defaultTtl
+	}))
+}
+
+func Test_DeleteExpired_Should_Keep_Messages_Within_Ttl(t *testing.T) {
+	// ARRANGE
+	service, memDb, _ := setupServiceForTest()
+	ctx := context.Background()
+	now := time.Now()
+	_, _ = memDb.SaveProcessedMessage(ctx, &model.ProcessedMessage{Consumer: "consumer", MessageId: "expired", ProcessedAt: now.Add(-2 * defaultTtl), ExpiresAt: now.Add(-defaultTtl)})
+	_, _ = memDb.SaveProcessedMessage(ctx, &model.ProcessedMessage{Consumer: "consumer", MessageId: "recent", ProcessedAt: now, ExpiresAt: now.Add(defaultTtl)})
+
+	// ACT
+	deletedRes, errRes := service.DeleteExpired(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 1, deletedRes)
+
+	saved, _ := memDb.SaveProcessedMessage(ctx, &model.ProcessedMessage{Consumer: "consumer", MessageId: "expired"})
+	assert.True(t, saved)
+	saved, _ = memDb.SaveProcessedMessage(ctx, &model.ProcessedMessage{Consumer: "consumer", MessageId: "recent"})
+	assert.False(t, saved)
+}
+
+func Test_DeleteExpired_Error(t *testing.T) {
+	// ARRANGE
+	dbMock := new(database.DbMock)
+	service := &ServiceImpl{db: dbMock, logService: new(logger.ServiceMock), ttl: defaultTtl}
+	ctx := context.Background()
+	dbErr := errors.New("error from DeleteExpiredProcessedMessages")
+
+	dbMock.On("DeleteExpiredProcessedMessages", ctx, mock.Anything).Return(0, dbErr)
+
+	// ACT
+	deletedRes, errRes := service.DeleteExpired(ctx)
+
+	// ASSERT
+	assert.Equal(t, 0, deletedRes)
+	assert.ErrorIs(t, errRes, dbErr)
+}
@@ -0,0 +1,26 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/mock"
+)
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) Handler(consumer string, newHandler NewHandlerFunc) nats.HandlerFunc {
+	args := s.Called(consumer, newHandler)
+	return args.Get(0).(nats.HandlerFunc)
+}
+
+func (s *ServiceMock) DeleteExpired(ctx context.Context) (int, error) {
+	args := s.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (s *ServiceMock) Run(ctx context.Context) {
+	s.Called(ctx)
+}
@@ -0,0 +1,11 @@
+package invite
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+)
+
+type Facade interface {
+	CreateInvite(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error)
+}
@@ -0,0 +1,61 @@
+package invite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pjmessi/golang-practice/internal/dto"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/structutil"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+)
+
+type FacadeImpl struct {
+	inviteService     Service
+	validationHandler validation.Handler
+	logService        logger.Service
+}
+
+func NewFacade(logService logger.Service, inviteService Service, validationHandler validation.Handler) Facade {
+	return &FacadeImpl{
+		inviteService:     inviteService,
+		validationHandler: validationHandler,
+		logService:        logService,
+	}
+}
+
+func (f *FacadeImpl) CreateInvite(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	var req model.CreateInviteApiReq
+
+	err := structutil.ConvertFromBytes(reqBytes, &req)
+	if err != nil {
+		return nil, exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+	}
+
+	err = f.validationHandler.ValidateStruct(req)
+	if err != nil {
+		var valErr validation.ValidationError
+		if errors.As(err, &valErr) {
+			return nil, exception.NewInvalidReqFromBase(exception.Base{
+				Details: &valErr.Details,
+			})
+		} else {
+			return nil, err
+		}
+	}
+
+	invite, err := f.inviteService.CreateInvite(ctx, jwtPayload.UserId, req.Email, req.MaxUses, req.ExpiresIn)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.CreateInviteApiRes{
+		Invite: dto.InviteToInviteRes(&invite),
+	}
+
+	return structutil.ConvertToBytes(res)
+}
@@ -0,0 +1,149 @@
+package invite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupMocksForFacadeImplTest creates FacadeImpl with mocked dependencies
+func setupMocksForFacadeImplTest() (*FacadeImpl, *ServiceMock, *validation.HandlerMock) {
+	inviteServiceMock := new(ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+	facade := &FacadeImpl{
+		inviteService:     inviteServiceMock,
+		validationHandler: validationHandlerMock,
+		logService:        new(logger.ServiceMock),
+	}
+	return facade, inviteServiceMock, validationHandlerMock
+}
+
+func Test_NewFacade(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	serviceMock := new(ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+
+	// ACT
+	res := NewFacade(logServiceMock, serviceMock, validationHandlerMock)
+
+	// ASSERT
+	resFacadeImpl := res.(*FacadeImpl)
+
+	assert.IsType(t, &FacadeImpl{}, res)
+	assert.Equal(t, res, resFacadeImpl)
+}
+
+func Test_Facade_CreateInvite_Invalid_Struct_In_Req_Bytes(t *testing.T) {
+	// ARRANGE
+	facade, _, _ := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	var reqBytes []byte
+
+	// ACT
+	bytesRes, errRes := facade.CreateInvite(ctx, reqBytes, jwt.JwtPayload{})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_CreateInvite_Invalid_Struct_Data_In_Req_Bytes(t *testing.T) {
+	// ARRANGE
+	facade, _, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	email := "invalid_format"
+	req := model.CreateInviteApiReq{Email: &email}
+	reqBytes, _ := json.Marshal(req)
+	validationErrDetails := map[string]string{"Email": "validation failed for tag: 'email'"}
+
+	validationHandlerMock.On("ValidateStruct", req).Return(validation.ValidationError{Details: validationErrDetails})
+
+	// ACT
+	bytesRes, errRes := facade.CreateInvite(ctx, reqBytes, jwt.JwtPayload{})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Details: &validationErrDetails})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_CreateInvite_Err_Creating_Invite(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()}
+	req := model.CreateInviteApiReq{MaxUses: 2}
+	reqBytes, _ := json.Marshal(req)
+	createErr := fmt.Errorf("error from CreateInvite")
+
+	validationHandlerMock.On("ValidateStruct", req).Return(nil)
+	serviceMock.On("CreateInvite", ctx, jwtPayload.UserId, req.Email, req.MaxUses, req.ExpiresIn).Return(model.Invite{}, createErr)
+
+	// ACT
+	bytesRes, errRes := facade.CreateInvite(ctx, reqBytes, jwtPayload)
+
+	// ASSERT
+	assert.Equal(t, createErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_CreateInvite_Success_Res(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()}
+	email := testutil.Fake.Internet().Email()
+	req := model.CreateInviteApiReq{Email: &email, MaxUses: 1, ExpiresIn: "1d"}
+	reqBytes, _ := json.Marshal(req)
+	expiresAt := time.Now().Add(24 * time.Hour)
+	invite := model.Invite{
+		Id:        testutil.Fake.UUID().V4(),
+		Code:      "code",
+		Email:     &email,
+		CreatedBy: jwtPayload.UserId,
+		MaxUses:   1,
+		ExpiresAt: &expiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	validationHandlerMock.On("ValidateStruct", req).Return(nil)
+	serviceMock.On("CreateInvite", ctx, jwtPayload.UserId, req.Email, req.MaxUses, req.ExpiresIn).Return(invite, nil)
+
+	// ACT
+	bytesRes, errRes := facade.CreateInvite(ctx, reqBytes, jwtPayload)
+
+	// ASSERT
+	expiresAtStr := expiresAt.Format(time.RFC3339)
+	expectedRes := model.CreateInviteApiRes{Invite: model.InviteRes{
+		Id:        invite.Id,
+		Code:      invite.Code,
+		Email:     &email,
+		MaxUses:   1,
+		UsedCount: 0,
+		ExpiresAt: &expiresAtStr,
+		CreatedAt: invite.CreatedAt.Format(time.RFC3339),
+	}}
+	expectedResBytes, _ := json.Marshal(expectedRes)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, expectedResBytes, bytesRes)
+}
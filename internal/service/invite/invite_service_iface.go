@@ -0,0 +1,18 @@
+package invite
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+)
+
+type Service interface {
+	CreateInvite(ctx context.Context, createdBy string, email *string, maxUses int, expiresIn string) (model.Invite, error)
+	// CreateOrgInvite creates a single use invite bound to the email which grants the role in the organization once redeemed
+	CreateOrgInvite(ctx context.Context, createdBy string, orgId string, role string, email string, expiresIn string) (model.Invite, error)
+	RedeemInvite(ctx context.Context, code string, email string) (model.Invite, error)
+	// WithDb returns a copy of the service which uses the given db, it lets callers include invite changes in their
+	// own transaction
+	WithDb(db database.Db) Service
+}
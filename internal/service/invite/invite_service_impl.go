@@ -0,0 +1,180 @@
+package invite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/randutil"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+)
+
+const defaultMaxUses = 1
+const inviteCodeByteLen = 16
+
+type ServiceImpl struct {
+	db         database.Db
+	logService logger.Service
+}
+
+func NewService(logService logger.Service, db database.Db) Service {
+	return &ServiceImpl{
+		db:         db,
+		logService: logService,
+	}
+}
+
+func (s *ServiceImpl) WithDb(db database.Db) Service {
+	return &ServiceImpl{
+		db:         db,
+		logService: s.logService,
+	}
+}
+
+func (s *ServiceImpl) CreateInvite(ctx context.Context, createdBy string, email *string, maxUses int, expiresIn string) (model.Invite, error) {
+	invite, err := s.createInvite(createdBy, email, maxUses, expiresIn)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	err = s.db.SaveInvite(ctx, &invite)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("invite '%s' created by user '%s'", invite.Id, createdBy))
+
+	return invite, nil
+}
+
+func (s *ServiceImpl) CreateOrgInvite(ctx context.Context, createdBy string, orgId string, role string, email string, expiresIn string) (model.Invite, error) {
+	invite, err := s.createInvite(createdBy, &email, 1, expiresIn)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	invite.OrgId = &orgId
+	invite.Role = &role
+
+	err = s.db.SaveInvite(ctx, &invite)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("invite '%s' for organization '%s' created by user '%s'", invite.Id, orgId, createdBy))
+
+	return invite, nil
+}
+
+func (s *ServiceImpl) createInvite(createdBy string, email *string, maxUses int, expiresIn string) (model.Invite, error) {
+	id, err := uuidutil.GenUuidV4()
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	code, err := randutil.GenSecureToken(inviteCodeByteLen)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	if maxUses <= 0 {
+		maxUses = defaultMaxUses
+	}
+
+	if email != nil {
+		lowercaseEmail := strings.ToLower(*email)
+		email = &lowercaseEmail
+	}
+
+	currentTime := timeutil.GetCurrentTime()
+
+	var expiresAt *time.Time
+	if expiresIn != "" {
+		expiresInSec, err := timeutil.ConvertDurationStrToSec(expiresIn)
+		if err != nil || expiresInSec <= 0 {
+			return model.Invite{}, exception.NewInvalidReqFromBase(exception.Base{
+				Details: &map[string]string{"expiresIn": "invalid duration"},
+			})
+		}
+		expiresAtTime := currentTime.Add(time.Duration(expiresInSec) * time.Second)
+		expiresAt = &expiresAtTime
+	}
+
+	return model.Invite{
+		Id:        id,
+		Code:      code,
+		Email:     email,
+		CreatedBy: createdBy,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		CreatedAt: currentTime,
+	}, nil
+}
+
+func (s *ServiceImpl) RedeemInvite(ctx context.Context, code string, email string) (model.Invite, error) {
+	exists, invite, err := s.db.GetInviteByCode(ctx, code)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	if !exists {
+		s.logService.DebugCtx(ctx, fmt.Sprintf("invite with the code '%s' does not exist", code))
+		return model.Invite{}, exception.NewFailedPreconditionFromBase(exception.Base{
+			Type:    errorcode.InviteNotFound,
+			Message: "invite not found",
+		})
+	}
+
+	if invite.Email != nil && !strings.EqualFold(*invite.Email, email) {
+		s.logService.DebugCtx(ctx, fmt.Sprintf("invite '%s' is not meant for the email '%s'", invite.Id, email))
+		return model.Invite{}, exception.NewFailedPreconditionFromBase(exception.Base{
+			Type:    errorcode.InviteEmailMismatch,
+			Message: "invite was issued for a different email",
+		})
+	}
+
+	currentTime := timeutil.GetCurrentTime()
+
+	if err := s.ensureNotExpired(ctx, invite, currentTime); err != nil {
+		return model.Invite{}, err
+	}
+
+	// the usage count is re-checked by the database while incrementing, so concurrent registrations cannot go past
+	// max uses even when they all passed the checks above
+	incremented, err := s.db.IncrementInviteUsage(ctx, invite.Id, currentTime)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	if !incremented {
+		s.logService.DebugCtx(ctx, fmt.Sprintf("invite '%s' has no uses left", invite.Id))
+		return model.Invite{}, exception.NewFailedPreconditionFromBase(exception.Base{
+			Type:    errorcode.InviteExhausted,
+			Message: "invite has already been used",
+		})
+	}
+
+	invite.UsedCount++
+
+	return invite, nil
+}
+
+func (s *ServiceImpl) ensureNotExpired(ctx context.Context, invite model.Invite, currentTime time.Time) error {
+	if invite.ExpiresAt == nil || invite.ExpiresAt.After(currentTime) {
+		return nil
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("invite '%s' expired at '%s'", invite.Id, invite.ExpiresAt.Format(time.RFC3339)))
+
+	return exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteExpired,
+		Message: "invite has expired",
+	})
+}
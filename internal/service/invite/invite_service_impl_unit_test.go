@@ -0,0 +1,277 @@
+package invite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupMocksForServiceImplTest creates ServiceImpl with mocked dependencies
+func setupMocksForServiceImplTest() (*ServiceImpl, *database.DbMock, *logger.ServiceMock) {
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	service := &ServiceImpl{
+		db:         dbMock,
+		logService: logServiceMock,
+	}
+	return service, dbMock, logServiceMock
+}
+
+func Test_NewService(t *testing.T) {
+	// ARRANGE
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+
+	// ACT
+	res := NewService(logServiceMock, dbMock)
+
+	// ASSERT
+	resServiceImpl := res.(*ServiceImpl)
+
+	assert.IsType(t, &ServiceImpl{}, res)
+	assert.Equal(t, res, resServiceImpl)
+}
+
+func Test_WithDb_Should_Return_Copy_Using_Given_Db(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+	txDbMock := new(database.DbMock)
+
+	// ACT
+	res := service.WithDb(txDbMock)
+
+	// ASSERT
+	resServiceImpl := res.(*ServiceImpl)
+
+	assert.Same(t, txDbMock, resServiceImpl.db)
+	assert.Same(t, logServiceMock, resServiceImpl.logService)
+	assert.Same(t, dbMock, service.db, "should not modify the original service")
+}
+
+func Test_CreateInvite_Should_Save_Invite_In_Db(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	createdBy := testutil.Fake.UUID().V4()
+	email := "John.Doe@Example.com"
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("SaveInvite", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	inviteRes, errRes := service.CreateInvite(ctx, createdBy, &email, 3, "2d")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, createdBy, inviteRes.CreatedBy)
+	assert.Equal(t, "john.doe@example.com", *inviteRes.Email)
+	assert.Equal(t, 3, inviteRes.MaxUses)
+	assert.Equal(t, 0, inviteRes.UsedCount)
+	assert.Len(t, inviteRes.Code, inviteCodeByteLen*2)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), *inviteRes.ExpiresAt, time.Second)
+	dbMock.AssertCalled(t, "SaveInvite", ctx, &inviteRes)
+}
+
+func Test_CreateInvite_Defaults_To_Single_Use_Without_Expiry(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("SaveInvite", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	inviteRes, errRes := service.CreateInvite(ctx, testutil.Fake.UUID().V4(), nil, 0, "")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, defaultMaxUses, inviteRes.MaxUses)
+	assert.Nil(t, inviteRes.ExpiresAt)
+	assert.Nil(t, inviteRes.Email)
+}
+
+func Test_CreateInvite_Invalid_Expiry(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+
+	// ACT
+	inviteRes, errRes := service.CreateInvite(ctx, testutil.Fake.UUID().V4(), nil, 1, "2w")
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{
+		Details: &map[string]string{"expiresIn": "invalid duration"},
+	})
+
+	assert.Equal(t, model.Invite{}, inviteRes)
+	assert.Equal(t, expectedErr, errRes)
+	dbMock.AssertNotCalled(t, "SaveInvite", mock.Anything, mock.Anything)
+}
+
+func Test_CreateInvite_Error_Saving_Invite(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	saveErr := fmt.Errorf("error from SaveInvite")
+
+	dbMock.On("SaveInvite", ctx, mock.Anything).Return(saveErr)
+
+	// ACT
+	inviteRes, errRes := service.CreateInvite(ctx, testutil.Fake.UUID().V4(), nil, 1, "")
+
+	// ASSERT
+	assert.Equal(t, model.Invite{}, inviteRes)
+	assert.Equal(t, saveErr, errRes)
+}
+
+func Test_CreateOrgInvite_Should_Save_Org_Invite_In_Db(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	createdBy := testutil.Fake.UUID().V4()
+	orgId := testutil.Fake.UUID().V4()
+	email := testutil.Fake.Internet().Email()
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("SaveInvite", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	inviteRes, errRes := service.CreateOrgInvite(ctx, createdBy, orgId, model.OrgRoleAdmin, email, "")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, orgId, *inviteRes.OrgId)
+	assert.Equal(t, model.OrgRoleAdmin, *inviteRes.Role)
+	assert.Equal(t, email, *inviteRes.Email)
+	assert.Equal(t, 1, inviteRes.MaxUses)
+	dbMock.AssertCalled(t, "SaveInvite", ctx, &inviteRes)
+}
+
+func Test_RedeemInvite_Invite_Not_Found(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	code := "unknown-code"
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetInviteByCode", ctx, code).Return(false, model.Invite{}, nil)
+
+	// ACT
+	inviteRes, errRes := service.RedeemInvite(ctx, code, testutil.Fake.Internet().Email())
+
+	// ASSERT
+	expectedErr := exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteNotFound,
+		Message: "invite not found",
+	})
+
+	assert.Equal(t, model.Invite{}, inviteRes)
+	assert.Equal(t, expectedErr, errRes)
+}
+
+func Test_RedeemInvite_Email_Mismatch(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	invitedEmail := "invited@example.com"
+	invite := model.Invite{Id: testutil.Fake.UUID().V4(), Code: "code", Email: &invitedEmail, MaxUses: 1}
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetInviteByCode", ctx, invite.Code).Return(true, invite, nil)
+
+	// ACT
+	_, errRes := service.RedeemInvite(ctx, invite.Code, "someone.else@example.com")
+
+	// ASSERT
+	expectedErr := exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteEmailMismatch,
+		Message: "invite was issued for a different email",
+	})
+
+	assert.Equal(t, expectedErr, errRes)
+	dbMock.AssertNotCalled(t, "IncrementInviteUsage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RedeemInvite_Expired(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(-time.Minute)
+	invite := model.Invite{Id: testutil.Fake.UUID().V4(), Code: "code", MaxUses: 1, ExpiresAt: &expiresAt}
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetInviteByCode", ctx, invite.Code).Return(true, invite, nil)
+
+	// ACT
+	_, errRes := service.RedeemInvite(ctx, invite.Code, testutil.Fake.Internet().Email())
+
+	// ASSERT
+	expectedErr := exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteExpired,
+		Message: "invite has expired",
+	})
+
+	assert.Equal(t, expectedErr, errRes)
+	dbMock.AssertNotCalled(t, "IncrementInviteUsage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RedeemInvite_Exhausted(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	invite := model.Invite{Id: testutil.Fake.UUID().V4(), Code: "code", MaxUses: 1, UsedCount: 1}
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetInviteByCode", ctx, invite.Code).Return(true, invite, nil)
+	dbMock.On("IncrementInviteUsage", ctx, invite.Id, mock.Anything).Return(false, nil)
+
+	// ACT
+	_, errRes := service.RedeemInvite(ctx, invite.Code, testutil.Fake.Internet().Email())
+
+	// ASSERT
+	expectedErr := exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteExhausted,
+		Message: "invite has already been used",
+	})
+
+	assert.Equal(t, expectedErr, errRes)
+}
+
+func Test_RedeemInvite_Success_Res(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	invitedEmail := "invited@example.com"
+	invite := model.Invite{Id: testutil.Fake.UUID().V4(), Code: "code", Email: &invitedEmail, MaxUses: 2, UsedCount: 1}
+
+	dbMock.On("GetInviteByCode", ctx, invite.Code).Return(true, invite, nil)
+	dbMock.On("IncrementInviteUsage", ctx, invite.Id, mock.Anything).Return(true, nil)
+
+	// ACT
+	inviteRes, errRes := service.RedeemInvite(ctx, invite.Code, "Invited@Example.com")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, invite.Id, inviteRes.Id)
+	assert.Equal(t, 2, inviteRes.UsedCount)
+}
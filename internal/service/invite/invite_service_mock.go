@@ -0,0 +1,33 @@
+package invite
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/stretchr/testify/mock"
+)
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) CreateInvite(ctx context.Context, createdBy string, email *string, maxUses int, expiresIn string) (model.Invite, error) {
+	args := s.Called(ctx, createdBy, email, maxUses, expiresIn)
+	return args.Get(0).(model.Invite), args.Error(1)
+}
+
+func (s *ServiceMock) CreateOrgInvite(ctx context.Context, createdBy string, orgId string, role string, email string, expiresIn string) (model.Invite, error) {
+	args := s.Called(ctx, createdBy, orgId, role, email, expiresIn)
+	return args.Get(0).(model.Invite), args.Error(1)
+}
+
+func (s *ServiceMock) RedeemInvite(ctx context.Context, code string, email string) (model.Invite, error) {
+	args := s.Called(ctx, code, email)
+	return args.Get(0).(model.Invite), args.Error(1)
+}
+
+// WithDb returns the mock itself so that expectations are shared with the transactional copy
+func (s *ServiceMock) WithDb(db database.Db) Service {
+	return s
+}
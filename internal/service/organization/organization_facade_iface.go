@@ -0,0 +1,21 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+)
+
+type Facade interface {
+	CreateOrganization(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error)
+	ListOrganizations(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error)
+	AcceptInvite(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error)
+
+	// VerifyMembership returns the membership of the user in the organization selected in the jwt
+	VerifyMembership(ctx context.Context, jwtPayload jwt.JwtPayload) (model.Membership, error)
+
+	// organization scoped APIs, these operate on the organization of the membership
+	ListMembers(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+	InviteMember(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+}
@@ -0,0 +1,144 @@
+package organization
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pjmessi/golang-practice/internal/dto"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/structutil"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+)
+
+type FacadeImpl struct {
+	orgService        Service
+	validationHandler validation.Handler
+	logService        logger.Service
+}
+
+func NewFacade(logService logger.Service, orgService Service, validationHandler validation.Handler) Facade {
+	return &FacadeImpl{
+		orgService:        orgService,
+		validationHandler: validationHandler,
+		logService:        logService,
+	}
+}
+
+func (f *FacadeImpl) CreateOrganization(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	var req model.CreateOrgApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	org, err := f.orgService.CreateOrganization(ctx, jwtPayload.UserId, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.CreateOrgApiRes{
+		Organization: dto.OrgToOrgRes(&org, model.OrgRoleOwner),
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) ListOrganizations(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	orgs, err := f.orgService.ListUserOrganizations(ctx, jwtPayload.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.ListOrgsApiRes{Organizations: []model.OrganizationRes{}}
+	for _, org := range orgs {
+		res.Organizations = append(res.Organizations, dto.OrgToOrgRes(&org.Organization, org.Role))
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) AcceptInvite(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	var req model.AcceptOrgInviteApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	org, err := f.orgService.AcceptInvite(ctx, jwtPayload.UserId, jwtPayload.UserEmail, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.AcceptOrgInviteApiRes{
+		Organization: dto.OrgToOrgRes(&org.Organization, org.Role),
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) VerifyMembership(ctx context.Context, jwtPayload jwt.JwtPayload) (model.Membership, error) {
+	if jwtPayload.OrgId == "" {
+		f.logService.DebugCtx(ctx, "organization not selected in the jwt")
+		return model.Membership{}, exception.NewFailedPreconditionFromBase(exception.Base{
+			Type:    errorcode.OrgNotSelected,
+			Message: "no organization selected, login with an organization first",
+		})
+	}
+
+	return f.orgService.GetMembership(ctx, jwtPayload.OrgId, jwtPayload.UserId)
+}
+
+func (f *FacadeImpl) ListMembers(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	members, err := f.orgService.ListMembers(ctx, membership.OrgId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.ListOrgMembersApiRes{Members: []model.OrgMemberRes{}}
+	for _, member := range members {
+		res.Members = append(res.Members, dto.OrgMemberToOrgMemberRes(&member))
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) InviteMember(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	var req model.InviteOrgMemberApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	invite, err := f.orgService.InviteMember(ctx, membership, req.Email, req.Role, req.ExpiresIn)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.InviteOrgMemberApiRes{
+		Invite: dto.InviteToInviteRes(&invite),
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) parseReq(reqBytes []byte, req interface{}) error {
+	err := structutil.ConvertFromBytes(reqBytes, req)
+	if err != nil {
+		return exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+	}
+
+	err = f.validationHandler.ValidateStruct(req)
+	if err != nil {
+		var valErr validation.ValidationError
+		if errors.As(err, &valErr) {
+			return exception.NewInvalidReqFromBase(exception.Base{
+				Details: &valErr.Details,
+			})
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
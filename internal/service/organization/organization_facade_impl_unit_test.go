@@ -0,0 +1,242 @@
+package organization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupMocksForFacadeImplTest creates FacadeImpl with mocked dependencies
+func setupMocksForFacadeImplTest() (*FacadeImpl, *ServiceMock, *logger.ServiceMock, *validation.HandlerMock) {
+	orgServiceMock := new(ServiceMock)
+	logServiceMock := new(logger.ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+	facade := &FacadeImpl{
+		orgService:        orgServiceMock,
+		validationHandler: validationHandlerMock,
+		logService:        logServiceMock,
+	}
+	return facade, orgServiceMock, logServiceMock, validationHandlerMock
+}
+
+func Test_NewFacade(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	serviceMock := new(ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+
+	// ACT
+	res := NewFacade(logServiceMock, serviceMock, validationHandlerMock)
+
+	// ASSERT
+	resFacadeImpl := res.(*FacadeImpl)
+
+	assert.IsType(t, &FacadeImpl{}, res)
+	assert.Equal(t, res, resFacadeImpl)
+}
+
+func Test_Facade_CreateOrganization_Invalid_Struct_In_Req_Bytes(t *testing.T) {
+	// ARRANGE
+	facade, _, _, _ := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	var reqBytes []byte
+
+	// ACT
+	bytesRes, errRes := facade.CreateOrganization(ctx, reqBytes, jwt.JwtPayload{})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_CreateOrganization_Success_Res(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()}
+	req := model.CreateOrgApiReq{Name: "org"}
+	reqBytes, _ := json.Marshal(req)
+	org := model.Organization{Id: testutil.Fake.UUID().V4(), Name: req.Name, CreatedBy: jwtPayload.UserId, CreatedAt: time.Now()}
+
+	validationHandlerMock.On("ValidateStruct", &req).Return(nil)
+	serviceMock.On("CreateOrganization", ctx, jwtPayload.UserId, req.Name).Return(org, nil)
+
+	// ACT
+	bytesRes, errRes := facade.CreateOrganization(ctx, reqBytes, jwtPayload)
+
+	// ASSERT
+	expectedRes := model.CreateOrgApiRes{Organization: model.OrganizationRes{
+		Id:        org.Id,
+		Name:      org.Name,
+		Role:      model.OrgRoleOwner,
+		CreatedAt: org.CreatedAt.Format(time.RFC3339),
+	}}
+	expectedResBytes, _ := json.Marshal(expectedRes)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, expectedResBytes, bytesRes)
+}
+
+func Test_Facade_ListOrganizations_Success_Res(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _, _ := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()}
+	org := model.UserOrganization{
+		Organization: model.Organization{Id: testutil.Fake.UUID().V4(), Name: "org", CreatedAt: time.Now()},
+		Role:         model.OrgRoleMember,
+	}
+
+	serviceMock.On("ListUserOrganizations", ctx, jwtPayload.UserId).Return([]model.UserOrganization{org}, nil)
+
+	// ACT
+	bytesRes, errRes := facade.ListOrganizations(ctx, nil, jwtPayload)
+
+	// ASSERT
+	expectedRes := model.ListOrgsApiRes{Organizations: []model.OrganizationRes{{
+		Id:        org.Id,
+		Name:      org.Name,
+		Role:      org.Role,
+		CreatedAt: org.CreatedAt.Format(time.RFC3339),
+	}}}
+	expectedResBytes, _ := json.Marshal(expectedRes)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, expectedResBytes, bytesRes)
+}
+
+func Test_Facade_VerifyMembership_Org_Not_Selected(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, logServiceMock, _ := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+
+	// ACT
+	membershipRes, errRes := facade.VerifyMembership(ctx, jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()})
+
+	// ASSERT
+	expectedErr := exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.OrgNotSelected,
+		Message: "no organization selected, login with an organization first",
+	})
+
+	assert.Equal(t, model.Membership{}, membershipRes)
+	assert.Equal(t, expectedErr, errRes)
+	serviceMock.AssertNotCalled(t, "GetMembership", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_Facade_VerifyMembership_Success_Res(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _, _ := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4(), OrgId: testutil.Fake.UUID().V4()}
+	membership := model.Membership{OrgId: jwtPayload.OrgId, UserId: jwtPayload.UserId, Role: model.OrgRoleAdmin}
+
+	serviceMock.On("GetMembership", ctx, jwtPayload.OrgId, jwtPayload.UserId).Return(membership, nil)
+
+	// ACT
+	membershipRes, errRes := facade.VerifyMembership(ctx, jwtPayload)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, membership, membershipRes)
+}
+
+func Test_Facade_ListMembers_Should_Use_Membership_Org(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _, _ := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	membership := model.Membership{OrgId: testutil.Fake.UUID().V4(), UserId: testutil.Fake.UUID().V4(), Role: model.OrgRoleMember}
+	member := model.OrgMember{UserId: membership.UserId, Email: testutil.Fake.Internet().Email(), Role: membership.Role, JoinedAt: time.Now()}
+
+	serviceMock.On("ListMembers", ctx, membership.OrgId).Return([]model.OrgMember{member}, nil)
+
+	// ACT
+	bytesRes, errRes := facade.ListMembers(ctx, nil, jwt.JwtPayload{}, membership)
+
+	// ASSERT
+	expectedRes := model.ListOrgMembersApiRes{Members: []model.OrgMemberRes{{
+		UserId:   member.UserId,
+		Email:    member.Email,
+		Role:     member.Role,
+		JoinedAt: member.JoinedAt.Format(time.RFC3339),
+	}}}
+	expectedResBytes, _ := json.Marshal(expectedRes)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, expectedResBytes, bytesRes)
+}
+
+func Test_Facade_InviteMember_Err_Inviting_Member(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	membership := model.Membership{OrgId: testutil.Fake.UUID().V4(), UserId: testutil.Fake.UUID().V4(), Role: model.OrgRoleMember}
+	req := model.InviteOrgMemberApiReq{Email: testutil.Fake.Internet().Email(), Role: model.OrgRoleMember}
+	reqBytes, _ := json.Marshal(req)
+	inviteErr := fmt.Errorf("error from InviteMember")
+
+	validationHandlerMock.On("ValidateStruct", &req).Return(nil)
+	serviceMock.On("InviteMember", ctx, membership, req.Email, req.Role, req.ExpiresIn).Return(model.Invite{}, inviteErr)
+
+	// ACT
+	bytesRes, errRes := facade.InviteMember(ctx, reqBytes, jwt.JwtPayload{}, membership)
+
+	// ASSERT
+	assert.Equal(t, inviteErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_AcceptInvite_Success_Res(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4(), UserEmail: testutil.Fake.Internet().Email()}
+	req := model.AcceptOrgInviteApiReq{Code: "code"}
+	reqBytes, _ := json.Marshal(req)
+	org := model.UserOrganization{
+		Organization: model.Organization{Id: testutil.Fake.UUID().V4(), Name: "org", CreatedAt: time.Now()},
+		Role:         model.OrgRoleMember,
+	}
+
+	validationHandlerMock.On("ValidateStruct", &req).Return(nil)
+	serviceMock.On("AcceptInvite", ctx, jwtPayload.UserId, jwtPayload.UserEmail, req.Code).Return(org, nil)
+
+	// ACT
+	bytesRes, errRes := facade.AcceptInvite(ctx, reqBytes, jwtPayload)
+
+	// ASSERT
+	expectedRes := model.AcceptOrgInviteApiRes{Organization: model.OrganizationRes{
+		Id:        org.Id,
+		Name:      org.Name,
+		Role:      org.Role,
+		CreatedAt: org.CreatedAt.Format(time.RFC3339),
+	}}
+	expectedResBytes, _ := json.Marshal(expectedRes)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, expectedResBytes, bytesRes)
+}
@@ -0,0 +1,43 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/stretchr/testify/mock"
+)
+
+type FacadeMock struct {
+	mock.Mock
+}
+
+func (f *FacadeMock) CreateOrganization(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) ListOrganizations(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) AcceptInvite(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) VerifyMembership(ctx context.Context, jwtPayload jwt.JwtPayload) (model.Membership, error) {
+	args := f.Called(ctx, jwtPayload)
+	return args.Get(0).(model.Membership), args.Error(1)
+}
+
+func (f *FacadeMock) ListMembers(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) InviteMember(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
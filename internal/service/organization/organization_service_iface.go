@@ -0,0 +1,16 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+)
+
+type Service interface {
+	CreateOrganization(ctx context.Context, userId string, name string) (model.Organization, error)
+	ListUserOrganizations(ctx context.Context, userId string) ([]model.UserOrganization, error)
+	GetMembership(ctx context.Context, orgId string, userId string) (model.Membership, error)
+	ListMembers(ctx context.Context, orgId string) ([]model.OrgMember, error)
+	InviteMember(ctx context.Context, inviter model.Membership, email string, role string, expiresIn string) (model.Invite, error)
+	AcceptInvite(ctx context.Context, userId string, email string, code string) (model.UserOrganization, error)
+}
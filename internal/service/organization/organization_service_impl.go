@@ -0,0 +1,176 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+)
+
+type ServiceImpl struct {
+	db            database.Db
+	logService    logger.Service
+	inviteService invite.Service
+}
+
+func NewService(logService logger.Service, db database.Db, inviteService invite.Service) Service {
+	return &ServiceImpl{
+		db:            db,
+		logService:    logService,
+		inviteService: inviteService,
+	}
+}
+
+func (s *ServiceImpl) CreateOrganization(ctx context.Context, userId string, name string) (model.Organization, error) {
+	orgId, err := uuidutil.GenUuidV4()
+	if err != nil {
+		return model.Organization{}, err
+	}
+
+	currentTime := timeutil.GetCurrentTime()
+	org := model.Organization{
+		Id:        orgId,
+		Name:      name,
+		CreatedBy: userId,
+		CreatedAt: currentTime,
+	}
+
+	err = s.db.WithTx(ctx, func(txDb database.Db) error {
+		err := txDb.SaveOrganization(ctx, &org)
+		if err != nil {
+			return err
+		}
+
+		// the creator of the organization becomes its first owner
+		return txDb.SaveMembership(ctx, &model.Membership{
+			OrgId:     org.Id,
+			UserId:    userId,
+			Role:      model.OrgRoleOwner,
+			CreatedAt: currentTime,
+		})
+	})
+	if err != nil {
+		return model.Organization{}, err
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("organization '%s' created by user '%s'", org.Id, userId))
+
+	return org, nil
+}
+
+func (s *ServiceImpl) ListUserOrganizations(ctx context.Context, userId string) ([]model.UserOrganization, error) {
+	return s.db.GetUserOrganizations(ctx, userId)
+}
+
+func (s *ServiceImpl) GetMembership(ctx context.Context, orgId string, userId string) (model.Membership, error) {
+	isMember, membership, err := s.db.GetMembership(ctx, orgId, userId)
+	if err != nil {
+		return model.Membership{}, err
+	}
+
+	if !isMember {
+		s.logService.DebugCtx(ctx, fmt.Sprintf("user '%s' is not a member of organization '%s'", userId, orgId))
+		return model.Membership{}, exception.NewUnauthorized(exception.Base{
+			Type:    errorcode.OrgNotMember,
+			Message: "user is not a member of the organization",
+		})
+	}
+
+	return membership, nil
+}
+
+func (s *ServiceImpl) ListMembers(ctx context.Context, orgId string) ([]model.OrgMember, error) {
+	return s.db.GetOrgMembers(ctx, orgId)
+}
+
+func (s *ServiceImpl) InviteMember(ctx context.Context, inviter model.Membership, email string, role string, expiresIn string) (model.Invite, error) {
+	if err := s.ensureCanGrantRole(ctx, inviter, role); err != nil {
+		return model.Invite{}, err
+	}
+
+	return s.inviteService.CreateOrgInvite(ctx, inviter.UserId, inviter.OrgId, role, email, expiresIn)
+}
+
+// ensureCanGrantRole makes sure that only owners and admins can invite members and only owners can invite other owners
+func (s *ServiceImpl) ensureCanGrantRole(ctx context.Context, inviter model.Membership, role string) error {
+	canGrant := inviter.Role == model.OrgRoleOwner ||
+		(inviter.Role == model.OrgRoleAdmin && role != model.OrgRoleOwner)
+
+	if canGrant {
+		return nil
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("user '%s' with role '%s' cannot grant role '%s' in organization '%s'", inviter.UserId, inviter.Role, role, inviter.OrgId))
+
+	return exception.NewUnauthorized(exception.Base{
+		Type:    errorcode.OrgPermissionDenied,
+		Message: fmt.Sprintf("role '%s' cannot invite members as '%s'", inviter.Role, role),
+	})
+}
+
+func (s *ServiceImpl) AcceptInvite(ctx context.Context, userId string, email string, code string) (model.UserOrganization, error) {
+	var userOrg model.UserOrganization
+
+	// the invite use is given back if the membership cannot be created
+	err := s.db.WithTx(ctx, func(txDb database.Db) error {
+		invite, err := s.inviteService.WithDb(txDb).RedeemInvite(ctx, code, email)
+		if err != nil {
+			return err
+		}
+
+		if invite.OrgId == nil || invite.Role == nil {
+			s.logService.DebugCtx(ctx, fmt.Sprintf("invite '%s' is not an organization invite", invite.Id))
+			return exception.NewFailedPreconditionFromBase(exception.Base{
+				Type:    errorcode.InviteNotForOrg,
+				Message: "invite is not for an organization",
+			})
+		}
+
+		isMember, _, err := txDb.GetMembership(ctx, *invite.OrgId, userId)
+		if err != nil {
+			return err
+		}
+
+		if isMember {
+			s.logService.DebugCtx(ctx, fmt.Sprintf("user '%s' is already a member of organization '%s'", userId, *invite.OrgId))
+			return exception.NewAlreadyExistsFromBase(exception.Base{
+				Type:    errorcode.OrgAlreadyMember,
+				Message: "user is already a member of the organization",
+			})
+		}
+
+		orgExists, org, err := txDb.GetOrganizationById(ctx, *invite.OrgId)
+		if err != nil {
+			return err
+		}
+
+		if !orgExists {
+			return fmt.Errorf("organization with id '%s' does not exist", *invite.OrgId)
+		}
+
+		err = txDb.SaveMembership(ctx, &model.Membership{
+			OrgId:     org.Id,
+			UserId:    userId,
+			Role:      *invite.Role,
+			CreatedAt: timeutil.GetCurrentTime(),
+		})
+		if err != nil {
+			return err
+		}
+
+		userOrg = model.UserOrganization{Organization: org, Role: *invite.Role}
+		return nil
+	})
+	if err != nil {
+		return model.UserOrganization{}, err
+	}
+
+	return userOrg, nil
+}
@@ -0,0 +1,292 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupMocksForServiceImplTest creates ServiceImpl with mocked dependencies
+func setupMocksForServiceImplTest() (*ServiceImpl, *database.DbMock, *logger.ServiceMock, *invite.ServiceMock) {
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	inviteServiceMock := new(invite.ServiceMock)
+	service := &ServiceImpl{
+		db:            dbMock,
+		logService:    logServiceMock,
+		inviteService: inviteServiceMock,
+	}
+	return service, dbMock, logServiceMock, inviteServiceMock
+}
+
+func Test_NewService(t *testing.T) {
+	// ARRANGE
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	inviteServiceMock := new(invite.ServiceMock)
+
+	// ACT
+	res := NewService(logServiceMock, dbMock, inviteServiceMock)
+
+	// ASSERT
+	resServiceImpl := res.(*ServiceImpl)
+
+	assert.IsType(t, &ServiceImpl{}, res)
+	assert.Equal(t, res, resServiceImpl)
+}
+
+func Test_CreateOrganization_Should_Save_Org_And_Owner_Membership(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	userId := testutil.Fake.UUID().V4()
+	name := testutil.Fake.Company().Name()
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("SaveOrganization", ctx, mock.Anything).Return(nil)
+	dbMock.On("SaveMembership", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	orgRes, errRes := service.CreateOrganization(ctx, userId, name)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, name, orgRes.Name)
+	assert.Equal(t, userId, orgRes.CreatedBy)
+	dbMock.AssertCalled(t, "SaveOrganization", ctx, &orgRes)
+	dbMock.AssertCalled(t, "SaveMembership", ctx, mock.MatchedBy(func(membership *model.Membership) bool {
+		return membership.OrgId == orgRes.Id &&
+			membership.UserId == userId &&
+			membership.Role == model.OrgRoleOwner
+	}))
+}
+
+func Test_CreateOrganization_Error_Saving_Org(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	saveErr := fmt.Errorf("error from SaveOrganization")
+
+	dbMock.On("SaveOrganization", ctx, mock.Anything).Return(saveErr)
+
+	// ACT
+	orgRes, errRes := service.CreateOrganization(ctx, testutil.Fake.UUID().V4(), "org")
+
+	// ASSERT
+	assert.Equal(t, model.Organization{}, orgRes)
+	assert.Equal(t, saveErr, errRes)
+	dbMock.AssertNotCalled(t, "SaveMembership", mock.Anything, mock.Anything)
+}
+
+func Test_GetMembership_Not_Member(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	orgId := testutil.Fake.UUID().V4()
+	userId := testutil.Fake.UUID().V4()
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("GetMembership", ctx, orgId, userId).Return(false, model.Membership{}, nil)
+
+	// ACT
+	membershipRes, errRes := service.GetMembership(ctx, orgId, userId)
+
+	// ASSERT
+	expectedErr := exception.NewUnauthorized(exception.Base{
+		Type:    errorcode.OrgNotMember,
+		Message: "user is not a member of the organization",
+	})
+
+	assert.Equal(t, model.Membership{}, membershipRes)
+	assert.Equal(t, expectedErr, errRes)
+}
+
+func Test_GetMembership_Success_Res(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	membership := model.Membership{OrgId: testutil.Fake.UUID().V4(), UserId: testutil.Fake.UUID().V4(), Role: model.OrgRoleAdmin}
+
+	dbMock.On("GetMembership", ctx, membership.OrgId, membership.UserId).Return(true, membership, nil)
+
+	// ACT
+	membershipRes, errRes := service.GetMembership(ctx, membership.OrgId, membership.UserId)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, membership, membershipRes)
+}
+
+func Test_InviteMember_Permission_Denied(t *testing.T) {
+	testCases := []struct {
+		inviterRole string
+		role        string
+	}{
+		{inviterRole: model.OrgRoleMember, role: model.OrgRoleMember},
+		{inviterRole: model.OrgRoleAdmin, role: model.OrgRoleOwner},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("%s inviting %s", testCase.inviterRole, testCase.role), func(t *testing.T) {
+			// ARRANGE
+			service, _, logServiceMock, inviteServiceMock := setupMocksForServiceImplTest()
+
+			ctx := context.Background()
+			inviter := model.Membership{OrgId: testutil.Fake.UUID().V4(), UserId: testutil.Fake.UUID().V4(), Role: testCase.inviterRole}
+
+			logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+
+			// ACT
+			_, errRes := service.InviteMember(ctx, inviter, testutil.Fake.Internet().Email(), testCase.role, "")
+
+			// ASSERT
+			expectedErr := exception.NewUnauthorized(exception.Base{
+				Type:    errorcode.OrgPermissionDenied,
+				Message: fmt.Sprintf("role '%s' cannot invite members as '%s'", testCase.inviterRole, testCase.role),
+			})
+
+			assert.Equal(t, expectedErr, errRes)
+			inviteServiceMock.AssertNotCalled(t, "CreateOrgInvite", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func Test_InviteMember_Success_Res(t *testing.T) {
+	// ARRANGE
+	service, _, _, inviteServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	inviter := model.Membership{OrgId: testutil.Fake.UUID().V4(), UserId: testutil.Fake.UUID().V4(), Role: model.OrgRoleAdmin}
+	email := testutil.Fake.Internet().Email()
+	invite := model.Invite{Id: testutil.Fake.UUID().V4(), Code: "code"}
+
+	inviteServiceMock.On("CreateOrgInvite", ctx, inviter.UserId, inviter.OrgId, model.OrgRoleMember, email, "7d").Return(invite, nil)
+
+	// ACT
+	inviteRes, errRes := service.InviteMember(ctx, inviter, email, model.OrgRoleMember, "7d")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, invite, inviteRes)
+}
+
+func Test_AcceptInvite_Not_Org_Invite(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, inviteServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	userId := testutil.Fake.UUID().V4()
+	email := testutil.Fake.Internet().Email()
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	inviteServiceMock.On("RedeemInvite", ctx, "code", email).Return(model.Invite{Id: testutil.Fake.UUID().V4()}, nil)
+
+	// ACT
+	_, errRes := service.AcceptInvite(ctx, userId, email, "code")
+
+	// ASSERT
+	expectedErr := exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteNotForOrg,
+		Message: "invite is not for an organization",
+	})
+
+	assert.Equal(t, expectedErr, errRes)
+	dbMock.AssertNotCalled(t, "SaveMembership", mock.Anything, mock.Anything)
+}
+
+func Test_AcceptInvite_Already_Member(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, inviteServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	userId := testutil.Fake.UUID().V4()
+	email := testutil.Fake.Internet().Email()
+	orgId := testutil.Fake.UUID().V4()
+	role := model.OrgRoleMember
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	inviteServiceMock.On("RedeemInvite", ctx, "code", email).Return(model.Invite{OrgId: &orgId, Role: &role}, nil)
+	dbMock.On("GetMembership", ctx, orgId, userId).Return(true, model.Membership{}, nil)
+
+	// ACT
+	_, errRes := service.AcceptInvite(ctx, userId, email, "code")
+
+	// ASSERT
+	expectedErr := exception.NewAlreadyExistsFromBase(exception.Base{
+		Type:    errorcode.OrgAlreadyMember,
+		Message: "user is already a member of the organization",
+	})
+
+	assert.Equal(t, expectedErr, errRes)
+	dbMock.AssertNotCalled(t, "SaveMembership", mock.Anything, mock.Anything)
+}
+
+func Test_AcceptInvite_Success_Res(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, inviteServiceMock := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	userId := testutil.Fake.UUID().V4()
+	email := testutil.Fake.Internet().Email()
+	org := model.Organization{Id: testutil.Fake.UUID().V4(), Name: "org"}
+	role := model.OrgRoleAdmin
+
+	inviteServiceMock.On("RedeemInvite", ctx, "code", email).Return(model.Invite{OrgId: &org.Id, Role: &role}, nil)
+	dbMock.On("GetMembership", ctx, org.Id, userId).Return(false, model.Membership{}, nil)
+	dbMock.On("GetOrganizationById", ctx, org.Id).Return(true, org, nil)
+	dbMock.On("SaveMembership", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	orgRes, errRes := service.AcceptInvite(ctx, userId, email, "code")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, model.UserOrganization{Organization: org, Role: role}, orgRes)
+	dbMock.AssertCalled(t, "SaveMembership", ctx, mock.MatchedBy(func(membership *model.Membership) bool {
+		return membership.OrgId == org.Id && membership.UserId == userId && membership.Role == role
+	}))
+}
+
+func Test_AcceptInvite_Should_Give_Back_Invite_Use_When_Already_Member_With_MemDb(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	memDb := testutil.NewMemDb()
+	service := NewService(logServiceMock, memDb, invite.NewService(logServiceMock, memDb))
+
+	ctx := context.Background()
+	ownerId := testutil.Fake.UUID().V4()
+	userId := testutil.Fake.UUID().V4()
+	email := testutil.Fake.Internet().Email()
+
+	org, err := service.CreateOrganization(ctx, ownerId, testutil.Fake.Company().Name())
+	assert.Nil(t, err)
+	err = memDb.SaveMembership(ctx, &model.Membership{OrgId: org.Id, UserId: userId, Role: model.OrgRoleMember, CreatedAt: org.CreatedAt})
+	assert.Nil(t, err)
+	orgInvite, err := service.InviteMember(ctx, model.Membership{OrgId: org.Id, UserId: ownerId, Role: model.OrgRoleOwner}, email, model.OrgRoleMember, "1d")
+	assert.Nil(t, err)
+
+	// ACT
+	_, errRes := service.AcceptInvite(ctx, userId, email, orgInvite.Code)
+
+	// ASSERT
+	assert.IsType(t, exception.AlreadyExists{}, errRes)
+
+	_, inviteRes, err := memDb.GetInviteByCode(ctx, orgInvite.Code)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, inviteRes.UsedCount)
+}
@@ -0,0 +1,42 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) CreateOrganization(ctx context.Context, userId string, name string) (model.Organization, error) {
+	args := s.Called(ctx, userId, name)
+	return args.Get(0).(model.Organization), args.Error(1)
+}
+
+func (s *ServiceMock) ListUserOrganizations(ctx context.Context, userId string) ([]model.UserOrganization, error) {
+	args := s.Called(ctx, userId)
+	return args.Get(0).([]model.UserOrganization), args.Error(1)
+}
+
+func (s *ServiceMock) GetMembership(ctx context.Context, orgId string, userId string) (model.Membership, error) {
+	args := s.Called(ctx, orgId, userId)
+	return args.Get(0).(model.Membership), args.Error(1)
+}
+
+func (s *ServiceMock) ListMembers(ctx context.Context, orgId string) ([]model.OrgMember, error) {
+	args := s.Called(ctx, orgId)
+	return args.Get(0).([]model.OrgMember), args.Error(1)
+}
+
+func (s *ServiceMock) InviteMember(ctx context.Context, inviter model.Membership, email string, role string, expiresIn string) (model.Invite, error) {
+	args := s.Called(ctx, inviter, email, role, expiresIn)
+	return args.Get(0).(model.Invite), args.Error(1)
+}
+
+func (s *ServiceMock) AcceptInvite(ctx context.Context, userId string, email string, code string) (model.UserOrganization, error) {
+	args := s.Called(ctx, userId, email, code)
+	return args.Get(0).(model.UserOrganization), args.Error(1)
+}
@@ -0,0 +1,21 @@
+package outbox
+
+import "context"
+
+// Relay publishes the events of the outbox to NATS JetStream, events are published at least once so consumers must
+// tolerate duplicates
+type Relay interface {
+	// Run relays the pending events every poll interval until ctx is cancelled
+	Run(ctx context.Context)
+	// RelayPending publishes one batch of pending events and returns how many have been published
+	RelayPending(ctx context.Context) (int, error)
+	Metrics() Metrics
+}
+
+type Metrics struct {
+	Pending int `json:"pending"`
+	// LagSec is the age of the oldest pending event in seconds
+	LagSec    float64 `json:"lagSec"`
+	Published uint64  `json:"published"`
+	Failed    uint64  `json:"failed"`
+}
@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+)
+
+const defaultPollInterval = time.Second
+const defaultBatchSize = 100
+
+// failed events are retried with an exponential backoff
+const retryBaseDelay = time.Second
+const retryMaxDelay = 5 * time.Minute
+
+// claimed events are hidden from other relays for claimLease, an event claimed by a relay which stopped before
+// publishing it is relayed again once its lease expires
+const claimLease = time.Minute
+
+type RelayImpl struct {
+	db           database.Db
+	logService   logger.Service
+	natsService  nats.Service
+	pollInterval time.Duration
+	batchSize    int
+
+	mu              sync.Mutex
+	pending         int
+	oldestPendingAt *time.Time
+	published       uint64
+	failed          uint64
+}
+
+func NewRelay(appConfig *config.AppConfig, logService logger.Service, db database.Db, natsService nats.Service) (Relay, error) {
+	pollInterval := defaultPollInterval
+	if appConfig.OUTBOX_POLL_INTERVAL != "" {
+		var err error
+		pollInterval, err = time.ParseDuration(appConfig.OUTBOX_POLL_INTERVAL)
+		if err != nil || pollInterval <= 0 {
+			return nil, fmt.Errorf("outbox.NewRelay(): invalid OUTBOX_POLL_INTERVAL '%s'", appConfig.OUTBOX_POLL_INTERVAL)
+		}
+	}
+
+	batchSize := defaultBatchSize
+	if appConfig.OUTBOX_BATCH_SIZE != "" {
+		var err error
+		batchSize, err = strconv.Atoi(appConfig.OUTBOX_BATCH_SIZE)
+		if err != nil || batchSize <= 0 {
+			return nil, fmt.Errorf("outbox.NewRelay(): invalid OUTBOX_BATCH_SIZE '%s'", appConfig.OUTBOX_BATCH_SIZE)
+		}
+	}
+
+	return &RelayImpl{
+		db:           db,
+		logService:   logService,
+		natsService:  natsService,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+	}, nil
+}
+
+func (r *RelayImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.RelayPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logService.Error(err.Error())
+		}
+
+		// a full batch means that more events are waiting, they are relayed without waiting for the next tick
+		if err == nil && published == r.batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *RelayImpl) RelayPending(ctx context.Context) (int, error) {
+	currentTime := timeutil.GetCurrentTime()
+	pendingEvents, err := r.db.GetPendingOutboxEvents(ctx, currentTime, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("outbox.RelayPending(): %w", err)
+	}
+
+	// every restapi instance runs a relay, each event is only published by the relay which claimed it
+	events := make([]model.OutboxEvent, 0, len(pendingEvents))
+	for _, event := range pendingEvents {
+		claimed, err := r.db.ClaimOutboxEvent(ctx, event.Id, currentTime, currentTime.Add(claimLease))
+		if err != nil {
+			return 0, fmt.Errorf("outbox.RelayPending(): %w", err)
+		}
+		if claimed {
+			events = append(events, event)
+		}
+	}
+
+	msgs := make([]nats.Msg, len(events))
+	for i, event := range events {
+		msgs[i] = nats.Msg{Subject: event.Topic, Data: event.Payload, Headers: getHeader(event.Payload)}
+	}
+
+	// the batch is published at once and the stream acknowledges every event, events published again are stored once
+	// thanks to the Nats-Msg-Id header set to the id of the event
+	var errs []error
+	if len(msgs) > 0 {
+		errs = r.natsService.PublishBatch(ctx, msgs)
+	}
+
+	published := 0
+	for i, event := range events {
+		err = errs[i]
+		if err != nil {
+			r.addFailed()
+			nextAttemptAt := currentTime.Add(getRetryDelay(event.Attempts))
+			r.logService.Error(fmt.Sprintf("error publishing outbox event '%s' to '%s' (attempt %d), retrying at %s: %s", event.Id, event.Topic, event.Attempts+1, nextAttemptAt.Format(time.RFC3339), err))
+
+			err = r.db.MarkOutboxEventFailed(ctx, event.Id, err.Error(), nextAttemptAt)
+			if err != nil {
+				return published, fmt.Errorf("outbox.RelayPending(): %w", err)
+			}
+			continue
+		}
+
+		// the event is published again if it cannot be marked as sent, which is why delivery is at least once
+		err = r.db.MarkOutboxEventSent(ctx, event.Id, timeutil.GetCurrentTime())
+		if err != nil {
+			return published, fmt.Errorf("outbox.RelayPending(): %w", err)
+		}
+
+		r.addPublished()
+		published++
+		r.logService.Debug(fmt.Sprintf("published outbox event '%s' to '%s'", event.Id, event.Topic))
+	}
+
+	err = r.refreshLag(ctx)
+	if err != nil {
+		return published, fmt.Errorf("outbox.RelayPending(): %w", err)
+	}
+
+	return published, nil
+}
+
+// getHeader returns the CloudEvents headers of the envelope of the payload, nil is returned for payloads which were
+// enqueued before envelopes were introduced
+func getHeader(payload []byte) natsgo.Header {
+	envelope, err := event.Parse(payload)
+	if err != nil {
+		return nil
+	}
+
+	return envelope.Headers()
+}
+
+// getRetryDelay doubles the delay with every failed attempt up to retryMaxDelay
+func getRetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 0; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, retryMaxDelay)
+}
+
+func (r *RelayImpl) refreshLag(ctx context.Context) error {
+	stats, err := r.db.GetOutboxStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = stats.Pending
+	r.oldestPendingAt = stats.OldestPendingAt
+
+	return nil
+}
+
+func (r *RelayImpl) addPublished() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.published++
+}
+
+func (r *RelayImpl) addFailed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failed++
+}
+
+func (r *RelayImpl) Metrics() Metrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metrics := Metrics{
+		Pending:   r.pending,
+		Published: r.published,
+		Failed:    r.failed,
+	}
+	if r.oldestPendingAt != nil {
+		metrics.LagSec = timeutil.GetCurrentTime().Sub(*r.oldestPendingAt).Seconds()
+	}
+
+	return metrics
+}
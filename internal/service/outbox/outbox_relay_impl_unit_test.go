@@ -0,0 +1,246 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupMocksForRelayImplTest creates RelayImpl with mocked dependencies
+func setupMocksForRelayImplTest() (*RelayImpl, *database.DbMock, *logger.ServiceMock, *nats.PubServiceMock) {
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	natsServiceMock := new(nats.PubServiceMock)
+	relay := &RelayImpl{
+		db:           dbMock,
+		logService:   logServiceMock,
+		natsService:  natsServiceMock,
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+	}
+	return relay, dbMock, logServiceMock, natsServiceMock
+}
+
+func genOutboxEvent(attempts int) model.OutboxEvent {
+	createdAt := time.Now().Add(-time.Minute)
+	return model.OutboxEvent{
+		Id:            testutil.Fake.UUID().V4(),
+		Topic:         "EVENT.USER.NEW",
+		Payload:       []byte(`{"id":"1"}`),
+		Attempts:      attempts,
+		NextAttemptAt: createdAt,
+		CreatedAt:     createdAt,
+	}
+}
+
+func Test_NewRelay(t *testing.T) {
+	// ARRANGE
+	appConfig := testutil.GetMockAppConfig(&config.AppConfig{OUTBOX_POLL_INTERVAL: "250ms", OUTBOX_BATCH_SIZE: "10"})
+
+	// ACT
+	res, errRes := NewRelay(&appConfig, new(logger.ServiceMock), new(database.DbMock), new(nats.PubServiceMock))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 250*time.Millisecond, res.(*RelayImpl).pollInterval)
+	assert.Equal(t, 10, res.(*RelayImpl).batchSize)
+}
+
+func Test_NewRelay_Invalid_Config(t *testing.T) {
+	testCases := []config.AppConfig{
+		{OUTBOX_POLL_INTERVAL: "soon"},
+		{OUTBOX_POLL_INTERVAL: "-1s"},
+		{OUTBOX_BATCH_SIZE: "many"},
+		{OUTBOX_BATCH_SIZE: "0"},
+	}
+
+	for _, testCase := range testCases {
+		// ARRANGE
+		appConfig := testCase
+
+		// ACT
+		res, errRes := NewRelay(&appConfig, new(logger.ServiceMock), new(database.DbMock), new(nats.PubServiceMock))
+
+		// ASSERT
+		assert.Nil(t, res)
+		assert.NotNil(t, errRes)
+	}
+}
+
+func Test_RelayPending_Should_Publish_And_Mark_Events_Sent(t *testing.T) {
+	// ARRANGE
+	relay, dbMock, logServiceMock, natsServiceMock := setupMocksForRelayImplTest()
+
+	ctx := context.Background()
+	events := []model.OutboxEvent{genOutboxEvent(0), genOutboxEvent(2)}
+
+	logServiceMock.On("Debug", mock.Anything)
+	dbMock.On("GetPendingOutboxEvents", ctx, mock.Anything, defaultBatchSize).Return(events, nil)
+	dbMock.On("ClaimOutboxEvent", ctx, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	dbMock.On("MarkOutboxEventSent", ctx, mock.Anything, mock.Anything).Return(nil)
+	dbMock.On("GetOutboxStats", ctx).Return(model.OutboxStats{}, nil)
+	natsServiceMock.On("PublishBatch", ctx, mock.Anything).Return([]error{nil, nil})
+
+	// ACT
+	publishedRes, errRes := relay.RelayPending(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 2, publishedRes)
+	natsServiceMock.AssertCalled(t, "PublishBatch", ctx, []nats.Msg{
+		{Subject: events[0].Topic, Data: events[0].Payload},
+		{Subject: events[1].Topic, Data: events[1].Payload},
+	})
+	for _, event := range events {
+		dbMock.AssertCalled(t, "MarkOutboxEventSent", ctx, event.Id, mock.Anything)
+	}
+	assert.Equal(t, Metrics{Published: 2}, relay.Metrics())
+}
+
+func Test_RelayPending_Should_Retry_Failed_Event_With_Backoff(t *testing.T) {
+	// ARRANGE
+	relay, dbMock, logServiceMock, natsServiceMock := setupMocksForRelayImplTest()
+
+	ctx := context.Background()
+	event := genOutboxEvent(3)
+	publishErr := fmt.Errorf("nats: no response from stream")
+	stats := model.OutboxStats{Pending: 1, OldestPendingAt: &event.CreatedAt}
+
+	logServiceMock.On("Error", mock.Anything)
+	dbMock.On("GetPendingOutboxEvents", ctx, mock.Anything, defaultBatchSize).Return([]model.OutboxEvent{event}, nil)
+	dbMock.On("ClaimOutboxEvent", ctx, event.Id, mock.Anything, mock.Anything).Return(true, nil)
+	dbMock.On("MarkOutboxEventFailed", ctx, event.Id, mock.Anything, mock.Anything).Return(nil)
+	dbMock.On("GetOutboxStats", ctx).Return(stats, nil)
+	natsServiceMock.On("PublishBatch", ctx, mock.Anything).Return([]error{publishErr})
+
+	// ACT
+	publishedRes, errRes := relay.RelayPending(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 0, publishedRes)
+	dbMock.AssertNotCalled(t, "MarkOutboxEventSent", mock.Anything, mock.Anything, mock.Anything)
+	dbMock.AssertCalled(t, "MarkOutboxEventFailed", ctx, event.Id, publishErr.Error(), mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return nextAttemptAt.Sub(time.Now()) > 7*time.Second && nextAttemptAt.Sub(time.Now()) <= 8*time.Second
+	}))
+
+	metrics := relay.Metrics()
+	assert.Equal(t, 1, metrics.Pending)
+	assert.Equal(t, uint64(1), metrics.Failed)
+	assert.InDelta(t, time.Minute.Seconds(), metrics.LagSec, 1)
+}
+
+func Test_RelayPending_Should_Skip_Events_Claimed_By_Another_Relay(t *testing.T) {
+	// ARRANGE
+	relay, dbMock, logServiceMock, natsServiceMock := setupMocksForRelayImplTest()
+
+	ctx := context.Background()
+	claimedEvent := genOutboxEvent(0)
+	takenEvent := genOutboxEvent(0)
+
+	logServiceMock.On("Debug", mock.Anything)
+	dbMock.On("GetPendingOutboxEvents", ctx, mock.Anything, defaultBatchSize).Return([]model.OutboxEvent{claimedEvent, takenEvent}, nil)
+	dbMock.On("ClaimOutboxEvent", ctx, claimedEvent.Id, mock.Anything, mock.Anything).Return(true, nil)
+	dbMock.On("ClaimOutboxEvent", ctx, takenEvent.Id, mock.Anything, mock.Anything).Return(false, nil)
+	dbMock.On("MarkOutboxEventSent", ctx, claimedEvent.Id, mock.Anything).Return(nil)
+	dbMock.On("GetOutboxStats", ctx).Return(model.OutboxStats{}, nil)
+	natsServiceMock.On("PublishBatch", ctx, mock.Anything).Return([]error{nil})
+
+	// ACT
+	publishedRes, errRes := relay.RelayPending(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 1, publishedRes)
+	dbMock.AssertCalled(t, "ClaimOutboxEvent", ctx, claimedEvent.Id, mock.Anything, mock.MatchedBy(func(leaseUntil time.Time) bool {
+		return leaseUntil.Sub(time.Now()) > claimLease-time.Second && leaseUntil.Sub(time.Now()) <= claimLease
+	}))
+	natsServiceMock.AssertCalled(t, "PublishBatch", ctx, []nats.Msg{{Subject: claimedEvent.Topic, Data: claimedEvent.Payload}})
+	dbMock.AssertNotCalled(t, "MarkOutboxEventSent", ctx, takenEvent.Id, mock.Anything)
+}
+
+func Test_RelayPending_Error_Getting_Pending_Events(t *testing.T) {
+	// ARRANGE
+	relay, dbMock, _, natsServiceMock := setupMocksForRelayImplTest()
+
+	ctx := context.Background()
+	dbErr := fmt.Errorf("error from GetPendingOutboxEvents")
+
+	dbMock.On("GetPendingOutboxEvents", ctx, mock.Anything, defaultBatchSize).Return([]model.OutboxEvent{}, dbErr)
+
+	// ACT
+	publishedRes, errRes := relay.RelayPending(ctx)
+
+	// ASSERT
+	assert.Equal(t, 0, publishedRes)
+	assert.True(t, errors.Is(errRes, dbErr))
+	natsServiceMock.AssertNotCalled(t, "PublishBatch", mock.Anything, mock.Anything)
+}
+
+func Test_Run_Should_Relay_Until_Cancelled(t *testing.T) {
+	// ARRANGE
+	relay, dbMock, _, _ := setupMocksForRelayImplTest()
+	relay.pollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	relayed := make(chan struct{}, 10)
+
+	dbMock.On("GetPendingOutboxEvents", ctx, mock.Anything, defaultBatchSize).
+		Run(func(args mock.Arguments) { relayed <- struct{}{} }).
+		Return([]model.OutboxEvent{}, nil)
+	dbMock.On("GetOutboxStats", ctx).Return(model.OutboxStats{}, nil)
+
+	// ACT
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	<-relayed
+	<-relayed
+	cancel()
+
+	// ASSERT
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run should return once the context is cancelled")
+	}
+}
+
+func Test_getRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, getRetryDelay(0))
+	assert.Equal(t, 2*time.Second, getRetryDelay(1))
+	assert.Equal(t, 64*time.Second, getRetryDelay(6))
+	assert.Equal(t, retryMaxDelay, getRetryDelay(9))
+	assert.Equal(t, retryMaxDelay, getRetryDelay(1000))
+}
+
+func Test_getHeader_Should_Return_CloudEvents_Headers_Of_Envelope(t *testing.T) {
+	// ARRANGE
+	eventRegistry, _ := eventtype.NewRegistry()
+	envelope, _ := eventRegistry.New(context.Background(), eventtype.UserRegistered, eventtype.UserRegisteredData{Id: "1", Email: "john@doe.com"})
+	payload, _ := json.Marshal(envelope)
+
+	// ACT
+	headerRes := getHeader(payload)
+	legacyHeaderRes := getHeader([]byte(`{"id":"1","email":"john@doe.com"}`))
+
+	// ASSERT
+	assert.Equal(t, envelope.Id, headerRes.Get("Ce-Id"))
+	assert.Equal(t, eventtype.UserRegistered, headerRes.Get("Ce-Type"))
+	assert.Nil(t, legacyHeaderRes)
+}
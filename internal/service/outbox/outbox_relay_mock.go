@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RelayMock struct {
+	mock.Mock
+}
+
+func (r *RelayMock) Run(ctx context.Context) {
+	r.Called(ctx)
+}
+
+func (r *RelayMock) RelayPending(ctx context.Context) (int, error) {
+	args := r.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *RelayMock) Metrics() Metrics {
+	args := r.Called()
+	return args.Get(0).(Metrics)
+}
@@ -0,0 +1,16 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+)
+
+type Service interface {
+	// Enqueue wraps the data in an envelope of the event type and saves it in the outbox, the relay publishes it to the
+	// topic once the transaction has committed. The data must be of the type registered for the event type.
+	Enqueue(ctx context.Context, topic string, eventType string, data any) error
+	// WithDb returns a copy of the service which uses the given db, events must be enqueued in the transaction which
+	// writes the data they describe
+	WithDb(db database.Db) Service
+}
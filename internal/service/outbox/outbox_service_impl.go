@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+)
+
+type ServiceImpl struct {
+	db            database.Db
+	logService    logger.Service
+	eventRegistry *event.Registry
+}
+
+func NewService(logService logger.Service, db database.Db, eventRegistry *event.Registry) Service {
+	return &ServiceImpl{
+		db:            db,
+		logService:    logService,
+		eventRegistry: eventRegistry,
+	}
+}
+
+func (s *ServiceImpl) WithDb(db database.Db) Service {
+	return &ServiceImpl{
+		db:            db,
+		logService:    s.logService,
+		eventRegistry: s.eventRegistry,
+	}
+}
+
+func (s *ServiceImpl) Enqueue(ctx context.Context, topic string, eventType string, data any) error {
+	envelope, err := s.eventRegistry.New(ctx, eventType, data)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("outbox.Enqueue(): %w", err)
+	}
+
+	// the outbox event shares the id of the envelope so that duplicates can be recognized downstream
+	eventId := envelope.Id
+	currentTime := timeutil.GetCurrentTime()
+	err = s.db.SaveOutboxEvent(ctx, &model.OutboxEvent{
+		Id:            eventId,
+		Topic:         topic,
+		Payload:       payload,
+		NextAttemptAt: currentTime,
+		CreatedAt:     currentTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("enqueued outbox event '%s' of type '%s' for topic '%s'", eventId, eventType, topic))
+
+	return nil
+}
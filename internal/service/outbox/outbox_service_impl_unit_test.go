@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupMocksForServiceImplTest creates ServiceImpl with mocked dependencies and the event registry of the app
+func setupMocksForServiceImplTest() (*ServiceImpl, *database.DbMock, *logger.ServiceMock) {
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	eventRegistry, _ := eventtype.NewRegistry()
+	service := &ServiceImpl{
+		db:            dbMock,
+		logService:    logServiceMock,
+		eventRegistry: eventRegistry,
+	}
+	return service, dbMock, logServiceMock
+}
+
+func Test_NewService(t *testing.T) {
+	// ARRANGE
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+
+	// ACT
+	res := NewService(logServiceMock, dbMock, event.NewRegistry(eventtype.Source))
+
+	// ASSERT
+	assert.IsType(t, &ServiceImpl{}, res)
+}
+
+func Test_WithDb_Should_Use_Given_Db(t *testing.T) {
+	// ARRANGE
+	service, _, _ := setupMocksForServiceImplTest()
+	txDbMock := new(database.DbMock)
+
+	// ACT
+	res := service.WithDb(txDbMock)
+
+	// ASSERT
+	assert.Equal(t, txDbMock, res.(*ServiceImpl).db)
+	assert.Equal(t, service.logService, res.(*ServiceImpl).logService)
+	assert.Equal(t, service.eventRegistry, res.(*ServiceImpl).eventRegistry)
+}
+
+func Test_Enqueue_Should_Save_Pending_Event_In_Envelope(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+
+	ctx := ctxutil.NewCtxWithTraceId("trace-id")
+	topic := "EVENT.USER.NEW"
+	data := eventtype.UserRegisteredData{Id: "1", Email: "john@doe.com"}
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("SaveOutboxEvent", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.Enqueue(ctx, topic, eventtype.UserRegistered, data)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveOutboxEvent", ctx, mock.MatchedBy(func(outboxEvent *model.OutboxEvent) bool {
+		envelope, dataRes, err := service.eventRegistry.Decode(outboxEvent.Payload)
+		return err == nil &&
+			outboxEvent.Id == envelope.Id &&
+			outboxEvent.Topic == topic &&
+			outboxEvent.Attempts == 0 &&
+			outboxEvent.SentAt == nil &&
+			outboxEvent.NextAttemptAt.Equal(outboxEvent.CreatedAt) &&
+			time.Since(outboxEvent.CreatedAt) < time.Second &&
+			envelope.Type == eventtype.UserRegistered &&
+			envelope.TraceId == "trace-id" &&
+			dataRes == data
+	}))
+}
+
+func Test_Enqueue_Should_Reject_Invalid_Event(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+
+	// ACT
+	errInvalidRes := service.Enqueue(ctx, "EVENT.USER.NEW", eventtype.UserRegistered, eventtype.UserRegisteredData{Id: "1"})
+	errUnknownRes := service.Enqueue(ctx, "EVENT.USER.NEW", "user.unknown", eventtype.UserRegisteredData{Id: "1", Email: "john@doe.com"})
+
+	// ASSERT
+	assert.EqualError(t, errInvalidRes, "event.Registry.New(): invalid 'user.registered': id and email are required")
+	assert.EqualError(t, errUnknownRes, "event.Registry.New(): unknown event type 'user.unknown'")
+	dbMock.AssertNotCalled(t, "SaveOutboxEvent", mock.Anything, mock.Anything)
+}
+
+func Test_Enqueue_Error_Saving_Event(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	saveErr := fmt.Errorf("error from SaveOutboxEvent")
+
+	dbMock.On("SaveOutboxEvent", ctx, mock.Anything).Return(saveErr)
+
+	// ACT
+	errRes := service.Enqueue(ctx, "EVENT.USER.NEW", eventtype.UserRegistered, eventtype.UserRegisteredData{Id: "1", Email: "john@doe.com"})
+
+	// ASSERT
+	assert.Equal(t, saveErr, errRes)
+}
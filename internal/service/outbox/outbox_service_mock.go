@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/stretchr/testify/mock"
+)
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) Enqueue(ctx context.Context, topic string, eventType string, data any) error {
+	args := s.Called(ctx, topic, eventType, data)
+	return args.Error(0)
+}
+
+// WithDb returns the mock itself so that expectations are shared with the transactional copy
+func (s *ServiceMock) WithDb(db database.Db) Service {
+	return s
+}
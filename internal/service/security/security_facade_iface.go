@@ -0,0 +1,11 @@
+package security
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+)
+
+type Facade interface {
+	GetSecurityEvents(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error)
+}
@@ -0,0 +1,72 @@
+package security
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pjmessi/golang-practice/internal/dto"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/structutil"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+)
+
+type FacadeImpl struct {
+	securityService   Service
+	validationHandler validation.Handler
+	logService        logger.Service
+}
+
+func NewFacade(logService logger.Service, securityService Service, validationHandler validation.Handler) Facade {
+	return &FacadeImpl{
+		securityService:   securityService,
+		validationHandler: validationHandler,
+		logService:        logService,
+	}
+}
+
+func (f *FacadeImpl) GetSecurityEvents(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	var req model.GetSecurityEventsApiReq
+
+	err := structutil.ConvertFromBytes(reqBytes, &req)
+	if err != nil {
+		return nil, exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+	}
+
+	err = f.validationHandler.ValidateStruct(req)
+	if err != nil {
+		var valErr validation.ValidationError
+		if errors.As(err, &valErr) {
+			return nil, exception.NewInvalidReqFromBase(exception.Base{
+				Details: &valErr.Details,
+			})
+		} else {
+			return nil, err
+		}
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = DefaultPageLimit
+	}
+
+	events, total, err := f.securityService.ListLoginEvents(ctx, jwtPayload.UserId, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.GetSecurityEventsApiRes{
+		Events:     []model.SecurityEventRes{},
+		Pagination: model.PaginationRes{Page: req.Page, Limit: req.Limit, Total: total},
+	}
+	for _, event := range events {
+		res.Events = append(res.Events, dto.LoginEventToSecurityEventRes(&event))
+	}
+
+	return structutil.ConvertToBytes(res)
+}
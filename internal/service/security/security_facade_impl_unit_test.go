@@ -0,0 +1,186 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupMocksForFacadeImplTest creates FacadeImpl with mocked dependencies
+func setupMocksForFacadeImplTest() (*FacadeImpl, *ServiceMock, *validation.HandlerMock) {
+	securityServiceMock := new(ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+	facade := &FacadeImpl{
+		securityService:   securityServiceMock,
+		validationHandler: validationHandlerMock,
+		logService:        new(logger.ServiceMock),
+	}
+	return facade, securityServiceMock, validationHandlerMock
+}
+
+func Test_NewFacade(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	serviceMock := new(ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+
+	// ACT
+	res := NewFacade(logServiceMock, serviceMock, validationHandlerMock)
+
+	// ASSERT
+	resFacadeImpl := res.(*FacadeImpl)
+
+	assert.IsType(t, &FacadeImpl{}, res)
+	assert.Equal(t, res, resFacadeImpl)
+}
+
+func Test_Facade_GetSecurityEvents_Invalid_Struct_In_Req_Bytes(t *testing.T) {
+	// ARRANGE
+	facade, _, _ := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	var reqBytes []byte
+
+	// ACT
+	bytesRes, errRes := facade.GetSecurityEvents(ctx, reqBytes, jwt.JwtPayload{})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_GetSecurityEvents_Invalid_Struct_Data_In_Req_Bytes(t *testing.T) {
+	// ARRANGE
+	facade, _, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	req := model.GetSecurityEventsApiReq{Limit: 1000}
+	reqBytes := []byte(`{"limit":"1000"}`)
+	validationErrDetails := map[string]string{"Limit": "validation failed for tag: 'max'"}
+
+	validationHandlerMock.On("ValidateStruct", req).Return(validation.ValidationError{Details: validationErrDetails})
+
+	// ACT
+	bytesRes, errRes := facade.GetSecurityEvents(ctx, reqBytes, jwt.JwtPayload{})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Details: &validationErrDetails})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_GetSecurityEvents_Should_Reject_Page_Beyond_Max(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _ := setupMocksForFacadeImplTest()
+	validationHandler, _ := validation.NewHandler()
+	facade.validationHandler = validationHandler
+
+	ctx := context.Background()
+	reqBytes := []byte(`{"page":"9223372036854775807"}`)
+
+	// ACT
+	bytesRes, errRes := facade.GetSecurityEvents(ctx, reqBytes, jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Details: &map[string]string{"Page": "validation failed for tag: 'max'"}})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+	serviceMock.AssertNotCalled(t, "ListLoginEvents")
+}
+
+func Test_Facade_GetSecurityEvents_Should_Use_Default_Pagination(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()}
+
+	validationHandlerMock.On("ValidateStruct", model.GetSecurityEventsApiReq{}).Return(nil)
+	serviceMock.On("ListLoginEvents", ctx, jwtPayload.UserId, 1, DefaultPageLimit).Return([]model.LoginEvent{}, 0, nil)
+
+	// ACT
+	bytesRes, errRes := facade.GetSecurityEvents(ctx, []byte("{}"), jwtPayload)
+
+	// ASSERT
+	var res model.GetSecurityEventsApiRes
+	_ = json.Unmarshal(bytesRes, &res)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, []model.SecurityEventRes{}, res.Events)
+	assert.Equal(t, model.PaginationRes{Page: 1, Limit: DefaultPageLimit, Total: 0}, res.Pagination)
+}
+
+func Test_Facade_GetSecurityEvents_Should_Return_Events(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()}
+	req := model.GetSecurityEventsApiReq{Page: 2, Limit: 1}
+	failureReason := model.LoginFailureInvalidPassword
+	event := model.LoginEvent{
+		Id:            testutil.Fake.UUID().V4(),
+		UserId:        &jwtPayload.UserId,
+		FailureReason: &failureReason,
+		Ip:            "127.0.0.1",
+		UserAgent:     "Go-http-client/1.1",
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+
+	validationHandlerMock.On("ValidateStruct", req).Return(nil)
+	serviceMock.On("ListLoginEvents", ctx, jwtPayload.UserId, 2, 1).Return([]model.LoginEvent{event}, 3, nil)
+
+	// ACT
+	bytesRes, errRes := facade.GetSecurityEvents(ctx, []byte(`{"page":"2","limit":"1"}`), jwtPayload)
+
+	// ASSERT
+	expectedRes := model.GetSecurityEventsApiRes{
+		Events: []model.SecurityEventRes{{
+			Id:            event.Id,
+			Success:       false,
+			FailureReason: &failureReason,
+			Ip:            event.Ip,
+			UserAgent:     event.UserAgent,
+			CreatedAt:     event.CreatedAt.Format(time.RFC3339),
+		}},
+		Pagination: model.PaginationRes{Page: 2, Limit: 1, Total: 3},
+	}
+	expectedBytes, _ := json.Marshal(expectedRes)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, expectedBytes, bytesRes)
+}
+
+func Test_Facade_GetSecurityEvents_ListLoginEvents_Returns_Err(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	jwtPayload := jwt.JwtPayload{UserId: testutil.Fake.UUID().V4()}
+	listErr := fmt.Errorf("error from ListLoginEvents")
+
+	validationHandlerMock.On("ValidateStruct", model.GetSecurityEventsApiReq{}).Return(nil)
+	serviceMock.On("ListLoginEvents", ctx, jwtPayload.UserId, 1, DefaultPageLimit).Return([]model.LoginEvent{}, 0, listErr)
+
+	// ACT
+	bytesRes, errRes := facade.GetSecurityEvents(ctx, []byte("{}"), jwtPayload)
+
+	// ASSERT
+	assert.Equal(t, listErr, errRes)
+	assert.Nil(t, bytesRes)
+}
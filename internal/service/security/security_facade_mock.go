@@ -0,0 +1,17 @@
+package security
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/stretchr/testify/mock"
+)
+
+type FacadeMock struct {
+	mock.Mock
+}
+
+func (f *FacadeMock) GetSecurityEvents(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload)
+	return args.Get(0).([]byte), args.Error(1)
+}
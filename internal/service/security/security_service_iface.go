@@ -0,0 +1,12 @@
+package security
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+)
+
+type Service interface {
+	RecordLoginAttempt(ctx context.Context, attempt model.LoginAttempt) error
+	ListLoginEvents(ctx context.Context, userId string, page int, limit int) (events []model.LoginEvent, total int, err error)
+}
@@ -0,0 +1,141 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/service/outbox"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+)
+
+const DefaultPageLimit = 20
+
+// maxUserAgentLen is the number of characters of the user agent column
+const maxUserAgentLen = 255
+
+type ServiceImpl struct {
+	db             database.Db
+	logService     logger.Service
+	outboxService  outbox.Service
+	newDeviceEvent string
+}
+
+func NewService(appConfig *config.AppConfig, logService logger.Service, db database.Db, outboxService outbox.Service) Service {
+	return &ServiceImpl{
+		db:             db,
+		logService:     logService,
+		outboxService:  outboxService,
+		newDeviceEvent: appConfig.NATS_EVENT_USER_NEW_DEVICE,
+	}
+}
+
+func (s *ServiceImpl) RecordLoginAttempt(ctx context.Context, attempt model.LoginAttempt) error {
+	eventId, err := uuidutil.GenUuidV4()
+	if err != nil {
+		return err
+	}
+
+	event := model.LoginEvent{
+		Id:            eventId,
+		UserId:        attempt.UserId,
+		Email:         attempt.Email,
+		Success:       attempt.FailureReason == nil,
+		FailureReason: attempt.FailureReason,
+		Ip:            s.getClientIp(ctx),
+		UserAgent:     s.getUserAgent(ctx),
+		MfaUsed:       attempt.MfaUsed,
+		CreatedAt:     timeutil.GetCurrentTime(),
+	}
+
+	if event.Success && event.UserId != nil {
+		event.NewDevice, err = s.isNewDeviceOrLocation(ctx, *event.UserId, event.Ip, event.UserAgent)
+		if err != nil {
+			return err
+		}
+	}
+
+	// new device events are not enqueued when NATS_EVENT_USER_NEW_DEVICE is not configured, they would have no subject
+	// to be published to
+	if !event.NewDevice || s.newDeviceEvent == "" {
+		return s.db.SaveLoginEvent(ctx, &event)
+	}
+
+	// the new device event is enqueued with the login event so that it is published if and only if the login is saved
+	return s.db.WithTx(ctx, func(txDb database.Db) error {
+		err := txDb.SaveLoginEvent(ctx, &event)
+		if err != nil {
+			return err
+		}
+
+		return s.enqueueNewDeviceEvent(ctx, txDb, event)
+	})
+}
+
+// isNewDeviceOrLocation returns true if the user has logged in before but never from the ip or with the user agent,
+// the very first login of a user is not considered as a new device
+func (s *ServiceImpl) isNewDeviceOrLocation(ctx context.Context, userId string, ip string, userAgent string) (bool, error) {
+	stats, err := s.db.GetLoginHistoryStats(ctx, userId, ip, userAgent)
+	if err != nil {
+		return false, err
+	}
+
+	if stats.SuccessfulLogins == 0 {
+		return false, nil
+	}
+
+	return stats.FromSameIp == 0 || stats.FromSameDevice == 0, nil
+}
+
+func (s *ServiceImpl) enqueueNewDeviceEvent(ctx context.Context, db database.Db, event model.LoginEvent) error {
+	return s.outboxService.WithDb(db).Enqueue(ctx, s.newDeviceEvent, eventtype.UserNewDeviceLogin, eventtype.UserNewDeviceLoginData{
+		UserId:    *event.UserId,
+		Email:     event.Email,
+		Ip:        event.Ip,
+		UserAgent: event.UserAgent,
+	})
+}
+
+func (s *ServiceImpl) getClientIp(ctx context.Context) string {
+	ip, _ := ctxutil.GetValue(ctx, "clientIp").(string)
+	return ip
+}
+
+// getUserAgent returns the user agent as valid UTF-8 truncated to maxUserAgentLen characters, cutting at a byte could
+// split a multi-byte character which strict databases reject
+func (s *ServiceImpl) getUserAgent(ctx context.Context) string {
+	userAgent, _ := ctxutil.GetValue(ctx, "userAgent").(string)
+	userAgent = strings.ToValidUTF8(userAgent, "")
+	if utf8.RuneCountInString(userAgent) > maxUserAgentLen {
+		userAgent = string([]rune(userAgent)[:maxUserAgentLen])
+	}
+	return userAgent
+}
+
+func (s *ServiceImpl) ListLoginEvents(ctx context.Context, userId string, page int, limit int) ([]model.LoginEvent, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+
+	total, err := s.db.CountLoginEvents(ctx, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events, err := s.db.GetLoginEvents(ctx, userId, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,328 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/internal/service/outbox"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const newDeviceEvent = "EVENT.USER.NEW_DEVICE_LOGIN"
+
+// setupMocksForServiceImplTest creates ServiceImpl with mocked dependencies
+func setupMocksForServiceImplTest() (*ServiceImpl, *database.DbMock, *logger.ServiceMock, *outbox.ServiceMock) {
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	outboxServiceMock := new(outbox.ServiceMock)
+	service := &ServiceImpl{
+		db:             dbMock,
+		logService:     logServiceMock,
+		outboxService:  outboxServiceMock,
+		newDeviceEvent: newDeviceEvent,
+	}
+	return service, dbMock, logServiceMock, outboxServiceMock
+}
+
+// genCtxWithClientInfo returns a context containing the client info added by the restapi ctx middleware
+func genCtxWithClientInfo(ip string, userAgent string) context.Context {
+	ctx := ctxutil.AddValue(context.Background(), "clientIp", ip)
+	return ctxutil.AddValue(ctx, "userAgent", userAgent)
+}
+
+func Test_NewService(t *testing.T) {
+	// ARRANGE
+	appConfig := testutil.GetMockAppConfig(&config.AppConfig{NATS_EVENT_USER_NEW_DEVICE: newDeviceEvent})
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	outboxServiceMock := new(outbox.ServiceMock)
+
+	// ACT
+	res := NewService(&appConfig, logServiceMock, dbMock, outboxServiceMock)
+
+	// ASSERT
+	resServiceImpl := res.(*ServiceImpl)
+
+	assert.IsType(t, &ServiceImpl{}, res)
+	assert.Equal(t, newDeviceEvent, resServiceImpl.newDeviceEvent)
+}
+
+func Test_RecordLoginAttempt_Should_Save_Failed_Attempt(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ip := testutil.Fake.Internet().Ipv4()
+	userAgent := testutil.Fake.UserAgent().UserAgent()
+	ctx := genCtxWithClientInfo(ip, userAgent)
+	email := testutil.Fake.Internet().Email()
+	failureReason := model.LoginFailureUserNotFound
+
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: email, FailureReason: &failureReason})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertNotCalled(t, "GetLoginHistoryStats", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	dbMock.AssertCalled(t, "SaveLoginEvent", ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.Id != "" &&
+			event.UserId == nil &&
+			event.Email == email &&
+			!event.Success &&
+			*event.FailureReason == failureReason &&
+			event.Ip == ip &&
+			event.UserAgent == userAgent &&
+			!event.NewDevice
+	}))
+}
+
+func Test_RecordLoginAttempt_Should_Not_Flag_First_Login_As_New_Device(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, outboxServiceMock := setupMocksForServiceImplTest()
+
+	ip := testutil.Fake.Internet().Ipv4()
+	userAgent := testutil.Fake.UserAgent().UserAgent()
+	ctx := genCtxWithClientInfo(ip, userAgent)
+	userId := testutil.Fake.UUID().V4()
+
+	dbMock.On("GetLoginHistoryStats", ctx, userId, ip, userAgent).Return(model.LoginHistoryStats{}, nil)
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", UserId: &userId})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveLoginEvent", ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.Success && !event.NewDevice
+	}))
+	outboxServiceMock.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RecordLoginAttempt_Should_Not_Flag_Known_Device(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, outboxServiceMock := setupMocksForServiceImplTest()
+
+	ip := testutil.Fake.Internet().Ipv4()
+	userAgent := testutil.Fake.UserAgent().UserAgent()
+	ctx := genCtxWithClientInfo(ip, userAgent)
+	userId := testutil.Fake.UUID().V4()
+	stats := model.LoginHistoryStats{SuccessfulLogins: 3, FromSameIp: 2, FromSameDevice: 1}
+
+	dbMock.On("GetLoginHistoryStats", ctx, userId, ip, userAgent).Return(stats, nil)
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", UserId: &userId})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveLoginEvent", ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.Success && !event.NewDevice
+	}))
+	outboxServiceMock.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RecordLoginAttempt_Should_Enqueue_Event_For_New_Device(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, outboxServiceMock := setupMocksForServiceImplTest()
+
+	ip := testutil.Fake.Internet().Ipv4()
+	userAgent := testutil.Fake.UserAgent().UserAgent()
+	ctx := genCtxWithClientInfo(ip, userAgent)
+	userId := testutil.Fake.UUID().V4()
+	stats := model.LoginHistoryStats{SuccessfulLogins: 3, FromSameIp: 0, FromSameDevice: 3}
+
+	dbMock.On("GetLoginHistoryStats", ctx, userId, ip, userAgent).Return(stats, nil)
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+	outboxServiceMock.On("Enqueue", ctx, newDeviceEvent, eventtype.UserNewDeviceLogin, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", UserId: &userId})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveLoginEvent", ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.Success && event.NewDevice
+	}))
+	outboxServiceMock.AssertCalled(t, "Enqueue", ctx, newDeviceEvent, eventtype.UserNewDeviceLogin, eventtype.UserNewDeviceLoginData{
+		UserId:    userId,
+		Email:     "john@doe.com",
+		Ip:        ip,
+		UserAgent: userAgent,
+	})
+}
+
+func Test_RecordLoginAttempt_Should_Not_Enqueue_Event_Without_Subject(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, outboxServiceMock := setupMocksForServiceImplTest()
+	service.newDeviceEvent = ""
+
+	ip := testutil.Fake.Internet().Ipv4()
+	userAgent := testutil.Fake.UserAgent().UserAgent()
+	ctx := genCtxWithClientInfo(ip, userAgent)
+	userId := testutil.Fake.UUID().V4()
+	stats := model.LoginHistoryStats{SuccessfulLogins: 3, FromSameIp: 0, FromSameDevice: 3}
+
+	dbMock.On("GetLoginHistoryStats", ctx, userId, ip, userAgent).Return(stats, nil)
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", UserId: &userId})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveLoginEvent", ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return event.Success && event.NewDevice
+	}))
+	dbMock.AssertNotCalled(t, "WithTx", mock.Anything, mock.Anything)
+	outboxServiceMock.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RecordLoginAttempt_Should_Fail_If_Enqueuing_Fails(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, outboxServiceMock := setupMocksForServiceImplTest()
+
+	ip := testutil.Fake.Internet().Ipv4()
+	ctx := genCtxWithClientInfo(ip, "")
+	userId := testutil.Fake.UUID().V4()
+	stats := model.LoginHistoryStats{SuccessfulLogins: 1, FromSameIp: 0, FromSameDevice: 1}
+	enqueueErr := fmt.Errorf("error from Enqueue")
+
+	dbMock.On("GetLoginHistoryStats", ctx, userId, ip, "").Return(stats, nil)
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+	outboxServiceMock.On("Enqueue", ctx, newDeviceEvent, eventtype.UserNewDeviceLogin, mock.Anything).Return(enqueueErr)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", UserId: &userId})
+
+	// ASSERT
+	assert.Equal(t, enqueueErr, errRes)
+}
+
+func Test_RecordLoginAttempt_Should_Truncate_Long_User_Agent(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ctx := genCtxWithClientInfo("127.0.0.1", strings.Repeat("a", maxUserAgentLen+10))
+	failureReason := model.LoginFailureInvalidPassword
+
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", FailureReason: &failureReason})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveLoginEvent", ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return len(event.UserAgent) == maxUserAgentLen
+	}))
+}
+
+func Test_RecordLoginAttempt_Should_Truncate_User_Agent_On_Character_Boundary(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	// "é" takes two bytes, cutting at a byte would split the last character
+	ctx := genCtxWithClientInfo("127.0.0.1", "a"+strings.Repeat("é", maxUserAgentLen)+"\xff")
+	failureReason := model.LoginFailureInvalidPassword
+
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(nil)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", FailureReason: &failureReason})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveLoginEvent", ctx, mock.MatchedBy(func(event *model.LoginEvent) bool {
+		return utf8.ValidString(event.UserAgent) &&
+			utf8.RuneCountInString(event.UserAgent) == maxUserAgentLen &&
+			event.UserAgent == "a"+strings.Repeat("é", maxUserAgentLen-1)
+	}))
+}
+
+func Test_RecordLoginAttempt_SaveLoginEvent_Returns_Err(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	failureReason := model.LoginFailureUserNotFound
+	saveErr := fmt.Errorf("error from SaveLoginEvent")
+
+	dbMock.On("SaveLoginEvent", ctx, mock.Anything).Return(saveErr)
+
+	// ACT
+	errRes := service.RecordLoginAttempt(ctx, model.LoginAttempt{Email: "john@doe.com", FailureReason: &failureReason})
+
+	// ASSERT
+	assert.Equal(t, saveErr, errRes)
+}
+
+func Test_ListLoginEvents_Should_Return_Page_Of_Events(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	userId := testutil.Fake.UUID().V4()
+	events := []model.LoginEvent{{Id: testutil.Fake.UUID().V4(), UserId: &userId, Success: true}}
+
+	dbMock.On("CountLoginEvents", ctx, userId).Return(11, nil)
+	dbMock.On("GetLoginEvents", ctx, userId, 5, 10).Return(events, nil)
+
+	// ACT
+	eventsRes, totalRes, errRes := service.ListLoginEvents(ctx, userId, 3, 5)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, events, eventsRes)
+	assert.Equal(t, 11, totalRes)
+}
+
+func Test_ListLoginEvents_Should_Use_Defaults_For_Invalid_Page(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	userId := testutil.Fake.UUID().V4()
+
+	dbMock.On("CountLoginEvents", ctx, userId).Return(0, nil)
+	dbMock.On("GetLoginEvents", ctx, userId, DefaultPageLimit, 0).Return([]model.LoginEvent{}, nil)
+
+	// ACT
+	_, _, errRes := service.ListLoginEvents(ctx, userId, 0, 0)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "GetLoginEvents", ctx, userId, DefaultPageLimit, 0)
+}
+
+func Test_ListLoginEvents_CountLoginEvents_Returns_Err(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _, _ := setupMocksForServiceImplTest()
+
+	ctx := context.Background()
+	userId := testutil.Fake.UUID().V4()
+	countErr := fmt.Errorf("error from CountLoginEvents")
+
+	dbMock.On("CountLoginEvents", ctx, userId).Return(0, countErr)
+
+	// ACT
+	eventsRes, totalRes, errRes := service.ListLoginEvents(ctx, userId, 1, 10)
+
+	// ASSERT
+	assert.Equal(t, countErr, errRes)
+	assert.Nil(t, eventsRes)
+	assert.Equal(t, 0, totalRes)
+}
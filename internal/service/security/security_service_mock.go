@@ -0,0 +1,22 @@
+package security
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) RecordLoginAttempt(ctx context.Context, attempt model.LoginAttempt) error {
+	args := s.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (s *ServiceMock) ListLoginEvents(ctx context.Context, userId string, page int, limit int) ([]model.LoginEvent, int, error) {
+	args := s.Called(ctx, userId, page, limit)
+	return args.Get(0).([]model.LoginEvent), args.Int(1), args.Error(2)
+}
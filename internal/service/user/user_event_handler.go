@@ -0,0 +1,38 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/service/idempotency"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+)
+
+const regEventDurable = "user_service_registration"
+
+// NewEventHandlers returns the NATS handlers of the user service, every event is handled once per handler
+func NewEventHandlers(appConfig *config.AppConfig, logService logger.Service, eventRegistry *event.Registry, idempotencyService idempotency.Service) []nats.Handler {
+	return []nats.Handler{
+		{
+			Subject: appConfig.NATS_EVENT_USER_REGISTRATION,
+			Durable: regEventDurable,
+			// the handler does not write to the database yet, its writes would go through the db of the transaction
+			Handle: idempotencyService.Handler(regEventDurable, func(database.Db) nats.HandlerFunc {
+				return event.Handler(eventRegistry, func(ctx context.Context, envelope event.Envelope, data eventtype.UserRegisteredData) error {
+					return handleRegEvent(ctx, logService, envelope, data)
+				})
+			}),
+		},
+	}
+}
+
+func handleRegEvent(ctx context.Context, logService logger.Service, envelope event.Envelope, data eventtype.UserRegisteredData) error {
+	logService.DebugCtx(ctx, fmt.Sprintf("user with id '%s' and email '%s' registered (event '%s')", data.Id, data.Email, envelope.Id))
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/internal/service/idempotency"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupEventHandlers returns the handlers of the user service, recording the processed messages in memory
+func setupEventHandlers(logService logger.Service) (*config.AppConfig, *event.Registry, []nats.Handler) {
+	appConfig := testutil.GetMockAppConfig(nil)
+	eventRegistry, _ := eventtype.NewRegistry()
+	idempotencyService, _ := idempotency.NewService(&appConfig, logService, testutil.NewMemDb())
+
+	return &appConfig, eventRegistry, NewEventHandlers(&appConfig, logService, eventRegistry, idempotencyService)
+}
+
+func Test_NewEventHandlers(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+
+	// ACT
+	appConfig, _, res := setupEventHandlers(logServiceMock)
+
+	// ASSERT
+	registry := nats.NewRegistry()
+	for _, handler := range res {
+		assert.Nil(t, registry.Register(handler))
+	}
+	assert.Equal(t, appConfig.NATS_EVENT_USER_REGISTRATION, res[0].Subject)
+}
+
+func Test_RegEventHandler_Should_Handle_Event(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	_, eventRegistry, handlers := setupEventHandlers(logServiceMock)
+	handler := handlers[0]
+
+	userId := testutil.Fake.UUID().V4()
+	email := testutil.Fake.Internet().Email()
+	envelope, _ := eventRegistry.New(ctxutil.NewCtxWithTraceId("trace-id"), eventtype.UserRegistered, eventtype.UserRegisteredData{Id: userId, Email: email})
+	data, _ := json.Marshal(envelope)
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+
+	// ACT
+	errRes := handler.Handle(context.Background(), nats.Msg{Subject: handler.Subject, Data: data, Headers: envelope.Headers(), NumDelivered: 1})
+	errRedeliveredRes := handler.Handle(context.Background(), nats.Msg{Subject: handler.Subject, Data: data, Headers: envelope.Headers(), NumDelivered: 2})
+
+	// ASSERT
+	expectedLogStr := fmt.Sprintf("user with id '%s' and email '%s' registered (event '%s')", userId, email, envelope.Id)
+
+	assert.Nil(t, errRes)
+	assert.Nil(t, errRedeliveredRes)
+	logServiceMock.AssertCalled(t, "DebugCtx", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctxutil.GetTraceIdFromCtx(ctx) == "trace-id"
+	}), expectedLogStr)
+	logServiceMock.AssertCalled(t, "DebugCtx", mock.Anything, fmt.Sprintf("skipped message '%s' of '%s' already processed by '%s'", envelope.Id, handler.Subject, handler.Durable))
+	logServiceMock.AssertNumberOfCalls(t, "DebugCtx", 2)
+}
+
+func Test_RegEventHandler_Should_Reject_Invalid_Event(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	_, _, handlers := setupEventHandlers(logServiceMock)
+	handler := handlers[0]
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	data := []byte(`{"specversion":"1.0","id":"1","type":"user.registered","dataversion":1,"data":{"email":""}}`)
+
+	// ACT
+	errRes := handler.Handle(context.Background(), nats.Msg{Subject: handler.Subject, Data: data})
+
+	// ASSERT
+	assert.EqualError(t, errRes, "event.Registry.Decode(): invalid 'user.registered': id and email are required")
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/pjmessi/golang-practice/config"
 	"testing"
 	"time"
 
@@ -13,45 +12,38 @@ import (
 	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
 	"github.com/pjmessi/golang-practice/pkg/exception"
 	"github.com/pjmessi/golang-practice/pkg/logger"
-	"github.com/pjmessi/golang-practice/pkg/nats"
 	"github.com/pjmessi/golang-practice/pkg/validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 // setupMocksForFacadeImplTest creates ServiceImpl with mocked dependencies
-func setupMocksForFacadeImplTest() (*FacadeImpl, *ServiceMock, *logger.ServiceMock, *validation.HandlerMock, *nats.PubServiceMock, string) {
+func setupMocksForFacadeImplTest() (*FacadeImpl, *ServiceMock, *logger.ServiceMock, *validation.HandlerMock) {
 	userService := new(ServiceMock)
 	validationUtilMock := new(validation.HandlerMock)
 	logServiceMock := new(logger.ServiceMock)
-	natsService := new(nats.PubServiceMock)
-	userRegEvent := "EVENT.USER.NEW"
 	authFacade := &FacadeImpl{
 		userService:       userService,
 		logService:        logServiceMock,
 		validationHandler: validationUtilMock,
-		natsService:       natsService,
-		userRegEvent:      userRegEvent,
 	}
-	return authFacade, userService, logServiceMock, validationUtilMock, natsService, userRegEvent
+	return authFacade, userService, logServiceMock, validationUtilMock
 }
 
 // setupMocksForNewService returns mocked dependencies for NewService func
-func setupMocksForNewFacade() (config.AppConfig, *logger.ServiceMock, *ServiceMock, *validation.HandlerMock, *nats.PubServiceMock) {
+func setupMocksForNewFacade() (*logger.ServiceMock, *ServiceMock, *validation.HandlerMock) {
 	validationUtilMock := new(validation.HandlerMock)
 	logServiceMock := new(logger.ServiceMock)
 	userServiceMock := new(ServiceMock)
-	natsServiceMock := new(nats.PubServiceMock)
-	appConfigMock := testutil.GetMockAppConfig(nil)
-	return appConfigMock, logServiceMock, userServiceMock, validationUtilMock, natsServiceMock
+	return logServiceMock, userServiceMock, validationUtilMock
 }
 
 func Test_NewFacade(t *testing.T) {
 	// ARRANGE
-	appConfig, logServiceMock, serviceMock, validationUtilMock, natsServiceMock := setupMocksForNewFacade()
+	logServiceMock, serviceMock, validationUtilMock := setupMocksForNewFacade()
 
 	// ACT
-	res := NewFacade(&appConfig, logServiceMock, serviceMock, validationUtilMock, natsServiceMock)
+	res := NewFacade(logServiceMock, serviceMock, validationUtilMock)
 
 	// ASSERT
 	resServiceImpl := res.(*FacadeImpl)
@@ -62,7 +54,7 @@ func Test_NewFacade(t *testing.T) {
 
 func Test_Facade_RegisterUser_Invalid_Struct_In_Req_Bytes(t *testing.T) {
 	// ARRANGE
-	facade, _, _, _, _, _ := setupMocksForFacadeImplTest()
+	facade, _, _, _ := setupMocksForFacadeImplTest()
 
 	ctx := context.Background()
 	var reqByte []byte
@@ -79,7 +71,7 @@ func Test_Facade_RegisterUser_Invalid_Struct_In_Req_Bytes(t *testing.T) {
 
 func Test_Facade_RegisterUser_Invalid_Struct_Data_In_Req_Bytes(t *testing.T) {
 	// ARRANGE
-	facade, _, _, validationUtilMock, _, _ := setupMocksForFacadeImplTest()
+	facade, _, _, validationUtilMock := setupMocksForFacadeImplTest()
 
 	ctx := context.Background()
 	regUserApiReq := testutil.GenMockRegUserApiReq(&model.UserRegApiReq{Email: "invalid_format"})
@@ -102,7 +94,7 @@ func Test_Facade_RegisterUser_Invalid_Struct_Data_In_Req_Bytes(t *testing.T) {
 
 func Test_Facade_RegisterUser_Error_While_Validating_Req_Bytes(t *testing.T) {
 	// ARRANGE
-	facade, _, _, validationUtilMock, _, _ := setupMocksForFacadeImplTest()
+	facade, _, _, validationUtilMock := setupMocksForFacadeImplTest()
 
 	ctx := context.Background()
 	regUserApiReq := testutil.GenMockRegUserApiReq(&model.UserRegApiReq{Email: "invalid_format"})
@@ -123,7 +115,7 @@ func Test_Facade_RegisterUser_Error_While_Validating_Req_Bytes(t *testing.T) {
 
 func Test_Facade_RegisterUser_Err_Creating_User(t *testing.T) {
 	// ARRANGE
-	facade, service, _, validationUtilMock, _, _ := setupMocksForFacadeImplTest()
+	facade, service, _, validationUtilMock := setupMocksForFacadeImplTest()
 
 	ctx := context.Background()
 	regUserApiReq := testutil.GenMockRegUserApiReq(nil)
@@ -131,7 +123,7 @@ func Test_Facade_RegisterUser_Err_Creating_User(t *testing.T) {
 	createUserErr := fmt.Errorf("error from CreateUser")
 
 	validationUtilMock.On("ValidateStruct", regUserApiReq).Return(nil)
-	service.On("CreateUser", ctx, regUserApiReq.Email, regUserApiReq.Password).Return(model.User{}, createUserErr)
+	service.On("CreateUser", ctx, regUserApiReq.Email, regUserApiReq.Password, regUserApiReq.InviteCode).Return(model.User{}, createUserErr)
 
 	// ACT
 	bytesRes, errRes := facade.RegisterUser(ctx, reqBytes)
@@ -143,36 +135,9 @@ func Test_Facade_RegisterUser_Err_Creating_User(t *testing.T) {
 	assert.Nil(t, bytesRes)
 }
 
-func Test_Facade_RegisterUser_Err_Publishing_Event(t *testing.T) {
-	// ARRANGE
-	facade, service, logServiceMock, validationUtilMock, natsServiceMock, userRegistrationEvent := setupMocksForFacadeImplTest()
-
-	email := testutil.Fake.Internet().Email()
-	ctx := context.Background()
-	regUserApiReq := testutil.GenMockRegUserApiReq(&model.UserRegApiReq{Email: email})
-	reqBytes, _ := json.Marshal(regUserApiReq)
-	user := testutil.GenMockUser(&model.User{Email: email})
-	publishErr := fmt.Errorf("error from Publish")
-
-	logServiceMock.On("ErrorCtx", mock.Anything, mock.Anything)
-	validationUtilMock.On("ValidateStruct", regUserApiReq).Return(nil)
-	service.On("CreateUser", ctx, regUserApiReq.Email, regUserApiReq.Password).Return(user, nil)
-	natsServiceMock.On("Publish", userRegistrationEvent, mock.Anything).Return(publishErr)
-
-	// ACT
-	_, errRes := facade.RegisterUser(ctx, reqBytes)
-
-	// ASSERT
-	// should log instead of returning error
-	expectedLogStr := fmt.Sprintf("error publishing 'nats.user.new_registration' nats for userId '%s' and email '%s': %s", user.Id, user.Email, publishErr)
-
-	assert.Equal(t, errRes, nil)
-	logServiceMock.AssertCalled(t, "ErrorCtx", ctx, expectedLogStr)
-}
-
 func Test_Facade_RegisterUser_Success_Res(t *testing.T) {
 	// ARRANGE
-	facade, service, logServiceMock, validationUtilMock, natsServiceMock, userRegistrationEvent := setupMocksForFacadeImplTest()
+	facade, service, logServiceMock, validationUtilMock := setupMocksForFacadeImplTest()
 
 	email := testutil.Fake.Internet().Email()
 	ctx := context.Background()
@@ -182,8 +147,7 @@ func Test_Facade_RegisterUser_Success_Res(t *testing.T) {
 
 	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
 	validationUtilMock.On("ValidateStruct", regUserApiReq).Return(nil)
-	service.On("CreateUser", ctx, regUserApiReq.Email, regUserApiReq.Password).Return(user, nil)
-	natsServiceMock.On("Publish", userRegistrationEvent, mock.Anything).Return(nil)
+	service.On("CreateUser", ctx, regUserApiReq.Email, regUserApiReq.Password, regUserApiReq.InviteCode).Return(user, nil)
 
 	// ACT
 	bytesRes, errRes := facade.RegisterUser(ctx, reqBytes)
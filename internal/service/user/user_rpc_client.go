@@ -0,0 +1,45 @@
+package user
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/rpc"
+)
+
+// RpcClient looks users up through the NATS responders of the user service, see NewRpcResponders
+type RpcClient interface {
+	// GetUser returns the user, exception.NotFound is returned when it does not exist
+	GetUser(ctx context.Context, userId string) (model.UserRes, error)
+	// GetUsers returns the users of up to 100 ids and the ids without a user
+	GetUsers(ctx context.Context, userIds []string) (model.UserBatchGetRpcRes, error)
+}
+
+type RpcClientImpl struct {
+	natsService     nats.Service
+	getSubject      string
+	batchGetSubject string
+}
+
+func NewRpcClient(appConfig *config.AppConfig, natsService nats.Service) RpcClient {
+	return &RpcClientImpl{
+		natsService:     natsService,
+		getSubject:      appConfig.NATS_RPC_USER_GET,
+		batchGetSubject: appConfig.NATS_RPC_USER_BATCH_GET,
+	}
+}
+
+func (c *RpcClientImpl) GetUser(ctx context.Context, userId string) (model.UserRes, error) {
+	res, err := rpc.Call[model.UserGetRpcReq, model.UserGetRpcRes](ctx, c.natsService, c.getSubject, model.UserGetRpcReq{Id: userId})
+	if err != nil {
+		return model.UserRes{}, err
+	}
+
+	return res.User, nil
+}
+
+func (c *RpcClientImpl) GetUsers(ctx context.Context, userIds []string) (model.UserBatchGetRpcRes, error) {
+	return rpc.Call[model.UserBatchGetRpcReq, model.UserBatchGetRpcRes](ctx, c.natsService, c.batchGetSubject, model.UserBatchGetRpcReq{Ids: userIds})
+}
@@ -0,0 +1,22 @@
+package user
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type RpcClientMock struct {
+	mock.Mock
+}
+
+func (c *RpcClientMock) GetUser(ctx context.Context, userId string) (model.UserRes, error) {
+	args := c.Called(ctx, userId)
+	return args.Get(0).(model.UserRes), args.Error(1)
+}
+
+func (c *RpcClientMock) GetUsers(ctx context.Context, userIds []string) (model.UserBatchGetRpcRes, error) {
+	args := c.Called(ctx, userIds)
+	return args.Get(0).(model.UserBatchGetRpcRes), args.Error(1)
+}
@@ -0,0 +1,78 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/dto"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/rpc"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+)
+
+// rpcQueue is the queue group of the responders, every instance of the app joins it so that a request is answered once
+const rpcQueue = "user_service"
+
+// NewRpcResponders returns the NATS responders letting other services look users up without the REST API
+func NewRpcResponders(appConfig *config.AppConfig, logService logger.Service, validationHandler validation.Handler, userService Service) []nats.Responder {
+	return []nats.Responder{
+		{
+			Subject: appConfig.NATS_RPC_USER_GET,
+			Queue:   rpcQueue,
+			Reply: rpc.Handler(logService, validationHandler, func(ctx context.Context, req model.UserGetRpcReq) (model.UserGetRpcRes, error) {
+				return getUser(ctx, userService, req)
+			}),
+		},
+		{
+			Subject: appConfig.NATS_RPC_USER_BATCH_GET,
+			Queue:   rpcQueue,
+			Reply: rpc.Handler(logService, validationHandler, func(ctx context.Context, req model.UserBatchGetRpcReq) (model.UserBatchGetRpcRes, error) {
+				return batchGetUsers(ctx, userService, req)
+			}),
+		},
+	}
+}
+
+func getUser(ctx context.Context, userService Service, req model.UserGetRpcReq) (model.UserGetRpcRes, error) {
+	users, err := userService.GetUsers(ctx, []string{req.Id})
+	if err != nil {
+		return model.UserGetRpcRes{}, err
+	}
+
+	if len(users) == 0 {
+		return model.UserGetRpcRes{}, exception.NewNotFoundFromBase(exception.Base{
+			Type:    errorcode.UserNotFound,
+			Message: fmt.Sprintf("user with id '%s' does not exist", req.Id),
+		})
+	}
+
+	return model.UserGetRpcRes{User: dto.UserToUserRes(&users[0])}, nil
+}
+
+func batchGetUsers(ctx context.Context, userService Service, req model.UserBatchGetRpcReq) (model.UserBatchGetRpcRes, error) {
+	users, err := userService.GetUsers(ctx, req.Ids)
+	if err != nil {
+		return model.UserBatchGetRpcRes{}, err
+	}
+
+	res := model.UserBatchGetRpcRes{Users: []model.UserRes{}, MissingIds: []string{}}
+	found := map[string]bool{}
+	for i := range users {
+		res.Users = append(res.Users, dto.UserToUserRes(&users[i]))
+		found[users[i].Id] = true
+	}
+	for _, userId := range req.Ids {
+		if !found[userId] {
+			res.MissingIds = append(res.MissingIds, userId)
+			// a missing id requested twice is only listed once
+			found[userId] = true
+		}
+	}
+
+	return res, nil
+}
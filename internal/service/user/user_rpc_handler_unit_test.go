@@ -0,0 +1,127 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupRpcClient starts the responders of the user service on an in process event bus and returns a client of them
+func setupRpcClient(t *testing.T) (RpcClient, *ServiceMock) {
+	appConfig := testutil.GetMockAppConfig(nil)
+	appConfig.NATS_DRIVER = "memory"
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("Debug", mock.Anything).Return()
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything).Return()
+	logServiceMock.On("ErrorCtx", mock.Anything, mock.Anything).Return()
+	validationHandler, _ := validation.NewHandler()
+	serviceMock := new(ServiceMock)
+
+	natsService, err := nats.NewService(&appConfig, logServiceMock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(natsService.Close)
+	for _, responder := range NewRpcResponders(&appConfig, logServiceMock, validationHandler, serviceMock) {
+		assert.Nil(t, natsService.Respond(responder))
+	}
+
+	return NewRpcClient(&appConfig, natsService), serviceMock
+}
+
+func Test_RpcClient_GetUser_Should_Return_User(t *testing.T) {
+	// ARRANGE
+	client, serviceMock := setupRpcClient(t)
+	user := testutil.GenMockUser(nil)
+	serviceMock.On("GetUsers", mock.Anything, []string{user.Id}).Return([]model.User{user}, nil)
+
+	// ACT
+	userRes, errRes := client.GetUser(context.Background(), user.Id)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, user.Id, userRes.Id)
+	assert.Equal(t, user.Email, userRes.Email)
+}
+
+func Test_RpcClient_GetUser_Should_Return_NotFound(t *testing.T) {
+	// ARRANGE
+	client, serviceMock := setupRpcClient(t)
+	serviceMock.On("GetUsers", mock.Anything, []string{"unknown"}).Return([]model.User{}, nil)
+
+	// ACT
+	_, errRes := client.GetUser(context.Background(), "unknown")
+
+	// ASSERT
+	assert.Equal(t, exception.NewNotFoundFromBase(exception.Base{
+		Type:    errorcode.UserNotFound,
+		Message: "user with id 'unknown' does not exist",
+	}), errRes)
+}
+
+func Test_RpcClient_GetUser_Should_Return_InvalidReq_Without_Id(t *testing.T) {
+	// ARRANGE
+	client, _ := setupRpcClient(t)
+
+	// ACT
+	_, errRes := client.GetUser(context.Background(), "")
+
+	// ASSERT
+	assert.IsType(t, exception.InvalidReq{}, errRes)
+}
+
+func Test_RpcClient_GetUsers_Should_Return_Missing_Ids(t *testing.T) {
+	// ARRANGE
+	client, serviceMock := setupRpcClient(t)
+	user := testutil.GenMockUser(nil)
+	ids := []string{"unknown", user.Id, "unknown"}
+	serviceMock.On("GetUsers", mock.Anything, ids).Return([]model.User{user}, nil)
+
+	// ACT
+	res, errRes := client.GetUsers(context.Background(), ids)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Len(t, res.Users, 1)
+	assert.Equal(t, user.Id, res.Users[0].Id)
+	assert.Equal(t, []string{"unknown"}, res.MissingIds)
+}
+
+func Test_RpcClient_GetUsers_Should_Limit_Ids(t *testing.T) {
+	// ARRANGE
+	client, _ := setupRpcClient(t)
+	ids := []string{}
+	for i := 0; i < 101; i++ {
+		ids = append(ids, fmt.Sprintf("id-%d", i))
+	}
+
+	// ACT
+	_, errRes := client.GetUsers(context.Background(), ids)
+
+	// ASSERT
+	var invalidReq exception.InvalidReq
+	assert.ErrorAs(t, errRes, &invalidReq)
+	assert.Equal(t, map[string]string{"ids": "validation failed for tag: 'max'"}, *invalidReq.Details)
+}
+
+func Test_RpcClient_GetUsers_Should_Hide_Error_Of_Service(t *testing.T) {
+	// ARRANGE
+	client, serviceMock := setupRpcClient(t)
+	serviceMock.On("GetUsers", mock.Anything, []string{"id"}).Return([]model.User{}, fmt.Errorf("connection refused"))
+
+	// ACT
+	_, errRes := client.GetUsers(context.Background(), []string{"id"})
+
+	// ASSERT
+	assert.EqualError(t, errRes, "rpc INTERNAL: INTERNAL: internal server error")
+}
@@ -7,6 +7,8 @@ import (
 )
 
 type Service interface {
-	CreateUser(ctx context.Context, email string, password string) (model.User, error)
+	CreateUser(ctx context.Context, email string, password string, inviteCode *string) (model.User, error)
 	GetProfile(ctx context.Context, userId string) (model.User, error)
+	// GetUsers returns the users which exist among the ids, in the order of the ids
+	GetUsers(ctx context.Context, userIds []string) ([]model.User, error)
 }
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/pjmessi/golang-practice/config"
 	"github.com/pjmessi/golang-practice/internal/errorcode"
 	"github.com/pjmessi/golang-practice/internal/model"
 	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
 	"github.com/pjmessi/golang-practice/internal/pkg/passwordutil"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/internal/service/outbox"
 	"github.com/pjmessi/golang-practice/pkg/exception"
 	"github.com/pjmessi/golang-practice/pkg/logger"
 	"github.com/pjmessi/golang-practice/pkg/timeutil"
@@ -16,31 +20,39 @@ import (
 )
 
 type ServiceImpl struct {
-	db         database.Db
-	logService logger.Service
+	db            database.Db
+	logService    logger.Service
+	inviteService invite.Service
+	outboxService outbox.Service
+	inviteOnly    bool
+	userRegEvent  string
 }
 
 func NewService(
+	appConfig *config.AppConfig,
 	logService logger.Service,
 	db database.Db,
+	inviteService invite.Service,
+	outboxService outbox.Service,
 ) Service {
 	return &ServiceImpl{
-		db:         db,
-		logService: logService,
+		db:            db,
+		logService:    logService,
+		inviteService: inviteService,
+		outboxService: outboxService,
+		inviteOnly:    appConfig.REGISTRATION_INVITE_ONLY == "true",
+		userRegEvent:  appConfig.NATS_EVENT_USER_REGISTRATION,
 	}
 }
 
-func (s *ServiceImpl) CreateUser(ctx context.Context, email string, password string) (model.User, error) {
+func (s *ServiceImpl) CreateUser(ctx context.Context, email string, password string, inviteCode *string) (model.User, error) {
 	lowercaseEmail := strings.ToLower(email)
 
 	if err := s.ensureStrongPw(ctx, password); err != nil {
 		return model.User{}, err
 	}
 
-	if err := s.ensureEmailNotUsed(ctx, lowercaseEmail); err != nil {
-		return model.User{}, err
-	}
-
+	// hashing is slow so it is done before the transaction starts to keep the locks short
 	hashedPw, err := passwordutil.Hash(password)
 	if err != nil {
 		return model.User{}, err
@@ -51,7 +63,28 @@ func (s *ServiceImpl) CreateUser(ctx context.Context, email string, password str
 		return model.User{}, err
 	}
 
-	err = s.db.SaveUser(ctx, &user)
+	err = s.db.WithTx(ctx, func(txDb database.Db) error {
+		if err := s.ensureEmailNotUsed(ctx, txDb, lowercaseEmail); err != nil {
+			return err
+		}
+
+		invite, err := s.redeemInviteIfRequired(ctx, txDb, lowercaseEmail, inviteCode)
+		if err != nil {
+			return err
+		}
+
+		err = txDb.SaveUser(ctx, &user)
+		if err != nil {
+			return err
+		}
+
+		err = s.joinInvitedOrg(ctx, txDb, invite, user.Id)
+		if err != nil {
+			return err
+		}
+
+		return s.enqueueNewRegEvent(ctx, txDb, user)
+	})
 	if err != nil {
 		return model.User{}, err
 	}
@@ -71,8 +104,8 @@ func (s *ServiceImpl) ensureStrongPw(ctx context.Context, password string) error
 	return nil
 }
 
-func (s *ServiceImpl) ensureEmailNotUsed(ctx context.Context, email string) error {
-	isEmailTaken, err := s.db.IsUserEmailTaken(ctx, email)
+func (s *ServiceImpl) ensureEmailNotUsed(ctx context.Context, db database.Db, email string) error {
+	isEmailTaken, err := db.IsUserEmailTaken(ctx, email)
 	if err != nil {
 		return err
 	}
@@ -89,6 +122,50 @@ func (s *ServiceImpl) ensureEmailNotUsed(ctx context.Context, email string) erro
 	})
 }
 
+func (s *ServiceImpl) redeemInviteIfRequired(ctx context.Context, db database.Db, email string, inviteCode *string) (*model.Invite, error) {
+	if inviteCode != nil && *inviteCode != "" {
+		invite, err := s.inviteService.WithDb(db).RedeemInvite(ctx, *inviteCode, email)
+		if err != nil {
+			return nil, err
+		}
+		return &invite, nil
+	}
+
+	if !s.inviteOnly {
+		return nil, nil
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("user with the email '%s' tried to register without an invite", email))
+
+	return nil, exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteRequired,
+		Message: "registration requires an invite",
+	})
+}
+
+// joinInvitedOrg adds the newly registered user to the organization if they registered with an organization invite
+func (s *ServiceImpl) joinInvitedOrg(ctx context.Context, db database.Db, invite *model.Invite, userId string) error {
+	if invite == nil || invite.OrgId == nil || invite.Role == nil {
+		return nil
+	}
+
+	return db.SaveMembership(ctx, &model.Membership{
+		OrgId:     *invite.OrgId,
+		UserId:    userId,
+		Role:      *invite.Role,
+		CreatedAt: timeutil.GetCurrentTime(),
+	})
+}
+
+// enqueueNewRegEvent saves the registration event in the outbox of the transaction, the event is only published if the
+// user is committed
+func (s *ServiceImpl) enqueueNewRegEvent(ctx context.Context, db database.Db, user model.User) error {
+	return s.outboxService.WithDb(db).Enqueue(ctx, s.userRegEvent, eventtype.UserRegistered, eventtype.UserRegisteredData{
+		Id:    user.Id,
+		Email: user.Email,
+	})
+}
+
 func (s *ServiceImpl) createUser(email string, hashedPw string) (model.User, error) {
 	uuidStr, err := uuidutil.GenUuidV4()
 	if err != nil {
@@ -115,3 +192,13 @@ func (s *ServiceImpl) GetProfile(ctx context.Context, userId string) (model.User
 
 	return user, nil
 }
+
+func (s *ServiceImpl) GetUsers(ctx context.Context, userIds []string) ([]model.User, error) {
+	users, err := s.db.GetUsersByIds(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("found %d of %d users", len(users), len(userIds)))
+	return users, nil
+}
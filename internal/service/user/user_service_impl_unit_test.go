@@ -7,10 +7,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pjmessi/golang-practice/config"
 	"github.com/pjmessi/golang-practice/internal/errorcode"
 	"github.com/pjmessi/golang-practice/internal/model"
 	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
 	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/internal/service/outbox"
 	"github.com/pjmessi/golang-practice/pkg/exception"
 	"github.com/pjmessi/golang-practice/pkg/logger"
 	"github.com/stretchr/testify/assert"
@@ -19,28 +23,50 @@ import (
 
 // setupMocksForServiceImplTest creates ServiceImpl with mocked dependencies
 func setupMocksForServiceImplTest() (*ServiceImpl, *database.DbMock, *logger.ServiceMock) {
+	service, dbMock, logServiceMock, _ := setupMocksForServiceImplTestWithInvite()
+	return service, dbMock, logServiceMock
+}
+
+// setupMocksForServiceImplTestWithInvite creates ServiceImpl with mocked dependencies including the invite service,
+// enqueuing to the outbox always succeeds
+func setupMocksForServiceImplTestWithInvite() (*ServiceImpl, *database.DbMock, *logger.ServiceMock, *invite.ServiceMock) {
+	service, dbMock, logServiceMock, outboxServiceMock := setupMocksForServiceImplTestWithOutbox()
+	outboxServiceMock.On("Enqueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return service, dbMock, logServiceMock, service.inviteService.(*invite.ServiceMock)
+}
+
+// setupMocksForServiceImplTestWithOutbox creates ServiceImpl with mocked dependencies including the outbox service
+func setupMocksForServiceImplTestWithOutbox() (*ServiceImpl, *database.DbMock, *logger.ServiceMock, *outbox.ServiceMock) {
 	dbMock := new(database.DbMock)
 	logServiceMock := new(logger.ServiceMock)
+	inviteServiceMock := new(invite.ServiceMock)
+	outboxServiceMock := new(outbox.ServiceMock)
 	service := &ServiceImpl{
-		db:         dbMock,
-		logService: logServiceMock,
+		db:            dbMock,
+		logService:    logServiceMock,
+		inviteService: inviteServiceMock,
+		outboxService: outboxServiceMock,
+		userRegEvent:  "nats.user.new_registration",
 	}
-	return service, dbMock, logServiceMock
+	return service, dbMock, logServiceMock, outboxServiceMock
 }
 
 // setupMocksForNewService returns mocked dependencies for NewService func
-func setupMocksForNewService() (*database.DbMock, *logger.ServiceMock) {
+func setupMocksForNewService() (config.AppConfig, *database.DbMock, *logger.ServiceMock, *invite.ServiceMock, *outbox.ServiceMock) {
 	dbMock := new(database.DbMock)
 	logServiceMock := new(logger.ServiceMock)
-	return dbMock, logServiceMock
+	inviteServiceMock := new(invite.ServiceMock)
+	outboxServiceMock := new(outbox.ServiceMock)
+	appConfigMock := testutil.GetMockAppConfig(nil)
+	return appConfigMock, dbMock, logServiceMock, inviteServiceMock, outboxServiceMock
 }
 
 func Test_NewService(t *testing.T) {
 	// ARRANGE
-	dbMock, logServiceMock := setupMocksForNewService()
+	appConfig, dbMock, logServiceMock, inviteServiceMock, outboxServiceMock := setupMocksForNewService()
 
 	// ACT
-	res := NewService(logServiceMock, dbMock)
+	res := NewService(&appConfig, logServiceMock, dbMock, inviteServiceMock, outboxServiceMock)
 
 	// ARRANGE
 	resServiceImpl := res.(*ServiceImpl)
@@ -61,7 +87,7 @@ func Test_CreateUser_Email_Already_Taken(t *testing.T) {
 	dbMock.On("IsUserEmailTaken", ctx, email).Return(true, nil)
 
 	// ACT
-	userRes, errRes := service.CreateUser(ctx, email, password)
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
 
 	// ASSERT
 	expectedLogStr := fmt.Sprintf("user with the email '%s' already exists", email)
@@ -89,7 +115,7 @@ func Test_CreateUser_Error_Checking_If_Email_Taken(t *testing.T) {
 	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, errIsUserEmailTaken)
 
 	// ACT
-	userRes, errRes := service.CreateUser(ctx, email, password)
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := model.User{}
@@ -111,7 +137,7 @@ func Test_CreateUser_Weak_Password(t *testing.T) {
 	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, nil)
 
 	// ACT
-	userRes, errRes := service.CreateUser(ctx, email, password)
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
 
 	// ASSERT
 	expectedLogStr := "user did not provide strong password"
@@ -138,7 +164,7 @@ func Test_CreateUser_Should_Save_User_In_Db(t *testing.T) {
 	dbMock.On("SaveUser", ctx, mock.Anything).Return(nil)
 
 	// ACT
-	service.CreateUser(ctx, email, password)
+	service.CreateUser(ctx, email, password, nil)
 
 	// ASSERT
 	dbMock.AssertCalled(t, "SaveUser", ctx, mock.MatchedBy(func(user *model.User) bool {
@@ -165,7 +191,7 @@ func Test_CreateUser_Error_Saving_User_In_Db(t *testing.T) {
 	dbMock.On("SaveUser", ctx, mock.Anything).Return(errCreateUser)
 
 	// ACT
-	userRes, errRes := service.CreateUser(ctx, email, password)
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
 
 	// ASSERT
 	expectedUserRes := model.User{}
@@ -188,7 +214,7 @@ func Test_CreateUser_Success_Res(t *testing.T) {
 	dbMock.On("SaveUser", ctx, mock.Anything).Return(nil)
 
 	// ACT
-	userRes, errRes := service.CreateUser(ctx, email, password)
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
 
 	// ASSERT
 	assert.Equal(t, email, userRes.Email)
@@ -199,3 +225,183 @@ func Test_CreateUser_Success_Res(t *testing.T) {
 	assert.Nil(t, userRes.LastName)
 	assert.Equal(t, nil, errRes)
 }
+
+func Test_CreateUser_Should_Enqueue_Reg_Event(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, outboxServiceMock := setupMocksForServiceImplTestWithOutbox()
+
+	ctx := context.Background()
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	password := "Password123!"
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, nil)
+	dbMock.On("SaveUser", ctx, mock.Anything).Return(nil)
+	outboxServiceMock.On("Enqueue", ctx, service.userRegEvent, eventtype.UserRegistered, mock.Anything).Return(nil)
+
+	// ACT
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
+
+	// ASSERT
+	expectedData := eventtype.UserRegisteredData{Id: userRes.Id, Email: email}
+
+	assert.Nil(t, errRes)
+	outboxServiceMock.AssertCalled(t, "Enqueue", ctx, service.userRegEvent, eventtype.UserRegistered, expectedData)
+}
+
+func Test_CreateUser_Error_Enqueuing_Reg_Event(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, outboxServiceMock := setupMocksForServiceImplTestWithOutbox()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	errEnqueue := fmt.Errorf("error enqueuing event")
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, nil)
+	dbMock.On("SaveUser", ctx, mock.Anything).Return(nil)
+	outboxServiceMock.On("Enqueue", ctx, service.userRegEvent, eventtype.UserRegistered, mock.Anything).Return(errEnqueue)
+
+	// ACT
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
+
+	// ASSERT
+	assert.Equal(t, model.User{}, userRes)
+	assert.Equal(t, errEnqueue, errRes)
+}
+
+func Test_CreateUser_Invite_Required(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+	service.inviteOnly = true
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, nil)
+
+	// ACT
+	userRes, errRes := service.CreateUser(ctx, email, password, nil)
+
+	// ASSERT
+	expectedErrRes := exception.NewFailedPreconditionFromBase(exception.Base{
+		Type:    errorcode.InviteRequired,
+		Message: "registration requires an invite",
+	})
+
+	assert.Equal(t, model.User{}, userRes)
+	assert.Equal(t, expectedErrRes, errRes)
+	dbMock.AssertNotCalled(t, "SaveUser", mock.Anything, mock.Anything)
+}
+
+func Test_CreateUser_Error_Redeeming_Invite(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, inviteServiceMock := setupMocksForServiceImplTestWithInvite()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	inviteCode := "invite-code"
+	errRedeemInvite := exception.NewFailedPreconditionFromBase(exception.Base{Type: errorcode.InviteExhausted})
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, nil)
+	inviteServiceMock.On("RedeemInvite", ctx, inviteCode, email).Return(model.Invite{}, errRedeemInvite)
+
+	// ACT
+	userRes, errRes := service.CreateUser(ctx, email, password, &inviteCode)
+
+	// ASSERT
+	assert.Equal(t, model.User{}, userRes)
+	assert.Equal(t, errRedeemInvite, errRes)
+	dbMock.AssertNotCalled(t, "SaveUser", mock.Anything, mock.Anything)
+}
+
+func Test_CreateUser_With_Invite_Success_Res(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, inviteServiceMock := setupMocksForServiceImplTestWithInvite()
+	service.inviteOnly = true
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	inviteCode := "invite-code"
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, nil)
+	dbMock.On("SaveUser", ctx, mock.Anything).Return(nil)
+	inviteServiceMock.On("RedeemInvite", ctx, inviteCode, email).Return(model.Invite{}, nil)
+
+	// ACT
+	userRes, errRes := service.CreateUser(ctx, email, password, &inviteCode)
+
+	// ASSERT
+	assert.Equal(t, email, userRes.Email)
+	assert.Equal(t, nil, errRes)
+	inviteServiceMock.AssertCalled(t, "RedeemInvite", ctx, inviteCode, email)
+}
+
+func Test_CreateUser_With_Org_Invite_Should_Save_Membership(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock, inviteServiceMock := setupMocksForServiceImplTestWithInvite()
+
+	ctx := context.Background()
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	inviteCode := "invite-code"
+	orgId := testutil.Fake.UUID().V4()
+	role := model.OrgRoleAdmin
+	invite := model.Invite{Code: inviteCode, Email: &email, OrgId: &orgId, Role: &role}
+
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	dbMock.On("IsUserEmailTaken", ctx, email).Return(false, nil)
+	dbMock.On("SaveUser", ctx, mock.Anything).Return(nil)
+	dbMock.On("SaveMembership", ctx, mock.Anything).Return(nil)
+	inviteServiceMock.On("RedeemInvite", ctx, inviteCode, email).Return(invite, nil)
+
+	// ACT
+	userRes, errRes := service.CreateUser(ctx, email, password, &inviteCode)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	dbMock.AssertCalled(t, "SaveMembership", ctx, mock.MatchedBy(func(membership *model.Membership) bool {
+		return membership.OrgId == orgId &&
+			membership.UserId == userRes.Id &&
+			membership.Role == role
+	}))
+}
+
+func Test_GetUsers_Should_Return_Users_Of_Db(t *testing.T) {
+	// ARRANGE
+	service, dbMock, logServiceMock := setupMocksForServiceImplTest()
+	ctx := context.Background()
+	user := testutil.GenMockUser(nil)
+	ids := []string{user.Id, "unknown"}
+	dbMock.On("GetUsersByIds", ctx, ids).Return([]model.User{user}, nil)
+	logServiceMock.On("DebugCtx", ctx, mock.Anything)
+
+	// ACT
+	usersRes, errRes := service.GetUsers(ctx, ids)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, []model.User{user}, usersRes)
+}
+
+func Test_GetUsers_Error_Getting_Users_From_Db(t *testing.T) {
+	// ARRANGE
+	service, dbMock, _ := setupMocksForServiceImplTest()
+	ctx := context.Background()
+	dbErr := fmt.Errorf("error from GetUsersByIds")
+	dbMock.On("GetUsersByIds", ctx, []string{"id"}).Return([]model.User{}, dbErr)
+
+	// ACT
+	usersRes, errRes := service.GetUsers(ctx, []string{"id"})
+
+	// ASSERT
+	assert.Equal(t, dbErr, errRes)
+	assert.Nil(t, usersRes)
+}
@@ -11,8 +11,8 @@ type ServiceMock struct {
 	mock.Mock
 }
 
-func (s *ServiceMock) CreateUser(ctx context.Context, email string, password string) (model.User, error) {
-	args := s.Called(ctx, email, password)
+func (s *ServiceMock) CreateUser(ctx context.Context, email string, password string, inviteCode *string) (model.User, error) {
+	args := s.Called(ctx, email, password, inviteCode)
 	return args.Get(0).(model.User), args.Error(1)
 }
 
@@ -20,3 +20,8 @@ func (s *ServiceMock) GetProfile(ctx context.Context, userId string) (model.User
 	args := s.Called(ctx, userId)
 	return args.Get(0).(model.User), args.Error(1)
 }
+
+func (s *ServiceMock) GetUsers(ctx context.Context, userIds []string) ([]model.User, error) {
+	args := s.Called(ctx, userIds)
+	return args.Get(0).([]model.User), args.Error(1)
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+)
+
+// NewEventHandlers returns the NATS handlers queueing the events for the webhooks, one per event subject. Subjects
+// which are not configured have no events and no handler.
+func NewEventHandlers(appConfig *config.AppConfig, eventRegistry *event.Registry, webhookService Service) []nats.Handler {
+	subjects := []struct {
+		subject string
+		durable string
+	}{
+		{appConfig.NATS_EVENT_USER_REGISTRATION, "webhook_delivery_registration"},
+		{appConfig.NATS_EVENT_USER_NEW_DEVICE, "webhook_delivery_new_device"},
+	}
+
+	handlers := []nats.Handler{}
+	for _, subject := range subjects {
+		if subject.subject == "" {
+			continue
+		}
+
+		handlers = append(handlers, nats.Handler{
+			Subject: subject.subject,
+			Durable: subject.durable,
+			Handle:  newQueueHandler(eventRegistry, webhookService),
+		})
+	}
+
+	return handlers
+}
+
+// newQueueHandler queues the events of any registered type, the deliveries are idempotent so the handler does not
+// need the idempotency service
+func newQueueHandler(eventRegistry *event.Registry, webhookService Service) nats.HandlerFunc {
+	return func(ctx context.Context, msg nats.Msg) error {
+		envelope, data, err := eventRegistry.Decode(msg.Data)
+		if err != nil {
+			return err
+		}
+
+		// webhooks belong to organizations, events which are not about a user belong to none and are not delivered
+		userData, ok := data.(eventtype.UserData)
+		if !ok {
+			return nil
+		}
+
+		if envelope.TraceId != "" {
+			ctx = ctxutil.WithTraceId(ctx, envelope.TraceId)
+		}
+
+		_, err = webhookService.QueueEvent(ctx, envelope, userData.GetUserId(), msg.Data)
+		return err
+	}
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_NewEventHandlers_Should_Skip_Subjects_Not_Configured(t *testing.T) {
+	// ARRANGE
+	appConfig := testutil.GetMockAppConfig(nil)
+	appConfig.NATS_EVENT_USER_NEW_DEVICE = ""
+	eventRegistry, _ := eventtype.NewRegistry()
+
+	// ACT
+	res := NewEventHandlers(&appConfig, eventRegistry, new(ServiceMock))
+	noSubjectsRes := NewEventHandlers(&config.AppConfig{}, eventRegistry, new(ServiceMock))
+
+	// ASSERT
+	assert.Len(t, res, 1)
+	assert.Equal(t, appConfig.NATS_EVENT_USER_REGISTRATION, res[0].Subject)
+	assert.Empty(t, noSubjectsRes)
+}
+
+func Test_QueueHandler_Should_Queue_Event_With_Its_Trace_Id(t *testing.T) {
+	// ARRANGE
+	appConfig := testutil.GetMockAppConfig(nil)
+	eventRegistry, _ := eventtype.NewRegistry()
+	serviceMock := new(ServiceMock)
+	handler := NewEventHandlers(&appConfig, eventRegistry, serviceMock)[0]
+
+	userId := testutil.Fake.UUID().V4()
+	envelope, _ := eventRegistry.New(ctxutil.NewCtxWithTraceId("trace-id"), eventtype.UserRegistered, eventtype.UserRegisteredData{
+		Id:    userId,
+		Email: testutil.Fake.Internet().Email(),
+	})
+	payload, _ := json.Marshal(envelope)
+	decoded, _, _ := eventRegistry.Decode(payload)
+
+	var traceId string
+	serviceMock.On("QueueEvent", mock.Anything, decoded, userId, payload).Run(func(args mock.Arguments) {
+		traceId = ctxutil.GetTraceIdFromCtx(args.Get(0).(context.Context))
+	}).Return(1, nil)
+
+	// ACT
+	errRes := handler.Handle(context.Background(), nats.Msg{Subject: handler.Subject, Data: payload})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, "trace-id", traceId)
+	serviceMock.AssertExpectations(t)
+}
+
+func Test_QueueHandler_Should_Return_Err(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     func(eventRegistry *event.Registry) []byte
+		queueErr error
+	}{
+		{"invalid_event", func(eventRegistry *event.Registry) []byte { return []byte("{}") }, nil},
+		{"queue_err", func(eventRegistry *event.Registry) []byte { _, payload := genEvent(t, eventRegistry); return payload }, fmt.Errorf("error from QueueEvent")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// ARRANGE
+			appConfig := testutil.GetMockAppConfig(nil)
+			eventRegistry, _ := eventtype.NewRegistry()
+			serviceMock := new(ServiceMock)
+			handler := NewEventHandlers(&appConfig, eventRegistry, serviceMock)[0]
+
+			serviceMock.On("QueueEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, testCase.queueErr)
+
+			// ACT
+			errRes := handler.Handle(context.Background(), nats.Msg{Subject: handler.Subject, Data: testCase.data(eventRegistry)})
+
+			// ASSERT
+			assert.NotNil(t, errRes)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+)
+
+// Facade exposes the webhooks of the organization of the membership
+type Facade interface {
+	CreateWebhook(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+	ListWebhooks(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+	DeleteWebhook(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+	ListDeliveries(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+	ListDeliveryAttempts(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+	Redeliver(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error)
+}
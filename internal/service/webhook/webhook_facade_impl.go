@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pjmessi/golang-practice/internal/dto"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/structutil"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+)
+
+type FacadeImpl struct {
+	webhookService    Service
+	validationHandler validation.Handler
+	logService        logger.Service
+}
+
+func NewFacade(logService logger.Service, webhookService Service, validationHandler validation.Handler) Facade {
+	return &FacadeImpl{
+		webhookService:    webhookService,
+		validationHandler: validationHandler,
+		logService:        logService,
+	}
+}
+
+func (f *FacadeImpl) CreateWebhook(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	var req model.CreateWebhookApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	webhook, err := f.webhookService.CreateWebhook(ctx, membership, req.Url, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.CreateWebhookApiRes{
+		Webhook: dto.WebhookToWebhookRes(&webhook),
+		Secret:  webhook.Secret,
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) ListWebhooks(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	webhooks, err := f.webhookService.ListWebhooks(ctx, membership)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.ListWebhooksApiRes{
+		Webhooks: []model.WebhookRes{},
+	}
+	for _, webhook := range webhooks {
+		res.Webhooks = append(res.Webhooks, dto.WebhookToWebhookRes(&webhook))
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) DeleteWebhook(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	var req model.DeleteWebhookApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	err := f.webhookService.DeleteWebhook(ctx, membership, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return structutil.ConvertToBytes(model.DeleteWebhookApiRes{Id: req.Id})
+}
+
+func (f *FacadeImpl) ListDeliveries(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	var req model.ListWebhookDeliveriesApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = DefaultPageLimit
+	}
+
+	deliveries, total, err := f.webhookService.ListDeliveries(ctx, membership, req.WebhookId, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.ListWebhookDeliveriesApiRes{
+		Deliveries: []model.WebhookDeliveryRes{},
+		Pagination: model.PaginationRes{Page: req.Page, Limit: req.Limit, Total: total},
+	}
+	for _, delivery := range deliveries {
+		res.Deliveries = append(res.Deliveries, dto.WebhookDeliveryToWebhookDeliveryRes(&delivery))
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) ListDeliveryAttempts(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	var req model.ListWebhookDeliveryAttemptsApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	attempts, err := f.webhookService.ListDeliveryAttempts(ctx, membership, req.DeliveryId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.ListWebhookDeliveryAttemptsApiRes{
+		Attempts: []model.WebhookDeliveryAttemptRes{},
+	}
+	for _, attempt := range attempts {
+		res.Attempts = append(res.Attempts, dto.WebhookDeliveryAttemptToWebhookDeliveryAttemptRes(&attempt))
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) Redeliver(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	var req model.RedeliverWebhookApiReq
+	if err := f.parseReq(reqBytes, &req); err != nil {
+		return nil, err
+	}
+
+	delivery, err := f.webhookService.Redeliver(ctx, membership, req.DeliveryId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := model.RedeliverWebhookApiRes{
+		Delivery: dto.WebhookDeliveryToWebhookDeliveryRes(&delivery),
+	}
+
+	return structutil.ConvertToBytes(res)
+}
+
+func (f *FacadeImpl) parseReq(reqBytes []byte, req interface{}) error {
+	err := structutil.ConvertFromBytes(reqBytes, req)
+	if err != nil {
+		return exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+	}
+
+	err = f.validationHandler.ValidateStruct(req)
+	if err != nil {
+		var valErr validation.ValidationError
+		if errors.As(err, &valErr) {
+			return exception.NewInvalidReqFromBase(exception.Base{
+				Details: &valErr.Details,
+			})
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupMocksForFacadeImplTest creates FacadeImpl with mocked dependencies
+func setupMocksForFacadeImplTest() (*FacadeImpl, *ServiceMock, *validation.HandlerMock) {
+	webhookServiceMock := new(ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+	facade := &FacadeImpl{
+		webhookService:    webhookServiceMock,
+		validationHandler: validationHandlerMock,
+		logService:        new(logger.ServiceMock),
+	}
+	return facade, webhookServiceMock, validationHandlerMock
+}
+
+func Test_NewFacade(t *testing.T) {
+	// ARRANGE
+	logServiceMock := new(logger.ServiceMock)
+	serviceMock := new(ServiceMock)
+	validationHandlerMock := new(validation.HandlerMock)
+
+	// ACT
+	res := NewFacade(logServiceMock, serviceMock, validationHandlerMock)
+
+	// ASSERT
+	assert.IsType(t, &FacadeImpl{}, res)
+}
+
+func Test_Facade_CreateWebhook_Invalid_Struct_In_Req_Bytes(t *testing.T) {
+	// ARRANGE
+	facade, _, _ := setupMocksForFacadeImplTest()
+
+	// ACT
+	bytesRes, errRes := facade.CreateWebhook(context.Background(), nil, jwt.JwtPayload{}, model.Membership{})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Message: errorcode.ReqDataMissing})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_CreateWebhook_Invalid_Struct_Data_In_Req_Bytes(t *testing.T) {
+	// ARRANGE
+	facade, _, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	req := model.CreateWebhookApiReq{Url: "example"}
+	validationErrDetails := map[string]string{"Url": "validation failed for tag: 'url'"}
+
+	validationHandlerMock.On("ValidateStruct", &req).Return(validation.ValidationError{Details: validationErrDetails})
+
+	// ACT
+	bytesRes, errRes := facade.CreateWebhook(context.Background(), []byte(`{"url":"example"}`), jwt.JwtPayload{}, model.Membership{})
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Details: &validationErrDetails})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+}
+
+func Test_Facade_CreateWebhook_Should_Return_Webhook_And_Secret(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	membership := model.Membership{OrgId: testutil.Fake.UUID().V4(), UserId: testutil.Fake.UUID().V4(), Role: model.OrgRoleOwner}
+	req := model.CreateWebhookApiReq{Url: "https://example.com", EventTypes: []string{model.AllEventTypes}}
+	webhook := model.Webhook{
+		Id:         testutil.Fake.UUID().V4(),
+		OrgId:      membership.OrgId,
+		Url:        req.Url,
+		Secret:     "whsec_secret",
+		EventTypes: req.EventTypes,
+		CreatedBy:  membership.UserId,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+
+	validationHandlerMock.On("ValidateStruct", &req).Return(nil)
+	serviceMock.On("CreateWebhook", ctx, membership, req.Url, req.EventTypes).Return(webhook, nil)
+
+	// ACT
+	bytesRes, errRes := facade.CreateWebhook(ctx, []byte(`{"url":"https://example.com","eventTypes":["*"]}`), jwt.JwtPayload{}, membership)
+
+	// ASSERT
+	expectedRes := model.CreateWebhookApiRes{
+		Webhook: model.WebhookRes{
+			Id:         webhook.Id,
+			Url:        webhook.Url,
+			EventTypes: webhook.EventTypes,
+			CreatedBy:  webhook.CreatedBy,
+			CreatedAt:  webhook.CreatedAt.Format(time.RFC3339),
+		},
+		Secret: webhook.Secret,
+	}
+	expectedBytes, _ := json.Marshal(expectedRes)
+
+	assert.Nil(t, errRes)
+	assert.Equal(t, expectedBytes, bytesRes)
+}
+
+func Test_Facade_ListDeliveries_Should_Reject_Page_Beyond_Max(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, _ := setupMocksForFacadeImplTest()
+	validationHandler, _ := validation.NewHandler()
+	facade.validationHandler = validationHandler
+
+	ctx := context.Background()
+	membership := model.Membership{OrgId: testutil.Fake.UUID().V4(), Role: model.OrgRoleAdmin}
+	reqBytes := []byte(fmt.Sprintf(`{"webhookId":"%s","page":"9223372036854775807"}`, testutil.Fake.UUID().V4()))
+
+	// ACT
+	bytesRes, errRes := facade.ListDeliveries(ctx, reqBytes, jwt.JwtPayload{}, membership)
+
+	// ASSERT
+	expectedErr := exception.NewInvalidReqFromBase(exception.Base{Details: &map[string]string{"Page": "validation failed for tag: 'max'"}})
+
+	assert.Equal(t, expectedErr, errRes)
+	assert.Nil(t, bytesRes)
+	serviceMock.AssertNotCalled(t, "ListDeliveries")
+}
+
+func Test_Facade_ListDeliveries_Should_Use_Default_Pagination(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	membership := model.Membership{OrgId: testutil.Fake.UUID().V4(), Role: model.OrgRoleAdmin}
+	req := model.ListWebhookDeliveriesApiReq{WebhookId: testutil.Fake.UUID().V4()}
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	delivery := model.WebhookDelivery{
+		Id:            testutil.Fake.UUID().V4(),
+		WebhookId:     req.WebhookId,
+		EventId:       testutil.Fake.UUID().V4(),
+		EventType:     "user.registered",
+		Status:        model.WebhookDeliveryPending,
+		NextAttemptAt: createdAt,
+		CreatedAt:     createdAt,
+	}
+
+	validationHandlerMock.On("ValidateStruct", &req).Return(nil)
+	serviceMock.On("ListDeliveries", ctx, membership, req.WebhookId, 1, DefaultPageLimit).Return([]model.WebhookDelivery{delivery}, 1, nil)
+
+	// ACT
+	bytesRes, errRes := facade.ListDeliveries(ctx, []byte(fmt.Sprintf(`{"webhookId":"%s"}`, req.WebhookId)), jwt.JwtPayload{}, membership)
+
+	// ASSERT
+	var res model.ListWebhookDeliveriesApiRes
+	_ = json.Unmarshal(bytesRes, &res)
+
+	nextAttemptAt := createdAt.Format(time.RFC3339)
+	assert.Nil(t, errRes)
+	assert.Equal(t, model.PaginationRes{Page: 1, Limit: DefaultPageLimit, Total: 1}, res.Pagination)
+	assert.Equal(t, []model.WebhookDeliveryRes{{
+		Id:            delivery.Id,
+		WebhookId:     delivery.WebhookId,
+		EventId:       delivery.EventId,
+		EventType:     delivery.EventType,
+		Status:        delivery.Status,
+		NextAttemptAt: &nextAttemptAt,
+		CreatedAt:     createdAt.Format(time.RFC3339),
+	}}, res.Deliveries)
+}
+
+func Test_Facade_Redeliver_Service_Returns_Err(t *testing.T) {
+	// ARRANGE
+	facade, serviceMock, validationHandlerMock := setupMocksForFacadeImplTest()
+
+	ctx := context.Background()
+	membership := model.Membership{OrgId: testutil.Fake.UUID().V4(), Role: model.OrgRoleAdmin}
+	req := model.RedeliverWebhookApiReq{DeliveryId: testutil.Fake.UUID().V4()}
+	redeliverErr := fmt.Errorf("error from Redeliver")
+
+	validationHandlerMock.On("ValidateStruct", &req).Return(nil)
+	serviceMock.On("Redeliver", ctx, membership, req.DeliveryId).Return(model.WebhookDelivery{}, redeliverErr)
+
+	// ACT
+	bytesRes, errRes := facade.Redeliver(ctx, []byte(fmt.Sprintf(`{"deliveryId":"%s"}`, req.DeliveryId)), jwt.JwtPayload{}, membership)
+
+	// ASSERT
+	assert.Equal(t, redeliverErr, errRes)
+	assert.Nil(t, bytesRes)
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/stretchr/testify/mock"
+)
+
+type FacadeMock struct {
+	mock.Mock
+}
+
+func (f *FacadeMock) CreateWebhook(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) ListWebhooks(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) DeleteWebhook(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) ListDeliveries(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) ListDeliveryAttempts(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (f *FacadeMock) Redeliver(ctx context.Context, reqBytes []byte, jwtPayload jwt.JwtPayload, membership model.Membership) ([]byte, error) {
+	args := f.Called(ctx, reqBytes, jwtPayload, membership)
+	return args.Get(0).([]byte), args.Error(1)
+}
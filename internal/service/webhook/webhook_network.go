@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddr is returned when a webhook targets an address of the host or of its private networks, webhooks are
+// registered by the organizations so they must not reach the internal services
+var ErrPrivateAddr = errors.New("webhook: the address is not public")
+
+// isPublicIp returns false for loopback, private, link local (which includes the metadata services of the cloud
+// providers), unspecified and multicast addresses
+func isPublicIp(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified() ||
+		ip.IsMulticast())
+}
+
+// checkDialAddr is the Control of the dialer, it runs after the host is resolved so a DNS record pointing to a private
+// address is refused as well, whichever address it resolves to at the time of the delivery
+func checkDialAddr(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIp(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddr, host)
+	}
+
+	return nil
+}
+
+// newHttpClient returns a client which does not follow redirects, an endpoint has to accept the delivery itself.
+// Unless allowPrivateNetworks is true, connections to addresses which are not public are refused.
+func newHttpClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = checkDialAddr
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// a proxy would connect to the endpoint on behalf of the worker, out of reach of the dialer
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
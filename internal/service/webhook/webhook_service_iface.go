@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/pkg/event"
+)
+
+// Service manages the webhooks of the organizations, only their owners and admins can see and change them
+type Service interface {
+	// CreateWebhook subscribes the url to the event types, model.AllEventTypes subscribes it to every type. The secret
+	// signing the payloads is generated here and can only be read from the returned webhook.
+	CreateWebhook(ctx context.Context, member model.Membership, url string, eventTypes []string) (model.Webhook, error)
+	ListWebhooks(ctx context.Context, member model.Membership) ([]model.Webhook, error)
+	// DeleteWebhook deletes the webhook with its deliveries, the deliveries being sent are not interrupted
+	DeleteWebhook(ctx context.Context, member model.Membership, webhookId string) error
+	// ListDeliveries returns the deliveries of the webhook, most recent first
+	ListDeliveries(ctx context.Context, member model.Membership, webhookId string, page int, limit int) (deliveries []model.WebhookDelivery, total int, err error)
+	ListDeliveryAttempts(ctx context.Context, member model.Membership, deliveryId string) ([]model.WebhookDeliveryAttempt, error)
+	// Redeliver sends the delivery again with a new set of attempts, whether it has succeeded, failed or is pending
+	Redeliver(ctx context.Context, member model.Membership, deliveryId string) (model.WebhookDelivery, error)
+
+	// QueueEvent creates a delivery of the event of the user for every webhook of the organizations of the user
+	// subscribed to its type and returns how many were created, an event is queued once per webhook however many times
+	// it is queued
+	QueueEvent(ctx context.Context, envelope event.Envelope, userId string, payload []byte) (int, error)
+}
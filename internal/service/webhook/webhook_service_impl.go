@@ -0,0 +1,308 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/randutil"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+)
+
+const DefaultPageLimit = 20
+
+const secretPrefix = "whsec_"
+const secretByteLen = 24
+
+type ServiceImpl struct {
+	db                   database.Db
+	logService           logger.Service
+	eventRegistry        *event.Registry
+	allowPrivateNetworks bool
+}
+
+func NewService(appConfig *config.AppConfig, logService logger.Service, db database.Db, eventRegistry *event.Registry) Service {
+	return &ServiceImpl{
+		db:                   db,
+		logService:           logService,
+		eventRegistry:        eventRegistry,
+		allowPrivateNetworks: appConfig.WEBHOOK_ALLOW_PRIVATE_NETWORKS == "true",
+	}
+}
+
+func (s *ServiceImpl) CreateWebhook(ctx context.Context, member model.Membership, webhookUrl string, eventTypes []string) (model.Webhook, error) {
+	if err := s.ensureCanManageWebhooks(ctx, member); err != nil {
+		return model.Webhook{}, err
+	}
+
+	if err := s.validateUrl(webhookUrl); err != nil {
+		return model.Webhook{}, err
+	}
+
+	if err := s.validateEventTypes(eventTypes); err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhookId, err := uuidutil.GenUuidV4()
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	secret, err := randutil.GenSecureToken(secretByteLen)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhook := model.Webhook{
+		Id:         webhookId,
+		OrgId:      member.OrgId,
+		Url:        webhookUrl,
+		Secret:     secretPrefix + secret,
+		EventTypes: eventTypes,
+		CreatedBy:  member.UserId,
+		CreatedAt:  timeutil.GetCurrentTime(),
+	}
+
+	err = s.db.SaveWebhook(ctx, &webhook)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("webhook '%s' created in organization '%s' for %v", webhook.Id, webhook.OrgId, webhook.EventTypes))
+
+	return webhook, nil
+}
+
+// validateUrl only accepts absolute http and https urls, and rejects the hosts which are not public so that the
+// registration fails early. Host names are checked again by the worker when it connects, as they may resolve to another
+// address by then.
+func (s *ServiceImpl) validateUrl(webhookUrl string) error {
+	parsedUrl, err := url.Parse(webhookUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return exception.NewInvalidReqFromBase(exception.Base{
+			Type:    errorcode.WebhookInvalidUrl,
+			Message: fmt.Sprintf("'%s' is not an http or https url", webhookUrl),
+		})
+	}
+
+	if s.allowPrivateNetworks {
+		return nil
+	}
+
+	host := parsedUrl.Hostname()
+	ip := net.ParseIP(host)
+	if strings.EqualFold(host, "localhost") || (ip != nil && !isPublicIp(ip)) {
+		return exception.NewInvalidReqFromBase(exception.Base{
+			Type:    errorcode.WebhookInvalidUrl,
+			Message: fmt.Sprintf("'%s' is not a public url", webhookUrl),
+		})
+	}
+
+	return nil
+}
+
+// validateEventTypes makes sure that every event type is known to the registry, so that typos do not silently
+// subscribe to nothing
+func (s *ServiceImpl) validateEventTypes(eventTypes []string) error {
+	knownTypes := s.eventRegistry.Types()
+	for _, eventType := range eventTypes {
+		if eventType != model.AllEventTypes && !slices.Contains(knownTypes, eventType) {
+			return exception.NewInvalidReqFromBase(exception.Base{
+				Type:    errorcode.WebhookInvalidEventType,
+				Message: fmt.Sprintf("unknown event type '%s', expected '%s' or one of %v", eventType, model.AllEventTypes, knownTypes),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) ListWebhooks(ctx context.Context, member model.Membership) ([]model.Webhook, error) {
+	if err := s.ensureCanManageWebhooks(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return s.db.GetOrgWebhooks(ctx, member.OrgId)
+}
+
+func (s *ServiceImpl) DeleteWebhook(ctx context.Context, member model.Membership, webhookId string) error {
+	if err := s.ensureCanManageWebhooks(ctx, member); err != nil {
+		return err
+	}
+
+	deleted, err := s.db.DeleteWebhook(ctx, member.OrgId, webhookId)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return s.newWebhookNotFound(ctx, member, webhookId)
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("webhook '%s' deleted from organization '%s'", webhookId, member.OrgId))
+
+	return nil
+}
+
+func (s *ServiceImpl) ListDeliveries(ctx context.Context, member model.Membership, webhookId string, page int, limit int) ([]model.WebhookDelivery, int, error) {
+	if err := s.ensureCanManageWebhooks(ctx, member); err != nil {
+		return nil, 0, err
+	}
+
+	exists, _, err := s.db.GetWebhook(ctx, member.OrgId, webhookId)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !exists {
+		return nil, 0, s.newWebhookNotFound(ctx, member, webhookId)
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+
+	total, err := s.db.CountWebhookDeliveries(ctx, member.OrgId, webhookId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, err := s.db.GetWebhookDeliveries(ctx, member.OrgId, webhookId, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+func (s *ServiceImpl) ListDeliveryAttempts(ctx context.Context, member model.Membership, deliveryId string) ([]model.WebhookDeliveryAttempt, error) {
+	if _, err := s.getDelivery(ctx, member, deliveryId); err != nil {
+		return nil, err
+	}
+
+	return s.db.GetWebhookDeliveryAttempts(ctx, member.OrgId, deliveryId)
+}
+
+func (s *ServiceImpl) Redeliver(ctx context.Context, member model.Membership, deliveryId string) (model.WebhookDelivery, error) {
+	delivery, err := s.getDelivery(ctx, member, deliveryId)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	// the attempts made so far stay in the log of the delivery
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = timeutil.GetCurrentTime()
+	err = s.db.UpdateWebhookDelivery(ctx, &delivery)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("delivery '%s' of webhook '%s' queued again by user '%s'", delivery.Id, delivery.WebhookId, member.UserId))
+
+	return delivery, nil
+}
+
+// getDelivery returns the delivery if it belongs to the organization of the member and the member can manage webhooks
+func (s *ServiceImpl) getDelivery(ctx context.Context, member model.Membership, deliveryId string) (model.WebhookDelivery, error) {
+	if err := s.ensureCanManageWebhooks(ctx, member); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	exists, delivery, err := s.db.GetWebhookDelivery(ctx, member.OrgId, deliveryId)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	if !exists {
+		s.logService.DebugCtx(ctx, fmt.Sprintf("delivery '%s' does not exist in organization '%s'", deliveryId, member.OrgId))
+		return model.WebhookDelivery{}, exception.NewNotFoundFromBase(exception.Base{
+			Type:    errorcode.WebhookDeliveryNotFound,
+			Message: fmt.Sprintf("delivery with id '%s' does not exist", deliveryId),
+		})
+	}
+
+	return delivery, nil
+}
+
+func (s *ServiceImpl) QueueEvent(ctx context.Context, envelope event.Envelope, userId string, payload []byte) (int, error) {
+	// the events of a user are only delivered to the organizations of the user, the webhooks of other organizations
+	// must never receive them
+	webhooks, err := s.db.GetWebhooksOfUserOrgs(ctx, userId)
+	if err != nil {
+		return 0, fmt.Errorf("webhook.QueueEvent(): %w", err)
+	}
+
+	queued := 0
+	currentTime := timeutil.GetCurrentTime()
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.EventTypes, envelope.Type) && !slices.Contains(webhook.EventTypes, model.AllEventTypes) {
+			continue
+		}
+
+		deliveryId, err := uuidutil.GenUuidV4()
+		if err != nil {
+			return queued, err
+		}
+
+		// the deliveries queued before a failure are skipped when the message is redelivered
+		saved, err := s.db.SaveWebhookDelivery(ctx, &model.WebhookDelivery{
+			Id:            deliveryId,
+			WebhookId:     webhook.Id,
+			OrgId:         webhook.OrgId,
+			EventId:       envelope.Id,
+			EventType:     envelope.Type,
+			Payload:       payload,
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: currentTime,
+			CreatedAt:     currentTime,
+		})
+		if err != nil {
+			return queued, fmt.Errorf("webhook.QueueEvent(): %w", err)
+		}
+		if saved {
+			queued++
+		}
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("event '%s' of type '%s' queued for %d webhooks", envelope.Id, envelope.Type, queued))
+
+	return queued, nil
+}
+
+// ensureCanManageWebhooks makes sure that only owners and admins manage the webhooks, as they receive the events of
+// every user
+func (s *ServiceImpl) ensureCanManageWebhooks(ctx context.Context, member model.Membership) error {
+	if member.Role == model.OrgRoleOwner || member.Role == model.OrgRoleAdmin {
+		return nil
+	}
+
+	s.logService.DebugCtx(ctx, fmt.Sprintf("user '%s' with role '%s' cannot manage the webhooks of organization '%s'", member.UserId, member.Role, member.OrgId))
+
+	return exception.NewUnauthorized(exception.Base{
+		Type:    errorcode.OrgPermissionDenied,
+		Message: fmt.Sprintf("role '%s' cannot manage webhooks", member.Role),
+	})
+}
+
+func (s *ServiceImpl) newWebhookNotFound(ctx context.Context, member model.Membership, webhookId string) error {
+	s.logService.DebugCtx(ctx, fmt.Sprintf("webhook '%s' does not exist in organization '%s'", webhookId, member.OrgId))
+
+	return exception.NewNotFoundFromBase(exception.Base{
+		Type:    errorcode.WebhookNotFound,
+		Message: fmt.Sprintf("webhook with id '%s' does not exist", webhookId),
+	})
+}
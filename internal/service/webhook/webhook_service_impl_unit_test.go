@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupServiceImplTest creates ServiceImpl on an in memory database
+func setupServiceImplTest() (*ServiceImpl, *testutil.MemDb, *logger.ServiceMock, *event.Registry) {
+	memDb := testutil.NewMemDb()
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything)
+	eventRegistry, _ := eventtype.NewRegistry()
+	service := &ServiceImpl{
+		db:            memDb,
+		logService:    logServiceMock,
+		eventRegistry: eventRegistry,
+	}
+	return service, memDb, logServiceMock, eventRegistry
+}
+
+func genMembership(role string) model.Membership {
+	return model.Membership{
+		OrgId:  testutil.Fake.UUID().V4(),
+		UserId: testutil.Fake.UUID().V4(),
+		Role:   role,
+	}
+}
+
+// genEvent returns a user registered event and its encoded payload
+func genEvent(t *testing.T, eventRegistry *event.Registry) (event.Envelope, []byte) {
+	return genUserEvent(t, eventRegistry, testutil.Fake.UUID().V4())
+}
+
+// genUserEvent returns the registered event of the user and its encoded payload
+func genUserEvent(t *testing.T, eventRegistry *event.Registry, userId string) (event.Envelope, []byte) {
+	envelope, err := eventRegistry.New(context.Background(), eventtype.UserRegistered, eventtype.UserRegisteredData{
+		Id:    userId,
+		Email: testutil.Fake.Internet().Email(),
+	})
+	assert.Nil(t, err)
+	payload, err := json.Marshal(envelope)
+	assert.Nil(t, err)
+	return envelope, payload
+}
+
+// getErrType returns the type of the exceptions returned by the service
+func getErrType(err error) string {
+	switch ex := err.(type) {
+	case exception.InvalidReq:
+		return ex.Type
+	case exception.NotFound:
+		return ex.Type
+	case exception.Unauthorized:
+		return ex.Type
+	}
+	return ""
+}
+
+func Test_NewService(t *testing.T) {
+	// ARRANGE
+	dbMock := new(database.DbMock)
+	logServiceMock := new(logger.ServiceMock)
+	eventRegistry, _ := eventtype.NewRegistry()
+	appConfig := testutil.GetMockAppConfig(nil)
+
+	// ACT
+	res := NewService(&appConfig, logServiceMock, dbMock, eventRegistry)
+
+	// ASSERT
+	assert.IsType(t, &ServiceImpl{}, res)
+}
+
+func Test_CreateWebhook_Should_Save_Webhook_With_A_Secret(t *testing.T) {
+	// ARRANGE
+	service, memDb, _, _ := setupServiceImplTest()
+	ctx := context.Background()
+	member := genMembership(model.OrgRoleAdmin)
+
+	// ACT
+	res, errRes := service.CreateWebhook(ctx, member, "https://example.com/hooks", []string{eventtype.UserRegistered})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, member.OrgId, res.OrgId)
+	assert.Equal(t, member.UserId, res.CreatedBy)
+	assert.Regexp(t, "^whsec_.+", res.Secret)
+
+	exists, saved, err := memDb.GetWebhook(ctx, member.OrgId, res.Id)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, res.Secret, saved.Secret)
+	assert.Equal(t, []string{eventtype.UserRegistered}, saved.EventTypes)
+}
+
+func Test_CreateWebhook_Should_Reject_Invalid_Req(t *testing.T) {
+	testCases := []struct {
+		name       string
+		role       string
+		url        string
+		eventTypes []string
+		errType    string
+	}{
+		{"member", model.OrgRoleMember, "https://example.com", []string{model.AllEventTypes}, errorcode.OrgPermissionDenied},
+		{"scheme", model.OrgRoleOwner, "ftp://example.com", []string{model.AllEventTypes}, errorcode.WebhookInvalidUrl},
+		{"relative", model.OrgRoleOwner, "/hooks", []string{model.AllEventTypes}, errorcode.WebhookInvalidUrl},
+		{"loopback", model.OrgRoleOwner, "http://127.0.0.1:3000/debug/vars", []string{model.AllEventTypes}, errorcode.WebhookInvalidUrl},
+		{"localhost", model.OrgRoleOwner, "http://localhost/hooks", []string{model.AllEventTypes}, errorcode.WebhookInvalidUrl},
+		{"metadata", model.OrgRoleOwner, "http://169.254.169.254/latest/meta-data", []string{model.AllEventTypes}, errorcode.WebhookInvalidUrl},
+		{"private", model.OrgRoleOwner, "https://10.0.0.5/hooks", []string{model.AllEventTypes}, errorcode.WebhookInvalidUrl},
+		{"ipv6_loopback", model.OrgRoleOwner, "http://[::1]:8080/hooks", []string{model.AllEventTypes}, errorcode.WebhookInvalidUrl},
+		{"event_type", model.OrgRoleOwner, "https://example.com", []string{"user.unknown"}, errorcode.WebhookInvalidEventType},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// ARRANGE
+			service, memDb, _, _ := setupServiceImplTest()
+			ctx := context.Background()
+			member := genMembership(testCase.role)
+
+			// ACT
+			_, errRes := service.CreateWebhook(ctx, member, testCase.url, testCase.eventTypes)
+
+			// ASSERT
+			assert.Equal(t, testCase.errType, getErrType(errRes))
+
+			webhooks, _ := memDb.GetOrgWebhooks(ctx, member.OrgId)
+			assert.Empty(t, webhooks)
+		})
+	}
+}
+
+func Test_DeleteWebhook_Should_Return_Not_Found_For_Webhook_Of_Another_Org(t *testing.T) {
+	// ARRANGE
+	service, memDb, _, _ := setupServiceImplTest()
+	ctx := context.Background()
+	member := genMembership(model.OrgRoleOwner)
+	webhook, _ := service.CreateWebhook(ctx, member, "https://example.com", []string{model.AllEventTypes})
+
+	// ACT
+	errRes := service.DeleteWebhook(ctx, genMembership(model.OrgRoleOwner), webhook.Id)
+	errDeletedRes := service.DeleteWebhook(ctx, member, webhook.Id)
+
+	// ASSERT
+	assert.IsType(t, exception.NotFound{}, errRes)
+	assert.Equal(t, errorcode.WebhookNotFound, getErrType(errRes))
+	assert.Nil(t, errDeletedRes)
+
+	exists, _, _ := memDb.GetWebhook(ctx, member.OrgId, webhook.Id)
+	assert.False(t, exists)
+}
+
+func Test_QueueEvent_Should_Queue_Matching_Webhooks_Once(t *testing.T) {
+	// ARRANGE
+	service, memDb, _, eventRegistry := setupServiceImplTest()
+	ctx := context.Background()
+	member := genMembership(model.OrgRoleOwner)
+	allWebhook, _ := service.CreateWebhook(ctx, member, "https://example.com/all", []string{model.AllEventTypes})
+	regWebhook, _ := service.CreateWebhook(ctx, member, "https://example.com/reg", []string{eventtype.UserRegistered})
+	_, _ = service.CreateWebhook(ctx, member, "https://example.com/login", []string{eventtype.UserNewDeviceLogin})
+	_ = memDb.SaveMembership(ctx, &member)
+	envelope, payload := genUserEvent(t, eventRegistry, member.UserId)
+
+	// ACT
+	res, errRes := service.QueueEvent(ctx, envelope, member.UserId, payload)
+	redeliveredRes, errRedeliveredRes := service.QueueEvent(ctx, envelope, member.UserId, payload)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 2, res)
+	assert.Nil(t, errRedeliveredRes)
+	assert.Equal(t, 0, redeliveredRes)
+
+	for _, webhook := range []model.Webhook{allWebhook, regWebhook} {
+		deliveries, total, err := service.ListDeliveries(ctx, member, webhook.Id, 1, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, envelope.Id, deliveries[0].EventId)
+		assert.Equal(t, model.WebhookDeliveryPending, deliveries[0].Status)
+		assert.Equal(t, payload, deliveries[0].Payload)
+	}
+
+	pending, _ := memDb.GetPendingWebhookDeliveries(ctx, time.Now().Add(time.Minute), 10)
+	assert.Len(t, pending, 2)
+}
+
+func Test_QueueEvent_Should_Only_Queue_Webhooks_Of_The_Orgs_Of_The_User(t *testing.T) {
+	// ARRANGE
+	service, memDb, _, eventRegistry := setupServiceImplTest()
+	ctx := context.Background()
+	member := genMembership(model.OrgRoleMember)
+	owner := genMembership(model.OrgRoleOwner)
+	owner.OrgId = member.OrgId
+	otherOwner := genMembership(model.OrgRoleOwner)
+	_ = memDb.SaveMembership(ctx, &member)
+	_ = memDb.SaveMembership(ctx, &owner)
+	_ = memDb.SaveMembership(ctx, &otherOwner)
+	orgWebhook, _ := service.CreateWebhook(ctx, owner, "https://example.com/org", []string{model.AllEventTypes})
+	otherOrgWebhook, _ := service.CreateWebhook(ctx, otherOwner, "https://example.com/other", []string{model.AllEventTypes})
+	envelope, payload := genUserEvent(t, eventRegistry, member.UserId)
+	strangerEnvelope, strangerPayload := genEvent(t, eventRegistry)
+
+	// ACT
+	res, errRes := service.QueueEvent(ctx, envelope, member.UserId, payload)
+	strangerRes, errStrangerRes := service.QueueEvent(ctx, strangerEnvelope, testutil.Fake.UUID().V4(), strangerPayload)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 1, res)
+	assert.Nil(t, errStrangerRes)
+	assert.Equal(t, 0, strangerRes, "the events of users outside every organization should not be queued")
+
+	_, total, _ := service.ListDeliveries(ctx, owner, orgWebhook.Id, 1, 10)
+	assert.Equal(t, 1, total)
+	_, otherTotal, _ := service.ListDeliveries(ctx, otherOwner, otherOrgWebhook.Id, 1, 10)
+	assert.Equal(t, 0, otherTotal, "the webhooks of other organizations should not receive the events of the user")
+}
+
+func Test_Redeliver_Should_Queue_Delivery_Again(t *testing.T) {
+	// ARRANGE
+	service, memDb, _, eventRegistry := setupServiceImplTest()
+	ctx := context.Background()
+	member := genMembership(model.OrgRoleOwner)
+	webhook, _ := service.CreateWebhook(ctx, member, "https://example.com", []string{model.AllEventTypes})
+	_ = memDb.SaveMembership(ctx, &member)
+	envelope, payload := genUserEvent(t, eventRegistry, member.UserId)
+	_, _ = service.QueueEvent(ctx, envelope, member.UserId, payload)
+	deliveries, _, _ := service.ListDeliveries(ctx, member, webhook.Id, 1, 10)
+	delivery := deliveries[0]
+	delivery.Status = model.WebhookDeliveryFailed
+	delivery.Attempts = 8
+	_ = memDb.UpdateWebhookDelivery(ctx, &delivery)
+
+	// ACT
+	res, errRes := service.Redeliver(ctx, member, delivery.Id)
+	_, errOtherOrgRes := service.Redeliver(ctx, genMembership(model.OrgRoleOwner), delivery.Id)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, model.WebhookDeliveryPending, res.Status)
+	assert.Equal(t, 0, res.Attempts)
+
+	assert.IsType(t, exception.NotFound{}, errOtherOrgRes)
+	assert.Equal(t, errorcode.WebhookDeliveryNotFound, getErrType(errOtherOrgRes))
+
+	_, saved, _ := memDb.GetWebhookDelivery(ctx, member.OrgId, delivery.Id)
+	assert.Equal(t, model.WebhookDeliveryPending, saved.Status)
+}
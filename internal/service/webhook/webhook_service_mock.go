@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/stretchr/testify/mock"
+)
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) CreateWebhook(ctx context.Context, member model.Membership, url string, eventTypes []string) (model.Webhook, error) {
+	args := s.Called(ctx, member, url, eventTypes)
+	return args.Get(0).(model.Webhook), args.Error(1)
+}
+
+func (s *ServiceMock) ListWebhooks(ctx context.Context, member model.Membership) ([]model.Webhook, error) {
+	args := s.Called(ctx, member)
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (s *ServiceMock) DeleteWebhook(ctx context.Context, member model.Membership, webhookId string) error {
+	args := s.Called(ctx, member, webhookId)
+	return args.Error(0)
+}
+
+func (s *ServiceMock) ListDeliveries(ctx context.Context, member model.Membership, webhookId string, page int, limit int) ([]model.WebhookDelivery, int, error) {
+	args := s.Called(ctx, member, webhookId, page, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Int(1), args.Error(2)
+}
+
+func (s *ServiceMock) ListDeliveryAttempts(ctx context.Context, member model.Membership, deliveryId string) ([]model.WebhookDeliveryAttempt, error) {
+	args := s.Called(ctx, member, deliveryId)
+	return args.Get(0).([]model.WebhookDeliveryAttempt), args.Error(1)
+}
+
+func (s *ServiceMock) Redeliver(ctx context.Context, member model.Membership, deliveryId string) (model.WebhookDelivery, error) {
+	args := s.Called(ctx, member, deliveryId)
+	return args.Get(0).(model.WebhookDelivery), args.Error(1)
+}
+
+func (s *ServiceMock) QueueEvent(ctx context.Context, envelope event.Envelope, userId string, payload []byte) (int, error) {
+	args := s.Called(ctx, envelope, userId, payload)
+	return args.Int(0), args.Error(1)
+}
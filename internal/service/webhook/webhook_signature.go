@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// headers of every delivery, the id of the delivery stays the same across its attempts so that endpoints can skip
+// the ones they have already handled
+const (
+	DeliveryIdHeader = "Webhook-Id"
+	EventTypeHeader  = "Webhook-Event"
+	TimestampHeader  = "Webhook-Timestamp"
+	SignatureHeader  = "Webhook-Signature"
+)
+
+const signatureVersion = "v1="
+
+// Sign returns the signature of the payload sent at timestamp (unix seconds), the hex encoded HMAC-SHA256 of
+// "<timestamp>.<payload>" keyed with the secret of the webhook. The timestamp is signed so that a captured request
+// cannot be replayed later.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a delivery the way endpoints are expected to, deliveries
+// signed more than tolerance away from now are rejected
+func Verify(secret string, signature string, timestamp string, payload []byte, now time.Time, tolerance time.Duration) error {
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("webhook.Verify(): invalid timestamp '%s'", timestamp)
+	}
+
+	age := now.Sub(time.Unix(sentAt, 0))
+	if age > tolerance || age < -tolerance {
+		return fmt.Errorf("webhook.Verify(): timestamp is outside of the tolerance of %s", tolerance)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, sentAt, payload))) {
+		return fmt.Errorf("webhook.Verify(): signature mismatch")
+	}
+
+	return nil
+}
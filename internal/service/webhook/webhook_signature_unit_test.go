@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sign_Should_Match_Known_Signature(t *testing.T) {
+	// ACT
+	res := Sign("whsec_secret", 1700000000, []byte(`{"id":"1"}`))
+
+	// ASSERT
+	assert.Equal(t, "v1=2fdce3d84622450ab95bc710630480e9484c3efe8046000fbbfabfb5b7ed109d", res)
+}
+
+func Test_Verify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":"1"}`)
+	signature := Sign("whsec_secret", now.Unix(), payload)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	testCases := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		payload   []byte
+		now       time.Time
+		valid     bool
+	}{
+		{"valid", "whsec_secret", signature, timestamp, payload, now.Add(time.Minute), true},
+		{"secret", "whsec_other", signature, timestamp, payload, now, false},
+		{"payload", "whsec_secret", signature, timestamp, []byte(`{"id":"2"}`), now, false},
+		{"timestamp", "whsec_secret", signature, strconv.FormatInt(now.Unix()+1, 10), payload, now, false},
+		{"invalid_timestamp", "whsec_secret", signature, "now", payload, now, false},
+		{"expired", "whsec_secret", signature, timestamp, payload, now.Add(10 * time.Minute), false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// ACT
+			errRes := Verify(testCase.secret, testCase.signature, testCase.timestamp, testCase.payload, testCase.now, 5*time.Minute)
+
+			// ASSERT
+			assert.Equal(t, testCase.valid, errRes == nil)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package webhook
+
+import "context"
+
+// Worker sends the queued deliveries to the webhooks and retries the failed ones, deliveries are sent at least once so
+// endpoints must tolerate duplicates
+type Worker interface {
+	// Run sends the pending deliveries every poll interval until ctx is cancelled
+	Run(ctx context.Context)
+	// DeliverPending sends one batch of pending deliveries and returns how many have succeeded
+	DeliverPending(ctx context.Context) (int, error)
+}
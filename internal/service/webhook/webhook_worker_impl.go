@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+)
+
+const defaultPollInterval = time.Second
+const defaultTimeout = 10 * time.Second
+const defaultMaxAttempts = 8
+const batchSize = 100
+
+// deliveryConcurrency is the number of deliveries sent at the same time, so a slow endpoint does not hold the others
+// back
+const deliveryConcurrency = 8
+
+// failed deliveries are retried with an exponential backoff
+const retryBaseDelay = 10 * time.Second
+const retryMaxDelay = time.Hour
+
+// a claimed delivery is hidden from the other workers for the timeout of the request plus claimMargin, a delivery
+// claimed by a worker which stopped before saving its outcome is sent again once its lease expires
+const claimMargin = time.Minute
+
+// maxResponseBodyLen is the number of bytes of the response body kept in the log of an attempt
+const maxResponseBodyLen = 1024
+
+const userAgent = "golang-practice-webhooks"
+
+type WorkerImpl struct {
+	db           database.Db
+	logService   logger.Service
+	httpClient   *http.Client
+	pollInterval time.Duration
+	maxAttempts  int
+	claimLease   time.Duration
+}
+
+func NewWorker(appConfig *config.AppConfig, logService logger.Service, db database.Db) (Worker, error) {
+	pollInterval := defaultPollInterval
+	if appConfig.WEBHOOK_POLL_INTERVAL != "" {
+		var err error
+		pollInterval, err = time.ParseDuration(appConfig.WEBHOOK_POLL_INTERVAL)
+		if err != nil || pollInterval <= 0 {
+			return nil, fmt.Errorf("webhook.NewWorker(): invalid WEBHOOK_POLL_INTERVAL '%s'", appConfig.WEBHOOK_POLL_INTERVAL)
+		}
+	}
+
+	timeout := defaultTimeout
+	if appConfig.WEBHOOK_TIMEOUT != "" {
+		var err error
+		timeout, err = time.ParseDuration(appConfig.WEBHOOK_TIMEOUT)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("webhook.NewWorker(): invalid WEBHOOK_TIMEOUT '%s'", appConfig.WEBHOOK_TIMEOUT)
+		}
+	}
+
+	maxAttempts := defaultMaxAttempts
+	if appConfig.WEBHOOK_MAX_ATTEMPTS != "" {
+		var err error
+		maxAttempts, err = strconv.Atoi(appConfig.WEBHOOK_MAX_ATTEMPTS)
+		if err != nil || maxAttempts <= 0 {
+			return nil, fmt.Errorf("webhook.NewWorker(): invalid WEBHOOK_MAX_ATTEMPTS '%s'", appConfig.WEBHOOK_MAX_ATTEMPTS)
+		}
+	}
+
+	return &WorkerImpl{
+		db:           db,
+		logService:   logService,
+		httpClient:   newHttpClient(timeout, appConfig.WEBHOOK_ALLOW_PRIVATE_NETWORKS == "true"),
+		pollInterval: pollInterval,
+		maxAttempts:  maxAttempts,
+		claimLease:   timeout + claimMargin,
+	}, nil
+}
+
+func (w *WorkerImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.DeliverPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logService.Error(err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *WorkerImpl) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := w.db.GetPendingWebhookDeliveries(ctx, timeutil.GetCurrentTime(), batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("webhook.DeliverPending(): %w", err)
+	}
+
+	var succeeded atomic.Int32
+	var errsMu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, deliveryConcurrency)
+	for _, delivery := range deliveries {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(delivery model.WebhookDelivery) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			ok, err := w.deliver(ctx, delivery)
+			if err != nil {
+				errsMu.Lock()
+				errs = append(errs, err)
+				errsMu.Unlock()
+			}
+			if ok {
+				succeeded.Add(1)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return int(succeeded.Load()), fmt.Errorf("webhook.DeliverPending(): %w", errors.Join(errs...))
+	}
+
+	return int(succeeded.Load()), nil
+}
+
+// deliver sends the delivery to its webhook and saves the outcome of the attempt, ok is true when the endpoint has
+// accepted it
+func (w *WorkerImpl) deliver(ctx context.Context, delivery model.WebhookDelivery) (ok bool, err error) {
+	// every restapi instance runs a worker, a delivery is only sent by the worker which claimed it
+	currentTime := timeutil.GetCurrentTime()
+	claimed, err := w.db.ClaimWebhookDelivery(ctx, delivery.Id, currentTime, currentTime.Add(w.claimLease))
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	exists, webhook, err := w.db.GetWebhook(ctx, delivery.OrgId, delivery.WebhookId)
+	if err != nil || !exists {
+		// the deliveries of a deleted webhook are deleted along with it
+		return false, err
+	}
+
+	attempt, ok := w.send(ctx, webhook, delivery)
+	if ctx.Err() != nil {
+		// the attempt was interrupted by the shutdown, it is made again once the claim expires
+		return false, nil
+	}
+
+	currentTime = timeutil.GetCurrentTime()
+	delivery.Attempts++
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	switch {
+	case ok:
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &currentTime
+		w.logService.Debug(fmt.Sprintf("delivered event '%s' to webhook '%s' (attempt %d)", delivery.EventId, webhook.Id, delivery.Attempts))
+	case delivery.Attempts >= w.maxAttempts:
+		delivery.Status = model.WebhookDeliveryFailed
+		w.logService.Error(fmt.Sprintf("gave up delivering event '%s' to webhook '%s' after %d attempts: %s", delivery.EventId, webhook.Id, delivery.Attempts, *attempt.Error))
+	default:
+		delivery.NextAttemptAt = currentTime.Add(getRetryDelay(delivery.Attempts - 1))
+		w.logService.Debug(fmt.Sprintf("error delivering event '%s' to webhook '%s' (attempt %d), retrying at %s: %s", delivery.EventId, webhook.Id, delivery.Attempts, delivery.NextAttemptAt.Format(time.RFC3339), *attempt.Error))
+	}
+
+	err = w.db.WithTx(ctx, func(txDb database.Db) error {
+		err := txDb.SaveWebhookDeliveryAttempt(ctx, &attempt)
+		if err != nil {
+			return err
+		}
+
+		return txDb.UpdateWebhookDelivery(ctx, &delivery)
+	})
+	if err != nil {
+		// the delivery is sent again if its outcome cannot be saved, which is why delivery is at least once
+		return ok, err
+	}
+
+	return ok, nil
+}
+
+// send posts the payload of the delivery to the webhook and returns the log of the attempt, ok is true when the
+// endpoint has answered with a 2xx status
+func (w *WorkerImpl) send(ctx context.Context, webhook model.Webhook, delivery model.WebhookDelivery) (attempt model.WebhookDeliveryAttempt, ok bool) {
+	startTime := timeutil.GetCurrentTime()
+	attempt = model.WebhookDeliveryAttempt{DeliveryId: delivery.Id, CreatedAt: startTime}
+	attempt.Id, _ = uuidutil.GenUuidV4()
+	fail := func(err error) (model.WebhookDeliveryAttempt, bool) {
+		errMsg := err.Error()
+		attempt.Error = &errMsg
+		attempt.DurationMs = int(time.Since(startTime).Milliseconds())
+		return attempt, false
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("Content-Type", event.ContentType)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(DeliveryIdHeader, delivery.Id)
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(startTime.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, startTime.Unix(), delivery.Payload))
+
+	res, err := w.httpClient.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer res.Body.Close()
+
+	attempt.StatusCode = &res.StatusCode
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBodyLen))
+	if err == nil && len(body) > 0 {
+		responseBody := string(body)
+		attempt.ResponseBody = &responseBody
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fail(fmt.Errorf("endpoint answered with status %d", res.StatusCode))
+	}
+
+	attempt.DurationMs = int(time.Since(startTime).Milliseconds())
+	return attempt, true
+}
+
+// getRetryDelay doubles the delay with every failed attempt up to retryMaxDelay
+func getRetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 0; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, retryMaxDelay)
+}
@@ -0,0 +1,348 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupWorkerImplTest creates WorkerImpl on an in memory database and queues one delivery to a webhook of url
+func setupWorkerImplTest(t *testing.T, url string, maxAttempts int) (*WorkerImpl, *testutil.MemDb, model.Webhook, model.WebhookDelivery) {
+	memDb := testutil.NewMemDb()
+	logServiceMock := new(logger.ServiceMock)
+	logServiceMock.On("Debug", mock.Anything)
+	logServiceMock.On("Error", mock.Anything)
+	worker := &WorkerImpl{
+		db:           memDb,
+		logService:   logServiceMock,
+		httpClient:   newHttpClient(time.Second, true),
+		pollInterval: 10 * time.Millisecond,
+		maxAttempts:  maxAttempts,
+		claimLease:   time.Minute,
+	}
+
+	ctx := context.Background()
+	currentTime := time.Now().Add(-time.Second)
+	webhook := model.Webhook{
+		Id:         testutil.Fake.UUID().V4(),
+		OrgId:      testutil.Fake.UUID().V4(),
+		Url:        url,
+		Secret:     "whsec_secret",
+		EventTypes: []string{model.AllEventTypes},
+		CreatedBy:  testutil.Fake.UUID().V4(),
+		CreatedAt:  currentTime,
+	}
+	assert.Nil(t, memDb.SaveWebhook(ctx, &webhook))
+
+	delivery := model.WebhookDelivery{
+		Id:            testutil.Fake.UUID().V4(),
+		WebhookId:     webhook.Id,
+		OrgId:         webhook.OrgId,
+		EventId:       testutil.Fake.UUID().V4(),
+		EventType:     "user.registered",
+		Payload:       []byte(`{"id":"1"}`),
+		Status:        model.WebhookDeliveryPending,
+		NextAttemptAt: currentTime,
+		CreatedAt:     currentTime,
+	}
+	_, err := memDb.SaveWebhookDelivery(ctx, &delivery)
+	assert.Nil(t, err)
+
+	return worker, memDb, webhook, delivery
+}
+
+func Test_NewWorker(t *testing.T) {
+	// ARRANGE
+	appConfig := testutil.GetMockAppConfig(&config.AppConfig{WEBHOOK_POLL_INTERVAL: "250ms", WEBHOOK_TIMEOUT: "3s", WEBHOOK_MAX_ATTEMPTS: "3"})
+
+	// ACT
+	res, errRes := NewWorker(&appConfig, new(logger.ServiceMock), new(database.DbMock))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 250*time.Millisecond, res.(*WorkerImpl).pollInterval)
+	assert.Equal(t, 3*time.Second, res.(*WorkerImpl).httpClient.Timeout)
+	assert.Equal(t, 3, res.(*WorkerImpl).maxAttempts)
+	assert.Equal(t, 3*time.Second+claimMargin, res.(*WorkerImpl).claimLease)
+}
+
+func Test_NewWorker_Invalid_Config(t *testing.T) {
+	testCases := []config.AppConfig{
+		{WEBHOOK_POLL_INTERVAL: "soon"},
+		{WEBHOOK_TIMEOUT: "-1s"},
+		{WEBHOOK_MAX_ATTEMPTS: "many"},
+		{WEBHOOK_MAX_ATTEMPTS: "0"},
+	}
+
+	for _, testCase := range testCases {
+		// ARRANGE
+		appConfig := testCase
+
+		// ACT
+		res, errRes := NewWorker(&appConfig, new(logger.ServiceMock), new(database.DbMock))
+
+		// ASSERT
+		assert.Nil(t, res)
+		assert.NotNil(t, errRes)
+	}
+}
+
+func Test_DeliverPending_Should_Send_Signed_Delivery(t *testing.T) {
+	// ARRANGE
+	var received atomic.Int32
+	var verifyErr error
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		headers = r.Header
+		verifyErr = Verify("whsec_secret", r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), body, time.Now(), 5*time.Minute)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	worker, memDb, webhook, delivery := setupWorkerImplTest(t, server.URL, 3)
+	ctx := context.Background()
+
+	// ACT
+	res, errRes := worker.DeliverPending(ctx)
+	sentAgainRes, _ := worker.DeliverPending(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 1, res)
+	assert.Equal(t, 0, sentAgainRes)
+	assert.Equal(t, int32(1), received.Load())
+	assert.Nil(t, verifyErr)
+	assert.Equal(t, delivery.Id, headers.Get(DeliveryIdHeader))
+	assert.Equal(t, delivery.EventType, headers.Get(EventTypeHeader))
+	assert.Equal(t, event.ContentType, headers.Get("Content-Type"))
+
+	_, saved, _ := memDb.GetWebhookDelivery(ctx, webhook.OrgId, delivery.Id)
+	assert.Equal(t, model.WebhookDeliverySucceeded, saved.Status)
+	assert.Equal(t, 1, saved.Attempts)
+	assert.NotNil(t, saved.DeliveredAt)
+
+	attempts, _ := memDb.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, http.StatusOK, *attempts[0].StatusCode)
+	assert.Equal(t, "ok", *attempts[0].ResponseBody)
+}
+
+func Test_DeliverPending_Should_Send_Delivery_Once_With_Several_Workers(t *testing.T) {
+	// ARRANGE
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+	worker, memDb, webhook, delivery := setupWorkerImplTest(t, server.URL, 3)
+	otherWorker := &WorkerImpl{
+		db:           memDb,
+		logService:   worker.logService,
+		httpClient:   worker.httpClient,
+		pollInterval: worker.pollInterval,
+		maxAttempts:  worker.maxAttempts,
+		claimLease:   worker.claimLease,
+	}
+	ctx := context.Background()
+
+	// ACT
+	var wg sync.WaitGroup
+	var delivered atomic.Int32
+	start := make(chan struct{})
+	for _, w := range []*WorkerImpl{worker, otherWorker} {
+		wg.Add(1)
+		go func(w *WorkerImpl) {
+			defer wg.Done()
+			<-start
+			res, err := w.DeliverPending(ctx)
+			assert.Nil(t, err)
+			delivered.Add(int32(res))
+		}(w)
+	}
+	close(start)
+	wg.Wait()
+
+	// ASSERT
+	assert.Equal(t, int32(1), received.Load(), "the delivery should be sent by one worker only")
+	assert.Equal(t, int32(1), delivered.Load())
+
+	_, saved, _ := memDb.GetWebhookDelivery(ctx, webhook.OrgId, delivery.Id)
+	assert.Equal(t, model.WebhookDeliverySucceeded, saved.Status)
+	assert.Equal(t, 1, saved.Attempts)
+
+	attempts, _ := memDb.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	assert.Len(t, attempts, 1)
+}
+
+func Test_DeliverPending_Should_Retry_Failed_Delivery_With_Backoff(t *testing.T) {
+	// ARRANGE
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	worker, memDb, webhook, delivery := setupWorkerImplTest(t, server.URL, 3)
+	ctx := context.Background()
+
+	// ACT
+	res, errRes := worker.DeliverPending(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 0, res)
+
+	_, saved, _ := memDb.GetWebhookDelivery(ctx, webhook.OrgId, delivery.Id)
+	assert.Equal(t, model.WebhookDeliveryPending, saved.Status)
+	assert.Equal(t, 1, saved.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, *saved.LastStatusCode)
+	assert.Equal(t, "endpoint answered with status 503", *saved.LastError)
+	assert.WithinDuration(t, time.Now().Add(retryBaseDelay), saved.NextAttemptAt, time.Second)
+}
+
+func Test_DeliverPending_Should_Fail_Delivery_After_Max_Attempts(t *testing.T) {
+	// ARRANGE
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// redirects are not followed, the endpoint has to accept the delivery itself
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer server.Close()
+	worker, memDb, webhook, delivery := setupWorkerImplTest(t, server.URL, 2)
+	ctx := context.Background()
+
+	// ACT
+	for i := 0; i < 2; i++ {
+		_, saved, _ := memDb.GetWebhookDelivery(ctx, webhook.OrgId, delivery.Id)
+		saved.NextAttemptAt = time.Now().Add(-time.Second)
+		_ = memDb.UpdateWebhookDelivery(ctx, &saved)
+		_, errRes := worker.DeliverPending(ctx)
+		assert.Nil(t, errRes)
+	}
+
+	// ASSERT
+	_, saved, _ := memDb.GetWebhookDelivery(ctx, webhook.OrgId, delivery.Id)
+	assert.Equal(t, model.WebhookDeliveryFailed, saved.Status)
+	assert.Equal(t, 2, saved.Attempts)
+	assert.Equal(t, http.StatusFound, *saved.LastStatusCode)
+	assert.Nil(t, saved.DeliveredAt)
+
+	attempts, _ := memDb.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	assert.Len(t, attempts, 2)
+}
+
+func Test_DeliverPending_Should_Record_Connection_Error(t *testing.T) {
+	// ARRANGE
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	worker, memDb, webhook, delivery := setupWorkerImplTest(t, server.URL, 3)
+	ctx := context.Background()
+
+	// ACT
+	res, errRes := worker.DeliverPending(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 0, res)
+
+	attempts, _ := memDb.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	assert.Len(t, attempts, 1)
+	assert.Nil(t, attempts[0].StatusCode)
+	assert.NotNil(t, attempts[0].Error)
+
+	_, saved, _ := memDb.GetWebhookDelivery(ctx, webhook.OrgId, delivery.Id)
+	assert.Equal(t, attempts[0].Error, saved.LastError)
+}
+
+func Test_DeliverPending_Should_Refuse_Loopback_Endpoint(t *testing.T) {
+	// ARRANGE
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.Write([]byte("internal data"))
+	}))
+	defer server.Close()
+	worker, memDb, webhook, delivery := setupWorkerImplTest(t, server.URL, 3)
+	worker.httpClient = newHttpClient(time.Second, false)
+	ctx := context.Background()
+
+	// ACT
+	res, errRes := worker.DeliverPending(ctx)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 0, res)
+	assert.Equal(t, int32(0), received.Load())
+
+	attempts, _ := memDb.GetWebhookDeliveryAttempts(ctx, webhook.OrgId, delivery.Id)
+	assert.Len(t, attempts, 1)
+	assert.Nil(t, attempts[0].StatusCode)
+	assert.Nil(t, attempts[0].ResponseBody)
+	assert.Contains(t, *attempts[0].Error, ErrPrivateAddr.Error())
+}
+
+func Test_CheckDialAddr(t *testing.T) {
+	testCases := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.0.0.1:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"0.0.0.0:80", false},
+		{"224.0.0.1:80", false},
+	}
+
+	for _, testCase := range testCases {
+		// ACT
+		errRes := checkDialAddr("tcp", testCase.address, nil)
+
+		// ASSERT
+		if testCase.allowed {
+			assert.Nil(t, errRes, testCase.address)
+		} else {
+			assert.ErrorIs(t, errRes, ErrPrivateAddr, testCase.address)
+		}
+	}
+}
+
+func Test_GetRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 20 * time.Second},
+		{3, 80 * time.Second},
+		{20, time.Hour},
+	}
+
+	for _, testCase := range testCases {
+		// ACT
+		res := getRetryDelay(testCase.attempts)
+
+		// ASSERT
+		assert.Equal(t, testCase.expected, res)
+	}
+}
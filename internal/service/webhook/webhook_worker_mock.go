@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WorkerMock struct {
+	mock.Mock
+}
+
+func (w *WorkerMock) Run(ctx context.Context) {
+	w.Called(ctx)
+}
+
+func (w *WorkerMock) DeliverPending(ctx context.Context) (int, error) {
+	args := w.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
@@ -1,7 +1,35 @@
 package main
 
-import "github.com/pjmessi/golang-practice/cmd/restapi"
+import (
+	"os"
+
+	"github.com/pjmessi/golang-practice/cmd/dlq"
+	"github.com/pjmessi/golang-practice/cmd/grpcapi"
+	"github.com/pjmessi/golang-practice/cmd/migrate"
+	"github.com/pjmessi/golang-practice/cmd/restapi"
+	"github.com/pjmessi/golang-practice/cmd/stream"
+)
 
 func main() {
-	restapi.StartApp()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate.StartApp(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "dlq" {
+		dlq.StartApp(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "grpcapi" {
+		grpcapi.StartApp(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "stream" {
+		stream.StartApp(os.Args[2:])
+		return
+	}
+
+	restapi.StartApp(os.Args[1:])
 }
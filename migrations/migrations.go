@@ -0,0 +1,8 @@
+// Package migrations embeds the versioned sql migrations so they ship inside the binary. Every database driver has its
+// own directory, files are named NNNN_description.up.sql and NNNN_description.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed */*.sql
+var FS embed.FS
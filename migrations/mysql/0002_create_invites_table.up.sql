@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `invites` (
+  `id` char(36) NOT NULL,
+  `code` varchar(64) NOT NULL,
+  `email` varchar(100) DEFAULT NULL,
+  `created_by` char(36) NOT NULL,
+  `org_id` char(36) DEFAULT NULL,
+  `role` varchar(20) DEFAULT NULL,
+  `max_uses` int NOT NULL DEFAULT '1',
+  `used_count` int NOT NULL DEFAULT '0',
+  `expires_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `invites_code_unique` (`code`),
+  KEY `invites_org_id_index` (`org_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `organizations` (
+  `id` char(36) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `created_by` char(36) NOT NULL,
+  `created_at` timestamp NOT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS `memberships` (
+  `org_id` char(36) NOT NULL,
+  `user_id` char(36) NOT NULL,
+  `role` varchar(20) NOT NULL,
+  `created_at` timestamp NOT NULL,
+  PRIMARY KEY (`org_id`, `user_id`),
+  KEY `memberships_user_id_index` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `login_events` (
+  `id` char(36) NOT NULL,
+  `user_id` char(36) DEFAULT NULL,
+  `email` varchar(100) NOT NULL,
+  `success` tinyint(1) NOT NULL,
+  `failure_reason` varchar(50) DEFAULT NULL,
+  `ip` varchar(45) NOT NULL,
+  `user_agent` varchar(255) NOT NULL,
+  `mfa_used` tinyint(1) NOT NULL DEFAULT '0',
+  `new_device` tinyint(1) NOT NULL DEFAULT '0',
+  `created_at` timestamp NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `login_events_user_id_created_at_index` (`user_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,2 @@
+-- the column collation is case insensitive, so emails differing only in case are duplicates
+CREATE UNIQUE INDEX `users_email_unique` ON `users` (`email`);
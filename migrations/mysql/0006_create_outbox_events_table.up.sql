@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `outbox_events` (
+  `id` char(36) NOT NULL,
+  `topic` varchar(255) NOT NULL,
+  `payload` mediumblob NOT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `last_error` text DEFAULT NULL,
+  `next_attempt_at` timestamp NOT NULL,
+  `created_at` timestamp NOT NULL,
+  `sent_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `outbox_events_sent_at_next_attempt_at_index` (`sent_at`, `next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
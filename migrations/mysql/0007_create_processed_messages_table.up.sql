@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `processed_messages` (
+  `consumer` varchar(255) NOT NULL,
+  `message_id` varchar(255) NOT NULL,
+  `processed_at` timestamp NOT NULL,
+  `expires_at` timestamp NOT NULL,
+  PRIMARY KEY (`consumer`, `message_id`),
+  KEY `processed_messages_expires_at_index` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS users (
+  id char(36) NOT NULL,
+  email varchar(100) NOT NULL,
+  password varchar(255) DEFAULT NULL,
+  first_name varchar(100) DEFAULT NULL,
+  last_name varchar(100) DEFAULT NULL,
+  created_at timestamp NOT NULL,
+  updated_at timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (id)
+);
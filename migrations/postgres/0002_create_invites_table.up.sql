@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS invites (
+  id char(36) NOT NULL,
+  code varchar(64) NOT NULL,
+  email varchar(100) DEFAULT NULL,
+  created_by char(36) NOT NULL,
+  org_id char(36) DEFAULT NULL,
+  role varchar(20) DEFAULT NULL,
+  max_uses int NOT NULL DEFAULT 1,
+  used_count int NOT NULL DEFAULT 0,
+  expires_at timestamp NULL DEFAULT NULL,
+  created_at timestamp NOT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT invites_code_unique UNIQUE (code)
+);
+
+CREATE INDEX IF NOT EXISTS invites_org_id_index ON invites (org_id);
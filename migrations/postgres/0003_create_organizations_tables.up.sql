@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS organizations (
+  id char(36) NOT NULL,
+  name varchar(100) NOT NULL,
+  created_by char(36) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_at timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS memberships (
+  org_id char(36) NOT NULL,
+  user_id char(36) NOT NULL,
+  role varchar(20) NOT NULL,
+  created_at timestamp NOT NULL,
+  PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS memberships_user_id_index ON memberships (user_id);
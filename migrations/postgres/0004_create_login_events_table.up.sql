@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS login_events (
+  id char(36) NOT NULL,
+  user_id char(36) DEFAULT NULL,
+  email varchar(100) NOT NULL,
+  success boolean NOT NULL,
+  failure_reason varchar(50) DEFAULT NULL,
+  ip varchar(45) NOT NULL,
+  user_agent varchar(255) NOT NULL,
+  mfa_used boolean NOT NULL DEFAULT FALSE,
+  new_device boolean NOT NULL DEFAULT FALSE,
+  created_at timestamp NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS login_events_user_id_created_at_index ON login_events (user_id, created_at);
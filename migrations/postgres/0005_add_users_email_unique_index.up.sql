@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_unique ON users (LOWER(email));
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS processed_messages (
+  consumer varchar(255) NOT NULL,
+  message_id varchar(255) NOT NULL,
+  processed_at timestamp NOT NULL,
+  expires_at timestamp NOT NULL,
+  PRIMARY KEY (consumer, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS processed_messages_expires_at_index ON processed_messages (expires_at);
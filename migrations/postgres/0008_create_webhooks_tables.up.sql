@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+  id char(36) NOT NULL,
+  org_id char(36) NOT NULL,
+  url varchar(2048) NOT NULL,
+  secret varchar(100) NOT NULL,
+  event_types varchar(1000) NOT NULL,
+  created_by char(36) NOT NULL,
+  created_at timestamp NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_org_id_index ON webhooks (org_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id char(36) NOT NULL,
+  webhook_id char(36) NOT NULL,
+  org_id char(36) NOT NULL,
+  event_id varchar(255) NOT NULL,
+  event_type varchar(255) NOT NULL,
+  payload bytea NOT NULL,
+  status varchar(20) NOT NULL,
+  attempts int NOT NULL DEFAULT 0,
+  last_status_code int DEFAULT NULL,
+  last_error text DEFAULT NULL,
+  next_attempt_at timestamp NOT NULL,
+  created_at timestamp NOT NULL,
+  delivered_at timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_event_id_unique ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_next_attempt_at_index ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_created_at_index ON webhook_deliveries (webhook_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+  id char(36) NOT NULL,
+  delivery_id char(36) NOT NULL,
+  status_code int DEFAULT NULL,
+  error text DEFAULT NULL,
+  response_body text DEFAULT NULL,
+  duration_ms int NOT NULL,
+  created_at timestamp NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_created_at_index ON webhook_delivery_attempts (delivery_id, created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS users (
+  id TEXT NOT NULL,
+  email TEXT NOT NULL,
+  password TEXT DEFAULT NULL,
+  first_name TEXT DEFAULT NULL,
+  last_name TEXT DEFAULT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY (id)
+);
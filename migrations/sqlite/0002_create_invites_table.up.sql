@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS invites (
+  id TEXT NOT NULL,
+  code TEXT NOT NULL,
+  email TEXT DEFAULT NULL,
+  created_by TEXT NOT NULL,
+  org_id TEXT DEFAULT NULL,
+  role TEXT DEFAULT NULL,
+  max_uses INTEGER NOT NULL DEFAULT 1,
+  used_count INTEGER NOT NULL DEFAULT 0,
+  expires_at TIMESTAMP NULL DEFAULT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT invites_code_unique UNIQUE (code)
+);
+
+CREATE INDEX IF NOT EXISTS invites_org_id_index ON invites (org_id);
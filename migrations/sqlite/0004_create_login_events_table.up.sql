@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS login_events (
+  id TEXT NOT NULL,
+  user_id TEXT DEFAULT NULL,
+  email TEXT NOT NULL,
+  success BOOLEAN NOT NULL,
+  failure_reason TEXT DEFAULT NULL,
+  ip TEXT NOT NULL,
+  user_agent TEXT NOT NULL,
+  mfa_used BOOLEAN NOT NULL DEFAULT FALSE,
+  new_device BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS login_events_user_id_created_at_index ON login_events (user_id, created_at);
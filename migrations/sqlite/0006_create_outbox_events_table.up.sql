@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id TEXT NOT NULL,
+  topic TEXT NOT NULL,
+  payload BLOB NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT DEFAULT NULL,
+  next_attempt_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  sent_at TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_sent_at_next_attempt_at_index ON outbox_events (sent_at, next_attempt_at);
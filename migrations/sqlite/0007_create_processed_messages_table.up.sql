@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS processed_messages (
+  consumer TEXT NOT NULL,
+  message_id TEXT NOT NULL,
+  processed_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (consumer, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS processed_messages_expires_at_index ON processed_messages (expires_at);
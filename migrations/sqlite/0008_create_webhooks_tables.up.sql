@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+  id TEXT NOT NULL,
+  org_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  event_types TEXT NOT NULL,
+  created_by TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_org_id_index ON webhooks (org_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id TEXT NOT NULL,
+  webhook_id TEXT NOT NULL,
+  org_id TEXT NOT NULL,
+  event_id TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  payload BLOB NOT NULL,
+  status TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_status_code INTEGER DEFAULT NULL,
+  last_error TEXT DEFAULT NULL,
+  next_attempt_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  delivered_at TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_event_id_unique ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_next_attempt_at_index ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_created_at_index ON webhook_deliveries (webhook_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+  id TEXT NOT NULL,
+  delivery_id TEXT NOT NULL,
+  status_code INTEGER DEFAULT NULL,
+  error TEXT DEFAULT NULL,
+  response_body TEXT DEFAULT NULL,
+  duration_ms INTEGER NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_created_at_index ON webhook_delivery_attempts (delivery_id, created_at);
@@ -8,6 +8,11 @@ func NewCtxWithTraceId(traceId string) context.Context {
 	return context.WithValue(context.Background(), contextKey("TraceId"), traceId)
 }
 
+// WithTraceId returns a copy of ctx carrying the trace id
+func WithTraceId(ctx context.Context, traceId string) context.Context {
+	return context.WithValue(ctx, contextKey("TraceId"), traceId)
+}
+
 func GetTraceIdFromCtx(ctx context.Context) string {
 	traceIdVal := ctx.Value(contextKey("TraceId"))
 	traceId, ok := traceIdVal.(string)
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// SpecVersion is the version of the CloudEvents specification the envelopes follow
+const SpecVersion = "1.0"
+
+// ContentType is the content type of an envelope in the structured mode of CloudEvents
+const ContentType = "application/cloudevents+json"
+
+const dataContentType = "application/json"
+
+// IdHeader is the NATS header of the id of the event
+const IdHeader = "Ce-Id"
+
+// Envelope wraps the data of every published event. It is serialized as a CloudEvents 1.0 structured JSON event,
+// the version of the data schema and the trace id are CloudEvents extension attributes.
+type Envelope struct {
+	SpecVersion     string    `json:"specversion"`
+	Id              string    `json:"id"`
+	Type            string    `json:"type"`
+	Source          string    `json:"source"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	// Version is the version of the schema of Data, it is incremented on every incompatible change
+	Version int    `json:"dataversion"`
+	TraceId string `json:"traceid,omitempty"`
+	// Data is the JSON payload of the event
+	Data json.RawMessage `json:"data"`
+}
+
+// Parse decodes an envelope without checking its type against a registry, use Registry.Decode to validate it
+func Parse(payload []byte) (Envelope, error) {
+	var envelope Envelope
+	err := json.Unmarshal(payload, &envelope)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("event.Parse(): %w", err)
+	}
+
+	if envelope.SpecVersion != SpecVersion || envelope.Id == "" || envelope.Type == "" {
+		return Envelope{}, fmt.Errorf("event.Parse(): not a CloudEvents %s envelope", SpecVersion)
+	}
+
+	return envelope, nil
+}
+
+// Headers returns the attributes of the envelope as NATS headers, following the binary mode of CloudEvents, so that
+// consumers can route messages without decoding them. The id of the event is the Nats-Msg-Id as well, so the stream
+// stores an event only once when it is published again
+func (e Envelope) Headers() nats.Header {
+	header := nats.Header{}
+	header.Set(jetstream.MsgIDHeader, e.Id)
+	header.Set("Content-Type", ContentType)
+	header.Set("Ce-Specversion", e.SpecVersion)
+	header.Set(IdHeader, e.Id)
+	header.Set("Ce-Type", e.Type)
+	header.Set("Ce-Source", e.Source)
+	header.Set("Ce-Time", e.Time.Format(time.RFC3339Nano))
+	header.Set("Ce-Dataversion", strconv.Itoa(e.Version))
+	if e.TraceId != "" {
+		header.Set("Ce-Traceid", e.TraceId)
+	}
+
+	return header
+}
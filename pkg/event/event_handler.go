@@ -0,0 +1,31 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+)
+
+// Handler returns a nats.HandlerFunc which decodes and validates the envelope with the registry before calling fn
+// with its data, the trace id of the envelope is added to the context
+func Handler[T any](r *Registry, fn func(ctx context.Context, envelope Envelope, data T) error) nats.HandlerFunc {
+	return func(ctx context.Context, msg nats.Msg) error {
+		envelope, rawData, err := r.Decode(msg.Data)
+		if err != nil {
+			return err
+		}
+
+		data, ok := rawData.(T)
+		if !ok {
+			return fmt.Errorf("event.Handler(): the data of '%s' is a %T, not a %T", envelope.Type, rawData, data)
+		}
+
+		if envelope.TraceId != "" {
+			ctx = ctxutil.WithTraceId(ctx, envelope.TraceId)
+		}
+
+		return fn(ctx, envelope, data)
+	}
+}
@@ -0,0 +1,171 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/timeutil"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+)
+
+// Validator is implemented by event data which has to be checked before being published and after being consumed
+type Validator interface {
+	Validate() error
+}
+
+type eventType struct {
+	version  int
+	dataType reflect.Type
+	// decode decodes and validates the data of an envelope
+	decode func(data []byte) (any, error)
+}
+
+// Registry knows every event type of the app with the current version and the Go type of its data, envelopes of
+// unknown types or versions are rejected when they are created and when they are decoded
+type Registry struct {
+	source string
+	mu     sync.RWMutex
+	types  map[string]eventType
+}
+
+// NewRegistry returns an empty registry, source is the producer written in the envelopes
+func NewRegistry(source string) *Registry {
+	return &Registry{
+		source: source,
+		types:  map[string]eventType{},
+	}
+}
+
+// Register adds the event type with the version of its schema, T is the type of its data
+func Register[T any](r *Registry, name string, version int) error {
+	if name == "" || version < 1 {
+		return fmt.Errorf("event.Register(): a name and a version of at least 1 are required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, isRegistered := r.types[name]; isRegistered {
+		return fmt.Errorf("event.Register(): the event type '%s' is already registered", name)
+	}
+
+	r.types[name] = eventType{
+		version:  version,
+		dataType: reflect.TypeOf((*T)(nil)).Elem(),
+		decode: func(rawData []byte) (any, error) {
+			var data T
+			err := json.Unmarshal(rawData, &data)
+			if err != nil {
+				return nil, err
+			}
+
+			return data, validate(data)
+		},
+	}
+
+	return nil
+}
+
+// Types returns the names of the registered event types in alphabetical order
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func validate(data any) error {
+	if validator, ok := data.(Validator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+func (r *Registry) getType(name string) (eventType, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registered, isRegistered := r.types[name]
+	if !isRegistered {
+		return eventType{}, fmt.Errorf("unknown event type '%s'", name)
+	}
+
+	return registered, nil
+}
+
+// New validates the data and wraps it in an envelope of the current version of the type, the trace id is taken from
+// ctx
+func (r *Registry) New(ctx context.Context, name string, data any) (Envelope, error) {
+	registered, err := r.getType(name)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("event.Registry.New(): %w", err)
+	}
+
+	if reflect.TypeOf(data) != registered.dataType {
+		return Envelope{}, fmt.Errorf("event.Registry.New(): the data of '%s' must be a %s, not a %T", name, registered.dataType, data)
+	}
+
+	err = validate(data)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("event.Registry.New(): invalid '%s': %w", name, err)
+	}
+
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("event.Registry.New(): %w", err)
+	}
+
+	id, err := uuidutil.GenUuidV4()
+	if err != nil {
+		return Envelope{}, fmt.Errorf("event.Registry.New(): %w", err)
+	}
+
+	return Envelope{
+		SpecVersion:     SpecVersion,
+		Id:              id,
+		Type:            name,
+		Source:          r.source,
+		Time:            timeutil.GetCurrentTime().UTC(),
+		DataContentType: dataContentType,
+		Version:         registered.version,
+		TraceId:         ctxutil.GetTraceIdFromCtx(ctx),
+		Data:            rawData,
+	}, nil
+}
+
+// Decode parses the envelope and validates its data against the registered type, the decoded data is returned with
+// the envelope
+func (r *Registry) Decode(payload []byte) (Envelope, any, error) {
+	envelope, err := Parse(payload)
+	if err != nil {
+		return Envelope{}, nil, err
+	}
+
+	registered, err := r.getType(envelope.Type)
+	if err != nil {
+		return Envelope{}, nil, fmt.Errorf("event.Registry.Decode(): %w", err)
+	}
+
+	if envelope.Version != registered.version {
+		return Envelope{}, nil, fmt.Errorf("event.Registry.Decode(): unsupported version %d of '%s', expected %d", envelope.Version, envelope.Type, registered.version)
+	}
+
+	data, err := registered.decode(envelope.Data)
+	if err != nil {
+		return Envelope{}, nil, fmt.Errorf("event.Registry.Decode(): invalid '%s': %w", envelope.Type, err)
+	}
+
+	return envelope, data, nil
+}
@@ -0,0 +1,174 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+)
+
+type testData struct {
+	Id string `json:"id"`
+}
+
+func (d testData) Validate() error {
+	if d.Id == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+// setupRegistry returns a registry with the "test.created" event type at version 2
+func setupRegistry(t *testing.T) *Registry {
+	registry := NewRegistry("test-source")
+	err := Register[testData](registry, "test.created", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func Test_Register_Should_Reject_Duplicates_And_Invalid_Versions(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+
+	// ACT
+	errDuplicateRes := Register[testData](registry, "test.created", 3)
+	errVersionRes := Register[testData](registry, "test.updated", 0)
+
+	// ASSERT
+	assert.EqualError(t, errDuplicateRes, "event.Register(): the event type 'test.created' is already registered")
+	assert.NotNil(t, errVersionRes)
+}
+
+func Test_Types_Should_Return_Sorted_Names(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+	err := Register[testData](registry, "test.archived", 1)
+	assert.Nil(t, err)
+
+	// ACT
+	typesRes := registry.Types()
+
+	// ASSERT
+	assert.Equal(t, []string{"test.archived", "test.created"}, typesRes)
+}
+
+func Test_New_Should_Wrap_Data_In_Envelope(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+	ctx := ctxutil.NewCtxWithTraceId("trace-id")
+
+	// ACT
+	envelopeRes, errRes := registry.New(ctx, "test.created", testData{Id: "1"})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.NotEmpty(t, envelopeRes.Id)
+	assert.Equal(t, SpecVersion, envelopeRes.SpecVersion)
+	assert.Equal(t, "test.created", envelopeRes.Type)
+	assert.Equal(t, "test-source", envelopeRes.Source)
+	assert.Equal(t, 2, envelopeRes.Version)
+	assert.Equal(t, "trace-id", envelopeRes.TraceId)
+	assert.Equal(t, "application/json", envelopeRes.DataContentType)
+	assert.JSONEq(t, `{"id":"1"}`, string(envelopeRes.Data))
+	assert.WithinDuration(t, time.Now(), envelopeRes.Time, time.Second)
+}
+
+func Test_New_Should_Validate_Data(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+	ctx := context.Background()
+
+	// ACT
+	_, errUnknownRes := registry.New(ctx, "test.deleted", testData{Id: "1"})
+	_, errTypeRes := registry.New(ctx, "test.created", map[string]string{"id": "1"})
+	_, errInvalidRes := registry.New(ctx, "test.created", testData{})
+
+	// ASSERT
+	assert.EqualError(t, errUnknownRes, "event.Registry.New(): unknown event type 'test.deleted'")
+	assert.EqualError(t, errTypeRes, "event.Registry.New(): the data of 'test.created' must be a event.testData, not a map[string]string")
+	assert.EqualError(t, errInvalidRes, "event.Registry.New(): invalid 'test.created': id is required")
+}
+
+func Test_Decode_Should_Return_Envelope_And_Data(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+	envelope, _ := registry.New(context.Background(), "test.created", testData{Id: "1"})
+	payload, _ := json.Marshal(envelope)
+
+	// ACT
+	envelopeRes, dataRes, errRes := registry.Decode(payload)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, envelope.Id, envelopeRes.Id)
+	assert.True(t, envelope.Time.Equal(envelopeRes.Time))
+	assert.Equal(t, testData{Id: "1"}, dataRes)
+}
+
+func Test_Decode_Should_Reject_Invalid_Envelopes(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+	testCases := map[string]string{
+		`{"id":"1"}`: "event.Parse(): not a CloudEvents 1.0 envelope",
+		`{"specversion":"1.0","id":"1","type":"test.deleted","dataversion":2,"data":{"id":"1"}}`: "event.Registry.Decode(): unknown event type 'test.deleted'",
+		`{"specversion":"1.0","id":"1","type":"test.created","dataversion":1,"data":{"id":"1"}}`: "event.Registry.Decode(): unsupported version 1 of 'test.created', expected 2",
+		`{"specversion":"1.0","id":"1","type":"test.created","dataversion":2,"data":{}}`:         "event.Registry.Decode(): invalid 'test.created': id is required",
+	}
+
+	for payload, expectedErr := range testCases {
+		// ACT
+		_, _, errRes := registry.Decode([]byte(payload))
+
+		// ASSERT
+		assert.EqualError(t, errRes, expectedErr)
+	}
+}
+
+func Test_Headers_Should_Contain_CloudEvents_Attributes(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+	envelope, _ := registry.New(ctxutil.NewCtxWithTraceId("trace-id"), "test.created", testData{Id: "1"})
+
+	// ACT
+	headerRes := envelope.Headers()
+
+	// ASSERT
+	assert.Equal(t, ContentType, headerRes.Get("Content-Type"))
+	assert.Equal(t, envelope.Id, headerRes.Get("Nats-Msg-Id"))
+	assert.Equal(t, SpecVersion, headerRes.Get("Ce-Specversion"))
+	assert.Equal(t, envelope.Id, headerRes.Get("Ce-Id"))
+	assert.Equal(t, "test.created", headerRes.Get("Ce-Type"))
+	assert.Equal(t, "test-source", headerRes.Get("Ce-Source"))
+	assert.Equal(t, "2", headerRes.Get("Ce-Dataversion"))
+	assert.Equal(t, "trace-id", headerRes.Get("Ce-Traceid"))
+}
+
+func Test_Handler_Should_Call_Fn_With_Typed_Data(t *testing.T) {
+	// ARRANGE
+	registry := setupRegistry(t)
+	envelope, _ := registry.New(ctxutil.NewCtxWithTraceId("trace-id"), "test.created", testData{Id: "1"})
+	payload, _ := json.Marshal(envelope)
+
+	var dataRes testData
+	var traceIdRes string
+	handle := Handler(registry, func(ctx context.Context, envelope Envelope, data testData) error {
+		dataRes = data
+		traceIdRes = ctxutil.GetTraceIdFromCtx(ctx)
+		return nil
+	})
+
+	// ACT
+	errRes := handle(context.Background(), nats.Msg{Subject: "TEST.CREATED", Data: payload})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, testData{Id: "1"}, dataRes)
+	assert.Equal(t, "trace-id", traceIdRes)
+}
@@ -0,0 +1,260 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// replayed messages are fetched in batches, waiting replayFetchWait at most for each batch
+const replayFetchBatch = 100
+const replayFetchWait = 5 * time.Second
+
+// replayInactiveThreshold is how long the server keeps the consumer of an interrupted replay
+const replayInactiveThreshold = time.Minute
+
+// StreamInfo describes a stream and the messages it stores
+type StreamInfo struct {
+	Name      string
+	Subjects  []string
+	Msgs      uint64
+	Bytes     uint64
+	FirstSeq  uint64
+	FirstTime time.Time
+	LastSeq   uint64
+	LastTime  time.Time
+	Consumers int
+}
+
+// ConsumerInfo describes a consumer of a stream and how far behind the stream it is
+type ConsumerInfo struct {
+	Stream        string
+	Name          string
+	FilterSubject string
+	// Delivered is the stream sequence of the last message delivered to the consumer
+	Delivered uint64
+	// AckFloor is the stream sequence up to which every message has been acknowledged
+	AckFloor uint64
+	// NumPending is the lag of the consumer, the messages of the stream which have not been delivered yet
+	NumPending uint64
+	// NumAckPending are the messages delivered but not acknowledged yet
+	NumAckPending  int
+	NumRedelivered int
+	// LastActive is when a message was last delivered, zero when none has been delivered
+	LastActive time.Time
+}
+
+// ReplayRange selects the messages of a stream which are replayed, every bound is inclusive and ignored when zero
+type ReplayRange struct {
+	// Subject only replays the messages of the subject, wildcards are allowed
+	Subject string
+	FromSeq uint64
+	ToSeq   uint64
+	Since   time.Time
+	Until   time.Time
+}
+
+func (r ReplayRange) validate() error {
+	if r.ToSeq != 0 && r.ToSeq < r.FromSeq {
+		return fmt.Errorf("invalid replay range, sequence %d is before %d", r.ToSeq, r.FromSeq)
+	}
+	if !r.Until.IsZero() && r.Until.Before(r.Since) {
+		return fmt.Errorf("invalid replay range, %s is before %s", r.Until.Format(time.RFC3339), r.Since.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// isPast tells whether a message and all the following ones are after the end of the range
+func (r ReplayRange) isPast(seq uint64, timestamp time.Time) bool {
+	return (r.ToSeq != 0 && seq > r.ToSeq) || (!r.Until.IsZero() && timestamp.After(r.Until))
+}
+
+// getReplayConsumerConfig starts the consumer at FromSeq, or at Since when only the time is given
+func getReplayConsumerConfig(replayRange ReplayRange) jetstream.OrderedConsumerConfig {
+	cfg := jetstream.OrderedConsumerConfig{
+		DeliverPolicy:     jetstream.DeliverAllPolicy,
+		InactiveThreshold: replayInactiveThreshold,
+	}
+	if replayRange.Subject != "" {
+		cfg.FilterSubjects = []string{replayRange.Subject}
+	}
+
+	switch {
+	case replayRange.FromSeq != 0:
+		cfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = replayRange.FromSeq
+	case !replayRange.Since.IsZero():
+		since := replayRange.Since
+		cfg.DeliverPolicy = jetstream.DeliverByStartTimePolicy
+		cfg.OptStartTime = &since
+	}
+
+	return cfg
+}
+
+// ListStreams returns the streams of the account
+func (s *ServiceImpl) ListStreams(ctx context.Context) ([]StreamInfo, error) {
+	lister := s.jetStream.ListStreams(ctx)
+
+	streams := []StreamInfo{}
+	for info := range lister.Info() {
+		streams = append(streams, StreamInfo{
+			Name:      info.Config.Name,
+			Subjects:  info.Config.Subjects,
+			Msgs:      info.State.Msgs,
+			Bytes:     info.State.Bytes,
+			FirstSeq:  info.State.FirstSeq,
+			FirstTime: info.State.FirstTime,
+			LastSeq:   info.State.LastSeq,
+			LastTime:  info.State.LastTime,
+			Consumers: info.State.Consumers,
+		})
+	}
+	if err := lister.Err(); err != nil {
+		return nil, fmt.Errorf("nats.ServiceImpl.ListStreams(): %w", err)
+	}
+
+	return streams, nil
+}
+
+// ListConsumers returns the consumers of the stream along with their lag
+func (s *ServiceImpl) ListConsumers(ctx context.Context, stream string) ([]ConsumerInfo, error) {
+	jsStream, err := s.jetStream.Stream(ctx, stream)
+	if err != nil {
+		return nil, fmt.Errorf("nats.ServiceImpl.ListConsumers(): %w", err)
+	}
+
+	lister := jsStream.ListConsumers(ctx)
+
+	consumers := []ConsumerInfo{}
+	for info := range lister.Info() {
+		consumers = append(consumers, toConsumerInfo(info))
+	}
+	if err := lister.Err(); err != nil {
+		return nil, fmt.Errorf("nats.ServiceImpl.ListConsumers(): %w", err)
+	}
+
+	return consumers, nil
+}
+
+func toConsumerInfo(info *jetstream.ConsumerInfo) ConsumerInfo {
+	consumer := ConsumerInfo{
+		Stream:         info.Stream,
+		Name:           info.Name,
+		FilterSubject:  info.Config.FilterSubject,
+		Delivered:      info.Delivered.Stream,
+		AckFloor:       info.AckFloor.Stream,
+		NumPending:     info.NumPending,
+		NumAckPending:  info.NumAckPending,
+		NumRedelivered: info.NumRedelivered,
+	}
+	if info.Delivered.Last != nil {
+		consumer.LastActive = *info.Delivered.Last
+	}
+
+	return consumer
+}
+
+// Replay calls handle with the messages of the stream within the range, oldest first, and returns how many were
+// replayed. Messages stored after the replay has started are not replayed, so replaying to a subject of the stream
+// itself ends as well. The replay stops at the first error of handle.
+func (s *ServiceImpl) Replay(ctx context.Context, stream string, replayRange ReplayRange, handle HandlerFunc) (int, error) {
+	err := replayRange.validate()
+	if err != nil {
+		return 0, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+	}
+
+	jsStream, err := s.jetStream.Stream(ctx, stream)
+	if err != nil {
+		return 0, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+	}
+
+	info, err := jsStream.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+	}
+	lastSeq := info.State.LastSeq
+	if lastSeq == 0 || lastSeq < replayRange.FromSeq {
+		return 0, nil
+	}
+
+	consumer, err := s.jetStream.OrderedConsumer(ctx, stream, getReplayConsumerConfig(replayRange))
+	if err != nil {
+		return 0, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+	}
+
+	consumerInfo, err := consumer.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+	}
+
+	replayed := 0
+	for pending := consumerInfo.NumPending; pending > 0; {
+		// a fetch waits until the batch is full, so it does not ask for more than the pending messages
+		batch, err := consumer.Fetch(int(min(pending, replayFetchBatch)), jetstream.FetchMaxWait(replayFetchWait))
+		if err != nil {
+			return replayed, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+		}
+
+		received := 0
+		for msg := range batch.Messages() {
+			received++
+			metadata, err := msg.Metadata()
+			if err != nil {
+				return replayed, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+			}
+			pending = metadata.NumPending
+
+			seq := metadata.Sequence.Stream
+			if seq > lastSeq || replayRange.isPast(seq, metadata.Timestamp) {
+				return replayed, nil
+			}
+			if metadata.Timestamp.Before(replayRange.Since) {
+				// the consumer starts at FromSeq when both FromSeq and Since are given
+				continue
+			}
+
+			err = handle(ctx, Msg{
+				Subject:      msg.Subject(),
+				Data:         msg.Data(),
+				Headers:      msg.Headers(),
+				NumDelivered: metadata.NumDelivered,
+			})
+			if err != nil {
+				return replayed, fmt.Errorf("nats.ServiceImpl.Replay(): error replaying message %d of '%s': %w", seq, stream, err)
+			}
+			replayed++
+		}
+		if err := batch.Error(); err != nil {
+			return replayed, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+		}
+		if received == 0 {
+			return replayed, fmt.Errorf("nats.ServiceImpl.Replay(): no message received from '%s' within %s", stream, replayFetchWait)
+		}
+		if err := ctx.Err(); err != nil {
+			return replayed, fmt.Errorf("nats.ServiceImpl.Replay(): %w", err)
+		}
+	}
+
+	s.logService.Debug(fmt.Sprintf("replayed %d message(s) of '%s'", replayed, stream))
+	return replayed, nil
+}
+
+// PurgeSubject deletes the messages of the subject from the stream, wildcards are allowed
+func (s *ServiceImpl) PurgeSubject(ctx context.Context, stream string, subject string) error {
+	jsStream, err := s.jetStream.Stream(ctx, stream)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.PurgeSubject(): %w", err)
+	}
+
+	err = jsStream.Purge(ctx, jetstream.WithPurgeSubject(subject))
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.PurgeSubject(): %w", err)
+	}
+
+	s.logService.Debug(fmt.Sprintf("purged '%s' from '%s'", subject, stream))
+	return nil
+}
@@ -0,0 +1,87 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getReplayConsumerConfig_Should_Deliver_All_Without_Start(t *testing.T) {
+	// ACT
+	cfgRes := getReplayConsumerConfig(ReplayRange{Subject: "EVENT.USER.NEW"})
+
+	// ASSERT
+	assert.Equal(t, jetstream.DeliverAllPolicy, cfgRes.DeliverPolicy)
+	assert.Equal(t, []string{"EVENT.USER.NEW"}, cfgRes.FilterSubjects)
+	assert.Nil(t, cfgRes.OptStartTime)
+}
+
+func Test_getReplayConsumerConfig_Should_Start_At_Sequence_Before_Time(t *testing.T) {
+	// ARRANGE
+	since := time.Now().Add(-time.Hour)
+
+	// ACT
+	cfgRes := getReplayConsumerConfig(ReplayRange{FromSeq: 42, Since: since})
+
+	// ASSERT
+	assert.Equal(t, jetstream.DeliverByStartSequencePolicy, cfgRes.DeliverPolicy)
+	assert.Equal(t, uint64(42), cfgRes.OptStartSeq)
+	assert.Nil(t, cfgRes.OptStartTime)
+	assert.Empty(t, cfgRes.FilterSubjects)
+}
+
+func Test_getReplayConsumerConfig_Should_Start_At_Time(t *testing.T) {
+	// ARRANGE
+	since := time.Now().Add(-time.Hour)
+
+	// ACT
+	cfgRes := getReplayConsumerConfig(ReplayRange{Since: since})
+
+	// ASSERT
+	assert.Equal(t, jetstream.DeliverByStartTimePolicy, cfgRes.DeliverPolicy)
+	if assert.NotNil(t, cfgRes.OptStartTime) {
+		assert.Equal(t, since, *cfgRes.OptStartTime)
+	}
+}
+
+func Test_ReplayRange_isPast(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name        string
+		replayRange ReplayRange
+		seq         uint64
+		timestamp   time.Time
+		expected    bool
+	}{
+		{"unbounded", ReplayRange{}, 1000, now, false},
+		{"last sequence", ReplayRange{ToSeq: 10}, 10, now, false},
+		{"after last sequence", ReplayRange{ToSeq: 10}, 11, now, true},
+		{"until", ReplayRange{Until: now}, 1, now, false},
+		{"after until", ReplayRange{Until: now}, 1, now.Add(time.Second), true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.replayRange.isPast(testCase.seq, testCase.timestamp))
+		})
+	}
+}
+
+func Test_Replay_Should_Return_Error_Of_Reversed_Range(t *testing.T) {
+	// ARRANGE
+	service, _, _ := setupMocksForConsumerTest()
+	now := time.Now()
+
+	// ACT
+	replayedSeqRes, errSeqRes := service.Replay(context.Background(), "GO_STREAM", ReplayRange{FromSeq: 10, ToSeq: 9}, nil)
+	replayedTimeRes, errTimeRes := service.Replay(context.Background(), "GO_STREAM", ReplayRange{Since: now, Until: now.Add(-time.Second)}, nil)
+
+	// ASSERT
+	assert.Equal(t, 0, replayedSeqRes)
+	assert.ErrorContains(t, errSeqRes, "invalid replay range")
+	assert.Equal(t, 0, replayedTimeRes)
+	assert.ErrorContains(t, errTimeRes, "invalid replay range")
+}
@@ -0,0 +1,135 @@
+package nats
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+)
+
+const connName = "golang-practice"
+
+const defaultReconnectWait = time.Second
+const maxReconnectWait = 30 * time.Second
+
+// the connection is reestablished forever by default, messages published meanwhile are buffered by the client
+const defaultMaxReconnects = -1
+
+// ConnStatus describes the connection to the NATS servers
+type ConnStatus struct {
+	Connected bool `json:"connected"`
+	// Status is the state of the connection, e.g. CONNECTED or RECONNECTING
+	Status string `json:"status"`
+	// Url is the url of the server the connection is established with, without credentials
+	Url        string `json:"url,omitempty"`
+	Reconnects uint64 `json:"reconnects"`
+	LastError  string `json:"lastError,omitempty"`
+}
+
+// getConnOptions returns the reconnection, authentication and TLS options of the connection, disconnections and
+// reconnections are logged with logService
+func getConnOptions(appConfig *config.AppConfig, logService logger.Service) ([]nats.Option, error) {
+	reconnectWait := defaultReconnectWait
+	if appConfig.NATS_RECONNECT_WAIT != "" {
+		var err error
+		reconnectWait, err = time.ParseDuration(appConfig.NATS_RECONNECT_WAIT)
+		if err != nil || reconnectWait <= 0 {
+			return nil, fmt.Errorf("invalid NATS_RECONNECT_WAIT '%s'", appConfig.NATS_RECONNECT_WAIT)
+		}
+	}
+
+	maxReconnects := defaultMaxReconnects
+	if appConfig.NATS_MAX_RECONNECTS != "" {
+		var err error
+		maxReconnects, err = strconv.Atoi(appConfig.NATS_MAX_RECONNECTS)
+		if err != nil || maxReconnects < -1 {
+			return nil, fmt.Errorf("invalid NATS_MAX_RECONNECTS '%s'", appConfig.NATS_MAX_RECONNECTS)
+		}
+	}
+
+	opts := []nats.Option{
+		nats.Name(connName),
+		nats.MaxReconnects(maxReconnects),
+		nats.CustomReconnectDelay(func(attempts int) time.Duration {
+			return getReconnectDelay(reconnectWait, attempts)
+		}),
+		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
+			if err != nil {
+				logService.Error(fmt.Sprintf("NATS disconnected: %s", err))
+				return
+			}
+			logService.Debug("NATS disconnected")
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			logService.Debug(fmt.Sprintf("NATS reconnected to %s", nc.ConnectedUrlRedacted()))
+		}),
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			if nc.LastError() != nil {
+				logService.Error(fmt.Sprintf("NATS connection closed: %s", nc.LastError()))
+			}
+		}),
+		nats.ErrorHandler(func(nc *nats.Conn, sub *nats.Subscription, err error) {
+			if sub != nil {
+				logService.Error(fmt.Sprintf("NATS error on '%s': %s", sub.Subject, err))
+				return
+			}
+			logService.Error(fmt.Sprintf("NATS error: %s", err))
+		}),
+	}
+
+	if appConfig.NATS_CREDS_FILE != "" && appConfig.NATS_NKEY_SEED_FILE != "" {
+		return nil, fmt.Errorf("NATS_CREDS_FILE and NATS_NKEY_SEED_FILE cannot be used together")
+	}
+	if appConfig.NATS_CREDS_FILE != "" {
+		opts = append(opts, nats.UserCredentials(appConfig.NATS_CREDS_FILE))
+	}
+	if appConfig.NATS_NKEY_SEED_FILE != "" {
+		nkeyOpt, err := nats.NkeyOptionFromSeed(appConfig.NATS_NKEY_SEED_FILE)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, nkeyOpt)
+	}
+
+	if appConfig.NATS_TLS_CA_FILE != "" {
+		opts = append(opts, nats.RootCAs(appConfig.NATS_TLS_CA_FILE))
+	}
+	if (appConfig.NATS_TLS_CERT_FILE == "") != (appConfig.NATS_TLS_KEY_FILE == "") {
+		return nil, fmt.Errorf("NATS_TLS_CERT_FILE and NATS_TLS_KEY_FILE must be set together")
+	}
+	if appConfig.NATS_TLS_CERT_FILE != "" {
+		opts = append(opts, nats.ClientCert(appConfig.NATS_TLS_CERT_FILE, appConfig.NATS_TLS_KEY_FILE))
+	}
+
+	return opts, nil
+}
+
+// getReconnectDelay doubles the wait with every failed attempt up to maxReconnectWait, a jitter of up to a fifth of
+// the delay is added so that every client of a restarted server does not reconnect at the same time
+func getReconnectDelay(wait time.Duration, attempts int) time.Duration {
+	delay := wait
+	for i := 1; i < attempts && delay < maxReconnectWait; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxReconnectWait)
+
+	return delay + time.Duration(rand.Int63n(int64(delay/5)+1))
+}
+
+func (s *ServiceImpl) Status() ConnStatus {
+	status := ConnStatus{
+		Connected:  s.natsCon.IsConnected(),
+		Status:     s.natsCon.Status().String(),
+		Url:        s.natsCon.ConnectedUrlRedacted(),
+		Reconnects: s.natsCon.Stats().Reconnects,
+	}
+	if s.natsCon.LastError() != nil {
+		status.LastError = s.natsCon.LastError().Error()
+	}
+
+	return status
+}
@@ -0,0 +1,103 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// applyConnOptions returns the client options set by the options of getConnOptions
+func applyConnOptions(t *testing.T, opts []nats.Option) nats.Options {
+	options := nats.GetDefaultOptions()
+	for _, opt := range opts {
+		err := opt(&options)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return options
+}
+
+func Test_getConnOptions_Should_Reconnect_With_Backoff(t *testing.T) {
+	// ARRANGE
+	appConfig := &config.AppConfig{NATS_RECONNECT_WAIT: "2s", NATS_MAX_RECONNECTS: "10"}
+
+	// ACT
+	optsRes, errRes := getConnOptions(appConfig, new(logger.ServiceMock))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	options := applyConnOptions(t, optsRes)
+	assert.Equal(t, connName, options.Name)
+	assert.Equal(t, 10, options.MaxReconnect)
+	assert.NotNil(t, options.DisconnectedErrCB)
+	assert.NotNil(t, options.ReconnectedCB)
+	if assert.NotNil(t, options.CustomReconnectDelayCB) {
+		assert.GreaterOrEqual(t, options.CustomReconnectDelayCB(3), 8*time.Second)
+	}
+}
+
+func Test_getConnOptions_Defaults(t *testing.T) {
+	// ACT
+	optsRes, errRes := getConnOptions(&config.AppConfig{}, new(logger.ServiceMock))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	options := applyConnOptions(t, optsRes)
+	assert.Equal(t, defaultMaxReconnects, options.MaxReconnect)
+	assert.Less(t, options.CustomReconnectDelayCB(1), 2*defaultReconnectWait)
+	assert.Nil(t, options.TLSConfig)
+}
+
+func Test_getConnOptions_Invalid_Config(t *testing.T) {
+	testCases := map[string]config.AppConfig{
+		"invalid NATS_RECONNECT_WAIT 'soon'":                              {NATS_RECONNECT_WAIT: "soon"},
+		"invalid NATS_RECONNECT_WAIT '0s'":                                {NATS_RECONNECT_WAIT: "0s"},
+		"invalid NATS_MAX_RECONNECTS '-2'":                                {NATS_MAX_RECONNECTS: "-2"},
+		"NATS_CREDS_FILE and NATS_NKEY_SEED_FILE cannot be used together": {NATS_CREDS_FILE: "user.creds", NATS_NKEY_SEED_FILE: "user.nk"},
+		"NATS_TLS_CERT_FILE and NATS_TLS_KEY_FILE must be set together":   {NATS_TLS_CERT_FILE: "client.pem"},
+	}
+
+	for expectedErr, testCase := range testCases {
+		// ARRANGE
+		appConfig := testCase
+
+		// ACT
+		optsRes, errRes := getConnOptions(&appConfig, new(logger.ServiceMock))
+
+		// ASSERT
+		assert.Nil(t, optsRes)
+		assert.EqualError(t, errRes, expectedErr)
+	}
+}
+
+func Test_getConnOptions_Should_Return_Error_Of_Missing_Nkey_Seed(t *testing.T) {
+	// ACT
+	_, errRes := getConnOptions(&config.AppConfig{NATS_NKEY_SEED_FILE: "/does/not/exist.nk"}, new(logger.ServiceMock))
+
+	// ASSERT
+	assert.NotNil(t, errRes)
+}
+
+func Test_getReconnectDelay(t *testing.T) {
+	testCases := map[int]time.Duration{
+		1:   time.Second,
+		2:   2 * time.Second,
+		4:   8 * time.Second,
+		10:  maxReconnectWait,
+		100: maxReconnectWait,
+	}
+
+	for attempts, expectedDelay := range testCases {
+		// ACT
+		delayRes := getReconnectDelay(time.Second, attempts)
+
+		// ASSERT
+		assert.GreaterOrEqual(t, delayRes, expectedDelay)
+		assert.LessOrEqual(t, delayRes, expectedDelay+expectedDelay/5)
+	}
+}
@@ -0,0 +1,187 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func (s *ServiceImpl) Subscribe(stream string, registry *Registry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	handlers := registry.Handlers()
+	jsStream, err := s.createStream(ctx, stream, handlers)
+	if err != nil {
+		return err
+	}
+
+	err = s.createDlqStream(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, handler := range handlers {
+		err = s.consume(ctx, jsStream, handler)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.logService.Debug(fmt.Sprintf("NATS consumers started for %d handlers", len(handlers)))
+	return nil
+}
+
+// consume creates the durable consumer of the handler and dispatches its messages to at most handler.Concurrency
+// goroutines at a time
+func (s *ServiceImpl) consume(ctx context.Context, stream jetstream.Stream, handler Handler) error {
+	if handler.MaxDeliver == 0 {
+		handler.MaxDeliver = s.maxDeliver
+	}
+
+	con, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       handler.Durable,
+		FilterSubject: handler.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		ReplayPolicy:  jetstream.ReplayInstantPolicy,
+		// the server never hands out more unacknowledged messages than the handler processes at the same time
+		MaxAckPending: handler.Concurrency,
+		// the server stops redelivering if the message could not be moved to the dead letter stream either
+		MaxDeliver: handler.MaxDeliver + dlqPublishAttempts,
+	})
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.Subscribe(): error creating consumer '%s': %w", handler.Durable, err)
+	}
+
+	slots := make(chan struct{}, handler.Concurrency)
+	consumeCtx, err := con.Consume(func(msg jetstream.Msg) {
+		// blocking here stops the consumer from pulling more messages until a slot is free
+		slots <- struct{}{}
+		if !s.startHandling() {
+			// the message is redelivered once the ack wait has passed
+			<-slots
+			return
+		}
+		go func() {
+			defer func() {
+				<-slots
+				s.inFlight.Done()
+			}()
+			s.handleMsg(handler, msg)
+		}()
+	}, jetstream.PullMaxMessages(handler.Concurrency), jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		s.logService.Error(fmt.Sprintf("nats.ServiceImpl.Subscribe(): error consuming '%s': %s", handler.Durable, err))
+	}))
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.Subscribe(): error setting consumer handler '%s': %w", handler.Durable, err)
+	}
+
+	s.mu.Lock()
+	s.consumeCtxs = append(s.consumeCtxs, consumeCtx)
+	s.mu.Unlock()
+
+	s.logService.Debug(fmt.Sprintf("NATS consumer '%s' created for '%s'", handler.Durable, handler.Subject))
+	return nil
+}
+
+// startHandling registers a message being handled, false is returned once the service is draining
+func (s *ServiceImpl) startHandling() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return false
+	}
+	s.inFlight.Add(1)
+
+	return true
+}
+
+// handleMsg acknowledges the message when the handler succeeds. Failed messages are redelivered with a backoff until
+// they have been delivered handler.MaxDeliver times, they are then moved to the dead letter stream.
+func (s *ServiceImpl) handleMsg(handler Handler, msg jetstream.Msg) {
+	numDelivered := uint64(1)
+	if metadata, err := msg.Metadata(); err == nil {
+		numDelivered = metadata.NumDelivered
+	}
+
+	// the handler has already failed on its last delivery, only moving the message to the dead letter stream failed
+	if numDelivered > uint64(handler.MaxDeliver) {
+		s.deadLetter(handler, msg, numDelivered, fmt.Errorf("failed on %d deliveries", handler.MaxDeliver))
+		return
+	}
+
+	err := handler.Handle(s.handlerCtx, Msg{
+		Subject:      msg.Subject(),
+		Data:         msg.Data(),
+		Headers:      msg.Headers(),
+		NumDelivered: numDelivered,
+	})
+	if err == nil {
+		s.ackOrLog(handler, msg.Ack())
+		return
+	}
+
+	if numDelivered >= uint64(handler.MaxDeliver) {
+		s.deadLetter(handler, msg, numDelivered, err)
+		return
+	}
+
+	delay := getNakDelay(numDelivered)
+	s.logService.Error(fmt.Sprintf("nats.ServiceImpl.handleMsg(): handler '%s' failed on delivery %d, redelivering in %s: %s", handler.Durable, numDelivered, delay, err))
+	s.ackOrLog(handler, msg.NakWithDelay(delay))
+}
+
+func (s *ServiceImpl) ackOrLog(handler Handler, err error) {
+	if err != nil {
+		s.logService.Error(fmt.Sprintf("nats.ServiceImpl.handleMsg(): error acknowledging message of '%s': %s", handler.Durable, err))
+	}
+}
+
+// Drain stops the consumers and waits for the messages being handled. When ctx is done first, the context given to
+// the handlers is cancelled and the messages which are not acknowledged yet are redelivered after the ack wait.
+func (s *ServiceImpl) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	for _, consumeCtx := range s.consumeCtxs {
+		consumeCtx.Stop()
+	}
+	s.consumeCtxs = nil
+	// the other members of the queue groups answer the requests from now on
+	for _, sub := range s.subs {
+		err := sub.Unsubscribe()
+		if err != nil {
+			s.logService.Error(fmt.Sprintf("nats.ServiceImpl.Drain(): error stopping responder of '%s': %s", sub.Subject, err))
+		}
+	}
+	hasStoppedResponders := len(s.subs) > 0
+	s.subs = nil
+	s.mu.Unlock()
+
+	// the server keeps routing requests to the responders until it has received their unsubscriptions
+	if hasStoppedResponders {
+		flushCtx, cancelFlush := withRpcTimeout(ctx, s.rpcTimeout)
+		err := s.natsCon.FlushWithContext(flushCtx)
+		cancelFlush()
+		if err != nil {
+			s.logService.Error(fmt.Sprintf("nats.ServiceImpl.Drain(): error flushing the unsubscriptions: %s", err))
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logService.Debug("NATS consumers drained")
+		return nil
+	case <-ctx.Done():
+		s.cancelHandlers()
+		return fmt.Errorf("nats.ServiceImpl.Drain(): %w", ctx.Err())
+	}
+}
@@ -0,0 +1,224 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+)
+
+const defaultMaxDeliver = 5
+
+// dlqPublishAttempts are the deliveries left to a message after its last handling attempt, they are only used to move
+// the message to the dead letter stream when publishing it failed the first time
+const dlqPublishAttempts = 3
+
+// failed messages are redelivered with an exponential backoff
+const nakBaseDelay = time.Second
+const nakMaxDelay = time.Minute
+
+// dead letters are published to "DLQ.<original subject>"
+const dlqSubjectPrefix = "DLQ."
+
+// headers added to dead letters, the headers of the original message are kept as well
+const (
+	DlqHeaderSubject      = "Dlq-Subject"
+	DlqHeaderDurable      = "Dlq-Durable"
+	DlqHeaderError        = "Dlq-Error"
+	DlqHeaderNumDelivered = "Dlq-Num-Delivered"
+	DlqHeaderFailedAt     = "Dlq-Failed-At"
+)
+
+// DeadLetter is a message whose handler has failed on every delivery
+type DeadLetter struct {
+	// Sequence is the sequence of the dead letter in the dead letter stream
+	Sequence     uint64
+	Subject      string
+	Durable      string
+	Error        string
+	NumDelivered uint64
+	FailedAt     time.Time
+	Data         []byte
+	// Headers are the headers of the original message
+	Headers nats.Header
+}
+
+func getMaxDeliver(appConfig *config.AppConfig) (int, error) {
+	if appConfig.NATS_MAX_DELIVER == "" {
+		return defaultMaxDeliver, nil
+	}
+
+	maxDeliver, err := strconv.Atoi(appConfig.NATS_MAX_DELIVER)
+	if err != nil || maxDeliver < 1 {
+		return 0, fmt.Errorf("invalid NATS_MAX_DELIVER '%s'", appConfig.NATS_MAX_DELIVER)
+	}
+
+	return maxDeliver, nil
+}
+
+// getDlqStream returns NATS_DLQ_STREAM, or the name of the stream followed by "_DLQ" when it is not set
+func getDlqStream(appConfig *config.AppConfig) string {
+	if appConfig.NATS_DLQ_STREAM != "" {
+		return appConfig.NATS_DLQ_STREAM
+	}
+
+	return appConfig.NATS_STREAM + "_DLQ"
+}
+
+// getNakDelay doubles the redelivery delay with every delivery up to nakMaxDelay
+func getNakDelay(numDelivered uint64) time.Duration {
+	delay := nakBaseDelay
+	for i := uint64(1); i < numDelivered && delay < nakMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, nakMaxDelay)
+}
+
+func (s *ServiceImpl) createDlqStream(ctx context.Context) error {
+	_, err := s.jetStream.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     s.dlqStream,
+		Subjects: []string{dlqSubjectPrefix + ">"},
+	})
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.createDlqStream(): %w", err)
+	}
+
+	return nil
+}
+
+// deadLetter publishes the message to the dead letter stream and terminates it, the message is redelivered when it
+// cannot be published
+func (s *ServiceImpl) deadLetter(handler Handler, msg jetstream.Msg, numDelivered uint64, handleErr error) {
+	header := getDeadLetterHeader(msg.Headers(), msg.Subject(), handler.Durable, numDelivered, handleErr)
+
+	ctx, cancel := context.WithTimeout(s.handlerCtx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.jetStream.PublishMsg(ctx, &nats.Msg{
+		Subject: dlqSubjectPrefix + msg.Subject(),
+		Data:    msg.Data(),
+		Header:  header,
+	})
+	if err != nil {
+		s.logService.Error(fmt.Sprintf("nats.ServiceImpl.deadLetter(): error moving message of '%s' to '%s': %s", handler.Durable, s.dlqStream, err))
+		s.ackOrLog(handler, msg.NakWithDelay(getNakDelay(numDelivered)))
+		return
+	}
+
+	s.logService.Error(fmt.Sprintf("nats.ServiceImpl.deadLetter(): moved message of '%s' to '%s' after %d deliveries: %s", handler.Durable, s.dlqStream, numDelivered, handleErr))
+	s.ackOrLog(handler, msg.Term())
+}
+
+// getDeadLetterHeader returns the headers of the original message along with the ones describing the failure
+func getDeadLetterHeader(msgHeader nats.Header, subject string, durable string, numDelivered uint64, handleErr error) nats.Header {
+	header := nats.Header{}
+	for key, values := range msgHeader {
+		header[key] = values
+	}
+	// the stream drops messages with the Nats-Msg-Id of a message it has stored within its duplicate window, which
+	// would drop the dead letters of the other handlers of the message and the replayed message
+	header.Del(jetstream.MsgIDHeader)
+	header.Set(DlqHeaderSubject, subject)
+	header.Set(DlqHeaderDurable, durable)
+	header.Set(DlqHeaderError, handleErr.Error())
+	header.Set(DlqHeaderNumDelivered, strconv.FormatUint(numDelivered, 10))
+	header.Set(DlqHeaderFailedAt, time.Now().UTC().Format(time.RFC3339))
+
+	return header
+}
+
+// ListDeadLetters returns up to limit dead letters, oldest first
+func (s *ServiceImpl) ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	stream, err := s.jetStream.Stream(ctx, s.dlqStream)
+	if err != nil {
+		return nil, fmt.Errorf("nats.ServiceImpl.ListDeadLetters(): %w", err)
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("nats.ServiceImpl.ListDeadLetters(): %w", err)
+	}
+
+	deadLetters := []DeadLetter{}
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq && len(deadLetters) < limit; seq++ {
+		rawMsg, err := stream.GetMsg(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			// replayed dead letters are deleted from the stream
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("nats.ServiceImpl.ListDeadLetters(): %w", err)
+		}
+
+		deadLetters = append(deadLetters, toDeadLetter(rawMsg))
+	}
+
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter publishes the dead letter back to its original subject and removes it from the dead letter stream.
+// Every consumer of the subject receives the replayed message, not only the one which has failed.
+func (s *ServiceImpl) ReplayDeadLetter(ctx context.Context, sequence uint64) error {
+	stream, err := s.jetStream.Stream(ctx, s.dlqStream)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.ReplayDeadLetter(): %w", err)
+	}
+
+	rawMsg, err := stream.GetMsg(ctx, sequence)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.ReplayDeadLetter(): %w", err)
+	}
+	deadLetter := toDeadLetter(rawMsg)
+
+	_, err = s.jetStream.PublishMsg(ctx, &nats.Msg{
+		Subject: deadLetter.Subject,
+		Data:    deadLetter.Data,
+		Header:  deadLetter.Headers,
+	})
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.ReplayDeadLetter(): %w", err)
+	}
+
+	err = stream.DeleteMsg(ctx, sequence)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.ReplayDeadLetter(): replayed but not deleted: %w", err)
+	}
+
+	s.logService.Debug(fmt.Sprintf("replayed dead letter %d to '%s'", sequence, deadLetter.Subject))
+	return nil
+}
+
+func toDeadLetter(rawMsg *jetstream.RawStreamMsg) DeadLetter {
+	deadLetter := DeadLetter{
+		Sequence: rawMsg.Sequence,
+		Subject:  strings.TrimPrefix(rawMsg.Subject, dlqSubjectPrefix),
+		Data:     rawMsg.Data,
+		Headers:  nats.Header{},
+	}
+
+	for key, values := range rawMsg.Header {
+		switch key {
+		case DlqHeaderSubject:
+			deadLetter.Subject = values[0]
+		case DlqHeaderDurable:
+			deadLetter.Durable = values[0]
+		case DlqHeaderError:
+			deadLetter.Error = values[0]
+		case DlqHeaderNumDelivered:
+			deadLetter.NumDelivered, _ = strconv.ParseUint(values[0], 10, 64)
+		case DlqHeaderFailedAt:
+			deadLetter.FailedAt, _ = time.Parse(time.RFC3339, values[0])
+		default:
+			deadLetter.Headers[key] = values
+		}
+	}
+
+	return deadLetter
+}
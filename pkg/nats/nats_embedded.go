@@ -0,0 +1,65 @@
+package nats
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+)
+
+const embeddedStartTimeout = 10 * time.Second
+
+// EmbeddedServer is a single JetStream enabled nats-server running inside the process, for development and tests
+type EmbeddedServer struct {
+	server   *server.Server
+	storeDir string
+}
+
+// StartEmbeddedServer starts a server listening on a random port of localhost. The streams are stored in a temporary
+// directory removed on Shutdown, so they only live as long as the server.
+func StartEmbeddedServer(logService logger.Service) (*EmbeddedServer, error) {
+	storeDir, err := os.MkdirTemp("", "nats-embedded-")
+	if err != nil {
+		return nil, fmt.Errorf("nats.StartEmbeddedServer(): %w", err)
+	}
+
+	ns, err := server.NewServer(&server.Options{
+		ServerName: "golang-practice-embedded",
+		Host:       "127.0.0.1",
+		Port:       server.RANDOM_PORT,
+		JetStream:  true,
+		StoreDir:   storeDir,
+		// the process handles its own signals and logs
+		NoSigs: true,
+		NoLog:  true,
+	})
+	if err != nil {
+		os.RemoveAll(storeDir)
+		return nil, fmt.Errorf("nats.StartEmbeddedServer(): %w", err)
+	}
+
+	ns.Start()
+	if !ns.ReadyForConnections(embeddedStartTimeout) {
+		ns.Shutdown()
+		os.RemoveAll(storeDir)
+		return nil, fmt.Errorf("nats.StartEmbeddedServer(): server not ready after %s", embeddedStartTimeout)
+	}
+
+	logService.Debug(fmt.Sprintf("embedded NATS server listening on %s", ns.ClientURL()))
+
+	return &EmbeddedServer{server: ns, storeDir: storeDir}, nil
+}
+
+// Url returns the url clients connect to
+func (e *EmbeddedServer) Url() string {
+	return e.server.ClientURL()
+}
+
+// Shutdown stops the server and removes its streams
+func (e *EmbeddedServer) Shutdown() {
+	e.server.Shutdown()
+	e.server.WaitForShutdown()
+	os.RemoveAll(e.storeDir)
+}
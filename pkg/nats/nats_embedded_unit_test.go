@@ -0,0 +1,39 @@
+package nats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_StartEmbeddedServer_Should_Serve_JetStream(t *testing.T) {
+	// ARRANGE
+	logServiceMock := &logger.ServiceMock{}
+	logServiceMock.On("Debug", mock.Anything).Return()
+
+	// ACT
+	embeddedServer, errRes := StartEmbeddedServer(logServiceMock)
+
+	// ASSERT
+	if !assert.Nil(t, errRes) {
+		return
+	}
+	defer embeddedServer.Shutdown()
+
+	service, err := NewPubService(&config.AppConfig{NATS_URL: embeddedServer.Url(), NATS_STREAM: "GO_STREAM", NATS_EVENT_USER_REGISTRATION: "EVENT.USER.NEW"}, logServiceMock)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer service.Close()
+
+	assert.Nil(t, service.Subscribe("GO_STREAM", NewRegistry()))
+	assert.Nil(t, service.PublishCtx(context.Background(), "EVENT.USER.NEW", []byte(`{}`)), "should store the message in the stream")
+
+	streams, err := service.ListStreams(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, streams, 2, "should only have the streams of the service")
+}
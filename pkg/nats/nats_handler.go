@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+const defaultHandlerConcurrency = 1
+
+// Msg is a message consumed from JetStream, or published to it with PublishBatch
+type Msg struct {
+	Subject string
+	Data    []byte
+	Headers nats.Header
+	// NumDelivered is 1 for the first delivery and is incremented on every redelivery, it is ignored when publishing
+	NumDelivered uint64
+}
+
+// HandlerFunc processes a message, the message is acknowledged when nil is returned and redelivered with a backoff
+// otherwise
+type HandlerFunc func(ctx context.Context, msg Msg) error
+
+// Handler consumes the messages of one subject with its own durable consumer, so every handler keeps its own position
+// in the stream and a slow handler does not hold the others back
+type Handler struct {
+	Subject string
+	// Durable is the name of the consumer, it must be unique in the stream and stable across deployments
+	Durable string
+	// Concurrency is the number of messages processed at the same time, 1 when not set
+	Concurrency int
+	// MaxDeliver is the number of deliveries before the message is moved to the dead letter stream, NATS_MAX_DELIVER
+	// when not set
+	MaxDeliver int
+	Handle     HandlerFunc
+}
+
+// JsonHandler returns a HandlerFunc which decodes the JSON payload into T before calling fn, messages which cannot be
+// decoded are returned as errors
+func JsonHandler[T any](fn func(ctx context.Context, payload T, msg Msg) error) HandlerFunc {
+	return func(ctx context.Context, msg Msg) error {
+		var payload T
+		err := json.Unmarshal(msg.Data, &payload)
+		if err != nil {
+			return fmt.Errorf("error decoding message of '%s': %w", msg.Subject, err)
+		}
+
+		return fn(ctx, payload, msg)
+	}
+}
+
+// Registry holds the handlers of the subjects, it is filled while wiring the app and given to Subscribe
+type Registry struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds the handler, a durable can only be registered once but several handlers may consume the same subject,
+// each of them receives every message of the subject
+func (r *Registry) Register(handler Handler) error {
+	if handler.Subject == "" || handler.Durable == "" || handler.Handle == nil {
+		return fmt.Errorf("nats.Registry.Register(): subject, durable and handle are required")
+	}
+	if handler.Concurrency < 0 {
+		return fmt.Errorf("nats.Registry.Register(): invalid concurrency %d for '%s'", handler.Concurrency, handler.Subject)
+	}
+	if handler.MaxDeliver < 0 {
+		return fmt.Errorf("nats.Registry.Register(): invalid max deliver %d for '%s'", handler.MaxDeliver, handler.Subject)
+	}
+	if handler.Concurrency == 0 {
+		handler.Concurrency = defaultHandlerConcurrency
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, registered := range r.handlers {
+		if registered.Durable == handler.Durable {
+			return fmt.Errorf("nats.Registry.Register(): the durable '%s' is already used", handler.Durable)
+		}
+	}
+	r.handlers = append(r.handlers, handler)
+
+	return nil
+}
+
+// Handlers returns the registered handlers in the order of registration
+func (r *Registry) Handlers() []Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Handler{}, r.handlers...)
+}
@@ -0,0 +1,391 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeMsg is a jetstream.Msg which records how it has been acknowledged
+type fakeMsg struct {
+	subject      string
+	data         []byte
+	numDelivered uint64
+	acked        bool
+	naked        bool
+	nakDelay     time.Duration
+	termed       bool
+}
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: m.numDelivered}, nil
+}
+func (m *fakeMsg) Data() []byte { return m.data }
+func (m *fakeMsg) Headers() nats.Header {
+	return nats.Header{"Trace-Id": []string{"trace"}, jetstream.MsgIDHeader: []string{"msg-id"}}
+}
+func (m *fakeMsg) Subject() string                 { return m.subject }
+func (m *fakeMsg) Reply() string                   { return "" }
+func (m *fakeMsg) Ack() error                      { m.acked = true; return nil }
+func (m *fakeMsg) DoubleAck(context.Context) error { m.acked = true; return nil }
+func (m *fakeMsg) Nak() error                      { m.naked = true; return nil }
+func (m *fakeMsg) NakWithDelay(delay time.Duration) error {
+	m.naked = true
+	m.nakDelay = delay
+	return nil
+}
+func (m *fakeMsg) InProgress() error { return nil }
+func (m *fakeMsg) Term() error       { m.termed = true; return nil }
+
+// fakeJetStream records the messages published with PublishMsg and PublishMsgAsync, the other methods are not
+// implemented
+type fakeJetStream struct {
+	jetstream.JetStream
+	published  []*nats.Msg
+	publishErr error
+	// asyncFutures receives the futures of PublishMsgAsync, which are acknowledged at once when it is nil
+	asyncFutures chan *fakePubAckFuture
+}
+
+func (js *fakeJetStream) PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if js.publishErr != nil {
+		return nil, js.publishErr
+	}
+	js.published = append(js.published, msg)
+	return &jetstream.PubAck{}, nil
+}
+
+func (js *fakeJetStream) PublishMsgAsync(msg *nats.Msg, opts ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	if js.publishErr != nil {
+		return nil, js.publishErr
+	}
+	js.published = append(js.published, msg)
+
+	future := &fakePubAckFuture{msg: msg, ok: make(chan *jetstream.PubAck, 1), err: make(chan error, 1)}
+	if js.asyncFutures == nil {
+		future.ok <- &jetstream.PubAck{Stream: "GO_STREAM"}
+	} else {
+		js.asyncFutures <- future
+	}
+	return future, nil
+}
+
+// fakePubAckFuture is resolved by sending to ok or err
+type fakePubAckFuture struct {
+	msg *nats.Msg
+	ok  chan *jetstream.PubAck
+	err chan error
+}
+
+func (f *fakePubAckFuture) Ok() <-chan *jetstream.PubAck { return f.ok }
+func (f *fakePubAckFuture) Err() <-chan error            { return f.err }
+func (f *fakePubAckFuture) Msg() *nats.Msg               { return f.msg }
+
+// setupMocksForConsumerTest creates ServiceImpl without a connection, which is enough to dispatch messages
+func setupMocksForConsumerTest() (*ServiceImpl, *logger.ServiceMock, *fakeJetStream) {
+	logServiceMock := new(logger.ServiceMock)
+	jetStream := &fakeJetStream{}
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
+	service := &ServiceImpl{
+		logService:        logServiceMock,
+		jetStream:         jetStream,
+		dlqStream:         "GO_STREAM_DLQ",
+		maxDeliver:        defaultMaxDeliver,
+		publishMaxPending: defaultPublishMaxPending,
+		handlerCtx:        handlerCtx,
+		cancelHandlers:    cancelHandlers,
+	}
+	return service, logServiceMock, jetStream
+}
+
+func genHandler(subject string, durable string) Handler {
+	return Handler{
+		Subject:    subject,
+		Durable:    durable,
+		MaxDeliver: 3,
+		Handle: func(ctx context.Context, msg Msg) error {
+			return nil
+		},
+	}
+}
+
+func Test_Registry_Register_Should_Default_Concurrency(t *testing.T) {
+	// ARRANGE
+	registry := NewRegistry()
+
+	// ACT
+	errRes := registry.Register(genHandler("EVENT.USER.NEW", "user_service_registration"))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Len(t, registry.Handlers(), 1)
+	assert.Equal(t, defaultHandlerConcurrency, registry.Handlers()[0].Concurrency)
+}
+
+func Test_Registry_Register_Should_Accept_Handlers_Of_The_Same_Subject(t *testing.T) {
+	// ARRANGE
+	registry := NewRegistry()
+
+	// ACT
+	errRes := registry.Register(genHandler("EVENT.USER.NEW", "user_service_registration"))
+	errSameSubjectRes := registry.Register(genHandler("EVENT.USER.NEW", "webhook_delivery_registration"))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Nil(t, errSameSubjectRes)
+	assert.Len(t, registry.Handlers(), 2)
+}
+
+func Test_Registry_Register_Should_Reject_Invalid_Handlers(t *testing.T) {
+	// ARRANGE
+	registry := NewRegistry()
+	err := registry.Register(genHandler("EVENT.USER.NEW", "user_service_registration"))
+	assert.Nil(t, err)
+
+	invalidConcurrency := genHandler("EVENT.USER.OTHER", "other")
+	invalidConcurrency.Concurrency = -1
+	testCases := []Handler{
+		genHandler("", "no_subject"),
+		genHandler("EVENT.USER.OTHER", ""),
+		{Subject: "EVENT.USER.OTHER", Durable: "no_handle"},
+		invalidConcurrency,
+		genHandler("EVENT.USER.OTHER", "user_service_registration"),
+	}
+
+	for _, testCase := range testCases {
+		// ACT
+		errRes := registry.Register(testCase)
+
+		// ASSERT
+		assert.NotNil(t, errRes, testCase.Durable)
+	}
+	assert.Len(t, registry.Handlers(), 1)
+}
+
+func Test_JsonHandler_Should_Decode_Payload(t *testing.T) {
+	// ARRANGE
+	type payload struct {
+		Id string `json:"id"`
+	}
+	var payloadRes payload
+	handle := JsonHandler(func(ctx context.Context, p payload, msg Msg) error {
+		payloadRes = p
+		return nil
+	})
+
+	// ACT
+	errRes := handle(context.Background(), Msg{Subject: "EVENT.USER.NEW", Data: []byte(`{"id":"1"}`)})
+	errInvalidRes := handle(context.Background(), Msg{Subject: "EVENT.USER.NEW", Data: []byte(`not json`)})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, payload{Id: "1"}, payloadRes)
+	assert.ErrorContains(t, errInvalidRes, "error decoding message of 'EVENT.USER.NEW'")
+}
+
+func Test_handleMsg_Should_Ack_On_Success(t *testing.T) {
+	// ARRANGE
+	service, _, _ := setupMocksForConsumerTest()
+	msg := &fakeMsg{subject: "EVENT.USER.NEW", data: []byte("{}"), numDelivered: 2}
+
+	var msgRes Msg
+	handler := genHandler("EVENT.USER.NEW", "user_service_registration")
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		msgRes = msg
+		return nil
+	}
+
+	// ACT
+	service.handleMsg(handler, msg)
+
+	// ASSERT
+	assert.True(t, msg.acked)
+	assert.False(t, msg.naked)
+	assert.Equal(t, "EVENT.USER.NEW", msgRes.Subject)
+	assert.Equal(t, uint64(2), msgRes.NumDelivered)
+}
+
+func Test_handleMsg_Should_Nak_With_Backoff_On_Error(t *testing.T) {
+	// ARRANGE
+	service, logServiceMock, jetStream := setupMocksForConsumerTest()
+	msg := &fakeMsg{subject: "EVENT.USER.NEW", data: []byte("{}"), numDelivered: 2}
+
+	handler := genHandler("EVENT.USER.NEW", "user_service_registration")
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		return fmt.Errorf("error from handler")
+	}
+
+	logServiceMock.On("Error", mock.Anything)
+
+	// ACT
+	service.handleMsg(handler, msg)
+
+	// ASSERT
+	assert.False(t, msg.acked)
+	assert.True(t, msg.naked)
+	assert.Equal(t, 2*time.Second, msg.nakDelay)
+	assert.Empty(t, jetStream.published)
+	logServiceMock.AssertCalled(t, "Error", "nats.ServiceImpl.handleMsg(): handler 'user_service_registration' failed on delivery 2, redelivering in 2s: error from handler")
+}
+
+func Test_handleMsg_Should_Move_To_Dlq_On_Last_Delivery(t *testing.T) {
+	// ARRANGE
+	service, logServiceMock, jetStream := setupMocksForConsumerTest()
+	msg := &fakeMsg{subject: "EVENT.USER.NEW", data: []byte(`{"id":"1"}`), numDelivered: 3}
+
+	handler := genHandler("EVENT.USER.NEW", "user_service_registration")
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		return fmt.Errorf("error from handler")
+	}
+
+	logServiceMock.On("Error", mock.Anything)
+
+	// ACT
+	service.handleMsg(handler, msg)
+
+	// ASSERT
+	assert.True(t, msg.termed)
+	assert.False(t, msg.naked)
+	if assert.Len(t, jetStream.published, 1) {
+		deadLetter := jetStream.published[0]
+		assert.Equal(t, "DLQ.EVENT.USER.NEW", deadLetter.Subject)
+		assert.Equal(t, msg.data, deadLetter.Data)
+		assert.Equal(t, "EVENT.USER.NEW", deadLetter.Header.Get(DlqHeaderSubject))
+		assert.Equal(t, "user_service_registration", deadLetter.Header.Get(DlqHeaderDurable))
+		assert.Equal(t, "error from handler", deadLetter.Header.Get(DlqHeaderError))
+		assert.Equal(t, "3", deadLetter.Header.Get(DlqHeaderNumDelivered))
+		assert.Equal(t, "trace", deadLetter.Header.Get("Trace-Id"))
+		assert.Empty(t, deadLetter.Header.Get(jetstream.MsgIDHeader))
+	}
+}
+
+func Test_handleMsg_Should_Nak_If_Moving_To_Dlq_Fails(t *testing.T) {
+	// ARRANGE
+	service, logServiceMock, jetStream := setupMocksForConsumerTest()
+	jetStream.publishErr = fmt.Errorf("nats: no response from stream")
+	msg := &fakeMsg{subject: "EVENT.USER.NEW", data: []byte("{}"), numDelivered: 4}
+
+	handled := false
+	handler := genHandler("EVENT.USER.NEW", "user_service_registration")
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		handled = true
+		return nil
+	}
+
+	logServiceMock.On("Error", mock.Anything)
+
+	// ACT
+	service.handleMsg(handler, msg)
+
+	// ASSERT
+	assert.False(t, handled, "should not handle a message which has exceeded its deliveries")
+	assert.False(t, msg.termed)
+	assert.True(t, msg.naked)
+}
+
+func Test_getNakDelay(t *testing.T) {
+	assert.Equal(t, time.Second, getNakDelay(1))
+	assert.Equal(t, 4*time.Second, getNakDelay(3))
+	assert.Equal(t, nakMaxDelay, getNakDelay(7))
+	assert.Equal(t, nakMaxDelay, getNakDelay(1000))
+}
+
+func Test_toDeadLetter_Should_Split_Dlq_Headers(t *testing.T) {
+	// ARRANGE
+	rawMsg := &jetstream.RawStreamMsg{
+		Subject:  "DLQ.EVENT.USER.NEW",
+		Sequence: 7,
+		Data:     []byte("{}"),
+		Header: nats.Header{
+			DlqHeaderSubject:      []string{"EVENT.USER.NEW"},
+			DlqHeaderDurable:      []string{"user_service_registration"},
+			DlqHeaderError:        []string{"error from handler"},
+			DlqHeaderNumDelivered: []string{"5"},
+			DlqHeaderFailedAt:     []string{"2024-01-02T03:04:05Z"},
+			"Trace-Id":            []string{"trace"},
+		},
+	}
+
+	// ACT
+	res := toDeadLetter(rawMsg)
+
+	// ASSERT
+	assert.Equal(t, DeadLetter{
+		Sequence:     7,
+		Subject:      "EVENT.USER.NEW",
+		Durable:      "user_service_registration",
+		Error:        "error from handler",
+		NumDelivered: 5,
+		FailedAt:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data:         []byte("{}"),
+		Headers:      nats.Header{"Trace-Id": []string{"trace"}},
+	}, res)
+}
+
+func Test_getMaxDeliver(t *testing.T) {
+	maxDeliver, err := getMaxDeliver(&config.AppConfig{})
+	assert.Nil(t, err)
+	assert.Equal(t, defaultMaxDeliver, maxDeliver)
+
+	maxDeliver, err = getMaxDeliver(&config.AppConfig{NATS_MAX_DELIVER: "8"})
+	assert.Nil(t, err)
+	assert.Equal(t, 8, maxDeliver)
+
+	_, err = getMaxDeliver(&config.AppConfig{NATS_MAX_DELIVER: "0"})
+	assert.EqualError(t, err, "invalid NATS_MAX_DELIVER '0'")
+}
+
+func Test_getDlqStream(t *testing.T) {
+	assert.Equal(t, "DEAD_LETTERS", getDlqStream(&config.AppConfig{NATS_STREAM: "GO_STREAM", NATS_DLQ_STREAM: "DEAD_LETTERS"}))
+	assert.Equal(t, "GO_STREAM_DLQ", getDlqStream(&config.AppConfig{NATS_STREAM: "GO_STREAM"}))
+}
+
+func Test_Drain_Should_Wait_For_Messages_Being_Handled(t *testing.T) {
+	// ARRANGE
+	service, logServiceMock, _ := setupMocksForConsumerTest()
+	logServiceMock.On("Debug", mock.Anything)
+
+	assert.True(t, service.startHandling())
+	handled := false
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		handled = true
+		service.inFlight.Done()
+	}()
+
+	// ACT
+	errRes := service.Drain(context.Background())
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.True(t, handled)
+	assert.False(t, service.startHandling(), "should not handle messages once draining")
+}
+
+func Test_Drain_Should_Cancel_Handlers_On_Timeout(t *testing.T) {
+	// ARRANGE
+	service, _, _ := setupMocksForConsumerTest()
+	assert.True(t, service.startHandling())
+	go func() {
+		<-service.handlerCtx.Done()
+		service.inFlight.Done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// ACT
+	errRes := service.Drain(ctx)
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, context.DeadlineExceeded)
+	assert.ErrorIs(t, service.handlerCtx.Err(), context.Canceled)
+}
@@ -0,0 +1,450 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+)
+
+// memDuplicateWindow is how long a Nats-Msg-Id is remembered, the default duplicate window of JetStream streams
+const memDuplicateWindow = 2 * time.Minute
+
+var ErrMemClosed = errors.New("nats.MemServiceImpl: event bus is closed")
+var ErrMemNoStream = errors.New("nats.MemServiceImpl: no stream for subject")
+var ErrMemMsgNotFound = errors.New("nats.MemServiceImpl: message not found")
+
+// memMsg is a message stored in a stream of the event bus
+type memMsg struct {
+	Seq     uint64      `json:"seq"`
+	Subject string      `json:"subject"`
+	Data    []byte      `json:"data"`
+	Headers nats.Header `json:"headers,omitempty"`
+	Time    time.Time   `json:"time"`
+}
+
+type memMsgId struct {
+	id       string
+	storedAt time.Time
+}
+
+// memStream stores the messages of its subjects in the order of their sequence, like a JetStream stream with the
+// limits retention policy and no limit
+type memStream struct {
+	name      string
+	subjects  []string
+	msgs      []memMsg
+	lastSeq   uint64
+	consumers map[string]*memConsumer
+	// msgIds are the Nats-Msg-Id headers stored within the duplicate window, oldest first in msgIdQueue
+	msgIds     map[string]uint64
+	msgIdQueue []memMsgId
+}
+
+func newMemStream(name string) *memStream {
+	return &memStream{
+		name:      name,
+		consumers: map[string]*memConsumer{},
+		msgIds:    map[string]uint64{},
+	}
+}
+
+func (st *memStream) matches(subject string) bool {
+	for _, filter := range st.subjects {
+		if matchSubject(filter, subject) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// index returns the position of the first message whose sequence is seq or more
+func (st *memStream) index(seq uint64) int {
+	return sort.Search(len(st.msgs), func(i int) bool {
+		return st.msgs[i].Seq >= seq
+	})
+}
+
+func (st *memStream) get(seq uint64) (memMsg, bool) {
+	i := st.index(seq)
+	if i == len(st.msgs) || st.msgs[i].Seq != seq {
+		return memMsg{}, false
+	}
+
+	return st.msgs[i], true
+}
+
+// pruneMsgIds forgets the ids stored before the duplicate window
+func (st *memStream) pruneMsgIds(now time.Time) {
+	for len(st.msgIdQueue) > 0 && now.Sub(st.msgIdQueue[0].storedAt) > memDuplicateWindow {
+		delete(st.msgIds, st.msgIdQueue[0].id)
+		st.msgIdQueue = st.msgIdQueue[1:]
+	}
+}
+
+// matchSubject tells whether the subject matches the filter, "*" matches a single token and a trailing ">" one or
+// more tokens
+func matchSubject(filter string, subject string) bool {
+	filterTokens := strings.Split(filter, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range filterTokens {
+		if token == ">" && i == len(filterTokens)-1 {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+
+	return len(filterTokens) == len(subjectTokens)
+}
+
+// MemServiceImpl is an in process event bus with the semantics of the JetStream implementation: messages are stored
+// in streams, deduplicated by their Nats-Msg-Id, delivered at least once to every durable consumer and moved to the
+// dead letter stream after failing MaxDeliver times. Streams and acknowledgements are persisted to NATS_MEMORY_FILE
+// when it is set, so that the messages which are not acknowledged are delivered again after a restart.
+type MemServiceImpl struct {
+	logService logger.Service
+	subjects   []string
+	dlqStream  string
+	maxDeliver int
+	rpcTimeout time.Duration
+	store      *memStore
+
+	mu      sync.Mutex
+	streams map[string]*memStream
+	closed  bool
+	// changed is closed and replaced whenever messages can be delivered, consumers wait on it
+	changed    chan struct{}
+	responders []*memResponder
+
+	// consumers of the handlers and the messages being handled, see Drain
+	stopConsumers  chan struct{}
+	draining       bool
+	inFlight       sync.WaitGroup
+	handlerCtx     context.Context
+	cancelHandlers context.CancelFunc
+}
+
+func NewMemService(appConfig *config.AppConfig, logService logger.Service) (Service, error) {
+	maxDeliver, err := getMaxDeliver(appConfig)
+	if err != nil {
+		return nil, fmt.Errorf("nats.NewMemService(): %w", err)
+	}
+
+	rpcTimeout, err := getRpcTimeout(appConfig)
+	if err != nil {
+		return nil, fmt.Errorf("nats.NewMemService(): %w", err)
+	}
+
+	subjects := []string{}
+	for _, subject := range []string{appConfig.NATS_EVENT_USER_REGISTRATION, appConfig.NATS_EVENT_USER_NEW_DEVICE} {
+		if subject != "" {
+			subjects = append(subjects, subject)
+		}
+	}
+
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
+	s := &MemServiceImpl{
+		logService:     logService,
+		subjects:       subjects,
+		dlqStream:      getDlqStream(appConfig),
+		maxDeliver:     maxDeliver,
+		rpcTimeout:     rpcTimeout,
+		streams:        map[string]*memStream{},
+		changed:        make(chan struct{}),
+		stopConsumers:  make(chan struct{}),
+		handlerCtx:     handlerCtx,
+		cancelHandlers: cancelHandlers,
+	}
+
+	if appConfig.NATS_MEMORY_FILE != "" {
+		store, records, err := openMemStore(appConfig.NATS_MEMORY_FILE, logService)
+		if err != nil {
+			return nil, fmt.Errorf("nats.NewMemService(): %w", err)
+		}
+		for _, record := range records {
+			s.apply(record)
+		}
+		err = store.compact(s.snapshot())
+		if err != nil {
+			store.close()
+			return nil, fmt.Errorf("nats.NewMemService(): %w", err)
+		}
+		s.store = store
+		logService.Debug(fmt.Sprintf("event bus loaded from '%s'", appConfig.NATS_MEMORY_FILE))
+	}
+
+	// the streams exist before Subscribe, so that events can be published before the consumers are started
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, subjects := range map[string][]string{appConfig.NATS_STREAM: subjects, s.dlqStream: {dlqSubjectPrefix + ">"}} {
+		if name == "" {
+			continue
+		}
+		err = s.ensureStream(name, subjects)
+		if err != nil {
+			s.closeStore()
+			return nil, fmt.Errorf("nats.NewMemService(): %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// Close stops the consumers, messages published afterwards are rejected
+func (s *MemServiceImpl) Close() {
+	s.stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.closeStore()
+	s.logService.Debug("event bus closed")
+}
+
+func (s *MemServiceImpl) closeStore() {
+	if s.store == nil {
+		return
+	}
+
+	err := s.store.close()
+	if err != nil {
+		s.logService.Error(fmt.Sprintf("nats.MemServiceImpl.Close(): %s", err))
+	}
+}
+
+func (s *MemServiceImpl) Status() ConnStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ConnStatus{Connected: false, Status: "CLOSED"}
+	}
+
+	return ConnStatus{Connected: true, Status: "IN_PROCESS"}
+}
+
+func (s *MemServiceImpl) Publish(topic string, payload []byte) error {
+	err := s.PublishCtx(context.Background(), topic, payload)
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.Publish(): %w", err)
+	}
+	return nil
+}
+
+func (s *MemServiceImpl) PublishCtx(ctx context.Context, topic string, payload []byte) error {
+	err := s.PublishToStream(ctx, topic, payload, nil)
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.PublishCtx(): %w", err)
+	}
+	return nil
+}
+
+func (s *MemServiceImpl) PublishToStream(ctx context.Context, topic string, payload []byte, header nats.Header) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.PublishToStream(): %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.publish(topic, payload, header)
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.PublishToStream(): %w", err)
+	}
+	return nil
+}
+
+// PublishBatch publishes the messages one after the other, storing a message does not wait for anything in memory
+func (s *MemServiceImpl) PublishBatch(ctx context.Context, msgs []Msg) []error {
+	errs := make([]error, len(msgs))
+	for i, msg := range msgs {
+		errs[i] = s.PublishToStream(ctx, msg.Subject, msg.Data, msg.Headers)
+	}
+
+	return errs
+}
+
+// publish stores the message in the stream of its subject, s.mu must be held
+func (s *MemServiceImpl) publish(subject string, data []byte, header nats.Header) error {
+	if s.closed {
+		return ErrMemClosed
+	}
+
+	stream := s.findStream(subject)
+	if stream == nil {
+		return fmt.Errorf("%w '%s'", ErrMemNoStream, subject)
+	}
+
+	now := time.Now().UTC()
+	stream.pruneMsgIds(now)
+	msgId := header.Get(jetstream.MsgIDHeader)
+	if seq, ok := stream.msgIds[msgId]; ok && msgId != "" {
+		s.logService.Debug(fmt.Sprintf("message '%s' of '%s' was already stored in '%s' at sequence %d", msgId, subject, stream.name, seq))
+		return nil
+	}
+
+	msg := memMsg{
+		Seq:     stream.lastSeq + 1,
+		Subject: subject,
+		Data:    slices.Clone(data),
+		Headers: cloneHeader(header),
+		Time:    now,
+	}
+	return s.commit(memRecord{Op: memOpMsg, Stream: stream.name, Msg: &msg})
+}
+
+func cloneHeader(header nats.Header) nats.Header {
+	if header == nil {
+		return nil
+	}
+
+	clone := nats.Header{}
+	for key, values := range header {
+		clone[key] = slices.Clone(values)
+	}
+
+	return clone
+}
+
+// findStream returns the stream storing the subject, streams are looked up by name when their subjects overlap
+func (s *MemServiceImpl) findStream(subject string) *memStream {
+	names := make([]string, 0, len(s.streams))
+	for name := range s.streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if s.streams[name].matches(subject) {
+			return s.streams[name]
+		}
+	}
+
+	return nil
+}
+
+// ensureStream creates the stream or adds the subjects it is missing, s.mu must be held
+func (s *MemServiceImpl) ensureStream(name string, subjects []string) error {
+	merged := []string{}
+	if stream, ok := s.streams[name]; ok {
+		merged = append(merged, stream.subjects...)
+	}
+	for _, subject := range subjects {
+		if !slices.Contains(merged, subject) {
+			merged = append(merged, subject)
+		}
+	}
+
+	if stream, ok := s.streams[name]; ok && len(stream.subjects) == len(merged) {
+		return nil
+	}
+
+	err := s.commit(memRecord{Op: memOpStream, Stream: name, Subjects: merged})
+	if err != nil {
+		return err
+	}
+
+	s.logService.Debug(fmt.Sprintf("event bus stream created: '%s'", name))
+	return nil
+}
+
+// notify wakes up the consumers waiting for messages, s.mu must be held
+func (s *MemServiceImpl) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// commit persists the change before applying it, s.mu must be held
+func (s *MemServiceImpl) commit(record memRecord) error {
+	if s.store != nil {
+		err := s.store.append(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.apply(record)
+	return nil
+}
+
+// apply changes the state of the bus, either at runtime or while loading the persisted records
+func (s *MemServiceImpl) apply(record memRecord) {
+	stream, ok := s.streams[record.Stream]
+	if !ok {
+		stream = newMemStream(record.Stream)
+		s.streams[record.Stream] = stream
+	}
+
+	switch record.Op {
+	case memOpStream:
+		stream.subjects = record.Subjects
+		stream.lastSeq = max(stream.lastSeq, record.Seq)
+	case memOpMsg:
+		msg := *record.Msg
+		stream.msgs = append(stream.msgs, msg)
+		stream.lastSeq = msg.Seq
+		if msgId := msg.Headers.Get(jetstream.MsgIDHeader); msgId != "" && time.Since(msg.Time) <= memDuplicateWindow {
+			stream.msgIds[msgId] = msg.Seq
+			stream.msgIdQueue = append(stream.msgIdQueue, memMsgId{id: msgId, storedAt: msg.Time})
+		}
+		s.notify()
+	case memOpDelete:
+		if i := stream.index(record.Seq); i < len(stream.msgs) && stream.msgs[i].Seq == record.Seq {
+			stream.msgs = slices.Delete(stream.msgs, i, i+1)
+		}
+	case memOpPurge:
+		stream.msgs = slices.DeleteFunc(stream.msgs, func(msg memMsg) bool {
+			return matchSubject(record.Subject, msg.Subject)
+		})
+	case memOpConsumer:
+		stream.consumer(record.Consumer).filter = record.Subject
+	case memOpAckFloor:
+		stream.consumer(record.Consumer).ackFloor = record.Seq
+	case memOpAck:
+		stream.consumer(record.Consumer).ack(stream, record.Seq)
+	}
+}
+
+// snapshot returns the records recreating the current state, s.mu must be held or the bus not shared yet
+func (s *MemServiceImpl) snapshot() []memRecord {
+	names := make([]string, 0, len(s.streams))
+	for name := range s.streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	records := []memRecord{}
+	for _, name := range names {
+		stream := s.streams[name]
+		// the last sequence is kept even when its message is deleted, so that sequences are never reused
+		records = append(records, memRecord{Op: memOpStream, Stream: name, Subjects: stream.subjects, Seq: stream.lastSeq})
+		for i := range stream.msgs {
+			records = append(records, memRecord{Op: memOpMsg, Stream: name, Msg: &stream.msgs[i]})
+		}
+
+		for consumerName, consumer := range stream.consumers {
+			records = append(records,
+				memRecord{Op: memOpConsumer, Stream: name, Consumer: consumerName, Subject: consumer.filter},
+				memRecord{Op: memOpAckFloor, Stream: name, Consumer: consumerName, Seq: consumer.ackFloor},
+			)
+			for seq := range consumer.acked {
+				records = append(records, memRecord{Op: memOpAck, Stream: name, Consumer: consumerName, Seq: seq})
+			}
+		}
+	}
+
+	return records
+}
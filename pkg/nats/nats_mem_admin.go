@@ -0,0 +1,215 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ListDeadLetters returns up to limit dead letters, oldest first
+func (s *MemServiceImpl) ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, ok := s.streams[s.dlqStream]
+	if !ok {
+		return nil, fmt.Errorf("nats.MemServiceImpl.ListDeadLetters(): %w '%s'", jetstream.ErrStreamNotFound, s.dlqStream)
+	}
+
+	deadLetters := []DeadLetter{}
+	for _, msg := range stream.msgs {
+		if len(deadLetters) == limit {
+			break
+		}
+		deadLetters = append(deadLetters, toDeadLetter(toRawStreamMsg(msg)))
+	}
+
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter publishes the dead letter back to its original subject and removes it from the dead letter stream.
+// Every consumer of the subject receives the replayed message, not only the one which has failed.
+func (s *MemServiceImpl) ReplayDeadLetter(ctx context.Context, sequence uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, ok := s.streams[s.dlqStream]
+	if !ok {
+		return fmt.Errorf("nats.MemServiceImpl.ReplayDeadLetter(): %w '%s'", jetstream.ErrStreamNotFound, s.dlqStream)
+	}
+
+	msg, ok := stream.get(sequence)
+	if !ok {
+		return fmt.Errorf("nats.MemServiceImpl.ReplayDeadLetter(): %w: %d", ErrMemMsgNotFound, sequence)
+	}
+	deadLetter := toDeadLetter(toRawStreamMsg(msg))
+
+	err := s.publish(deadLetter.Subject, deadLetter.Data, deadLetter.Headers)
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.ReplayDeadLetter(): %w", err)
+	}
+
+	err = s.commit(memRecord{Op: memOpDelete, Stream: s.dlqStream, Seq: sequence})
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.ReplayDeadLetter(): replayed but not deleted: %w", err)
+	}
+
+	s.logService.Debug(fmt.Sprintf("replayed dead letter %d to '%s'", sequence, deadLetter.Subject))
+	return nil
+}
+
+func toRawStreamMsg(msg memMsg) *jetstream.RawStreamMsg {
+	return &jetstream.RawStreamMsg{
+		Subject:  msg.Subject,
+		Sequence: msg.Seq,
+		Header:   cloneHeader(msg.Headers),
+		Data:     msg.Data,
+		Time:     msg.Time,
+	}
+}
+
+// ListStreams returns the streams of the bus ordered by name
+func (s *MemServiceImpl) ListStreams(ctx context.Context) ([]StreamInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	streams := []StreamInfo{}
+	for _, stream := range s.streams {
+		info := StreamInfo{
+			Name:      stream.name,
+			Subjects:  append([]string{}, stream.subjects...),
+			Msgs:      uint64(len(stream.msgs)),
+			FirstSeq:  stream.lastSeq + 1,
+			LastSeq:   stream.lastSeq,
+			Consumers: len(stream.consumers),
+		}
+		for _, msg := range stream.msgs {
+			info.Bytes += uint64(len(msg.Subject) + len(msg.Data))
+		}
+		if len(stream.msgs) > 0 {
+			info.FirstSeq = stream.msgs[0].Seq
+			info.FirstTime = stream.msgs[0].Time
+			info.LastTime = stream.msgs[len(stream.msgs)-1].Time
+		}
+		streams = append(streams, info)
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		return streams[i].Name < streams[j].Name
+	})
+
+	return streams, nil
+}
+
+// ListConsumers returns the consumers of the stream ordered by name along with their lag
+func (s *MemServiceImpl) ListConsumers(ctx context.Context, stream string) ([]ConsumerInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	memStream, ok := s.streams[stream]
+	if !ok {
+		return nil, fmt.Errorf("nats.MemServiceImpl.ListConsumers(): %w '%s'", jetstream.ErrStreamNotFound, stream)
+	}
+
+	consumers := []ConsumerInfo{}
+	for name, consumer := range memStream.consumers {
+		info := ConsumerInfo{
+			Stream:        stream,
+			Name:          name,
+			FilterSubject: consumer.filter,
+			AckFloor:      consumer.ackFloor,
+			Delivered:     consumer.ackFloor,
+			LastActive:    consumer.lastActive,
+		}
+		for _, msg := range memStream.msgs[memStream.index(consumer.ackFloor+1):] {
+			numDelivered := consumer.numDelivered[msg.Seq]
+			switch {
+			case !consumer.matches(msg) || consumer.acked[msg.Seq]:
+				info.Delivered = max(info.Delivered, msg.Seq)
+			case numDelivered == 0:
+				info.NumPending++
+			default:
+				info.Delivered = max(info.Delivered, msg.Seq)
+				info.NumAckPending++
+				if numDelivered > 1 {
+					info.NumRedelivered++
+				}
+			}
+		}
+		consumers = append(consumers, info)
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumers[i].Name < consumers[j].Name
+	})
+
+	return consumers, nil
+}
+
+// Replay calls handle with the messages of the stream within the range, oldest first, and returns how many were
+// replayed. Messages stored after the replay has started are not replayed. The replay stops at the first error of
+// handle.
+func (s *MemServiceImpl) Replay(ctx context.Context, stream string, replayRange ReplayRange, handle HandlerFunc) (int, error) {
+	err := replayRange.validate()
+	if err != nil {
+		return 0, fmt.Errorf("nats.MemServiceImpl.Replay(): %w", err)
+	}
+
+	// the messages are copied, so that handle can publish to the bus
+	s.mu.Lock()
+	memStream, ok := s.streams[stream]
+	msgs := []memMsg{}
+	if ok {
+		for _, msg := range memStream.msgs[memStream.index(replayRange.FromSeq):] {
+			if replayRange.isPast(msg.Seq, msg.Time) {
+				break
+			}
+			if msg.Time.Before(replayRange.Since) || (replayRange.Subject != "" && !matchSubject(replayRange.Subject, msg.Subject)) {
+				continue
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return 0, fmt.Errorf("nats.MemServiceImpl.Replay(): %w '%s'", jetstream.ErrStreamNotFound, stream)
+	}
+
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			return i, fmt.Errorf("nats.MemServiceImpl.Replay(): %w", err)
+		}
+
+		err = handle(ctx, Msg{
+			Subject:      msg.Subject,
+			Data:         msg.Data,
+			Headers:      cloneHeader(msg.Headers),
+			NumDelivered: 1,
+		})
+		if err != nil {
+			return i, fmt.Errorf("nats.MemServiceImpl.Replay(): error replaying message %d of '%s': %w", msg.Seq, stream, err)
+		}
+	}
+
+	s.logService.Debug(fmt.Sprintf("replayed %d message(s) of '%s'", len(msgs), stream))
+	return len(msgs), nil
+}
+
+// PurgeSubject deletes the messages of the subject from the stream, wildcards are allowed
+func (s *MemServiceImpl) PurgeSubject(ctx context.Context, stream string, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.streams[stream]; !ok {
+		return fmt.Errorf("nats.MemServiceImpl.PurgeSubject(): %w '%s'", jetstream.ErrStreamNotFound, stream)
+	}
+
+	err := s.commit(memRecord{Op: memOpPurge, Stream: stream, Subject: subject})
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.PurgeSubject(): %w", err)
+	}
+
+	s.logService.Debug(fmt.Sprintf("purged '%s' from '%s'", subject, stream))
+	return nil
+}
@@ -0,0 +1,284 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// memConsumer is the position of a durable consumer in a stream. Messages up to ackFloor are acknowledged, the ones
+// after it are acknowledged when they are in acked.
+type memConsumer struct {
+	filter   string
+	ackFloor uint64
+	acked    map[uint64]bool
+	// numDelivered, inFlight and retryAt describe the delivered messages which are not acknowledged yet, they are not
+	// persisted so the messages are delivered again from scratch after a restart
+	numDelivered map[uint64]uint64
+	inFlight     map[uint64]bool
+	retryAt      map[uint64]time.Time
+	lastActive   time.Time
+}
+
+// consumer returns the consumer with the name, it is created when it does not exist yet
+func (st *memStream) consumer(name string) *memConsumer {
+	consumer, ok := st.consumers[name]
+	if !ok {
+		consumer = &memConsumer{
+			acked:        map[uint64]bool{},
+			numDelivered: map[uint64]uint64{},
+			inFlight:     map[uint64]bool{},
+			retryAt:      map[uint64]time.Time{},
+		}
+		st.consumers[name] = consumer
+	}
+
+	return consumer
+}
+
+func (c *memConsumer) matches(msg memMsg) bool {
+	return c.filter == "" || matchSubject(c.filter, msg.Subject)
+}
+
+// ack acknowledges the message and moves the ack floor past the messages which are acknowledged or not consumed
+func (c *memConsumer) ack(stream *memStream, seq uint64) {
+	if seq <= c.ackFloor {
+		return
+	}
+	c.acked[seq] = true
+	delete(c.numDelivered, seq)
+	delete(c.retryAt, seq)
+
+	for i := stream.index(c.ackFloor + 1); i < len(stream.msgs); i++ {
+		msg := stream.msgs[i]
+		if c.matches(msg) && !c.acked[msg.Seq] {
+			break
+		}
+		c.ackFloor = msg.Seq
+	}
+	for acked := range c.acked {
+		if acked <= c.ackFloor {
+			delete(c.acked, acked)
+		}
+	}
+}
+
+// next returns the oldest message to deliver now. Otherwise wait is how long until a failed message can be
+// redelivered, zero when no message is waiting for its redelivery.
+func (c *memConsumer) next(stream *memStream, now time.Time) (msg memMsg, ok bool, wait time.Duration) {
+	for i := stream.index(c.ackFloor + 1); i < len(stream.msgs); i++ {
+		msg := stream.msgs[i]
+		if !c.matches(msg) || c.acked[msg.Seq] || c.inFlight[msg.Seq] {
+			continue
+		}
+
+		retryAt, isRetrying := c.retryAt[msg.Seq]
+		if !isRetrying || !retryAt.After(now) {
+			return msg, true, 0
+		}
+		if wait == 0 || retryAt.Sub(now) < wait {
+			wait = retryAt.Sub(now)
+		}
+	}
+
+	return memMsg{}, false, wait
+}
+
+func (s *MemServiceImpl) Subscribe(stream string, registry *Registry) error {
+	handlers := registry.Handlers()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subjects := append([]string{}, s.subjects...)
+	for _, handler := range handlers {
+		subjects = append(subjects, handler.Subject)
+	}
+	err := s.ensureStream(stream, subjects)
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.Subscribe(): %w", err)
+	}
+	memStream := s.streams[stream]
+
+	for _, handler := range handlers {
+		if handler.MaxDeliver == 0 {
+			handler.MaxDeliver = s.maxDeliver
+		}
+
+		consumer := memStream.consumer(handler.Durable)
+		if consumer.filter != handler.Subject {
+			err = s.commit(memRecord{Op: memOpConsumer, Stream: stream, Consumer: handler.Durable, Subject: handler.Subject})
+			if err != nil {
+				return fmt.Errorf("nats.MemServiceImpl.Subscribe(): error creating consumer '%s': %w", handler.Durable, err)
+			}
+		}
+
+		go s.dispatch(memStream, consumer, handler)
+		s.logService.Debug(fmt.Sprintf("event bus consumer '%s' created for '%s'", handler.Durable, handler.Subject))
+	}
+
+	s.logService.Debug(fmt.Sprintf("event bus consumers started for %d handlers", len(handlers)))
+	return nil
+}
+
+// dispatch delivers the messages of the consumer to at most handler.Concurrency goroutines at a time, until the
+// consumers are stopped
+func (s *MemServiceImpl) dispatch(stream *memStream, consumer *memConsumer, handler Handler) {
+	slots := make(chan struct{}, handler.Concurrency)
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-s.stopConsumers:
+			return
+		}
+		if !s.startHandling() {
+			return
+		}
+
+		s.mu.Lock()
+		msg, ok, wait := consumer.next(stream, time.Now())
+		changed := s.changed
+		if ok {
+			consumer.inFlight[msg.Seq] = true
+			consumer.numDelivered[msg.Seq]++
+			consumer.lastActive = time.Now().UTC()
+
+			numDelivered := consumer.numDelivered[msg.Seq]
+			go func() {
+				defer func() {
+					<-slots
+					s.inFlight.Done()
+				}()
+				s.handleMemMsg(stream, consumer, handler, msg, numDelivered)
+			}()
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			<-slots
+			s.inFlight.Done()
+			if !s.waitForMsgs(changed, wait) {
+				return
+			}
+		}
+	}
+}
+
+// waitForMsgs waits for a new message, a finished one or the next redelivery, false is returned once the consumers
+// are stopped
+func (s *MemServiceImpl) waitForMsgs(changed <-chan struct{}, wait time.Duration) bool {
+	var retry <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		retry = timer.C
+	}
+
+	select {
+	case <-changed:
+		return true
+	case <-retry:
+		return true
+	case <-s.stopConsumers:
+		return false
+	}
+}
+
+// startHandling registers a message being handled, false is returned once the service is draining
+func (s *MemServiceImpl) startHandling() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return false
+	}
+	s.inFlight.Add(1)
+
+	return true
+}
+
+// handleMemMsg acknowledges the message when the handler succeeds. Failed messages are redelivered with a backoff until
+// they have been delivered handler.MaxDeliver times, they are then moved to the dead letter stream.
+func (s *MemServiceImpl) handleMemMsg(stream *memStream, consumer *memConsumer, handler Handler, msg memMsg, numDelivered uint64) {
+	var err error
+	if numDelivered > uint64(handler.MaxDeliver) {
+		// the handler has already failed on its last delivery, only moving the message to the dead letter stream failed
+		err = fmt.Errorf("failed on %d deliveries", handler.MaxDeliver)
+	} else {
+		err = handler.Handle(s.handlerCtx, Msg{
+			Subject:      msg.Subject,
+			Data:         msg.Data,
+			Headers:      cloneHeader(msg.Headers),
+			NumDelivered: numDelivered,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(consumer.inFlight, msg.Seq)
+	// a finished message may let the consumer deliver the next one
+	defer s.notify()
+
+	if err == nil {
+		s.ackOrLog(stream, handler, msg.Seq)
+		return
+	}
+
+	if numDelivered >= uint64(handler.MaxDeliver) {
+		header := getDeadLetterHeader(msg.Headers, msg.Subject, handler.Durable, numDelivered, err)
+		dlqErr := s.publish(dlqSubjectPrefix+msg.Subject, msg.Data, header)
+		if dlqErr == nil {
+			s.logService.Error(fmt.Sprintf("nats.MemServiceImpl.handleMsg(): moved message of '%s' to '%s' after %d deliveries: %s", handler.Durable, s.dlqStream, numDelivered, err))
+			s.ackOrLog(stream, handler, msg.Seq)
+			return
+		}
+		s.logService.Error(fmt.Sprintf("nats.MemServiceImpl.handleMsg(): error moving message of '%s' to '%s': %s", handler.Durable, s.dlqStream, dlqErr))
+	}
+
+	delay := getNakDelay(numDelivered)
+	s.logService.Error(fmt.Sprintf("nats.MemServiceImpl.handleMsg(): handler '%s' failed on delivery %d, redelivering in %s: %s", handler.Durable, numDelivered, delay, err))
+	consumer.retryAt[msg.Seq] = time.Now().Add(delay)
+}
+
+// ackOrLog acknowledges the message, s.mu must be held. The message is delivered again after a restart when the
+// acknowledgement could not be persisted.
+func (s *MemServiceImpl) ackOrLog(stream *memStream, handler Handler, seq uint64) {
+	record := memRecord{Op: memOpAck, Stream: stream.name, Consumer: handler.Durable, Seq: seq}
+	err := s.commit(record)
+	if err != nil {
+		s.logService.Error(fmt.Sprintf("nats.MemServiceImpl.handleMsg(): error acknowledging message of '%s': %s", handler.Durable, err))
+		s.apply(record)
+	}
+}
+
+// stop stops the consumers from delivering messages
+func (s *MemServiceImpl) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.draining {
+		s.draining = true
+		close(s.stopConsumers)
+	}
+}
+
+// Drain stops the consumers and waits for the messages being handled. When ctx is done first, the context given to
+// the handlers is cancelled and the messages which are not acknowledged are delivered again after a restart.
+func (s *MemServiceImpl) Drain(ctx context.Context) error {
+	s.stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logService.Debug("event bus consumers drained")
+		return nil
+	case <-ctx.Done():
+		s.cancelHandlers()
+		return fmt.Errorf("nats.MemServiceImpl.Drain(): %w", ctx.Err())
+	}
+}
@@ -0,0 +1,90 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"github.com/nats-io/nats.go"
+)
+
+// memResponder is a responder of the event bus, slots bound the requests it answers at the same time
+type memResponder struct {
+	Responder
+	slots chan struct{}
+}
+
+// Respond starts answering the requests of the responder until Drain is called
+func (s *MemServiceImpl) Respond(responder Responder) error {
+	responder, err := validateResponder(responder)
+	if err != nil {
+		return fmt.Errorf("nats.MemServiceImpl.Respond(): %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return fmt.Errorf("nats.MemServiceImpl.Respond(): the service is draining")
+	}
+	s.responders = append(s.responders, &memResponder{Responder: responder, slots: make(chan struct{}, responder.Concurrency)})
+
+	s.logService.Debug(fmt.Sprintf("event bus responder started for '%s' in queue '%s'", responder.Subject, responder.Queue))
+	return nil
+}
+
+// Request sends the request to one responder of every queue group matching the subject, picked at random like the
+// NATS server does, and returns the first reply
+func (s *MemServiceImpl) Request(ctx context.Context, subject string, data []byte, header nats.Header) (Msg, error) {
+	ctx, cancel := withRpcTimeout(ctx, s.rpcTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return Msg{}, fmt.Errorf("nats.MemServiceImpl.Request(): %w", ErrMemClosed)
+	}
+
+	groups := map[string][]*memResponder{}
+	if !s.draining {
+		for _, responder := range s.responders {
+			if matchSubject(responder.Subject, subject) {
+				groups[responder.Queue] = append(groups[responder.Queue], responder)
+			}
+		}
+	}
+
+	// buffered so that the replies arriving after the first one do not block their responders
+	replies := make(chan []byte, len(groups))
+	for _, group := range groups {
+		responder := group[rand.Intn(len(group))]
+		msg := Msg{Subject: subject, Data: slices.Clone(data), Headers: cloneHeader(header), NumDelivered: 1}
+
+		s.inFlight.Add(1)
+		go func() {
+			defer s.inFlight.Done()
+
+			select {
+			case responder.slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-responder.slots }()
+
+			replies <- callReply(s.handlerCtx, s.rpcTimeout, responder.Responder, msg)
+		}()
+	}
+	s.mu.Unlock()
+
+	if len(groups) == 0 {
+		return Msg{}, fmt.Errorf("nats.MemServiceImpl.Request(): '%s': %w", subject, nats.ErrNoResponders)
+	}
+
+	select {
+	case reply := <-replies:
+		return Msg{Data: reply, NumDelivered: 1}, nil
+	case <-ctx.Done():
+		return Msg{}, fmt.Errorf("nats.MemServiceImpl.Request(): '%s': %w", subject, ctx.Err())
+	}
+}
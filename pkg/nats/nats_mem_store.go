@@ -0,0 +1,149 @@
+package nats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pjmessi/golang-practice/pkg/logger"
+)
+
+// operations of the records of the event bus file
+const (
+	memOpStream   = "stream"
+	memOpMsg      = "msg"
+	memOpDelete   = "delete"
+	memOpPurge    = "purge"
+	memOpConsumer = "consumer"
+	memOpAckFloor = "ackfloor"
+	memOpAck      = "ack"
+)
+
+// memRecord is a change of the state of the event bus, the fields used depend on Op
+type memRecord struct {
+	Op       string   `json:"op"`
+	Stream   string   `json:"stream"`
+	Subjects []string `json:"subjects,omitempty"`
+	Msg      *memMsg  `json:"msg,omitempty"`
+	Consumer string   `json:"consumer,omitempty"`
+	Subject  string   `json:"subject,omitempty"`
+	Seq      uint64   `json:"seq,omitempty"`
+}
+
+// memStore appends the changes of the event bus to a file as JSON lines, every change is synced to the disk before it
+// is applied. The file is replayed and compacted when the bus is created.
+type memStore struct {
+	path string
+	file *os.File
+}
+
+// openMemStore reads the records of the file, which is created when it does not exist. A last line which is not
+// complete, because the process stopped while writing it, is ignored.
+func openMemStore(path string, logService logger.Service) (*memStore, []memRecord, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("nats.openMemStore(): %w", err)
+	}
+
+	records := []memRecord{}
+	lines := bytes.Split(bytes.TrimRight(content, "\n"), []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		var record memRecord
+		err = json.Unmarshal(line, &record)
+		if err != nil && i == len(lines)-1 {
+			logService.Error(fmt.Sprintf("nats.openMemStore(): ignoring the incomplete last record of '%s': %s", path, err))
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("nats.openMemStore(): invalid record %d of '%s': %w", i+1, path, err)
+		}
+		records = append(records, record)
+	}
+
+	return &memStore{path: path}, records, nil
+}
+
+func (st *memStore) append(record memRecord) error {
+	if st.file == nil {
+		return fmt.Errorf("nats.memStore.append(): '%s' is closed", st.path)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("nats.memStore.append(): %w", err)
+	}
+
+	_, err = st.file.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("nats.memStore.append(): %w", err)
+	}
+
+	err = st.file.Sync()
+	if err != nil {
+		return fmt.Errorf("nats.memStore.append(): %w", err)
+	}
+
+	return nil
+}
+
+// compact replaces the content of the file with the records, which recreate the current state, and opens it for
+// appending. The file is replaced atomically, so it holds either the old or the new records if the process stops.
+func (st *memStore) compact(records []memRecord) error {
+	tmpPath := st.path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("nats.memStore.compact(): %w", err)
+	}
+
+	writer := bufio.NewWriter(tmpFile)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		err = encoder.Encode(record)
+		if err != nil {
+			tmpFile.Close()
+			return fmt.Errorf("nats.memStore.compact(): %w", err)
+		}
+	}
+
+	err = writer.Flush()
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, st.path)
+	}
+	if err != nil {
+		return fmt.Errorf("nats.memStore.compact(): %w", err)
+	}
+
+	st.file, err = os.OpenFile(st.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("nats.memStore.compact(): %w", err)
+	}
+
+	return nil
+}
+
+func (st *memStore) close() error {
+	if st.file == nil {
+		return nil
+	}
+
+	err := st.file.Close()
+	st.file = nil
+	if err != nil {
+		return fmt.Errorf("nats.memStore.close(): %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,386 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func genMemConfig(memoryFile string) *config.AppConfig {
+	return &config.AppConfig{
+		NATS_DRIVER:                  "memory",
+		NATS_MEMORY_FILE:             memoryFile,
+		NATS_STREAM:                  "GO_STREAM",
+		NATS_EVENT_USER_REGISTRATION: "EVENT.USER.NEW",
+		NATS_MAX_DELIVER:             "1",
+	}
+}
+
+func setupMemService(t *testing.T, appConfig *config.AppConfig) *MemServiceImpl {
+	logServiceMock := &logger.ServiceMock{}
+	logServiceMock.On("Debug", mock.Anything).Return()
+	logServiceMock.On("Error", mock.Anything).Return()
+
+	service, err := NewService(appConfig, logServiceMock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(service.Close)
+
+	return service.(*MemServiceImpl)
+}
+
+// waitFor polls the condition, since the messages are delivered by other goroutines
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
+
+func Test_matchSubject(t *testing.T) {
+	tests := []struct {
+		filter  string
+		subject string
+		matches bool
+	}{
+		{"EVENT.USER.NEW", "EVENT.USER.NEW", true},
+		{"EVENT.USER.NEW", "EVENT.USER.DEVICE", false},
+		{"EVENT.USER.NEW", "EVENT.USER", false},
+		{"EVENT.*.NEW", "EVENT.USER.NEW", true},
+		{"EVENT.*", "EVENT.USER.NEW", false},
+		{"EVENT.>", "EVENT.USER.NEW", true},
+		{"EVENT.>", "EVENT", false},
+		{">", "EVENT", true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.matches, matchSubject(test.filter, test.subject), "'%s' matching '%s'", test.filter, test.subject)
+	}
+}
+
+func Test_NewService_Should_Return_Error_Of_Invalid_Driver(t *testing.T) {
+	// ACT
+	service, errRes := NewService(&config.AppConfig{NATS_DRIVER: "kafka"}, &logger.ServiceMock{})
+
+	// ASSERT
+	assert.Nil(t, service)
+	assert.EqualError(t, errRes, "nats.NewService(): invalid NATS_DRIVER 'kafka'")
+}
+
+func Test_MemService_PublishToStream_Should_Deduplicate_By_Msg_Id(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+	header := nats.Header{jetstream.MsgIDHeader: []string{"msg-id"}}
+
+	// ACT
+	errRes1 := service.PublishToStream(context.Background(), "EVENT.USER.NEW", []byte(`1`), header)
+	errRes2 := service.PublishToStream(context.Background(), "EVENT.USER.NEW", []byte(`2`), header)
+
+	// ASSERT
+	assert.Nil(t, errRes1)
+	assert.Nil(t, errRes2)
+	streams, _ := service.ListStreams(context.Background())
+	assert.Equal(t, "GO_STREAM", streams[0].Name)
+	assert.Equal(t, uint64(1), streams[0].Msgs, "should store the message once")
+}
+
+func Test_MemService_PublishToStream_Should_Return_Error_Without_Stream(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	// ACT
+	errRes := service.PublishToStream(context.Background(), "UNKNOWN", []byte(`{}`), nil)
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, ErrMemNoStream)
+}
+
+func Test_MemService_PublishToStream_Should_Return_Error_Once_Closed(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+	service.Close()
+
+	// ACT
+	errRes := service.PublishToStream(context.Background(), "EVENT.USER.NEW", []byte(`{}`), nil)
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, ErrMemClosed)
+	assert.Equal(t, ConnStatus{Connected: false, Status: "CLOSED"}, service.Status())
+}
+
+func Test_MemService_Subscribe_Should_Deliver_To_Every_Durable(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	var handled1, handled2 atomic.Int32
+	registry := NewRegistry()
+	handler1 := genHandler("EVENT.USER.*", "durable1")
+	handler1.Handle = func(ctx context.Context, msg Msg) error { handled1.Add(1); return nil }
+	handler2 := genHandler("EVENT.USER.NEW", "durable2")
+	handler2.Handle = func(ctx context.Context, msg Msg) error { handled2.Add(1); return nil }
+	assert.Nil(t, registry.Register(handler1))
+	assert.Nil(t, registry.Register(handler2))
+
+	// ACT
+	errRes := service.Subscribe("GO_STREAM", registry)
+	assert.Nil(t, service.Publish("EVENT.USER.NEW", []byte(`{}`)))
+	assert.Nil(t, service.Publish("EVENT.USER.DEVICE", []byte(`{}`)))
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.True(t, waitFor(func() bool { return handled1.Load() == 2 && handled2.Load() == 1 }))
+
+	consumers, err := service.ListConsumers(context.Background(), "GO_STREAM")
+	assert.Nil(t, err)
+	assert.Len(t, consumers, 2)
+	assert.True(t, waitFor(func() bool {
+		consumers, _ = service.ListConsumers(context.Background(), "GO_STREAM")
+		return consumers[0].AckFloor == 2 && consumers[1].AckFloor == 2
+	}), "should acknowledge the messages, skipping the ones not matching the durable")
+}
+
+func Test_MemService_Subscribe_Should_Bound_Concurrency(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	var running, maxRunning, handled atomic.Int32
+	handler := genHandler("EVENT.USER.NEW", "durable")
+	handler.Concurrency = 2
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		current := running.Add(1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		handled.Add(1)
+		return nil
+	}
+	registry := NewRegistry()
+	assert.Nil(t, registry.Register(handler))
+
+	// ACT
+	assert.Nil(t, service.Subscribe("GO_STREAM", registry))
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, service.Publish("EVENT.USER.NEW", []byte(`{}`)))
+	}
+
+	// ASSERT
+	assert.True(t, waitFor(func() bool { return handled.Load() == 6 }))
+	assert.Equal(t, int32(2), maxRunning.Load())
+}
+
+func Test_MemService_Should_Move_To_Dlq_After_Max_Deliver(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	var deliveries atomic.Int32
+	handler := genHandler("EVENT.USER.NEW", "durable")
+	handler.MaxDeliver = 0
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		if deliveries.Add(1) == 1 {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+	registry := NewRegistry()
+	assert.Nil(t, registry.Register(handler))
+	assert.Nil(t, service.Subscribe("GO_STREAM", registry))
+
+	// ACT
+	assert.Nil(t, service.PublishToStream(context.Background(), "EVENT.USER.NEW", []byte(`{"id":1}`), nats.Header{"Trace-Id": []string{"trace"}}))
+
+	// ASSERT
+	var deadLetters []DeadLetter
+	assert.True(t, waitFor(func() bool {
+		deadLetters, _ = service.ListDeadLetters(context.Background(), 10)
+		return len(deadLetters) == 1
+	}), "should move the message to the dead letter stream with NATS_MAX_DELIVER=1")
+	assert.Equal(t, "EVENT.USER.NEW", deadLetters[0].Subject)
+	assert.Equal(t, "durable", deadLetters[0].Durable)
+	assert.Equal(t, "handler failed", deadLetters[0].Error)
+	assert.Equal(t, "trace", deadLetters[0].Headers.Get("Trace-Id"))
+
+	// ACT
+	errRes := service.ReplayDeadLetter(context.Background(), deadLetters[0].Sequence)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.True(t, waitFor(func() bool { return deliveries.Load() == 2 }), "should deliver the replayed message")
+	deadLetters, _ = service.ListDeadLetters(context.Background(), 10)
+	assert.Empty(t, deadLetters)
+	assert.ErrorIs(t, service.ReplayDeadLetter(context.Background(), 1), ErrMemMsgNotFound)
+}
+
+func Test_MemService_Should_Redeliver_Unacknowledged_Msgs_After_Restart(t *testing.T) {
+	// ARRANGE
+	memoryFile := filepath.Join(t.TempDir(), "bus.jsonl")
+	service := setupMemService(t, genMemConfig(memoryFile))
+	for _, data := range []string{`1`, `2`, `3`} {
+		assert.Nil(t, service.Publish("EVENT.USER.NEW", []byte(data)))
+	}
+
+	handled := make(chan string, 3)
+	handler := genHandler("EVENT.USER.NEW", "durable")
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		handled <- string(msg.Data)
+		if string(msg.Data) == "1" {
+			return nil
+		}
+		// the other messages are still being handled when the bus stops
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	registry := NewRegistry()
+	assert.Nil(t, registry.Register(handler))
+	assert.Nil(t, service.Subscribe("GO_STREAM", registry))
+	assert.Equal(t, "1", <-handled)
+	assert.Equal(t, "2", <-handled)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_ = service.Drain(ctx)
+	service.Close()
+
+	// the process stopped while writing a record
+	file, _ := os.OpenFile(memoryFile, os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = file.WriteString(`{"op":"ack","str`)
+	file.Close()
+
+	// ACT
+	restarted := setupMemService(t, genMemConfig(memoryFile))
+	redelivered := make(chan string, 3)
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		redelivered <- string(msg.Data)
+		return nil
+	}
+	registry = NewRegistry()
+	assert.Nil(t, registry.Register(handler))
+	errRes := restarted.Subscribe("GO_STREAM", registry)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, "2", <-redelivered, "should not deliver the acknowledged message again")
+	assert.Equal(t, "3", <-redelivered)
+	assert.Nil(t, restarted.Publish("EVENT.USER.NEW", []byte(`4`)))
+	assert.Equal(t, "4", <-redelivered, "should not reuse the sequences")
+}
+
+func Test_MemService_Replay_Should_Replay_Range_Of_Subject(t *testing.T) {
+	// ARRANGE
+	appConfig := genMemConfig("")
+	appConfig.NATS_EVENT_USER_NEW_DEVICE = "EVENT.USER.DEVICE"
+	service := setupMemService(t, appConfig)
+	for _, subject := range []string{"EVENT.USER.NEW", "EVENT.USER.DEVICE", "EVENT.USER.NEW", "EVENT.USER.NEW"} {
+		assert.Nil(t, service.Publish(subject, []byte(`{}`)))
+	}
+
+	replayed := []string{}
+	handle := func(ctx context.Context, msg Msg) error {
+		replayed = append(replayed, msg.Subject)
+		return nil
+	}
+
+	// ACT
+	count, errRes := service.Replay(context.Background(), "GO_STREAM", ReplayRange{Subject: "EVENT.USER.NEW", FromSeq: 2, ToSeq: 3}, handle)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"EVENT.USER.NEW"}, replayed)
+
+	// ACT
+	errRes = service.PurgeSubject(context.Background(), "GO_STREAM", "EVENT.USER.NEW")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	streams, _ := service.ListStreams(context.Background())
+	assert.Equal(t, uint64(1), streams[0].Msgs)
+	assert.Equal(t, uint64(2), streams[0].FirstSeq)
+	assert.Equal(t, uint64(4), streams[0].LastSeq)
+	_, errRes = service.Replay(context.Background(), "UNKNOWN", ReplayRange{}, handle)
+	assert.ErrorIs(t, errRes, jetstream.ErrStreamNotFound)
+}
+
+func Test_MemService_ListConsumers_Should_Return_Lag(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	release := make(chan struct{})
+	handler := genHandler("EVENT.USER.NEW", "durable")
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		<-release
+		return nil
+	}
+	registry := NewRegistry()
+	assert.Nil(t, registry.Register(handler))
+	assert.Nil(t, service.Subscribe("GO_STREAM", registry))
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, service.Publish("EVENT.USER.NEW", []byte(`{}`)))
+	}
+
+	// ACT
+	var consumers []ConsumerInfo
+	ok := waitFor(func() bool {
+		consumers, _ = service.ListConsumers(context.Background(), "GO_STREAM")
+		return consumers[0].NumAckPending == 1
+	})
+
+	// ASSERT
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), consumers[0].NumPending)
+	assert.Equal(t, uint64(1), consumers[0].Delivered)
+	assert.Equal(t, uint64(0), consumers[0].AckFloor)
+
+	close(release)
+	assert.True(t, waitFor(func() bool {
+		consumers, _ = service.ListConsumers(context.Background(), "GO_STREAM")
+		return consumers[0].AckFloor == 3 && consumers[0].NumPending == 0
+	}))
+}
+
+func Test_MemService_Drain_Should_Wait_For_Messages_Being_Handled(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	started := make(chan struct{})
+	var handled atomic.Bool
+	handler := genHandler("EVENT.USER.NEW", "durable")
+	handler.Handle = func(ctx context.Context, msg Msg) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		handled.Store(true)
+		return nil
+	}
+	registry := NewRegistry()
+	assert.Nil(t, registry.Register(handler))
+	assert.Nil(t, service.Subscribe("GO_STREAM", registry))
+	assert.Nil(t, service.Publish("EVENT.USER.NEW", []byte(`{}`)))
+	<-started
+
+	// ACT
+	errRes := service.Drain(context.Background())
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.True(t, handled.Load())
+}
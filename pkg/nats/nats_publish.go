@@ -0,0 +1,128 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+)
+
+const defaultPublishMaxPending = 256
+
+// publishAckTimeout is how long an async publish waits for the acknowledgement of the stream
+const publishAckTimeout = 5 * time.Second
+
+func getPublishMaxPending(appConfig *config.AppConfig) (int, error) {
+	if appConfig.NATS_PUBLISH_MAX_PENDING == "" {
+		return defaultPublishMaxPending, nil
+	}
+
+	maxPending, err := strconv.Atoi(appConfig.NATS_PUBLISH_MAX_PENDING)
+	if err != nil || maxPending < 1 {
+		return 0, fmt.Errorf("invalid NATS_PUBLISH_MAX_PENDING '%s'", appConfig.NATS_PUBLISH_MAX_PENDING)
+	}
+
+	return maxPending, nil
+}
+
+func (s *ServiceImpl) Publish(topic string, payload []byte) error {
+	err := s.PublishCtx(context.Background(), topic, payload)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.Publish(): %w", err)
+	}
+	return nil
+}
+
+func (s *ServiceImpl) PublishCtx(ctx context.Context, topic string, payload []byte) error {
+	err := s.PublishToStream(ctx, topic, payload, nil)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.PublishCtx(): %w", err)
+	}
+	return nil
+}
+
+func (s *ServiceImpl) PublishToStream(ctx context.Context, topic string, payload []byte, header nats.Header) error {
+	msg := &nats.Msg{Subject: topic, Data: payload, Header: header}
+	pubAck, err := s.jetStream.PublishMsg(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.PublishToStream(): %w", err)
+	}
+
+	s.logPubAck(msg, pubAck)
+	return nil
+}
+
+func (s *ServiceImpl) PublishBatch(ctx context.Context, msgs []Msg) []error {
+	errs := make([]error, len(msgs))
+
+	// every message holds a slot of the window until it is acknowledged, so no more than publishMaxPending messages
+	// are waiting for their acknowledgement at the same time
+	window := make(chan struct{}, s.publishMaxPending)
+	var acks sync.WaitGroup
+
+	for i, msg := range msgs {
+		select {
+		case window <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		// the remaining messages are not published once ctx is done, even when a slot was free
+		if ctx.Err() != nil {
+			for j := i; j < len(msgs); j++ {
+				errs[j] = fmt.Errorf("nats.ServiceImpl.PublishBatch(): %w", ctx.Err())
+			}
+			break
+		}
+
+		future, err := s.jetStream.PublishMsgAsync(&nats.Msg{Subject: msg.Subject, Data: msg.Data, Header: msg.Headers})
+		if err != nil {
+			errs[i] = fmt.Errorf("nats.ServiceImpl.PublishBatch(): %w", err)
+			<-window
+			continue
+		}
+
+		acks.Add(1)
+		go func(i int, future jetstream.PubAckFuture) {
+			defer acks.Done()
+			defer func() { <-window }()
+
+			err := s.waitPubAck(ctx, future)
+			if err != nil {
+				errs[i] = fmt.Errorf("nats.ServiceImpl.PublishBatch(): %w", err)
+			}
+		}(i, future)
+	}
+
+	acks.Wait()
+	return errs
+}
+
+// waitPubAck waits until the stream has acknowledged the message of the future, publishAckTimeout or ctx is done
+func (s *ServiceImpl) waitPubAck(ctx context.Context, future jetstream.PubAckFuture) error {
+	timer := time.NewTimer(publishAckTimeout)
+	defer timer.Stop()
+
+	select {
+	case pubAck := <-future.Ok():
+		s.logPubAck(future.Msg(), pubAck)
+		return nil
+	case err := <-future.Err():
+		return err
+	case <-timer.C:
+		return fmt.Errorf("no acknowledgement of '%s' within %s", future.Msg().Subject, publishAckTimeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// logPubAck logs the messages which the stream had already stored with the same Nats-Msg-Id, they are not stored twice
+func (s *ServiceImpl) logPubAck(msg *nats.Msg, pubAck *jetstream.PubAck) {
+	if pubAck.Duplicate {
+		s.logService.Debug(fmt.Sprintf("message '%s' of '%s' was already stored in '%s' at sequence %d", msg.Header.Get(jetstream.MsgIDHeader), msg.Subject, pubAck.Stream, pubAck.Sequence))
+	}
+}
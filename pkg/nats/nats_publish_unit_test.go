@@ -0,0 +1,148 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func genMsgs(count int) []Msg {
+	msgs := make([]Msg, count)
+	for i := range msgs {
+		msgs[i] = Msg{
+			Subject: "EVENT.USER.NEW",
+			Data:    []byte(fmt.Sprintf(`{"id":"%d"}`, i)),
+			Headers: nats.Header{jetstream.MsgIDHeader: []string{fmt.Sprint(i)}},
+		}
+	}
+	return msgs
+}
+
+func Test_PublishToStream_Should_Publish_Headers(t *testing.T) {
+	// ARRANGE
+	service, _, jetStream := setupMocksForConsumerTest()
+	header := nats.Header{jetstream.MsgIDHeader: []string{"event-1"}}
+
+	// ACT
+	errRes := service.PublishToStream(context.Background(), "EVENT.USER.NEW", []byte(`{}`), header)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	if assert.Len(t, jetStream.published, 1) {
+		assert.Equal(t, "EVENT.USER.NEW", jetStream.published[0].Subject)
+		assert.Equal(t, "event-1", jetStream.published[0].Header.Get(jetstream.MsgIDHeader))
+	}
+}
+
+func Test_PublishCtx_Should_Return_Error_Of_Stream(t *testing.T) {
+	// ARRANGE
+	service, _, jetStream := setupMocksForConsumerTest()
+	jetStream.publishErr = jetstream.ErrNoStreamResponse
+
+	// ACT
+	errRes := service.PublishCtx(context.Background(), "EVENT.USER.NEW", []byte(`{}`))
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, jetstream.ErrNoStreamResponse)
+}
+
+func Test_PublishBatch_Should_Return_Error_Of_Every_Message(t *testing.T) {
+	// ARRANGE
+	service, logServiceMock, jetStream := setupMocksForConsumerTest()
+	msgs := genMsgs(3)
+	jetStream.asyncFutures = make(chan *fakePubAckFuture, len(msgs))
+	ackErr := errors.New("nats: maximum messages exceeded")
+
+	logServiceMock.On("Debug", "message '2' of 'EVENT.USER.NEW' was already stored in 'GO_STREAM' at sequence 7")
+
+	go func() {
+		(<-jetStream.asyncFutures).ok <- &jetstream.PubAck{Stream: "GO_STREAM", Sequence: 10}
+		(<-jetStream.asyncFutures).err <- ackErr
+		(<-jetStream.asyncFutures).ok <- &jetstream.PubAck{Stream: "GO_STREAM", Sequence: 7, Duplicate: true}
+	}()
+
+	// ACT
+	errsRes := service.PublishBatch(context.Background(), msgs)
+
+	// ASSERT
+	assert.Len(t, errsRes, 3)
+	assert.Nil(t, errsRes[0])
+	assert.ErrorIs(t, errsRes[1], ackErr)
+	assert.Nil(t, errsRes[2])
+	logServiceMock.AssertNumberOfCalls(t, "Debug", 1)
+}
+
+func Test_PublishBatch_Should_Bound_Messages_Waiting_For_Acknowledgement(t *testing.T) {
+	// ARRANGE
+	service, _, jetStream := setupMocksForConsumerTest()
+	service.publishMaxPending = 2
+	msgs := genMsgs(5)
+	jetStream.asyncFutures = make(chan *fakePubAckFuture, len(msgs))
+
+	// ACT
+	done := make(chan []error)
+	go func() {
+		done <- service.PublishBatch(context.Background(), msgs)
+	}()
+
+	// ASSERT
+	first := <-jetStream.asyncFutures
+	second := <-jetStream.asyncFutures
+	select {
+	case <-jetStream.asyncFutures:
+		t.Fatal("a third message should not be published before one of the others is acknowledged")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first.ok <- &jetstream.PubAck{Stream: "GO_STREAM"}
+	second.ok <- &jetstream.PubAck{Stream: "GO_STREAM"}
+	for i := 0; i < 3; i++ {
+		(<-jetStream.asyncFutures).ok <- &jetstream.PubAck{Stream: "GO_STREAM"}
+	}
+
+	errsRes := <-done
+	assert.Equal(t, make([]error, 5), errsRes)
+	assert.Len(t, jetStream.published, 5)
+}
+
+func Test_PublishBatch_Should_Stop_When_Ctx_Is_Done(t *testing.T) {
+	// ARRANGE
+	service, _, jetStream := setupMocksForConsumerTest()
+	service.publishMaxPending = 1
+	msgs := genMsgs(3)
+	jetStream.asyncFutures = make(chan *fakePubAckFuture, len(msgs))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// ACT
+	go func() {
+		<-jetStream.asyncFutures
+		cancel()
+	}()
+	errsRes := service.PublishBatch(ctx, msgs)
+
+	// ASSERT
+	assert.Len(t, jetStream.published, 1)
+	for _, errRes := range errsRes {
+		assert.ErrorIs(t, errRes, context.Canceled)
+	}
+}
+
+func Test_getPublishMaxPending(t *testing.T) {
+	maxPending, err := getPublishMaxPending(&config.AppConfig{})
+	assert.Nil(t, err)
+	assert.Equal(t, defaultPublishMaxPending, maxPending)
+
+	maxPending, err = getPublishMaxPending(&config.AppConfig{NATS_PUBLISH_MAX_PENDING: "16"})
+	assert.Nil(t, err)
+	assert.Equal(t, 16, maxPending)
+
+	_, err = getPublishMaxPending(&config.AppConfig{NATS_PUBLISH_MAX_PENDING: "0"})
+	assert.EqualError(t, err, "invalid NATS_PUBLISH_MAX_PENDING '0'")
+}
@@ -0,0 +1,131 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/config"
+)
+
+const defaultRpcTimeout = 5 * time.Second
+const defaultResponderConcurrency = 16
+
+// ReplyFunc answers a request with the data of the reply. Failures are encoded in the reply, so that the requester can
+// tell them apart from a missing responder or a timeout.
+type ReplyFunc func(ctx context.Context, msg Msg) []byte
+
+// Responder answers the requests of a subject. The requests are load balanced between the responders of a queue group,
+// so every instance of the app registers the same queue to share the load.
+type Responder struct {
+	Subject string
+	Queue   string
+	// Concurrency is the number of requests answered at the same time, 16 when not set
+	Concurrency int
+	Reply       ReplyFunc
+}
+
+func getRpcTimeout(appConfig *config.AppConfig) (time.Duration, error) {
+	if appConfig.NATS_RPC_TIMEOUT == "" {
+		return defaultRpcTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(appConfig.NATS_RPC_TIMEOUT)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid NATS_RPC_TIMEOUT '%s'", appConfig.NATS_RPC_TIMEOUT)
+	}
+
+	return timeout, nil
+}
+
+// validateResponder checks the responder and defaults its concurrency
+func validateResponder(responder Responder) (Responder, error) {
+	if responder.Subject == "" || responder.Queue == "" || responder.Reply == nil {
+		return Responder{}, fmt.Errorf("subject, queue and reply are required")
+	}
+	if responder.Concurrency < 0 {
+		return Responder{}, fmt.Errorf("invalid concurrency %d for '%s'", responder.Concurrency, responder.Subject)
+	}
+	if responder.Concurrency == 0 {
+		responder.Concurrency = defaultResponderConcurrency
+	}
+
+	return responder, nil
+}
+
+// withRpcTimeout bounds ctx by the timeout when it has no deadline of its own
+func withRpcTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// callReply calls the responder with a context cancelled after the timeout or when the handlers are cancelled by Drain
+func callReply(handlerCtx context.Context, timeout time.Duration, responder Responder, msg Msg) []byte {
+	ctx, cancel := context.WithTimeout(handlerCtx, timeout)
+	defer cancel()
+
+	return responder.Reply(ctx, msg)
+}
+
+// Respond starts answering the requests of the responder until Drain is called
+func (s *ServiceImpl) Respond(responder Responder) error {
+	responder, err := validateResponder(responder)
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.Respond(): %w", err)
+	}
+
+	// nats.go calls the callback of a subscription one message at a time, the requests are answered by other goroutines
+	slots := make(chan struct{}, responder.Concurrency)
+	sub, err := s.natsCon.QueueSubscribe(responder.Subject, responder.Queue, func(msg *nats.Msg) {
+		slots <- struct{}{}
+		if !s.startHandling() {
+			<-slots
+			return
+		}
+
+		go func() {
+			defer func() {
+				<-slots
+				s.inFlight.Done()
+			}()
+
+			data := callReply(s.handlerCtx, s.rpcTimeout, responder, Msg{Subject: msg.Subject, Data: msg.Data, Headers: msg.Header, NumDelivered: 1})
+			err := msg.Respond(data)
+			if err != nil {
+				s.logService.Error(fmt.Sprintf("nats.ServiceImpl.Respond(): error replying to '%s': %s", responder.Subject, err))
+			}
+		}()
+	})
+	if err != nil {
+		return fmt.Errorf("nats.ServiceImpl.Respond(): %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		_ = sub.Unsubscribe()
+		return fmt.Errorf("nats.ServiceImpl.Respond(): the service is draining")
+	}
+	s.subs = append(s.subs, sub)
+
+	s.logService.Debug(fmt.Sprintf("NATS responder started for '%s' in queue '%s'", responder.Subject, responder.Queue))
+	return nil
+}
+
+// Request sends the request and waits for the first reply until ctx is done, or NATS_RPC_TIMEOUT when ctx has no
+// deadline. nats.ErrNoResponders is returned at once when nobody answers the subject.
+func (s *ServiceImpl) Request(ctx context.Context, subject string, data []byte, header nats.Header) (Msg, error) {
+	ctx, cancel := withRpcTimeout(ctx, s.rpcTimeout)
+	defer cancel()
+
+	reply, err := s.natsCon.RequestMsgWithContext(ctx, &nats.Msg{Subject: subject, Data: data, Header: header})
+	if err != nil {
+		return Msg{}, fmt.Errorf("nats.ServiceImpl.Request(): '%s': %w", subject, err)
+	}
+
+	return Msg{Subject: reply.Subject, Data: reply.Data, Headers: reply.Header, NumDelivered: 1}, nil
+}
@@ -0,0 +1,160 @@
+package nats
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func genResponder(subject string, queue string, reply ReplyFunc) Responder {
+	return Responder{Subject: subject, Queue: queue, Reply: reply}
+}
+
+func Test_getRpcTimeout(t *testing.T) {
+	timeout, err := getRpcTimeout(&config.AppConfig{})
+	assert.Nil(t, err)
+	assert.Equal(t, defaultRpcTimeout, timeout)
+
+	timeout, err = getRpcTimeout(&config.AppConfig{NATS_RPC_TIMEOUT: "250ms"})
+	assert.Nil(t, err)
+	assert.Equal(t, 250*time.Millisecond, timeout)
+
+	_, err = getRpcTimeout(&config.AppConfig{NATS_RPC_TIMEOUT: "0s"})
+	assert.EqualError(t, err, "invalid NATS_RPC_TIMEOUT '0s'")
+}
+
+func Test_validateResponder(t *testing.T) {
+	responder, err := validateResponder(genResponder("svc.user.get", "user_service", func(ctx context.Context, msg Msg) []byte { return nil }))
+	assert.Nil(t, err)
+	assert.Equal(t, defaultResponderConcurrency, responder.Concurrency)
+
+	_, err = validateResponder(genResponder("svc.user.get", "", func(ctx context.Context, msg Msg) []byte { return nil }))
+	assert.EqualError(t, err, "subject, queue and reply are required")
+}
+
+func Test_MemService_Request_Should_Be_Answered_Once_Per_Queue_Group(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	var answered atomic.Int32
+	reply := func(ctx context.Context, msg Msg) []byte {
+		answered.Add(1)
+		return append([]byte("re: "), msg.Data...)
+	}
+	assert.Nil(t, service.Respond(genResponder("svc.user.*", "user_service", reply)))
+	assert.Nil(t, service.Respond(genResponder("svc.user.get", "user_service", reply)))
+
+	// ACT
+	replyRes, errRes := service.Request(context.Background(), "svc.user.get", []byte("hello"), nats.Header{"Trace-Id": []string{"trace"}})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, "re: hello", string(replyRes.Data))
+	assert.Equal(t, int32(1), answered.Load(), "should only be answered by one member of the queue group")
+}
+
+func Test_MemService_Request_Should_Return_ErrNoResponders(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+
+	// ACT
+	_, errRes := service.Request(context.Background(), "svc.user.get", []byte("{}"), nil)
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, nats.ErrNoResponders)
+}
+
+func Test_MemService_Request_Should_Time_Out(t *testing.T) {
+	// ARRANGE
+	appConfig := genMemConfig("")
+	appConfig.NATS_RPC_TIMEOUT = "50ms"
+	service := setupMemService(t, appConfig)
+
+	var replyCtxErr atomic.Value
+	assert.Nil(t, service.Respond(genResponder("svc.user.get", "user_service", func(ctx context.Context, msg Msg) []byte {
+		<-ctx.Done()
+		replyCtxErr.Store(ctx.Err())
+		return nil
+	})))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// ACT
+	_, errRes := service.Request(ctx, "svc.user.get", []byte("{}"), nil)
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, context.DeadlineExceeded)
+	assert.True(t, waitFor(func() bool { return replyCtxErr.Load() == context.DeadlineExceeded }), "should cancel the context of the responder after NATS_RPC_TIMEOUT")
+}
+
+func Test_MemService_Drain_Should_Stop_Responders(t *testing.T) {
+	// ARRANGE
+	service := setupMemService(t, genMemConfig(""))
+	assert.Nil(t, service.Respond(genResponder("svc.user.get", "user_service", func(ctx context.Context, msg Msg) []byte { return msg.Data })))
+
+	// ACT
+	errRes := service.Drain(context.Background())
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	_, err := service.Request(context.Background(), "svc.user.get", []byte("{}"), nil)
+	assert.ErrorIs(t, err, nats.ErrNoResponders)
+	assert.NotNil(t, service.Respond(genResponder("svc.user.get", "user_service", func(ctx context.Context, msg Msg) []byte { return nil })))
+}
+
+func Test_Service_Request_Should_Be_Answered_By_Queue_Group_Over_NATS(t *testing.T) {
+	// ARRANGE
+	logServiceMock := &logger.ServiceMock{}
+	logServiceMock.On("Debug", mock.Anything).Return()
+	embeddedServer, err := StartEmbeddedServer(logServiceMock)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer embeddedServer.Shutdown()
+
+	appConfig := &config.AppConfig{NATS_URL: embeddedServer.Url()}
+	responders := []Service{}
+	var answered atomic.Int32
+	for i := 0; i < 2; i++ {
+		service, err := NewPubService(appConfig, logServiceMock)
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer service.Close()
+		assert.Nil(t, service.Respond(genResponder("svc.user.get", "user_service", func(ctx context.Context, msg Msg) []byte {
+			answered.Add(1)
+			return append([]byte(msg.Headers.Get("Trace-Id")+": "), msg.Data...)
+		})))
+		responders = append(responders, service)
+	}
+	requester, err := NewPubService(appConfig, logServiceMock)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer requester.Close()
+
+	// ACT
+	replyRes, errRes := requester.Request(context.Background(), "svc.user.get", []byte("hello"), nats.Header{"Trace-Id": []string{"trace"}})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, "trace: hello", string(replyRes.Data))
+	assert.Equal(t, int32(1), answered.Load())
+
+	// ACT
+	for _, responder := range responders {
+		assert.Nil(t, responder.Drain(context.Background()))
+	}
+	_, errRes = requester.Request(context.Background(), "svc.user.get", []byte("hello"), nil)
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, nats.ErrNoResponders)
+}
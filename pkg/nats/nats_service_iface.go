@@ -1,7 +1,45 @@
 package nats
 
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
 type Service interface {
 	Close()
+	// Status returns the state of the connection, the client reconnects on its own when the connection is lost
+	Status() ConnStatus
+	// Publish publishes to JetStream and waits until the stream has stored the message
 	Publish(topic string, payload []byte) error
-	Subscribe(topic string) error
+	// PublishCtx works like Publish, waiting until ctx is done at most
+	PublishCtx(ctx context.Context, topic string, payload []byte) error
+	// PublishToStream works like PublishCtx with the headers of the message, header may be nil. A message is stored only
+	// once when it is published again with the same Nats-Msg-Id header within the duplicate window of the stream
+	PublishToStream(ctx context.Context, topic string, payload []byte, header nats.Header) error
+	// PublishBatch publishes the messages without waiting for the acknowledgement of each message before publishing the
+	// next one, the error of every message is returned in the order of msgs, nil when it was stored
+	PublishBatch(ctx context.Context, msgs []Msg) []error
+	// Subscribe creates the stream and starts a durable consumer for every handler of the registry
+	Subscribe(stream string, registry *Registry) error
+	// Drain stops consuming and answering requests, and waits until the messages and requests being handled are done or
+	// ctx is done
+	Drain(ctx context.Context) error
+	// Respond starts answering the requests of the responder, the responders of a queue group share the requests
+	Respond(responder Responder) error
+	// Request sends a request and waits for its reply until ctx is done, NATS_RPC_TIMEOUT when ctx has no deadline
+	Request(ctx context.Context, subject string, data []byte, header nats.Header) (Msg, error)
+	// ListDeadLetters returns up to limit messages of the dead letter stream, oldest first
+	ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error)
+	// ReplayDeadLetter publishes the dead letter back to its original subject and removes it from the dead letter stream
+	ReplayDeadLetter(ctx context.Context, sequence uint64) error
+	// ListStreams returns the streams of the account
+	ListStreams(ctx context.Context) ([]StreamInfo, error)
+	// ListConsumers returns the consumers of the stream along with their lag
+	ListConsumers(ctx context.Context, stream string) ([]ConsumerInfo, error)
+	// Replay calls handle with the messages of the stream within the range, oldest first, and returns how many were
+	// replayed. The consumers of the stream are not affected.
+	Replay(ctx context.Context, stream string, replayRange ReplayRange, handle HandlerFunc) (int, error)
+	// PurgeSubject deletes the messages of the subject from the stream
+	PurgeSubject(ctx context.Context, stream string, subject string) error
 }
@@ -3,7 +3,8 @@ package nats
 import (
 	"context"
 	"fmt"
-	"log"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -17,6 +18,34 @@ type ServiceImpl struct {
 	logService logger.Service
 	jetStream  jetstream.JetStream
 	subjects   []string
+	dlqStream  string
+	maxDeliver int
+
+	// publishMaxPending bounds the messages of PublishBatch waiting for their acknowledgement
+	publishMaxPending int
+	// rpcTimeout bounds the requests without a deadline and the replies of the responders
+	rpcTimeout time.Duration
+
+	// consumers of the handlers and the messages being handled, see Drain
+	mu             sync.Mutex
+	consumeCtxs    []jetstream.ConsumeContext
+	subs           []*nats.Subscription
+	draining       bool
+	inFlight       sync.WaitGroup
+	handlerCtx     context.Context
+	cancelHandlers context.CancelFunc
+}
+
+// NewService returns the implementation selected by NATS_DRIVER, JetStream by default or the in process event bus
+func NewService(appConfig *config.AppConfig, logService logger.Service) (Service, error) {
+	switch appConfig.NATS_DRIVER {
+	case "", "nats":
+		return NewPubService(appConfig, logService)
+	case "memory":
+		return NewMemService(appConfig, logService)
+	default:
+		return nil, fmt.Errorf("nats.NewService(): invalid NATS_DRIVER '%s'", appConfig.NATS_DRIVER)
+	}
 }
 
 func NewPubService(appConfig *config.AppConfig, logService logger.Service) (Service, error) {
@@ -26,85 +55,77 @@ func NewPubService(appConfig *config.AppConfig, logService logger.Service) (Serv
 		logService.Debug(fmt.Sprintf("NATS url is not provided, so using default url of %s", url))
 	}
 
-	nc, err := nats.Connect(nats.DefaultURL)
+	maxDeliver, err := getMaxDeliver(appConfig)
 	if err != nil {
 		return nil, fmt.Errorf("nats.NewPublisherService(): %w", err)
 	}
 
-	js, err := jetstream.New(nc)
+	publishMaxPending, err := getPublishMaxPending(appConfig)
 	if err != nil {
 		return nil, fmt.Errorf("nats.NewPublisherService(): %w", err)
 	}
 
-	return &ServiceImpl{natsCon: nc, logService: logService, jetStream: js, subjects: []string{
-		appConfig.NATS_EVENT_USER_REGISTRATION,
-	}}, nil
-}
-
-func (s *ServiceImpl) Close() {
-	s.natsCon.Close()
-	s.logService.Debug("NATS connection closed")
-}
-
-func (s *ServiceImpl) Publish(topic string, payload []byte) error {
-	err := s.natsCon.Publish(topic, payload)
+	rpcTimeout, err := getRpcTimeout(appConfig)
 	if err != nil {
-		return fmt.Errorf("nats.ServiceImpl.Publish(): %w", err)
+		return nil, fmt.Errorf("nats.NewPublisherService(): %w", err)
 	}
-	return nil
-}
 
-func (s *ServiceImpl) Subscribe(topic string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	connOpts, err := getConnOptions(appConfig, logService)
+	if err != nil {
+		return nil, fmt.Errorf("nats.NewPublisherService(): %w", err)
+	}
 
-	stream, err := s.createStream(ctx, topic)
+	// url may list several servers separated by commas, the client reconnects to any of them
+	nc, err := nats.Connect(url, connOpts...)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("nats.NewPublisherService(): %w", err)
 	}
 
-	con, err := s.createConsumer(ctx, stream)
+	js, err := jetstream.New(nc, jetstream.WithPublishAsyncMaxPending(publishMaxPending))
 	if err != nil {
-		return err
+		nc.Close()
+		return nil, fmt.Errorf("nats.NewPublisherService(): %w", err)
 	}
 
-	// we don't need to stop the consuming if we run s.Close() func
-	_, err = con.Consume(func(msg jetstream.Msg) {
-		log.Printf("Topic: %s, Message: %s", msg.Subject(), msg.Data())
-		err := msg.Ack()
-		if err != nil {
-			s.logService.Error(fmt.Sprintf("nats.ServiceImpl.Subscribe(): error fetching messages : %s", err))
+	subjects := []string{}
+	for _, subject := range []string{appConfig.NATS_EVENT_USER_REGISTRATION, appConfig.NATS_EVENT_USER_NEW_DEVICE} {
+		if subject != "" {
+			subjects = append(subjects, subject)
 		}
-	})
-
-	if err != nil {
-		return fmt.Errorf("nats.ServiceImpl.Subscribe(): error setting consumer handler: %w", err)
 	}
 
-	s.logService.Debug("NATS consumer started")
-	return nil
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
+
+	return &ServiceImpl{
+		natsCon:           nc,
+		logService:        logService,
+		jetStream:         js,
+		subjects:          subjects,
+		dlqStream:         getDlqStream(appConfig),
+		maxDeliver:        maxDeliver,
+		publishMaxPending: publishMaxPending,
+		rpcTimeout:        rpcTimeout,
+		handlerCtx:        handlerCtx,
+		cancelHandlers:    cancelHandlers,
+	}, nil
 }
 
-func (s *ServiceImpl) createConsumer(ctx context.Context, stream jetstream.Stream) (jetstream.Consumer, error) {
-	con, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
-		Durable:      "database_usage_service",
-		AckPolicy:    jetstream.AckExplicitPolicy,
-		ReplayPolicy: jetstream.ReplayInstantPolicy,
-	})
+func (s *ServiceImpl) Close() {
+	s.natsCon.Close()
+	s.logService.Debug("NATS connection closed")
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("nats.ServiceImpl.Subscribe(): error creating consumer : %w", err)
+func (s *ServiceImpl) createStream(ctx context.Context, name string, handlers []Handler) (jetstream.Stream, error) {
+	subjects := append([]string{}, s.subjects...)
+	for _, handler := range handlers {
+		if !slices.Contains(subjects, handler.Subject) {
+			subjects = append(subjects, handler.Subject)
+		}
 	}
 
-	s.logService.Debug("NATS consumer created")
-
-	return con, nil
-}
-
-func (s *ServiceImpl) createStream(ctx context.Context, name string) (jetstream.Stream, error) {
 	stream, err := s.jetStream.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
 		Name:     name,
-		Subjects: s.subjects,
+		Subjects: subjects,
 	})
 
 	if err != nil {
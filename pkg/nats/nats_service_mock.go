@@ -1,6 +1,11 @@
 package nats
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/mock"
+)
 
 type PubServiceMock struct {
 	mock.Mock
@@ -10,12 +15,77 @@ func (p *PubServiceMock) Close() {
 	p.Called()
 }
 
+func (p *PubServiceMock) Status() ConnStatus {
+	args := p.Called()
+	return args.Get(0).(ConnStatus)
+}
+
 func (p *PubServiceMock) Publish(topic string, payload []byte) error {
 	args := p.Called(topic, payload)
 	return args.Error(0)
 }
 
-func (p *PubServiceMock) Subscribe(topic string) error {
-	args := p.Called(topic)
+func (p *PubServiceMock) PublishCtx(ctx context.Context, topic string, payload []byte) error {
+	args := p.Called(ctx, topic, payload)
+	return args.Error(0)
+}
+
+func (p *PubServiceMock) PublishToStream(ctx context.Context, topic string, payload []byte, header nats.Header) error {
+	args := p.Called(ctx, topic, payload, header)
+	return args.Error(0)
+}
+
+func (p *PubServiceMock) PublishBatch(ctx context.Context, msgs []Msg) []error {
+	args := p.Called(ctx, msgs)
+	return args.Get(0).([]error)
+}
+
+func (p *PubServiceMock) Subscribe(stream string, registry *Registry) error {
+	args := p.Called(stream, registry)
+	return args.Error(0)
+}
+
+func (p *PubServiceMock) Drain(ctx context.Context) error {
+	args := p.Called(ctx)
+	return args.Error(0)
+}
+
+func (p *PubServiceMock) Respond(responder Responder) error {
+	args := p.Called(responder)
+	return args.Error(0)
+}
+
+func (p *PubServiceMock) Request(ctx context.Context, subject string, data []byte, header nats.Header) (Msg, error) {
+	args := p.Called(ctx, subject, data, header)
+	return args.Get(0).(Msg), args.Error(1)
+}
+
+func (p *PubServiceMock) ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	args := p.Called(ctx, limit)
+	return args.Get(0).([]DeadLetter), args.Error(1)
+}
+
+func (p *PubServiceMock) ReplayDeadLetter(ctx context.Context, sequence uint64) error {
+	args := p.Called(ctx, sequence)
+	return args.Error(0)
+}
+
+func (p *PubServiceMock) ListStreams(ctx context.Context) ([]StreamInfo, error) {
+	args := p.Called(ctx)
+	return args.Get(0).([]StreamInfo), args.Error(1)
+}
+
+func (p *PubServiceMock) ListConsumers(ctx context.Context, stream string) ([]ConsumerInfo, error) {
+	args := p.Called(ctx, stream)
+	return args.Get(0).([]ConsumerInfo), args.Error(1)
+}
+
+func (p *PubServiceMock) Replay(ctx context.Context, stream string, replayRange ReplayRange, handle HandlerFunc) (int, error) {
+	args := p.Called(ctx, stream, replayRange, handle)
+	return args.Int(0), args.Error(1)
+}
+
+func (p *PubServiceMock) PurgeSubject(ctx context.Context, stream string, subject string) error {
+	args := p.Called(ctx, stream, subject)
 	return args.Error(0)
 }
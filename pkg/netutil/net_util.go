@@ -0,0 +1,79 @@
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseTrustedProxies parses a comma separated list of ips and cidrs, e.g. "10.0.0.0/8,192.168.1.10"
+func ParseTrustedProxies(proxies string) ([]*net.IPNet, error) {
+	trustedProxies := []*net.IPNet{}
+	for _, proxy := range strings.Split(proxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("netutil.ParseTrustedProxies(): invalid ip '%s'", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trustedProxies = append(trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("netutil.ParseTrustedProxies(): invalid cidr '%s'", proxy)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+
+	return trustedProxies, nil
+}
+
+// GetClientIp returns the ip of the client from the address of the peer and the X-Forwarded-For header. The header can
+// be set by anyone, so it is only read when the peer is a trusted proxy: its addresses are walked from the nearest one
+// and the first address which is not a trusted proxy is the client. Addresses which are not valid ips end the walk.
+func GetClientIp(remoteAddr string, forwardedFor string, trustedProxies []*net.IPNet) string {
+	clientIp := remoteAddr
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err == nil {
+		clientIp = host
+	}
+
+	ip := net.ParseIP(clientIp)
+	if ip == nil || !isTrusted(ip, trustedProxies) || forwardedFor == "" {
+		return clientIp
+	}
+
+	addrs := strings.Split(forwardedFor, ",")
+	for i := len(addrs) - 1; i >= 0; i-- {
+		ip = net.ParseIP(strings.TrimSpace(addrs[i]))
+		if ip == nil {
+			break
+		}
+
+		clientIp = ip.String()
+		if !isTrusted(ip, trustedProxies) {
+			break
+		}
+	}
+
+	return clientIp
+}
+
+func isTrusted(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, trustedProxy := range trustedProxies {
+		if trustedProxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package netutil
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseTrustedProxies(t *testing.T) {
+	// ACT
+	res, errRes := ParseTrustedProxies(" 10.0.0.0/8, 192.168.1.10,::1,")
+	_, errInvalidIpRes := ParseTrustedProxies("10.0.0.300")
+	_, errInvalidCidrRes := ParseTrustedProxies("10.0.0.0/33")
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Len(t, res, 3)
+	assert.Equal(t, "10.0.0.0/8", res[0].String())
+	assert.Equal(t, "192.168.1.10/32", res[1].String())
+	assert.Equal(t, "::1/128", res[2].String())
+	assert.EqualError(t, errInvalidIpRes, "netutil.ParseTrustedProxies(): invalid ip '10.0.0.300'")
+	assert.EqualError(t, errInvalidCidrRes, "netutil.ParseTrustedProxies(): invalid cidr '10.0.0.0/33'")
+}
+
+func Test_GetClientIp(t *testing.T) {
+	trustedProxies, _ := ParseTrustedProxies("10.0.0.0/8")
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		trustProxies bool
+		expected     string
+	}{
+		{"no_proxy", "203.0.113.7:51234", "", false, "203.0.113.7"},
+		{"untrusted_peer", "203.0.113.7:51234", "198.51.100.1", true, "203.0.113.7"},
+		{"no_trusted_proxies", "10.0.0.2:51234", "198.51.100.1", false, "10.0.0.2"},
+		{"trusted_peer", "10.0.0.2:51234", "198.51.100.1", true, "198.51.100.1"},
+		{"spoofed_first_address", "10.0.0.2:51234", "1.2.3.4, 198.51.100.1, 10.0.0.3", true, "198.51.100.1"},
+		{"invalid_address", "10.0.0.2:51234", "198.51.100.1, not-an-ip", true, "10.0.0.2"},
+		{"oversized_address", "10.0.0.2:51234", strings.Repeat("1", 200), true, "10.0.0.2"},
+		{"only_trusted_proxies", "10.0.0.2:51234", "10.0.0.4, 10.0.0.3", true, "10.0.0.4"},
+		{"ipv6", "[2001:db8::1]:443", "", false, "2001:db8::1"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// ARRANGE
+			var proxies []*net.IPNet
+			if testCase.trustProxies {
+				proxies = trustedProxies
+			}
+
+			// ACT
+			res := GetClientIp(testCase.remoteAddr, testCase.forwardedFor, proxies)
+
+			// ASSERT
+			assert.Equal(t, testCase.expected, res)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// Generator generates the schemas of Go types from their json and validate tags. Structs are added to the schemas of
+// the components under the name of their type and referenced, a type is expected to be used either in requests or in
+// responses:
+//   - request properties are required when their validate tag has "required", the rules of the tag become constraints
+//   - response properties are required unless their json tag has "omitempty"
+//
+// Pointers are nullable in both.
+type Generator struct {
+	schemas map[string]*Schema
+}
+
+func NewGenerator() *Generator {
+	return &Generator{schemas: map[string]*Schema{}}
+}
+
+// Schemas returns the schemas of the structs generated so far, to be used as the schemas of the components
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// RequestSchema returns the schema of the request body v
+func (g *Generator) RequestSchema(v any) *Schema {
+	return g.schemaOf(reflect.TypeOf(v), true)
+}
+
+// ResponseSchema returns the schema of the response body v
+func (g *Generator) ResponseSchema(v any) *Schema {
+	return g.schemaOf(reflect.TypeOf(v), false)
+}
+
+// QueryParameters returns a query parameter per field of the struct v, for requests whose fields are read from the
+// query string. Query parameters are strings, so the ",string" option of the json tag does not change their schema.
+func (g *Generator) QueryParameters(v any) []Parameter {
+	parameters := []Parameter{}
+	for _, field := range getFields(reflect.TypeOf(v)) {
+		schema := g.schemaOf(field.typ, true)
+		applyRules(schema, field.rules)
+		parameters = append(parameters, Parameter{
+			Name:     field.name,
+			In:       "query",
+			Required: field.rules.required,
+			Schema:   schema,
+		})
+	}
+
+	return parameters
+}
+
+func (g *Generator) schemaOf(t reflect.Type, isReq bool) *Schema {
+	switch {
+	case t == nil:
+		return &Schema{}
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schemaOf(t.Elem(), isReq)
+		if schema.Ref != "" {
+			// siblings of $ref are ignored, the reference is wrapped to be nullable
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem(), isReq)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem(), isReq)}
+	case reflect.Struct:
+		return g.structSchema(t, isReq)
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *Generator) structSchema(t reflect.Type, isReq bool) *Schema {
+	name := t.Name()
+	if name == "" {
+		return g.newStructSchema(t, isReq)
+	}
+
+	if _, exists := g.schemas[name]; !exists {
+		// the name is taken before the properties are generated so that recursive types end
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.newStructSchema(t, isReq)
+	}
+
+	return NewRef(name)
+}
+
+func (g *Generator) newStructSchema(t reflect.Type, isReq bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range getFields(t) {
+		var propSchema *Schema
+		fieldRules := field.rules
+		if field.asString && isNumber(field.typ) {
+			// the range of a number encoded as a string cannot be expressed, only its digits are
+			propSchema = &Schema{Type: "string", Pattern: "^-?[0-9]+$"}
+			fieldRules.min, fieldRules.max = nil, nil
+		} else {
+			propSchema = g.schemaOf(field.typ, isReq)
+		}
+
+		if isReq {
+			applyRules(propSchema, fieldRules)
+		}
+		schema.Properties[field.name] = propSchema
+
+		if (isReq && field.rules.required) || (!isReq && !field.omitEmpty) {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+
+	return schema
+}
+
+type field struct {
+	name      string
+	typ       reflect.Type
+	omitEmpty bool
+	asString  bool
+	rules     rules
+}
+
+// getFields returns the fields of the struct encoded to JSON, the fields of embedded structs are promoted like
+// encoding/json does
+func getFields(t reflect.Type) []field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := []field{}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		jsonTag := structField.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(jsonTag, ",")
+		if structField.Anonymous && name == "" {
+			fields = append(fields, getFields(structField.Type)...)
+			continue
+		}
+		if !structField.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+
+		fields = append(fields, field{
+			name:      name,
+			typ:       structField.Type,
+			omitEmpty: strings.Contains(options, "omitempty"),
+			asString:  strings.Contains(options, "string"),
+			rules:     parseRules(structField.Tag.Get("validate")),
+		})
+	}
+
+	return fields
+}
+
+// rules are the validate tag rules which have an equivalent in a schema
+type rules struct {
+	required bool
+	format   string
+	min      *float64
+	max      *float64
+	oneOf    []string
+	// dive holds the rules of the items of a slice
+	dive *rules
+}
+
+func parseRules(tag string) rules {
+	var r rules
+	if tag == "" {
+		return r
+	}
+
+	parts := strings.Split(tag, ",")
+	for i, part := range parts {
+		rule, param, _ := strings.Cut(part, "=")
+		switch rule {
+		case "required":
+			r.required = true
+		case "email":
+			r.format = "email"
+		case "url":
+			r.format = "uri"
+		case "uuid", "uuid4":
+			r.format = "uuid"
+		case "min", "gte":
+			r.min = parseFloat(param)
+		case "max", "lte":
+			r.max = parseFloat(param)
+		case "len":
+			r.min = parseFloat(param)
+			r.max = r.min
+		case "oneof":
+			r.oneOf = strings.Fields(param)
+		case "dive":
+			diveRules := parseRules(strings.Join(parts[i+1:], ","))
+			r.dive = &diveRules
+			return r
+		}
+	}
+
+	return r
+}
+
+func parseFloat(param string) *float64 {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// applyRules adds the constraints of the rules to the schema, min and max are lengths for strings and sizes for arrays
+func applyRules(schema *Schema, r rules) {
+	if r.format != "" {
+		schema.Format = r.format
+	}
+	for _, value := range r.oneOf {
+		schema.Enum = append(schema.Enum, value)
+	}
+
+	toInt := func(value *float64) *int {
+		if value == nil {
+			return nil
+		}
+		intValue := int(*value)
+		return &intValue
+	}
+
+	switch schema.Type {
+	case "string":
+		schema.MinLength = toInt(r.min)
+		schema.MaxLength = toInt(r.max)
+		// required rejects empty strings
+		if r.required && schema.MinLength == nil && schema.Pattern == "" {
+			minLength := 1
+			schema.MinLength = &minLength
+		}
+	case "integer", "number":
+		schema.Minimum = r.min
+		schema.Maximum = r.max
+	case "array":
+		schema.MinItems = toInt(r.min)
+		schema.MaxItems = toInt(r.max)
+		if r.dive != nil && schema.Items != nil && schema.Items.Ref == "" {
+			applyRules(schema.Items, *r.dive)
+		}
+	}
+}
+
+func isNumber(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required,max=50"`
+}
+
+type testReq struct {
+	Email    string       `json:"email" validate:"required,email"`
+	Role     string       `json:"role" validate:"required,oneof=owner member"`
+	Nickname *string      `json:"nickname" validate:"omitempty,min=2"`
+	Age      int          `json:"age" validate:"omitempty,gte=18,lte=130"`
+	Page     int          `json:"page,string" validate:"omitempty,min=1"`
+	Tags     []string     `json:"tags" validate:"required,min=1,max=5,dive,required,max=10"`
+	Address  *testAddress `json:"address"`
+	Ignored  string       `json:"-"`
+	internal string
+}
+
+type testItemRes struct {
+	Id string `json:"id"`
+}
+
+type testRes struct {
+	Id        string            `json:"id"`
+	Items     []testItemRes     `json:"items"`
+	Labels    map[string]string `json:"labels"`
+	Note      *string           `json:"note,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+type testQueryReq struct {
+	WebhookId string `json:"webhookId" validate:"required"`
+	Limit     int    `json:"limit,string" validate:"omitempty,min=1,max=100"`
+}
+
+func toJson(t *testing.T, v any) string {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bytes)
+}
+
+func Test_RequestSchema_Should_Convert_Validate_Tags(t *testing.T) {
+	// ARRANGE
+	generator := NewGenerator()
+
+	// ACT
+	schemaRes := generator.RequestSchema(testReq{})
+
+	// ASSERT
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/testReq"}, schemaRes)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "format": "email", "minLength": 1},
+			"role": {"type": "string", "enum": ["owner", "member"], "minLength": 1},
+			"nickname": {"type": "string", "nullable": true, "minLength": 2},
+			"age": {"type": "integer", "minimum": 18, "maximum": 130},
+			"page": {"type": "string", "pattern": "^-?[0-9]+$"},
+			"tags": {"type": "array", "items": {"type": "string", "minLength": 1, "maxLength": 10}, "minItems": 1, "maxItems": 5},
+			"address": {"allOf": [{"$ref": "#/components/schemas/testAddress"}], "nullable": true}
+		},
+		"required": ["email", "role", "tags"]
+	}`, toJson(t, generator.Schemas()["testReq"]))
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {"city": {"type": "string", "minLength": 1, "maxLength": 50}},
+		"required": ["city"]
+	}`, toJson(t, generator.Schemas()["testAddress"]))
+}
+
+func Test_ResponseSchema_Should_Require_Fields_Without_Omitempty(t *testing.T) {
+	// ARRANGE
+	generator := NewGenerator()
+
+	// ACT
+	schemaRes := generator.ResponseSchema(testRes{})
+
+	// ASSERT
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/testRes"}, schemaRes)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"items": {"type": "array", "items": {"$ref": "#/components/schemas/testItemRes"}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"note": {"type": "string", "nullable": true},
+			"createdAt": {"type": "string", "format": "date-time"}
+		},
+		"required": ["id", "items", "labels", "createdAt"]
+	}`, toJson(t, generator.Schemas()["testRes"]))
+	assert.Contains(t, generator.Schemas(), "testItemRes")
+}
+
+func Test_QueryParameters_Should_Return_Parameter_Per_Field(t *testing.T) {
+	// ARRANGE
+	generator := NewGenerator()
+
+	// ACT
+	parametersRes := generator.QueryParameters(testQueryReq{})
+
+	// ASSERT
+	assert.JSONEq(t, `[
+		{"name": "webhookId", "in": "query", "required": true, "schema": {"type": "string", "minLength": 1}},
+		{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}}
+	]`, toJson(t, parametersRes))
+	assert.Empty(t, generator.Schemas())
+}
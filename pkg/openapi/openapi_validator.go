@@ -0,0 +1,320 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidateRequest validates a request against the operation of the document, reqBytes is the JSON body or, for
+// operations with query parameters, the query parameters as a JSON object of strings the way the restapi reads them.
+// Properties which are not in the schema are accepted, they are ignored when the request is parsed.
+func (d *Document) ValidateRequest(method string, path string, reqBytes []byte) error {
+	operation, err := d.getOperation(method, path)
+	if err != nil {
+		return fmt.Errorf("openapi.ValidateRequest(): %w", err)
+	}
+
+	if len(operation.Parameters) > 0 {
+		err = d.validateQueryParameters(operation.Parameters, reqBytes)
+	} else if operation.RequestBody != nil {
+		err = d.validateBody(operation.RequestBody.Content, reqBytes, false)
+	}
+	if err != nil {
+		return fmt.Errorf("openapi.ValidateRequest(): %s %s: %w", strings.ToUpper(method), path, err)
+	}
+
+	return nil
+}
+
+// ValidateResponse validates a response against the operation of the document, the status code must be documented and
+// the body must not have properties which are not in the schema
+func (d *Document) ValidateResponse(method string, path string, statusCode int, resBytes []byte) error {
+	operation, err := d.getOperation(method, path)
+	if err != nil {
+		return fmt.Errorf("openapi.ValidateResponse(): %w", err)
+	}
+
+	response, exists := operation.Responses[strconv.Itoa(statusCode)]
+	if !exists {
+		response, exists = operation.Responses["default"]
+	}
+	if !exists {
+		return fmt.Errorf("openapi.ValidateResponse(): %s %s: status %d is not documented", strings.ToUpper(method), path, statusCode)
+	}
+
+	err = d.validateBody(response.Content, resBytes, true)
+	if err != nil {
+		return fmt.Errorf("openapi.ValidateResponse(): %s %s %d: %w", strings.ToUpper(method), path, statusCode, err)
+	}
+
+	return nil
+}
+
+func (d *Document) getOperation(method string, path string) (*Operation, error) {
+	path, _, _ = strings.Cut(path, "?")
+	pathItem, exists := d.Paths[path]
+	if !exists {
+		return nil, fmt.Errorf("path '%s' is not documented", path)
+	}
+
+	operation, exists := pathItem[strings.ToLower(method)]
+	if !exists {
+		return nil, fmt.Errorf("method '%s' of path '%s' is not documented", strings.ToUpper(method), path)
+	}
+
+	return operation, nil
+}
+
+func (d *Document) validateBody(content map[string]MediaType, body []byte, strict bool) error {
+	mediaType, exists := content[ContentType]
+	if !exists {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return errors.New("body is not documented")
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		return fmt.Errorf("body is not valid JSON: %w", err)
+	}
+
+	return errors.Join(d.validateValue(mediaType.Schema, value, "$", strict)...)
+}
+
+func (d *Document) validateQueryParameters(parameters []Parameter, reqBytes []byte) error {
+	queryParams := map[string]string{}
+	if len(reqBytes) > 0 {
+		err := json.Unmarshal(reqBytes, &queryParams)
+		if err != nil {
+			return fmt.Errorf("query parameters are not a JSON object of strings: %w", err)
+		}
+	}
+
+	var errs []error
+	for _, parameter := range parameters {
+		param, exists := queryParams[parameter.Name]
+		if !exists {
+			if parameter.Required {
+				errs = append(errs, fmt.Errorf("query parameter '%s' is required", parameter.Name))
+			}
+			continue
+		}
+
+		// query parameters are strings, numbers are parsed before they are validated
+		var value any = param
+		schema := d.resolve(parameter.Schema)
+		if schema.Type == "integer" || schema.Type == "number" {
+			value = json.Number(param)
+		}
+		errs = append(errs, d.validateValue(parameter.Schema, value, parameter.Name, false)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// resolve follows the reference of the schema to the schema of the components
+func (d *Document) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]
+	}
+	if schema == nil {
+		return &Schema{}
+	}
+	return schema
+}
+
+// validateValue returns the errors of the value decoded with json.Number numbers, path locates the value in the body
+// for the error messages
+func (d *Document) validateValue(schema *Schema, value any, path string, strict bool) []error {
+	if schema != nil && schema.Ref != "" {
+		if _, exists := d.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]; !exists {
+			return []error{fmt.Errorf("%s: schema '%s' does not exist", path, schema.Ref)}
+		}
+		schema = d.resolve(schema)
+	}
+	if schema == nil {
+		return nil
+	}
+
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return []error{fmt.Errorf("%s: must not be null", path)}
+	}
+
+	var errs []error
+	for _, subSchema := range schema.AllOf {
+		errs = append(errs, d.validateValue(subSchema, value, path, strict)...)
+	}
+
+	if len(schema.Enum) > 0 && !isInEnum(schema.Enum, value) {
+		errs = append(errs, fmt.Errorf("%s: must be one of %v", path, schema.Enum))
+	}
+
+	switch schema.Type {
+	case "":
+		return errs
+	case "object":
+		return append(errs, d.validateObject(schema, value, path, strict)...)
+	case "array":
+		return append(errs, d.validateArray(schema, value, path, strict)...)
+	case "string":
+		return append(errs, validateString(schema, value, path)...)
+	case "integer", "number":
+		return append(errs, validateNumber(schema, value, path)...)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, fmt.Errorf("%s: must be a boolean", path))
+		}
+		return errs
+	default:
+		return append(errs, fmt.Errorf("%s: unknown type '%s'", path, schema.Type))
+	}
+}
+
+func (d *Document) validateObject(schema *Schema, value any, path string, strict bool) []error {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return []error{fmt.Errorf("%s: must be an object", path)}
+	}
+
+	var errs []error
+	for _, name := range schema.Required {
+		if _, exists := object[name]; !exists {
+			errs = append(errs, fmt.Errorf("%s.%s: is required", path, name))
+		}
+	}
+
+	for name, propValue := range object {
+		propSchema, exists := schema.Properties[name]
+		switch {
+		case exists:
+			errs = append(errs, d.validateValue(propSchema, propValue, path+"."+name, strict)...)
+		case schema.AdditionalProperties != nil:
+			errs = append(errs, d.validateValue(schema.AdditionalProperties, propValue, path+"."+name, strict)...)
+		case strict && schema.Properties != nil:
+			errs = append(errs, fmt.Errorf("%s.%s: is not documented", path, name))
+		}
+	}
+
+	return errs
+}
+
+func (d *Document) validateArray(schema *Schema, value any, path string, strict bool) []error {
+	array, ok := value.([]any)
+	if !ok {
+		return []error{fmt.Errorf("%s: must be an array", path)}
+	}
+
+	var errs []error
+	if schema.MinItems != nil && len(array) < *schema.MinItems {
+		errs = append(errs, fmt.Errorf("%s: must have at least %d items", path, *schema.MinItems))
+	}
+	if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+		errs = append(errs, fmt.Errorf("%s: must have at most %d items", path, *schema.MaxItems))
+	}
+
+	for i, item := range array {
+		errs = append(errs, d.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), strict)...)
+	}
+
+	return errs
+}
+
+func validateString(schema *Schema, value any, path string) []error {
+	str, ok := value.(string)
+	if !ok {
+		return []error{fmt.Errorf("%s: must be a string", path)}
+	}
+
+	var errs []error
+	length := len([]rune(str))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		errs = append(errs, fmt.Errorf("%s: must be at least %d characters long", path, *schema.MinLength))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		errs = append(errs, fmt.Errorf("%s: must be at most %d characters long", path, *schema.MaxLength))
+	}
+
+	if schema.Pattern != "" {
+		matched, err := regexp.MatchString(schema.Pattern, str)
+		if err != nil || !matched {
+			errs = append(errs, fmt.Errorf("%s: must match the pattern '%s'", path, schema.Pattern))
+		}
+	}
+
+	if !isValidFormat(schema.Format, str) {
+		errs = append(errs, fmt.Errorf("%s: must be a valid %s", path, schema.Format))
+	}
+
+	return errs
+}
+
+func validateNumber(schema *Schema, value any, path string) []error {
+	number, ok := value.(json.Number)
+	if !ok {
+		return []error{fmt.Errorf("%s: must be a number", path)}
+	}
+
+	float, err := number.Float64()
+	if err != nil {
+		return []error{fmt.Errorf("%s: must be a number", path)}
+	}
+	if schema.Type == "integer" && float != math.Trunc(float) {
+		return []error{fmt.Errorf("%s: must be an integer", path)}
+	}
+
+	var errs []error
+	if schema.Minimum != nil && float < *schema.Minimum {
+		errs = append(errs, fmt.Errorf("%s: must be at least %v", path, *schema.Minimum))
+	}
+	if schema.Maximum != nil && float > *schema.Maximum {
+		errs = append(errs, fmt.Errorf("%s: must be at most %v", path, *schema.Maximum))
+	}
+
+	return errs
+}
+
+// isValidFormat checks the formats the generator produces, other formats are not checked
+func isValidFormat(format string, str string) bool {
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(str)
+		return err == nil && address.Address == str
+	case "uri":
+		uri, err := url.ParseRequestURI(str)
+		return err == nil && uri.Scheme != "" && uri.Host != ""
+	case "uuid":
+		return uuidRegex.MatchString(str)
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, str)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+func isInEnum(enum []any, value any) bool {
+	for _, enumValue := range enum {
+		if fmt.Sprint(enumValue) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
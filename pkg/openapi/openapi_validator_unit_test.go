@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupDocument returns a document with a POST /items operation taking testReq and answering testRes, and a GET
+// /items operation taking the query parameters of testQueryReq
+func setupDocument() *Document {
+	generator := NewGenerator()
+	document := &Document{
+		OpenApi: Version,
+		Paths: map[string]PathItem{
+			"/items": {
+				"post": {
+					RequestBody: &RequestBody{
+						Required: true,
+						Content:  map[string]MediaType{ContentType: {Schema: generator.RequestSchema(testReq{})}},
+					},
+					Responses: map[string]*Response{
+						"200": {Content: map[string]MediaType{ContentType: {Schema: generator.ResponseSchema(testRes{})}}},
+						"204": {Description: "no content"},
+					},
+				},
+				"get": {
+					Parameters: generator.QueryParameters(testQueryReq{}),
+					Responses:  map[string]*Response{},
+				},
+			},
+		},
+	}
+	document.Components.Schemas = generator.Schemas()
+	return document
+}
+
+func Test_ValidateRequest_Should_Accept_Valid_Body(t *testing.T) {
+	// ARRANGE
+	document := setupDocument()
+	reqBytes := []byte(`{"email":"a@b.com","role":"owner","age":20,"page":"2","tags":["x"],"address":null,"unknown":1}`)
+
+	// ACT
+	errRes := document.ValidateRequest("POST", "/items", reqBytes)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+}
+
+func Test_ValidateRequest_Should_Reject_Invalid_Body(t *testing.T) {
+	// ARRANGE
+	document := setupDocument()
+	reqBytes := []byte(`{"email":"not-an-email","role":"guest","age":12.5,"page":"two","tags":["x","01234567890"],"address":{}}`)
+
+	// ACT
+	errRes := document.ValidateRequest("POST", "/items", reqBytes)
+
+	// ASSERT
+	assert.NotNil(t, errRes)
+	assert.ErrorContains(t, errRes, "$.email: must be a valid email")
+	assert.ErrorContains(t, errRes, "$.role: must be one of [owner member]")
+	assert.ErrorContains(t, errRes, "$.age: must be an integer")
+	assert.ErrorContains(t, errRes, "$.page: must match the pattern")
+	assert.ErrorContains(t, errRes, "$.tags[1]: must be at most 10 characters long")
+	assert.ErrorContains(t, errRes, "$.address.city: is required")
+}
+
+func Test_ValidateRequest_Should_Validate_Query_Parameters(t *testing.T) {
+	// ARRANGE
+	document := setupDocument()
+
+	// ACT
+	errValidRes := document.ValidateRequest("GET", "/items?webhookId=1", []byte(`{"webhookId":"1","limit":"100"}`))
+	errMissingRes := document.ValidateRequest("GET", "/items", []byte(`{"limit":"101"}`))
+
+	// ASSERT
+	assert.Nil(t, errValidRes)
+	assert.ErrorContains(t, errMissingRes, "query parameter 'webhookId' is required")
+	assert.ErrorContains(t, errMissingRes, "limit: must be at most 100")
+}
+
+func Test_ValidateRequest_Should_Reject_Undocumented_Operation(t *testing.T) {
+	// ARRANGE
+	document := setupDocument()
+
+	// ACT
+	errPathRes := document.ValidateRequest("POST", "/unknown", nil)
+	errMethodRes := document.ValidateRequest("DELETE", "/items", nil)
+
+	// ASSERT
+	assert.EqualError(t, errPathRes, "openapi.ValidateRequest(): path '/unknown' is not documented")
+	assert.EqualError(t, errMethodRes, "openapi.ValidateRequest(): method 'DELETE' of path '/items' is not documented")
+}
+
+func Test_ValidateResponse_Should_Accept_Valid_Body(t *testing.T) {
+	// ARRANGE
+	document := setupDocument()
+	resBytes := []byte(`{"id":"1","items":[{"id":"2"}],"labels":{"a":"b"},"createdAt":"2023-01-02T03:04:05Z"}`)
+
+	// ACT
+	errRes := document.ValidateResponse("POST", "/items", 200, resBytes)
+	errNoContentRes := document.ValidateResponse("POST", "/items", 204, nil)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Nil(t, errNoContentRes)
+}
+
+func Test_ValidateResponse_Should_Reject_Undocumented_Properties_And_Status(t *testing.T) {
+	// ARRANGE
+	document := setupDocument()
+	resBytes := []byte(`{"id":1,"items":[{"id":"2","extra":true}],"labels":{"a":1},"note":null,"createdAt":"yesterday"}`)
+
+	// ACT
+	errRes := document.ValidateResponse("POST", "/items", 200, resBytes)
+	errStatusRes := document.ValidateResponse("POST", "/items", 500, []byte(`{}`))
+
+	// ASSERT
+	assert.NotNil(t, errRes)
+	assert.ErrorContains(t, errRes, "$.id: must be a string")
+	assert.ErrorContains(t, errRes, "$.items[0].extra: is not documented")
+	assert.ErrorContains(t, errRes, "$.labels.a: must be a string")
+	assert.ErrorContains(t, errRes, "$.createdAt: must be a valid date-time")
+	assert.NotContains(t, errRes.Error(), "$.note")
+	assert.EqualError(t, errStatusRes, "openapi.ValidateResponse(): POST /items: status 500 is not documented")
+}
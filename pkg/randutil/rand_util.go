@@ -0,0 +1,16 @@
+package randutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// GenSecureToken returns a hex encoded string generated from byteLen cryptographically secure random bytes
+func GenSecureToken(byteLen int) (string, error) {
+	bytes := make([]byte, byteLen)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("randutil.GenSecureToken(): %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,114 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/strutil"
+)
+
+// TraceIdHeader is the NATS header carrying the trace id of the request, the responder logs with the same trace id as
+// the requester
+const TraceIdHeader = "Trace-Id"
+
+// Codes of the errors of a reply, one per exception of pkg/exception so that the requester gets the same exception back
+const (
+	CodeInvalidArgument    = "INVALID_ARGUMENT"
+	CodeNotFound           = "NOT_FOUND"
+	CodeUnauthenticated    = "UNAUTHENTICATED"
+	CodePermissionDenied   = "PERMISSION_DENIED"
+	CodeAlreadyExists      = "ALREADY_EXISTS"
+	CodeFailedPrecondition = "FAILED_PRECONDITION"
+	CodeDeadlineExceeded   = "DEADLINE_EXCEEDED"
+	CodeInternal           = "INTERNAL"
+)
+
+// Reply is the JSON envelope of every reply, exactly one of Data and Error is set
+type Reply struct {
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error *Error          `json:"error,omitempty"`
+}
+
+// Error is an error returned by the responder, Type, Message and Details are the ones of the exception
+type Error struct {
+	Code    string             `json:"code"`
+	Type    string             `json:"type"`
+	Message string             `json:"message"`
+	Details *map[string]string `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc %s: %s: %s", e.Code, e.Type, e.Message)
+}
+
+// internalErr hides the unexpected errors of the responder from the requester, like the 500 responses of the restapi
+var internalErr = Error{Code: CodeInternal, Type: "INTERNAL", Message: "internal server error"}
+
+// toError maps the error of a handler to the error of its reply, ok is false when the error is not an exception
+func toError(err error) (rpcErr *Error, ok bool) {
+	newError := func(code string, base *exception.Base) *Error {
+		return &Error{Code: code, Type: base.Type, Message: base.Message, Details: base.Details}
+	}
+
+	switch e := err.(type) {
+	case exception.InvalidReq:
+		return newError(CodeInvalidArgument, convertDetailsKeyToCamelcase(e.Base)), true
+	case exception.NotFound:
+		return newError(CodeNotFound, e.Base), true
+	case exception.Unauthenticated:
+		return newError(CodeUnauthenticated, e.Base), true
+	case exception.Unauthorized:
+		return newError(CodePermissionDenied, e.Base), true
+	case exception.AlreadyExists:
+		return newError(CodeAlreadyExists, e.Base), true
+	case exception.FailedPrecondition:
+		return newError(CodeFailedPrecondition, e.Base), true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Code: CodeDeadlineExceeded, Type: "DEADLINE_EXCEEDED", Message: "request timed out"}, true
+	}
+
+	rpcErr = new(Error)
+	*rpcErr = internalErr
+	return rpcErr, false
+}
+
+// toException returns the exception of the error, errors whose code has no exception are returned as they are
+func (e *Error) toException() error {
+	base := exception.Base{Type: e.Type, Message: e.Message, Details: e.Details}
+
+	switch e.Code {
+	case CodeInvalidArgument:
+		return exception.NewInvalidReqFromBase(base)
+	case CodeNotFound:
+		return exception.NewNotFoundFromBase(base)
+	case CodeUnauthenticated:
+		return exception.NewUnauthenticatedFromBase(base)
+	case CodePermissionDenied:
+		return exception.NewUnauthorized(base)
+	case CodeAlreadyExists:
+		return exception.NewAlreadyExistsFromBase(base)
+	case CodeFailedPrecondition:
+		return exception.NewFailedPreconditionFromBase(base)
+	default:
+		return e
+	}
+}
+
+// convertDetailsKeyToCamelcase names the invalid fields like their JSON keys, the restapi does the same
+func convertDetailsKeyToCamelcase(base *exception.Base) *exception.Base {
+	if base.Details == nil {
+		return base
+	}
+
+	camelCaseDetails := map[string]string{}
+	for key, val := range *base.Details {
+		camelCaseDetails[strutil.PascalCaseToCamelCase(key)] = val
+	}
+
+	return &exception.Base{Type: base.Type, Message: base.Message, Details: &camelCaseDetails}
+}
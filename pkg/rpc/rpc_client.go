@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+)
+
+// Call sends req as JSON to the subject and decodes the data of the reply into Res. The errors of the responder are
+// returned as the exceptions of pkg/exception they were created from, or as *Error when they have no exception. The
+// trace id of ctx is sent along with the request.
+func Call[Req any, Res any](ctx context.Context, natsService nats.Service, subject string, req Req) (Res, error) {
+	var res Res
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return res, fmt.Errorf("rpc.Call(): %w", err)
+	}
+
+	header := natsgo.Header{}
+	if traceId := ctxutil.GetTraceIdFromCtx(ctx); traceId != "" {
+		header.Set(TraceIdHeader, traceId)
+	}
+
+	msg, err := natsService.Request(ctx, subject, reqBytes, header)
+	if err != nil {
+		return res, fmt.Errorf("rpc.Call(): %w", err)
+	}
+
+	var reply Reply
+	err = json.Unmarshal(msg.Data, &reply)
+	if err != nil {
+		return res, fmt.Errorf("rpc.Call(): invalid reply of '%s': %w", subject, err)
+	}
+
+	if reply.Error != nil {
+		return res, reply.Error.toException()
+	}
+
+	err = json.Unmarshal(reply.Data, &res)
+	if err != nil {
+		return res, fmt.Errorf("rpc.Call(): invalid data in the reply of '%s': %w", subject, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/uuidutil"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+)
+
+// Handler returns a nats.ReplyFunc which decodes and validates the JSON request into Req before calling fn, the result
+// of fn or its error is encoded in a Reply. Exceptions keep their type and message, other errors are logged and
+// replied as internal errors. The trace id of the request is added to the context, a new one when it has none.
+func Handler[Req any, Res any](logService logger.Service, validationHandler validation.Handler, fn func(ctx context.Context, req Req) (Res, error)) nats.ReplyFunc {
+	return func(ctx context.Context, msg nats.Msg) (replyBytes []byte) {
+		traceId := msg.Headers.Get(TraceIdHeader)
+		if traceId == "" {
+			traceId, _ = uuidutil.GenUuidV4()
+		}
+		ctx = ctxutil.WithTraceId(ctx, traceId)
+
+		// a panicking handler must not stop the responders of the process
+		defer func() {
+			if recoverRes := recover(); recoverRes != nil {
+				stack := make([]byte, 1024)
+				runtime.Stack(stack, false)
+				logService.ErrorCtx(ctx, fmt.Sprintf("recovered from panic replying to '%s': %v\n%s", msg.Subject, recoverRes, stack))
+				replyBytes = encodeError(ctx, logService, &internalErr)
+			}
+		}()
+
+		res, err := handle(ctx, validationHandler, msg, fn)
+		if err != nil {
+			rpcErr, isException := toError(err)
+			if !isException {
+				logService.ErrorCtx(ctx, fmt.Sprintf("unexpected error replying to '%s': %s", msg.Subject, err))
+			}
+			return encodeError(ctx, logService, rpcErr)
+		}
+
+		data, err := json.Marshal(res)
+		if err != nil {
+			logService.ErrorCtx(ctx, fmt.Sprintf("error encoding reply to '%s': %s", msg.Subject, err))
+			return encodeError(ctx, logService, &internalErr)
+		}
+
+		logService.DebugCtx(ctx, fmt.Sprintf("replied to '%s'", msg.Subject))
+		return encode(ctx, logService, Reply{Data: data})
+	}
+}
+
+func handle[Req any, Res any](ctx context.Context, validationHandler validation.Handler, msg nats.Msg, fn func(ctx context.Context, req Req) (Res, error)) (Res, error) {
+	var req Req
+	var res Res
+
+	err := json.Unmarshal(msg.Data, &req)
+	if err != nil {
+		return res, exception.NewInvalidReqFromBase(exception.Base{Message: "request data is missing or malformed"})
+	}
+
+	err = validationHandler.ValidateStruct(req)
+	if err != nil {
+		var valErr validation.ValidationError
+		if errors.As(err, &valErr) {
+			return res, exception.NewInvalidReqFromBase(exception.Base{Details: &valErr.Details})
+		}
+		return res, err
+	}
+
+	return fn(ctx, req)
+}
+
+func encodeError(ctx context.Context, logService logger.Service, rpcErr *Error) []byte {
+	return encode(ctx, logService, Reply{Error: rpcErr})
+}
+
+func encode(ctx context.Context, logService logger.Service, reply Reply) []byte {
+	replyBytes, err := json.Marshal(reply)
+	if err != nil {
+		logService.ErrorCtx(ctx, fmt.Sprintf("rpc.encode(): %s", err))
+		replyBytes, _ = json.Marshal(Reply{Error: &internalErr})
+	}
+
+	return replyBytes
+}
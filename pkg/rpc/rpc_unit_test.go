@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/pjmessi/golang-practice/config"
+	"github.com/pjmessi/golang-practice/pkg/ctxutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/pjmessi/golang-practice/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testSubject = "svc.test.echo"
+
+type echoReq struct {
+	Text string `json:"text" validate:"required"`
+}
+
+type echoRes struct {
+	Text    string `json:"text"`
+	TraceId string `json:"traceId"`
+}
+
+// setupResponder starts a responder calling fn on an in process event bus, which is returned to send requests with
+func setupResponder(t *testing.T, fn func(ctx context.Context, req echoReq) (echoRes, error)) (nats.Service, *logger.ServiceMock) {
+	logServiceMock := &logger.ServiceMock{}
+	logServiceMock.On("Debug", mock.Anything).Return()
+	logServiceMock.On("DebugCtx", mock.Anything, mock.Anything).Return()
+
+	natsService, err := nats.NewService(&config.AppConfig{NATS_DRIVER: "memory"}, logServiceMock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(natsService.Close)
+
+	validationHandler, err := validation.NewHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = natsService.Respond(nats.Responder{Subject: testSubject, Queue: "test", Reply: Handler(logServiceMock, validationHandler, fn)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return natsService, logServiceMock
+}
+
+func Test_Call_Should_Return_Data_Of_Reply_With_Trace_Id(t *testing.T) {
+	// ARRANGE
+	natsService, _ := setupResponder(t, func(ctx context.Context, req echoReq) (echoRes, error) {
+		return echoRes{Text: req.Text, TraceId: ctxutil.GetTraceIdFromCtx(ctx)}, nil
+	})
+
+	// ACT
+	res, errRes := Call[echoReq, echoRes](ctxutil.NewCtxWithTraceId("trace"), natsService, testSubject, echoReq{Text: "hello"})
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, echoRes{Text: "hello", TraceId: "trace"}, res)
+}
+
+func Test_Call_Should_Return_Exception_Of_Responder(t *testing.T) {
+	// ARRANGE
+	notFound := exception.NewNotFoundFromBase(exception.Base{Type: "USER.NOT_FOUND", Message: "user not found"})
+	natsService, _ := setupResponder(t, func(ctx context.Context, req echoReq) (echoRes, error) {
+		return echoRes{}, notFound
+	})
+
+	// ACT
+	_, errRes := Call[echoReq, echoRes](context.Background(), natsService, testSubject, echoReq{Text: "hello"})
+
+	// ASSERT
+	assert.Equal(t, notFound, errRes)
+}
+
+func Test_Call_Should_Return_InvalidReq_With_Json_Field_Names(t *testing.T) {
+	// ARRANGE
+	natsService, _ := setupResponder(t, func(ctx context.Context, req echoReq) (echoRes, error) {
+		return echoRes{}, nil
+	})
+
+	// ACT
+	_, errRes := Call[echoReq, echoRes](context.Background(), natsService, testSubject, echoReq{})
+
+	// ASSERT
+	var invalidReq exception.InvalidReq
+	assert.ErrorAs(t, errRes, &invalidReq)
+	assert.Equal(t, map[string]string{"text": "validation failed for tag: 'required'"}, *invalidReq.Details)
+}
+
+func Test_Call_Should_Hide_Unexpected_Errors_And_Panics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(ctx context.Context, req echoReq) (echoRes, error)
+	}{
+		{"error", func(ctx context.Context, req echoReq) (echoRes, error) {
+			return echoRes{}, errors.New("connection refused")
+		}},
+		{"panic", func(ctx context.Context, req echoReq) (echoRes, error) {
+			panic("nil map")
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// ARRANGE
+			natsService, logServiceMock := setupResponder(t, test.fn)
+			logServiceMock.On("ErrorCtx", mock.Anything, mock.Anything).Return()
+
+			// ACT
+			_, errRes := Call[echoReq, echoRes](context.Background(), natsService, testSubject, echoReq{Text: "hello"})
+
+			// ASSERT
+			var rpcErr *Error
+			assert.ErrorAs(t, errRes, &rpcErr)
+			assert.Equal(t, internalErr, *rpcErr)
+			logServiceMock.AssertCalled(t, "ErrorCtx", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func Test_Call_Should_Return_Error_Without_Responders(t *testing.T) {
+	// ARRANGE
+	natsService, _ := setupResponder(t, func(ctx context.Context, req echoReq) (echoRes, error) {
+		return echoRes{}, nil
+	})
+
+	// ACT
+	_, errRes := Call[echoReq, echoRes](context.Background(), natsService, "svc.test.unknown", echoReq{Text: "hello"})
+
+	// ASSERT
+	assert.ErrorIs(t, errRes, natsgo.ErrNoResponders)
+}
+
+func Test_toError(t *testing.T) {
+	details := map[string]string{"Email": "invalid"}
+	tests := []struct {
+		err         error
+		code        string
+		isException bool
+	}{
+		{exception.NewInvalidReqFromBase(exception.Base{Details: &details}), CodeInvalidArgument, true},
+		{exception.NewNotFound(), CodeNotFound, true},
+		{exception.NewUnauthenticated(), CodeUnauthenticated, true},
+		{exception.NewUnauthorized(exception.Base{}), CodePermissionDenied, true},
+		{exception.NewAlreadyExists(), CodeAlreadyExists, true},
+		{exception.NewFailedPrecondition(), CodeFailedPrecondition, true},
+		{context.DeadlineExceeded, CodeDeadlineExceeded, true},
+		{errors.New("unexpected"), CodeInternal, false},
+	}
+
+	for _, test := range tests {
+		rpcErr, isException := toError(test.err)
+		assert.Equal(t, test.code, rpcErr.Code, test.err.Error())
+		assert.Equal(t, test.isException, isException, test.err.Error())
+		if test.isException && test.code != CodeDeadlineExceeded {
+			assert.IsType(t, test.err, rpcErr.toException(), "should map the code back to the exception")
+		}
+	}
+	assert.Equal(t, map[string]string{"Email": "invalid"}, details, "should not change the details of the exception")
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/cmd/grpcapi"
+	authv1 "github.com/pjmessi/golang-practice/internal/pb/auth/v1"
+	userv1 "github.com/pjmessi/golang-practice/internal/pb/user/v1"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// getErrorInfo returns the ErrorInfo detail of the status of the error
+func getErrorInfo(err error) *errdetails.ErrorInfo {
+	for _, detail := range status.Convert(err).Details() {
+		if errInfo, ok := detail.(*errdetails.ErrorInfo); ok {
+			return errInfo
+		}
+	}
+	return nil
+}
+
+func TestIntegrationGrpcRegisterLoginAndGetProfile(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	authClient := authv1.NewAuthServiceClient(grpcConn)
+	userClient := userv1.NewUserServiceClient(grpcConn)
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+
+	// ACT
+	regRes, errRegRes := userClient.RegisterUser(context.Background(), &userv1.RegisterUserRequest{Email: email, Password: "Password123!"})
+	var header metadata.MD
+	loginRes, errLoginRes := authClient.Login(context.Background(), &authv1.LoginRequest{Email: email, Password: "Password123!"}, grpc.Header(&header))
+	authCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", fmt.Sprintf("Bearer %s", loginRes.GetJwt()))
+	profileRes, errProfileRes := userClient.GetProfile(authCtx, &userv1.GetProfileRequest{})
+
+	// ASSERT
+	assert.Nil(t, errRegRes)
+	assert.Equal(t, email, regRes.GetUser().GetEmail(), "should return the registered user")
+	assert.Nil(t, errLoginRes)
+	assert.NotEmpty(t, loginRes.GetJwt(), "should return a jwt")
+	assert.Equal(t, regRes.GetUser().GetId(), loginRes.GetUser().GetId())
+	assert.Len(t, header.Get(grpcapi.TraceIdHeader), 1, "should send the trace id in the header")
+	assert.Nil(t, errProfileRes)
+	assert.Equal(t, regRes.GetUser().GetId(), profileRes.GetUser().GetId(), "should return the user of the jwt")
+}
+
+func TestIntegrationGrpcErrorsShouldBeMappedToStatusCodes(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	authClient := authv1.NewAuthServiceClient(grpcConn)
+	userClient := userv1.NewUserServiceClient(grpcConn)
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	_, err := userClient.RegisterUser(context.Background(), &userv1.RegisterUserRequest{Email: email, Password: "Password123!"})
+	assert.Nil(t, err)
+
+	// ACT
+	_, errInvalidRes := authClient.Login(context.Background(), &authv1.LoginRequest{})
+	_, errCredentialsRes := authClient.Login(context.Background(), &authv1.LoginRequest{Email: email, Password: "wrong"})
+	_, errExistsRes := userClient.RegisterUser(context.Background(), &userv1.RegisterUserRequest{Email: email, Password: "Password123!"})
+	_, errNoJwtRes := userClient.GetProfile(context.Background(), &userv1.GetProfileRequest{})
+
+	// ASSERT
+	assert.Equal(t, codes.InvalidArgument, status.Code(errInvalidRes))
+	assert.Equal(t, "REQUEST_DATA.INVALID", getErrorInfo(errInvalidRes).GetReason())
+	assert.Equal(t, map[string]string{
+		"email":    "validation failed for tag: 'required'",
+		"password": "validation failed for tag: 'required'",
+	}, getErrorInfo(errInvalidRes).GetMetadata(), "should name the invalid fields like the restapi")
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(errCredentialsRes))
+	assert.Equal(t, "invalid credentials", status.Convert(errCredentialsRes).Message())
+
+	assert.Equal(t, codes.AlreadyExists, status.Code(errExistsRes))
+	assert.Equal(t, "USER.ALREADY_EXISTS", getErrorInfo(errExistsRes).GetReason())
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(errNoJwtRes))
+	assert.Equal(t, "user not authenticated", status.Convert(errNoJwtRes).Message())
+}
+
+func TestIntegrationGrpcHealthCheck(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	healthClient := healthpb.NewHealthClient(grpcConn)
+
+	// ACT
+	serverRes, errServerRes := healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	userRes, errUserRes := healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{Service: userv1.UserService_ServiceDesc.ServiceName})
+	_, errUnknownRes := healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+
+	// ASSERT
+	assert.Nil(t, errServerRes)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, serverRes.GetStatus())
+	assert.Nil(t, errUserRes)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, userRes.GetStatus())
+	assert.Equal(t, codes.NotFound, status.Code(errUnknownRes))
+}
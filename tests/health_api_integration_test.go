@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/cmd/restapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationHealthShouldBeUp(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	// ACT
+	res, resBody := sendTestReq("GET", "/health", "", "")
+
+	// ASSERT
+	healthRes := restapi.HealthRes{}
+	err := json.Unmarshal(resBody, &healthRes)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "UP", healthRes.Status)
+	assert.Equal(t, "UP", healthRes.Database.Status)
+	assert.Equal(t, "UP", healthRes.Nats.Status)
+	assert.JSONEq(t, `{"status":"UP","database":{"status":"UP"},"nats":{"status":"UP"}}`, string(resBody), "should not describe the infrastructure")
+}
+
+func TestIntegrationHealthShouldBeDownWithoutNats(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+	natsService.Close()
+
+	// ACT
+	res, resBody := sendTestReq("GET", "/health", "", "")
+
+	// ASSERT
+	healthRes := restapi.HealthRes{}
+	err := json.Unmarshal(resBody, &healthRes)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, "DOWN", healthRes.Status)
+	assert.Equal(t, "UP", healthRes.Database.Status)
+	assert.Equal(t, "DOWN", healthRes.Nats.Status)
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestInvite(jwt string, reqBody string) model.CreateInviteApiRes {
+	url := fmt.Sprintf("%s/invites", testServer.URL)
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer([]byte(reqBody)))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+	resp, _ := http.DefaultClient.Do(req)
+
+	responseBodyByte, _ := io.ReadAll(resp.Body)
+	responseBody := model.CreateInviteApiRes{}
+	_ = json.Unmarshal(responseBodyByte, &responseBody)
+	return responseBody
+}
+
+func TestIntegrationCreateInviteWithoutToken(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	url := fmt.Sprintf("%s/invites", testServer.URL)
+
+	// ACT
+	resp, _ := http.Post(url, "application/json", bytes.NewBuffer([]byte(`{}`)))
+
+	// ASSERT
+	responseBody, _ := io.ReadAll(resp.Body)
+	expectedResponseBody := `{"type":"UNAUTHENTICATED","message":"user not authenticated","details":null}`
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "should return 401 status code")
+	assert.Equal(t, expectedResponseBody, string(responseBody), "should return error details in the response body")
+}
+
+func TestIntegrationCreateInviteSuccessfulResponse(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	loginRes := testutil.SetupTestUser(testServer.URL)
+	email := testutil.Fake.Internet().Email()
+
+	// ACT
+	res := createTestInvite(loginRes.Jwt, fmt.Sprintf(`{"email": "%s", "maxUses": 2, "expiresIn": "1d"}`, email))
+
+	// ASSERT
+	assert.NotEmpty(t, res.Invite.Code, "should return the invite code in the response body")
+	assert.Equal(t, email, *res.Invite.Email, "should return the invited email in the response body")
+	assert.Equal(t, 2, res.Invite.MaxUses, "should return max uses in the response body")
+	assert.NotNil(t, res.Invite.ExpiresAt, "should return the expiry in the response body")
+}
+
+func TestIntegrationRegisterUserWithExhaustedInvite(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	loginRes := testutil.SetupTestUser(testServer.URL)
+	invite := createTestInvite(loginRes.Jwt, `{"maxUses": 1}`)
+	url := fmt.Sprintf("%s/users/registration", testServer.URL)
+
+	firstReqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "Password123!","inviteCode": "%s"}`, testutil.Fake.Internet().Email(), invite.Invite.Code))
+	firstResp, _ := http.Post(url, "application/json", bytes.NewBuffer(firstReqBody))
+
+	// ACT
+	reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "Password123!","inviteCode": "%s"}`, testutil.Fake.Internet().Email(), invite.Invite.Code))
+	resp, _ := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+
+	// ASSERT
+	responseBody, _ := io.ReadAll(resp.Body)
+	expectedResponseBody := `{"type":"INVITE.EXHAUSTED","message":"invite has already been used","details":null}`
+	assert.Equal(t, http.StatusOK, firstResp.StatusCode, "first registration with the invite should succeed")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "should return 400 status code")
+	assert.Equal(t, expectedResponseBody, string(responseBody), "should return error details in the response body")
+}
+
+func TestIntegrationRegisterUserWithInviteConcurrently(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	maxUses := 3
+	totalRegistrations := 10
+	loginRes := testutil.SetupTestUser(testServer.URL)
+	invite := createTestInvite(loginRes.Jwt, fmt.Sprintf(`{"maxUses": %d}`, maxUses))
+	url := fmt.Sprintf("%s/users/registration", testServer.URL)
+
+	// ACT
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successCount := 0
+	for i := 0; i < totalRegistrations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "Password123!","inviteCode": "%s"}`, testutil.Fake.Internet().Email(), invite.Invite.Code))
+			resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+			if err == nil && resp.StatusCode == http.StatusOK {
+				mu.Lock()
+				successCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// ASSERT
+	_, savedInvite, _ := db.GetInviteByCode(context.Background(), invite.Invite.Code)
+	assert.Equal(t, maxUses, successCount, "only max uses registrations should succeed")
+	assert.Equal(t, maxUses, savedInvite.UsedCount, "invite usage should never exceed max uses")
+}
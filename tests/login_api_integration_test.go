@@ -2,11 +2,13 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,9 +70,7 @@ func TestIntegrationLoginWithIncorrectPassword(t *testing.T) {
 	password := testutil.Fake.Internet().Password()
 
 	// adding user with the email in the database with random password hash
-	smt, _ := testDbCon.Prepare("INSERT INTO users (id, email, password, first_name, last_name, created_at, updated_at) VALUE (?, ?, ?, ?, ?, ?, ?)")
-	smt.Exec(user.Id, user.Email, user.Password, user.FirstName, user.LastName, user.CreatedAt, user.UpdatedAt)
-	smt.Close()
+	_ = db.SaveUser(context.Background(), &user)
 
 	// ACT
 	reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password))
@@ -96,9 +96,7 @@ func TestIntegrationLoginSuccessfulResponse(t *testing.T) {
 	email := user.Email
 
 	// adding user with the email in the database with random password hash
-	smt, _ := testDbCon.Prepare("INSERT INTO users (id, email, password, first_name, last_name, created_at, updated_at) VALUE (?, ?, ?, ?, ?, ?, ?)")
-	smt.Exec(user.Id, user.Email, user.Password, user.FirstName, user.LastName, user.CreatedAt, user.UpdatedAt)
-	smt.Close()
+	_ = db.SaveUser(context.Background(), &user)
 
 	// ACT
 	reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password))
@@ -123,3 +121,20 @@ func TestIntegrationLoginSuccessfulResponse(t *testing.T) {
 	resp, _ = http.DefaultClient.Do(req)
 	assert.Equal(t, http.StatusOK, resp.StatusCode, "the returned jwt should be able to authenticate the user")
 }
+
+func TestIntegrationLoginWithMixedCaseEmail(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	password := "Password123!"
+	sendTestReq("POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password))
+
+	// ACT
+	loginRes := loginTestUser(strings.ToUpper(email[:1])+email[1:], password, "")
+
+	// ASSERT
+	assert.NotEmpty(t, loginRes.Jwt, "should log in whatever the case of the email")
+	assert.Equal(t, email, loginRes.User.Email, "should return the email saved during the registration")
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+)
+
+// createAdminTestStream creates a stream of its own with a consumer for the test, so that purging it does not affect
+// the other tests, and returns its name and the prefix of its subjects
+func createAdminTestStream(t *testing.T) (string, string) {
+	suffix := strings.ToUpper(strings.ReplaceAll(testutil.Fake.UUID().V4(), "-", ""))
+	stream := "ADMIN_TEST_" + suffix
+	prefix := "ADMIN_TEST_" + suffix
+
+	nc, err := natsgo.Connect(appConfig.NATS_URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsStream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{Name: stream, Subjects: []string{prefix + ".>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jsStream.CreateConsumer(context.Background(), jetstream.ConsumerConfig{Durable: "admin_test", AckPolicy: jetstream.AckExplicitPolicy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return stream, prefix
+}
+
+func deleteAdminTestStream(t *testing.T, stream string) {
+	nc, err := natsgo.Connect(appConfig.NATS_URL)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+
+	err = js.DeleteStream(context.Background(), stream)
+	if err != nil {
+		t.Log(err)
+	}
+}
+
+func TestIntegrationStreamAdminShouldListReplayAndPurge(t *testing.T) {
+	skipWithMemoryNats(t)
+
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	stream, prefix := createAdminTestStream(t)
+	defer deleteAdminTestStream(t, stream)
+
+	// messages 1, 3 and 5 are published to "a", 2 and 4 to "b"
+	for i := 1; i <= 5; i++ {
+		subject := prefix + ".a"
+		if i%2 == 0 {
+			subject = prefix + ".b"
+		}
+		err := natsService.PublishCtx(context.Background(), subject, []byte(fmt.Sprint(i)))
+		assert.Nil(t, err)
+	}
+
+	replayedData := []string{}
+	handle := func(ctx context.Context, msg nats.Msg) error {
+		replayedData = append(replayedData, string(msg.Data))
+		return nil
+	}
+
+	// ACT
+	streamsRes, errStreamsRes := natsService.ListStreams(context.Background())
+	consumersRes, errConsumersRes := natsService.ListConsumers(context.Background(), stream)
+	replayedRes, errReplayRes := natsService.Replay(context.Background(), stream, nats.ReplayRange{Subject: prefix + ".a", FromSeq: 2, ToSeq: 4}, handle)
+	errPurgeRes := natsService.PurgeSubject(context.Background(), stream, prefix+".a")
+
+	// ASSERT
+	assert.Nil(t, errStreamsRes)
+	var streamRes *nats.StreamInfo
+	for i := range streamsRes {
+		if streamsRes[i].Name == stream {
+			streamRes = &streamsRes[i]
+		}
+	}
+	if assert.NotNil(t, streamRes, "should list the stream") {
+		assert.Equal(t, uint64(5), streamRes.Msgs)
+		assert.Equal(t, uint64(5), streamRes.LastSeq)
+		assert.Equal(t, 1, streamRes.Consumers)
+	}
+
+	assert.Nil(t, errConsumersRes)
+	if assert.Len(t, consumersRes, 1) {
+		assert.Equal(t, "admin_test", consumersRes[0].Name)
+		assert.Equal(t, uint64(5), consumersRes[0].NumPending, "the lag should be every message of the stream")
+	}
+
+	assert.Nil(t, errReplayRes)
+	assert.Equal(t, 1, replayedRes)
+	assert.Equal(t, []string{"3"}, replayedData, "should only replay the messages of the subject within the range")
+
+	assert.Nil(t, errPurgeRes)
+	assert.Equal(t, uint64(2), getStreamMsgs(t, stream), "should only keep the messages of the other subject")
+}
+
+func TestIntegrationReplayShouldReplayWholeStream(t *testing.T) {
+	skipWithMemoryNats(t)
+
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	stream, prefix := createAdminTestStream(t)
+	defer deleteAdminTestStream(t, stream)
+
+	for i := 1; i <= 250; i++ {
+		err := natsService.PublishCtx(context.Background(), prefix+".a", []byte(fmt.Sprint(i)))
+		assert.Nil(t, err)
+	}
+
+	replayedData := []string{}
+	handle := func(ctx context.Context, msg nats.Msg) error {
+		replayedData = append(replayedData, string(msg.Data))
+		return nil
+	}
+
+	// ACT
+	replayedRes, errRes := natsService.Replay(context.Background(), stream, nats.ReplayRange{}, handle)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Equal(t, 250, replayedRes, "should replay every batch")
+	if assert.Len(t, replayedData, 250) {
+		assert.Equal(t, "1", replayedData[0])
+		assert.Equal(t, "250", replayedData[249])
+	}
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/event"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+)
+
+// deleteTestConsumer removes the durable consumer created by a test from the stream
+func deleteTestConsumer(t *testing.T, durable string) {
+	nc, err := natsgo.Connect(appConfig.NATS_URL)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+
+	err = js.DeleteConsumer(context.Background(), appConfig.NATS_STREAM, durable)
+	if err != nil {
+		t.Log(err)
+	}
+}
+
+func TestIntegrationRegistrationEventShouldReachHandler(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	durable := "integration_" + strings.ReplaceAll(testutil.Fake.UUID().V4(), "-", "")
+	received := make(chan eventtype.UserRegisteredData, 100)
+
+	registry := nats.NewRegistry()
+	err := registry.Register(nats.Handler{
+		Subject: appConfig.NATS_EVENT_USER_REGISTRATION,
+		Durable: durable,
+		Handle: event.Handler(eventRegistry, func(ctx context.Context, envelope event.Envelope, data eventtype.UserRegisteredData) error {
+			received <- data
+			return nil
+		}),
+	})
+	assert.Nil(t, err)
+
+	err = natsService.Subscribe(appConfig.NATS_STREAM, registry)
+	assert.Nil(t, err)
+	defer deleteTestConsumer(t, durable)
+
+	// ACT
+	sendTestReq("POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "Password123!"}`, email))
+	publishedRes, errRelayRes := outboxRelay.RelayPending(context.Background())
+
+	// ASSERT
+	assert.Nil(t, errRelayRes)
+	assert.Equal(t, 1, publishedRes, "should publish the registration event of the outbox")
+
+	// the durable starts from the beginning of the stream, so events of previous runs are skipped
+	timeout := time.After(10 * time.Second)
+	for isReceived := false; !isReceived; {
+		select {
+		case data := <-received:
+			isReceived = data.Email == email
+		case <-timeout:
+			t.Fatal("the handler should receive the registration event")
+		}
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, natsService.Drain(drainCtx))
+}
+
+func TestIntegrationFailingMessageShouldBeDeadLetteredAndReplayed(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	durable := "integration_" + strings.ReplaceAll(testutil.Fake.UUID().V4(), "-", "")
+	received := make(chan eventtype.UserRegisteredData, 100)
+	isFailing := make(chan bool, 1)
+	isFailing <- true
+
+	registry := nats.NewRegistry()
+	err := registry.Register(nats.Handler{
+		Subject:    appConfig.NATS_EVENT_USER_REGISTRATION,
+		Durable:    durable,
+		MaxDeliver: 1,
+		Handle: event.Handler(eventRegistry, func(ctx context.Context, envelope event.Envelope, data eventtype.UserRegisteredData) error {
+			if data.Email != email {
+				return nil
+			}
+
+			// the event fails the first time only
+			select {
+			case <-isFailing:
+				return fmt.Errorf("error from handler")
+			default:
+				received <- data
+				return nil
+			}
+		}),
+	})
+	assert.Nil(t, err)
+
+	err = natsService.Subscribe(appConfig.NATS_STREAM, registry)
+	assert.Nil(t, err)
+	defer deleteTestConsumer(t, durable)
+
+	// ACT
+	sendTestReq("POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "Password123!"}`, email))
+	_, err = outboxRelay.RelayPending(context.Background())
+	assert.Nil(t, err)
+
+	var deadLetterRes *nats.DeadLetter
+	for attempt := 0; attempt < 100 && deadLetterRes == nil; attempt++ {
+		time.Sleep(50 * time.Millisecond)
+		deadLetters, err := natsService.ListDeadLetters(context.Background(), 10000)
+		assert.Nil(t, err)
+		for _, deadLetter := range deadLetters {
+			if deadLetter.Durable == durable {
+				found := deadLetter
+				deadLetterRes = &found
+			}
+		}
+	}
+
+	// ASSERT
+	if !assert.NotNil(t, deadLetterRes, "should move the failed message to the dead letter stream") {
+		return
+	}
+	assert.Equal(t, appConfig.NATS_EVENT_USER_REGISTRATION, deadLetterRes.Subject)
+	assert.Contains(t, deadLetterRes.Error, "error from handler")
+	assert.Equal(t, uint64(1), deadLetterRes.NumDelivered)
+
+	err = natsService.ReplayDeadLetter(context.Background(), deadLetterRes.Sequence)
+	assert.Nil(t, err)
+
+	select {
+	case data := <-received:
+		assert.Equal(t, email, data.Email)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the handler should receive the replayed message")
+	}
+}
+
+func TestIntegrationRedeliveredEventShouldBeHandledOnce(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	durable := "integration_" + strings.ReplaceAll(testutil.Fake.UUID().V4(), "-", "")
+	received := make(chan eventtype.UserRegisteredData, 100)
+
+	registry := nats.NewRegistry()
+	err := registry.Register(nats.Handler{
+		Subject: appConfig.NATS_EVENT_USER_REGISTRATION,
+		Durable: durable,
+		Handle: idempotencyService.Handler(durable, func(txDb database.Db) nats.HandlerFunc {
+			return event.Handler(eventRegistry, func(ctx context.Context, envelope event.Envelope, data eventtype.UserRegisteredData) error {
+				received <- data
+				return nil
+			})
+		}),
+	})
+	assert.Nil(t, err)
+
+	err = natsService.Subscribe(appConfig.NATS_STREAM, registry)
+	assert.Nil(t, err)
+	defer deleteTestConsumer(t, durable)
+
+	envelope, err := eventRegistry.New(context.Background(), eventtype.UserRegistered, eventtype.UserRegisteredData{Id: testutil.Fake.UUID().V4(), Email: email})
+	assert.Nil(t, err)
+	payload, err := json.Marshal(envelope)
+	assert.Nil(t, err)
+
+	// without its Nats-Msg-Id the stream stores the event twice, like a redelivery
+	header := envelope.Headers()
+	header.Del(jetstream.MsgIDHeader)
+
+	// ACT
+	errRes := natsService.PublishToStream(context.Background(), appConfig.NATS_EVENT_USER_REGISTRATION, payload, header)
+	errAgainRes := natsService.PublishToStream(context.Background(), appConfig.NATS_EVENT_USER_REGISTRATION, payload, header)
+
+	// ASSERT
+	assert.Nil(t, errRes)
+	assert.Nil(t, errAgainRes)
+
+	handled := 0
+	timeout := time.After(3 * time.Second)
+	for isTimedOut := false; !isTimedOut; {
+		select {
+		case data := <-received:
+			if data.Email == email {
+				handled++
+			}
+		case <-timeout:
+			isTimedOut = true
+		}
+	}
+	assert.Equal(t, 1, handled, "should handle the event once")
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, natsService.Drain(drainCtx))
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+)
+
+// getStreamMsgs returns the number of messages stored in the stream
+func getStreamMsgs(t *testing.T, stream string) uint64 {
+	nc, err := natsgo.Connect(appConfig.NATS_URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := js.Stream(context.Background(), stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamInfo, err := info.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return streamInfo.State.Msgs
+}
+
+func TestIntegrationPublishBatchShouldStoreDuplicatesOnce(t *testing.T) {
+	skipWithMemoryNats(t)
+
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	err := natsService.Subscribe(appConfig.NATS_STREAM, nats.NewRegistry())
+	assert.Nil(t, err)
+
+	envelope, err := eventRegistry.New(context.Background(), eventtype.UserRegistered, eventtype.UserRegisteredData{
+		Id:    testutil.Fake.UUID().V4(),
+		Email: testutil.Fake.Internet().Email(),
+	})
+	assert.Nil(t, err)
+	payload, err := json.Marshal(envelope)
+	assert.Nil(t, err)
+
+	// the Nats-Msg-Id header of the envelope is its id
+	msg := nats.Msg{Subject: appConfig.NATS_EVENT_USER_REGISTRATION, Data: payload, Headers: envelope.Headers()}
+	msgsBefore := getStreamMsgs(t, appConfig.NATS_STREAM)
+
+	// ACT
+	errsRes := natsService.PublishBatch(context.Background(), []nats.Msg{msg, msg})
+	errRes := natsService.PublishToStream(context.Background(), msg.Subject, msg.Data, msg.Headers)
+
+	// ASSERT
+	assert.Equal(t, []error{nil, nil}, errsRes)
+	assert.Nil(t, errRes)
+	assert.Equal(t, msgsBefore+1, getStreamMsgs(t, appConfig.NATS_STREAM), "should store the message once")
+}
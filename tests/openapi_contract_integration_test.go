@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestOpenApiDocument(t *testing.T) *openapi.Document {
+	resp, err := http.Get(fmt.Sprintf("%s/openapi.json", testServer.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(resp.Body)
+	document := &openapi.Document{}
+	err = json.Unmarshal(responseBody, document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func TestIntegrationOpenApiDocumentShouldBeServed(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	// ACT
+	document := getTestOpenApiDocument(t)
+	docsResp, docsResponseBody := sendTestReq("GET", "/docs", "", "")
+	bundleResp, _ := sendTestReq("GET", "/docs/swagger-ui-bundle.js", "", "")
+	initializerResp, initializerResponseBody := sendTestReq("GET", "/docs/swagger-initializer.js", "", "")
+	unknownAssetResp, _ := sendTestReq("GET", "/docs/index.html", "", "")
+
+	// ASSERT
+	assert.Equal(t, openapi.Version, document.OpenApi, "should be an OpenAPI 3 document")
+	assert.Contains(t, document.Paths, "/auth/login", "should document the login route")
+	assert.Contains(t, document.Paths, "/users/registration", "should document the registration route")
+	assert.Contains(t, document.Paths, "/users/profile", "should document the profile route")
+	assert.Empty(t, document.Paths["/auth/login"]["post"].Security, "login should be public")
+	assert.NotEmpty(t, document.Paths["/users/profile"]["get"].Security, "profile should require the jwt")
+	assert.Equal(t, http.StatusOK, docsResp.StatusCode, "should serve the docs UI")
+	assert.Contains(t, docsResp.Header.Get("Content-Security-Policy"), "script-src 'self'", "docs UI should only run its own scripts")
+	assert.NotContains(t, string(docsResponseBody), "https://", "docs UI should not load third party assets")
+	assert.Equal(t, http.StatusOK, bundleResp.StatusCode, "should serve the embedded Swagger UI assets")
+	assert.Equal(t, http.StatusOK, initializerResp.StatusCode, "should serve the initializer of the docs UI")
+	assert.Contains(t, string(initializerResponseBody), "/openapi.json", "docs UI should render the document")
+	assert.Equal(t, http.StatusNotFound, unknownAssetResp.StatusCode, "should only serve the assets of the docs UI")
+}
+
+// TestIntegrationResponsesShouldMatchOpenApiDocument sends requests to the documented routes and checks that the
+// status codes and bodies of the responses are documented, and that the document accepts the requests the API accepts
+func TestIntegrationResponsesShouldMatchOpenApiDocument(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	document := getTestOpenApiDocument(t)
+	loginRes := testutil.SetupTestUser(testServer.URL)
+	email := loginRes.User.Email
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		jwt            string
+		reqBody        string
+		wantStatusCode int
+		// wantValidReq is false when the request breaks the schema, requests can also be rejected by the services
+		wantValidReq bool
+	}{
+		{"login", "POST", "/auth/login", "", fmt.Sprintf(`{"email": "%s","password": "Password123!"}`, email), http.StatusOK, true},
+		{"login with missing fields", "POST", "/auth/login", "", `{"email": "","password": ""}`, http.StatusUnprocessableEntity, false},
+		{"login with invalid email", "POST", "/auth/login", "", `{"email": "not-an-email","password": "Password123!"}`, http.StatusUnprocessableEntity, false},
+		{"login with malformed body", "POST", "/auth/login", "", `{"email":`, http.StatusUnprocessableEntity, false},
+		{"login with incorrect password", "POST", "/auth/login", "", fmt.Sprintf(`{"email": "%s","password": "Incorrect123!"}`, email), http.StatusUnauthorized, true},
+		{"login with unregistered email", "POST", "/auth/login", "", fmt.Sprintf(`{"email": "%s","password": "Password123!"}`, testutil.Fake.Internet().Email()), http.StatusUnauthorized, true},
+		{"login into organization without membership", "POST", "/auth/login", "", fmt.Sprintf(`{"email": "%s","password": "Password123!","orgId": "%s"}`, email, testutil.Fake.UUID().V4()), http.StatusForbidden, true},
+		{"registration", "POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "Password123!"}`, testutil.Fake.Internet().Email()), http.StatusOK, true},
+		{"registration with missing fields", "POST", "/users/registration", "", `{}`, http.StatusUnprocessableEntity, false},
+		{"registration with weak password", "POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "password"}`, testutil.Fake.Internet().Email()), http.StatusUnprocessableEntity, true},
+		{"registration with used email", "POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "Password123!"}`, email), http.StatusBadRequest, true},
+		{"registration with unknown invite", "POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "Password123!","inviteCode": "unknown"}`, testutil.Fake.Internet().Email()), http.StatusBadRequest, true},
+		{"profile", "GET", "/users/profile", loginRes.Jwt, "", http.StatusOK, true},
+		{"profile without token", "GET", "/users/profile", "", "", http.StatusUnauthorized, true},
+		{"profile with invalid token", "GET", "/users/profile", "invalid", "", http.StatusUnauthorized, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// ACT
+			resp, responseBody := sendTestReq(testCase.method, testCase.path, testCase.jwt, testCase.reqBody)
+
+			// ASSERT
+			reqBytes := []byte(testCase.reqBody)
+			if testCase.method == "GET" {
+				reqBytes = []byte(`{}`)
+			}
+			errReq := document.ValidateRequest(testCase.method, testCase.path, reqBytes)
+			assert.Equal(t, testCase.wantStatusCode, resp.StatusCode, "should return the expected status code")
+			assert.Nil(t, document.ValidateResponse(testCase.method, testCase.path, resp.StatusCode, responseBody), "response should match the document")
+			if testCase.wantValidReq {
+				assert.Nil(t, errReq, "document should accept the request")
+			} else {
+				assert.NotNil(t, errReq, "document should reject the request")
+			}
+		})
+	}
+}
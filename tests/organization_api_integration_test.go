@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendTestReq(method string, path string, jwt string, reqBody string) (*http.Response, []byte) {
+	url := fmt.Sprintf("%s%s", testServer.URL, path)
+	req, _ := http.NewRequest(method, url, bytes.NewBuffer([]byte(reqBody)))
+	if jwt != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+	}
+	resp, _ := http.DefaultClient.Do(req)
+	responseBody, _ := io.ReadAll(resp.Body)
+	return resp, responseBody
+}
+
+func registerAndLoginTestUser(email string, orgId string) model.LoginApiRes {
+	password := "Password123!"
+	sendTestReq("POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password))
+	return loginTestUser(email, password, orgId)
+}
+
+func loginTestUser(email string, password string, orgId string) model.LoginApiRes {
+	reqBody := fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password)
+	if orgId != "" {
+		reqBody = fmt.Sprintf(`{"email": "%s","password": "%s","orgId": "%s"}`, email, password, orgId)
+	}
+	_, responseBody := sendTestReq("POST", "/auth/login", "", reqBody)
+	loginRes := model.LoginApiRes{}
+	_ = json.Unmarshal(responseBody, &loginRes)
+	return loginRes
+}
+
+func TestIntegrationCreateOrganizationSuccessfulResponse(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	loginRes := testutil.SetupTestUser(testServer.URL)
+
+	// ACT
+	resp, responseBody := sendTestReq("POST", "/organizations", loginRes.Jwt, `{"name": "Acme"}`)
+
+	// ASSERT
+	createOrgRes := model.CreateOrgApiRes{}
+	_ = json.Unmarshal(responseBody, &createOrgRes)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return 200 status code")
+	assert.Equal(t, "Acme", createOrgRes.Organization.Name, "should return organization name in the response body")
+	assert.Equal(t, model.OrgRoleOwner, createOrgRes.Organization.Role, "creator should be the owner of the organization")
+}
+
+func TestIntegrationOrgScopedRouteWithoutSelectedOrg(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	loginRes := testutil.SetupTestUser(testServer.URL)
+
+	// ACT
+	resp, responseBody := sendTestReq("GET", "/organizations/current/members", loginRes.Jwt, "")
+
+	// ASSERT
+	expectedResponseBody := `{"type":"ORGANIZATION.NOT_SELECTED","message":"no organization selected, login with an organization first","details":null}`
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "should return 400 status code")
+	assert.Equal(t, expectedResponseBody, string(responseBody), "should return error details in the response body")
+}
+
+func TestIntegrationLoginIntoOrgWithoutMembership(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ownerLoginRes := testutil.SetupTestUser(testServer.URL)
+	_, responseBody := sendTestReq("POST", "/organizations", ownerLoginRes.Jwt, `{"name": "Acme"}`)
+	createOrgRes := model.CreateOrgApiRes{}
+	_ = json.Unmarshal(responseBody, &createOrgRes)
+	email := testutil.Fake.Internet().Email()
+	password := "Password123!"
+	sendTestReq("POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password))
+
+	// ACT
+	reqBody := fmt.Sprintf(`{"email": "%s","password": "%s","orgId": "%s"}`, email, password, createOrgRes.Organization.Id)
+	resp, responseBody := sendTestReq("POST", "/auth/login", "", reqBody)
+
+	// ASSERT
+	expectedResponseBody := `{"type":"ORGANIZATION.NOT_MEMBER","message":"user is not a member of the organization","details":null}`
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "should return 403 status code")
+	assert.Equal(t, expectedResponseBody, string(responseBody), "should return error details in the response body")
+}
+
+func TestIntegrationInviteAndAcceptOrgMember(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ownerEmail := testutil.Fake.Internet().Email()
+	ownerLoginRes := registerAndLoginTestUser(ownerEmail, "")
+	_, responseBody := sendTestReq("POST", "/organizations", ownerLoginRes.Jwt, `{"name": "Acme"}`)
+	createOrgRes := model.CreateOrgApiRes{}
+	_ = json.Unmarshal(responseBody, &createOrgRes)
+	orgId := createOrgRes.Organization.Id
+	ownerOrgLoginRes := loginTestUser(ownerEmail, "Password123!", orgId)
+
+	memberEmail := testutil.Fake.Internet().Email()
+	memberLoginRes := registerAndLoginTestUser(memberEmail, "")
+
+	// ACT
+	_, responseBody = sendTestReq("POST", "/organizations/current/invitations", ownerOrgLoginRes.Jwt, fmt.Sprintf(`{"email": "%s","role": "admin"}`, memberEmail))
+	inviteRes := model.InviteOrgMemberApiRes{}
+	_ = json.Unmarshal(responseBody, &inviteRes)
+	acceptResp, _ := sendTestReq("POST", "/organizations/invitations/accept", memberLoginRes.Jwt, fmt.Sprintf(`{"code": "%s"}`, inviteRes.Invite.Code))
+
+	// ASSERT
+	assert.Equal(t, http.StatusOK, acceptResp.StatusCode, "invited user should be able to accept the invite")
+
+	memberOrgLoginRes := loginTestUser(memberEmail, "Password123!", orgId)
+	resp, responseBody := sendTestReq("GET", "/organizations/current/members", memberOrgLoginRes.Jwt, "")
+	membersRes := model.ListOrgMembersApiRes{}
+	_ = json.Unmarshal(responseBody, &membersRes)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return 200 status code")
+	assert.Len(t, membersRes.Members, 2, "organization should have the owner and the new member")
+
+	roles := map[string]string{}
+	for _, member := range membersRes.Members {
+		roles[member.Email] = member.Role
+	}
+	assert.Equal(t, model.OrgRoleOwner, roles[ownerEmail], "owner should keep the owner role")
+	assert.Equal(t, model.OrgRoleAdmin, roles[memberEmail], "invited user should get the invited role")
+}
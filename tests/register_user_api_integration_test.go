@@ -2,15 +2,21 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
 	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/nats"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -65,9 +71,7 @@ func TestIntegrationRegisterUserWithUsedEmail(t *testing.T) {
 	password := "Password123!"
 
 	// adding user with the email in the database
-	smt, _ := testDbCon.Prepare("INSERT INTO users (id, email, password, first_name, last_name, created_at, updated_at) VALUE (?, ?, ?, ?, ?, ?, ?)")
-	smt.Exec(user.Id, user.Email, user.Password, user.FirstName, user.LastName, user.CreatedAt, user.UpdatedAt)
-	smt.Close()
+	_ = db.SaveUser(context.Background(), &user)
 
 	// ACT
 	reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password))
@@ -104,10 +108,124 @@ func TestIntegrationRegisterUserSuccessfulRegistration(t *testing.T) {
 	assert.NotNil(t, responseBody.User.Id, "should return user id in the response body")
 	assert.WithinDuration(t, time.Now(), userCreatedAtRes, time.Second, "should return user creation date in the response body")
 
-	count := 0
-	res, _ := testDbCon.Query(fmt.Sprintf("SELECT COUNT(*) FROM users WHERE email=\"%s\"", email))
-	if res.Next() {
-		res.Scan(&count)
+	exists, _, _ := db.GetUserByEmail(context.Background(), email)
+	assert.True(t, exists, "there should be a user with the email in the database")
+}
+
+func TestIntegrationRegisterUserConcurrentlyWithSameEmail(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	url := fmt.Sprintf("%s/users/registration", testServer.URL)
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	password := "Password123!"
+	concurrentReqs := 10
+
+	// ACT
+	var wg sync.WaitGroup
+	statusCodes := make([]int, concurrentReqs)
+	responseBodies := make([]string, concurrentReqs)
+	for i := 0; i < concurrentReqs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// every other request uses another case of the same email
+			reqEmail := email
+			if i%2 == 1 {
+				reqEmail = strings.ToUpper(email)
+			}
+
+			reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "%s"}`, reqEmail, password))
+			resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+
+			responseBody, _ := io.ReadAll(resp.Body)
+			statusCodes[i] = resp.StatusCode
+			responseBodies[i] = string(responseBody)
+		}(i)
 	}
-	assert.Equal(t, 1, count, "there should be a user with the email in the database")
+	wg.Wait()
+
+	// ASSERT
+	successfulRegistrations := 0
+	expectedResponseBody := fmt.Sprintf(`{"type":"USER.ALREADY_EXISTS","message":"user with the email '%s' already exists","details":null}`, email)
+	for i, statusCode := range statusCodes {
+		if statusCode == http.StatusOK {
+			successfulRegistrations++
+			continue
+		}
+
+		assert.Equal(t, http.StatusBadRequest, statusCode, "failed registrations should return 400 status code")
+		assert.Equal(t, expectedResponseBody, responseBodies[i], "failed registrations should return the error details in the response body")
+	}
+	assert.Equal(t, 1, successfulRegistrations, "only one registration should succeed")
+
+	var usersWithEmail int
+	err := testDbCon.QueryRow(database.Rebind(database.GetDriver(appConfig), "SELECT COUNT(*) FROM users WHERE LOWER(email) = ?;"), email).Scan(&usersWithEmail)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, usersWithEmail, "there should be a single user with the email in the database")
+}
+
+func TestIntegrationRegisterUserShouldEnqueueRegistrationEvent(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	url := fmt.Sprintf("%s/users/registration", testServer.URL)
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	password := "Password123!"
+
+	// ACT
+	reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "%s"}`, email, password))
+	resp, _ := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+
+	// ASSERT
+	events, err := db.GetPendingOutboxEvents(context.Background(), time.Now().Add(time.Second), 10)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return 200 status code")
+	assert.Nil(t, err)
+	if assert.Len(t, events, 1, "should save the event in the outbox with the user") {
+		assert.Equal(t, appConfig.NATS_EVENT_USER_REGISTRATION, events[0].Topic)
+		assert.Contains(t, string(events[0].Payload), email)
+	}
+}
+
+func TestIntegrationRegisterUserShouldPublishRegistrationEvent(t *testing.T) {
+	skipWithMemoryNats(t)
+
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	err := natsService.Subscribe(appConfig.NATS_STREAM, nats.NewRegistry())
+	assert.Nil(t, err)
+
+	recorder, err := testutil.NewNatsRecorder(appConfig.NATS_URL, appConfig.NATS_EVENT_USER_REGISTRATION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Close()
+
+	url := fmt.Sprintf("%s/users/registration", testServer.URL)
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+
+	// ACT
+	reqBody := []byte(fmt.Sprintf(`{"email": "%s","password": "Password123!"}`, email))
+	resp, _ := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+	_, errRelayRes := outboxRelay.RelayPending(context.Background())
+
+	// ASSERT
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return 200 status code")
+	assert.Nil(t, errRelayRes)
+	data, _ := testutil.AssertEventPublished(t, recorder, appConfig.NATS_EVENT_USER_REGISTRATION, eventtype.UserRegistered, 5*time.Second, func(data eventtype.UserRegisteredData) bool {
+		return data.Email == email
+	})
+	assert.NotEmpty(t, data.Id, "should publish the id of the user")
+	assert.Len(t, recorder.Msgs(appConfig.NATS_EVENT_USER_REGISTRATION), 1, "should publish the event once")
 }
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+// the repository tests run the Db implementation selected by DB_DRIVER against a real database, the CI runs them for
+// every supported driver so that all backends behave the same way
+
+func TestIntegrationRepositoryUsers(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	user := testutil.GenMockUser(nil)
+
+	// ACT
+	saveErr := db.SaveUser(ctx, &user)
+	isTaken, isTakenErr := db.IsUserEmailTaken(ctx, user.Email)
+	existsByEmail, userByEmail, byEmailErr := db.GetUserByEmail(ctx, user.Email)
+	existsById, userById, byIdErr := db.GetUserById(ctx, user.Id)
+	isOtherTaken, _ := db.IsUserEmailTaken(ctx, "other."+user.Email)
+
+	// ASSERT
+	assert.Nil(t, saveErr)
+	assert.Nil(t, isTakenErr)
+	assert.Nil(t, byEmailErr)
+	assert.Nil(t, byIdErr)
+	assert.True(t, isTaken, "email should be taken")
+	assert.False(t, isOtherTaken, "other emails should not be taken")
+	assert.True(t, existsByEmail)
+	assert.True(t, existsById)
+	assert.Equal(t, user.Id, userByEmail.Id)
+	assert.Equal(t, user.Email, userById.Email)
+	assert.Equal(t, *user.Password, *userById.Password)
+	assert.WithinDuration(t, user.CreatedAt, userById.CreatedAt, time.Second)
+}
+
+func TestIntegrationRepositoryUserNotFound(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+
+	// ACT
+	exists, _, err := db.GetUserById(ctx, testutil.Fake.UUID().V4())
+
+	// ASSERT
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestIntegrationRepositoryDuplicateKeyReturnsAlreadyExists(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	user := testutil.GenMockUser(nil)
+	_ = db.SaveUser(ctx, &user)
+
+	// ACT
+	err := db.SaveUser(ctx, &user)
+
+	// ASSERT
+	assert.IsType(t, exception.AlreadyExists{}, err, "unique violations should be returned as AlreadyExists")
+}
+
+func TestIntegrationRepositoryInvites(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	invite := model.Invite{
+		Id:        testutil.Fake.UUID().V4(),
+		Code:      testutil.Fake.RandomStringWithLength(32),
+		CreatedBy: testutil.Fake.UUID().V4(),
+		MaxUses:   1,
+		CreatedAt: now,
+	}
+
+	// ACT
+	saveErr := db.SaveInvite(ctx, &invite)
+	firstUse, firstUseErr := db.IncrementInviteUsage(ctx, invite.Id, now)
+	secondUse, secondUseErr := db.IncrementInviteUsage(ctx, invite.Id, now)
+	exists, savedInvite, getErr := db.GetInviteByCode(ctx, invite.Code)
+
+	// ASSERT
+	assert.Nil(t, saveErr)
+	assert.Nil(t, firstUseErr)
+	assert.Nil(t, secondUseErr)
+	assert.Nil(t, getErr)
+	assert.True(t, firstUse, "first use should be allowed")
+	assert.False(t, secondUse, "uses should not exceed max uses")
+	assert.True(t, exists)
+	assert.Equal(t, 1, savedInvite.UsedCount)
+	assert.Nil(t, savedInvite.Email)
+	assert.Nil(t, savedInvite.ExpiresAt)
+}
+
+func TestIntegrationRepositoryOrganizations(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	user := testutil.GenMockUser(nil)
+	org := model.Organization{Id: testutil.Fake.UUID().V4(), Name: "Acme", CreatedBy: user.Id, CreatedAt: now}
+	membership := model.Membership{OrgId: org.Id, UserId: user.Id, Role: model.OrgRoleOwner, CreatedAt: now}
+	_ = db.SaveUser(ctx, &user)
+
+	// ACT
+	saveOrgErr := db.SaveOrganization(ctx, &org)
+	saveMembershipErr := db.SaveMembership(ctx, &membership)
+	orgExists, savedOrg, _ := db.GetOrganizationById(ctx, org.Id)
+	isMember, savedMembership, _ := db.GetMembership(ctx, org.Id, user.Id)
+	members, membersErr := db.GetOrgMembers(ctx, org.Id)
+	userOrgs, userOrgsErr := db.GetUserOrganizations(ctx, user.Id)
+
+	// ASSERT
+	assert.Nil(t, saveOrgErr)
+	assert.Nil(t, saveMembershipErr)
+	assert.Nil(t, membersErr)
+	assert.Nil(t, userOrgsErr)
+	assert.True(t, orgExists)
+	assert.Equal(t, org.Name, savedOrg.Name)
+	assert.True(t, isMember)
+	assert.Equal(t, model.OrgRoleOwner, savedMembership.Role)
+	assert.Len(t, members, 1)
+	assert.Equal(t, user.Email, members[0].Email)
+	assert.Len(t, userOrgs, 1)
+	assert.Equal(t, org.Id, userOrgs[0].Id)
+	assert.Equal(t, model.OrgRoleOwner, userOrgs[0].Role)
+}
+
+func TestIntegrationRepositoryLoginEvents(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	userId := testutil.Fake.UUID().V4()
+	failureReason := model.LoginFailureInvalidPassword
+	events := []model.LoginEvent{
+		{Id: testutil.Fake.UUID().V4(), UserId: &userId, Email: "john@doe.com", Success: true, Ip: "10.0.0.1", UserAgent: "agent-a", CreatedAt: now.Add(-2 * time.Minute)},
+		{Id: testutil.Fake.UUID().V4(), UserId: &userId, Email: "john@doe.com", Success: false, FailureReason: &failureReason, Ip: "10.0.0.2", UserAgent: "agent-b", CreatedAt: now.Add(-time.Minute)},
+		{Id: testutil.Fake.UUID().V4(), UserId: &userId, Email: "john@doe.com", Success: true, Ip: "10.0.0.1", UserAgent: "agent-b", NewDevice: true, CreatedAt: now},
+	}
+	for _, event := range events {
+		_ = db.SaveLoginEvent(ctx, &event)
+	}
+
+	// ACT
+	total, countErr := db.CountLoginEvents(ctx, userId)
+	page, pageErr := db.GetLoginEvents(ctx, userId, 2, 1)
+	stats, statsErr := db.GetLoginHistoryStats(ctx, userId, "10.0.0.1", "agent-a")
+
+	// ASSERT
+	assert.Nil(t, countErr)
+	assert.Nil(t, pageErr)
+	assert.Nil(t, statsErr)
+	assert.Equal(t, 3, total)
+	assert.Len(t, page, 2)
+	assert.Equal(t, events[1].Id, page[0].Id, "events should be ordered from the latest")
+	assert.Equal(t, failureReason, *page[0].FailureReason)
+	assert.Equal(t, model.LoginHistoryStats{SuccessfulLogins: 2, FromSameIp: 2, FromSameDevice: 1}, stats)
+}
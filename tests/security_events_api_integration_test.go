@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationGetSecurityEventsWithoutToken(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	// ACT
+	resp, _ := sendTestReq("GET", "/users/me/security-events", "", "")
+
+	// ASSERT
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "should return 401 status code")
+}
+
+func TestIntegrationGetSecurityEventsWithInvalidLimit(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	loginRes := registerAndLoginTestUser("john.security@doe.com", "")
+
+	// ACT
+	resp, _ := sendTestReq("GET", "/users/me/security-events?limit=1000", loginRes.Jwt, "")
+
+	// ASSERT
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode, "should return 422 status code")
+}
+
+func TestIntegrationGetSecurityEventsSuccessfulResponse(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	email := "jane.security@doe.com"
+	registerAndLoginTestUser(email, "")
+	loginTestUser(email, "WrongPassword123!", "")
+	loginRes := loginTestUser(email, "Password123!", "")
+
+	// ACT
+	resp, responseBody := sendTestReq("GET", "/users/me/security-events?page=1&limit=2", loginRes.Jwt, "")
+	_, allEventsResponseBody := sendTestReq("GET", "/users/me/security-events", loginRes.Jwt, "")
+
+	// ASSERT
+	securityEventsRes := model.GetSecurityEventsApiRes{}
+	_ = json.Unmarshal(responseBody, &securityEventsRes)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return 200 status code")
+	assert.Equal(t, model.PaginationRes{Page: 1, Limit: 2, Total: 3}, securityEventsRes.Pagination, "should return pagination details")
+	assert.Len(t, securityEventsRes.Events, 2, "should return a single page of events")
+
+	allEventsRes := model.GetSecurityEventsApiRes{}
+	_ = json.Unmarshal(allEventsResponseBody, &allEventsRes)
+	failureReasons := []string{}
+	for _, event := range allEventsRes.Events {
+		assert.NotEmpty(t, event.Ip, "should record the client ip")
+		if !event.Success {
+			failureReasons = append(failureReasons, *event.FailureReason)
+		}
+	}
+	assert.Len(t, allEventsRes.Events, 3, "should record every login attempt")
+	assert.Equal(t, []string{model.LoginFailureInvalidPassword}, failureReasons, "should record the failure reason of failed attempts")
+}
+
+func TestIntegrationSecurityEventsShouldIgnoreUntrustedForwardedFor(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	email := "joe.security@doe.com"
+	registerAndLoginTestUser(email, "")
+	req, _ := http.NewRequest("POST", fmt.Sprintf("%s/auth/login", testServer.URL), strings.NewReader(fmt.Sprintf(`{"email": "%s","password": "WrongPassword123!"}`, email)))
+	req.Header.Set("X-Forwarded-For", strings.Repeat("1", 200))
+	resp, _ := http.DefaultClient.Do(req)
+	loginRes := loginTestUser(email, "Password123!", "")
+
+	// ACT
+	_, responseBody := sendTestReq("GET", "/users/me/security-events", loginRes.Jwt, "")
+
+	// ASSERT
+	securityEventsRes := model.GetSecurityEventsApiRes{}
+	_ = json.Unmarshal(responseBody, &securityEventsRes)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "should return 401 status code")
+	assert.Len(t, securityEventsRes.Events, 3, "should record the attempt with the spoofed header")
+	for _, event := range securityEventsRes.Events {
+		assert.Equal(t, "127.0.0.1", event.Ip, "should record the address of the connection without trusted proxies")
+	}
+}
@@ -1,31 +1,88 @@
 package tests
 
 import (
+	"context"
 	"log"
+	"net"
 	"net/http/httptest"
+	"os"
+	"testing"
 
 	"database/sql"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/pjmessi/golang-practice/cmd/grpcapi"
 	"github.com/pjmessi/golang-practice/cmd/restapi"
 	"github.com/pjmessi/golang-practice/config"
 	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/eventtype"
 	"github.com/pjmessi/golang-practice/internal/pkg/jwt"
+	"github.com/pjmessi/golang-practice/internal/pkg/migration"
 	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
 	"github.com/pjmessi/golang-practice/internal/service/auth"
+	"github.com/pjmessi/golang-practice/internal/service/idempotency"
+	"github.com/pjmessi/golang-practice/internal/service/invite"
+	"github.com/pjmessi/golang-practice/internal/service/organization"
+	"github.com/pjmessi/golang-practice/internal/service/outbox"
+	"github.com/pjmessi/golang-practice/internal/service/security"
 	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/internal/service/webhook"
+	"github.com/pjmessi/golang-practice/pkg/event"
 	"github.com/pjmessi/golang-practice/pkg/logger"
 	"github.com/pjmessi/golang-practice/pkg/nats"
 	"github.com/pjmessi/golang-practice/pkg/validation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 var testServer *httptest.Server
+var grpcServer *grpc.Server
+var grpcConn *grpc.ClientConn
 var db database.Db
 var appConfig *config.AppConfig
 var testDbCon *sql.DB
+var outboxRelay outbox.Relay
+var natsService nats.Service
+var eventRegistry *event.Registry
+var idempotencyService idempotency.Service
+var userService user.Service
+var webhookService webhook.Service
+var validationHandler validation.Handler
+
+// embeddedNatsServer is started for the whole run when NATS_EMBEDDED is "true", tests connect to it instead of NATS_URL
+var embeddedNatsServer *nats.EmbeddedServer
+
+func TestMain(m *testing.M) {
+	if config.GetAppConfig("test").NATS_EMBEDDED == "true" {
+		var err error
+		embeddedNatsServer, err = nats.StartEmbeddedServer(logger.NewService())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	code := m.Run()
+
+	if embeddedNatsServer != nil {
+		embeddedNatsServer.Shutdown()
+	}
+	os.Exit(code)
+}
+
+// skipWithMemoryNats skips the tests which reach the JetStream server directly, to create streams or record messages,
+// as there is none with the in process event bus
+func skipWithMemoryNats(t *testing.T) {
+	if config.GetAppConfig("test").NATS_DRIVER == "memory" {
+		t.Skip("needs a NATS server, NATS_DRIVER is memory")
+	}
+}
 
 func setupIntegrationTest() {
 	appConfig = config.GetAppConfig("test")
+	// the webhooks of the tests are delivered to local servers
+	appConfig.WEBHOOK_ALLOW_PRIVATE_NETWORKS = "true"
+	if embeddedNatsServer != nil {
+		appConfig.NATS_URL = embeddedNatsServer.Url()
+	}
 
 	var err error
 
@@ -41,7 +98,7 @@ func setupIntegrationTest() {
 
 	// initialize common services
 	logService := logger.NewService()
-	validationHandler, err := validation.NewHandler()
+	validationHandler, err = validation.NewHandler()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -51,36 +108,92 @@ func setupIntegrationTest() {
 	}
 
 	// initialize core services
-	userService := user.NewService(logService, db)
-	authService := auth.NewService(logService, jwtHandler, db)
-	natsService, err := nats.NewPubService(appConfig, logService)
+	natsService, err = nats.NewService(appConfig, logService)
+	if err != nil {
+		log.Fatal(err)
+	}
+	eventRegistry, err = eventtype.NewRegistry()
+	if err != nil {
+		log.Fatal(err)
+	}
+	outboxService := outbox.NewService(logService, db, eventRegistry)
+	// the relay is not started, tests relay the outbox themselves to be deterministic
+	outboxRelay, err = outbox.NewRelay(appConfig, logService, db, natsService)
 	if err != nil {
 		log.Fatal(err)
 	}
+	idempotencyService, err = idempotency.NewService(appConfig, logService, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	inviteService := invite.NewService(logService, db)
+	userService = user.NewService(appConfig, logService, db, inviteService, outboxService)
+	orgService := organization.NewService(logService, db, inviteService)
+	securityService := security.NewService(appConfig, logService, db, outboxService)
+	authService := auth.NewService(logService, jwtHandler, db, securityService)
+	webhookService = webhook.NewService(appConfig, logService, db, eventRegistry)
 
 	// initialize facades
-	userFacade := user.NewFacade(appConfig, logService, userService, validationHandler, natsService)
+	userFacade := user.NewFacade(logService, userService, validationHandler)
 	authFacade := auth.NewFacade(logService, authService, validationHandler)
+	inviteFacade := invite.NewFacade(logService, inviteService, validationHandler)
+	orgFacade := organization.NewFacade(logService, orgService, validationHandler)
+	securityFacade := security.NewFacade(logService, securityService, validationHandler)
+	webhookFacade := webhook.NewFacade(logService, webhookService, validationHandler)
 
 	// register REST API routes
-	router := restapi.RegisterRoutes(logService, authFacade, userFacade)
+	router := restapi.RegisterRoutes(logService, authFacade, userFacade, inviteFacade, orgFacade, securityFacade, webhookFacade, restapi.NewHealthHandler(logService, db, natsService), nil)
 
 	// start http server
 	testServer = httptest.NewServer(router)
 
+	// start gRPC server, on the same facades as the http server
+	grpcServer = grpcapi.NewServer(logService, db, authFacade, userFacade, nil)
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	go grpcServer.Serve(grpcListener)
+	grpcConn, err = grpc.Dial(grpcListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// initialize database connection for testing
 	testDbCon, err = testutil.GetTestDbCon(appConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// apply the schema, already applied migrations are skipped
+	migrationRunner, err := migration.NewEmbeddedRunner(logService, testDbCon, database.GetDriver(appConfig))
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = migrationRunner.Up(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 func teardownIntegrationTest() {
 	testDbCon.Exec("DELETE FROM users;")
+	testDbCon.Exec("DELETE FROM invites;")
+	testDbCon.Exec("DELETE FROM memberships;")
+	testDbCon.Exec("DELETE FROM organizations;")
+	testDbCon.Exec("DELETE FROM login_events;")
+	testDbCon.Exec("DELETE FROM outbox_events;")
+	testDbCon.Exec("DELETE FROM processed_messages;")
+	testDbCon.Exec("DELETE FROM webhook_delivery_attempts;")
+	testDbCon.Exec("DELETE FROM webhook_deliveries;")
+	testDbCon.Exec("DELETE FROM webhooks;")
 
 	// Clean up resources and shut down the test server and test database
 	testDbCon.Close()
 	db.CloseConnection()
+	natsService.Close()
 	testServer.Close()
+	grpcConn.Close()
+	grpcServer.Stop()
 	// Additional cleanup as needed
 }
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/pkg/database"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationWithTxCommitsOnSuccess(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	user := testutil.GenMockUser(nil)
+
+	// ACT
+	err := db.WithTx(ctx, func(txDb database.Db) error {
+		return txDb.SaveUser(ctx, &user)
+	})
+
+	// ASSERT
+	exists, _, _ := db.GetUserById(ctx, user.Id)
+	assert.Nil(t, err)
+	assert.True(t, exists, "should commit the changes")
+}
+
+func TestIntegrationWithTxRollsBackOnErr(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	user := testutil.GenMockUser(nil)
+	fnErr := fmt.Errorf("error from fn")
+
+	// ACT
+	err := db.WithTx(ctx, func(txDb database.Db) error {
+		if err := txDb.SaveUser(ctx, &user); err != nil {
+			return err
+		}
+		return fnErr
+	})
+
+	// ASSERT
+	exists, _, _ := db.GetUserById(ctx, user.Id)
+	assert.Equal(t, fnErr, err, "should return the error of fn")
+	assert.False(t, exists, "should roll back the changes")
+}
+
+func TestIntegrationWithTxNestedRollsBackToSavepoint(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	ctx := context.Background()
+	outerUser := testutil.GenMockUser(nil)
+	innerUser := testutil.GenMockUser(nil)
+
+	// ACT
+	err := db.WithTx(ctx, func(txDb database.Db) error {
+		if err := txDb.SaveUser(ctx, &outerUser); err != nil {
+			return err
+		}
+
+		_ = txDb.WithTx(ctx, func(nestedTxDb database.Db) error {
+			if err := nestedTxDb.SaveUser(ctx, &innerUser); err != nil {
+				return err
+			}
+			return fmt.Errorf("error from nested fn")
+		})
+
+		return nil
+	})
+
+	// ASSERT
+	outerExists, _, _ := db.GetUserById(ctx, outerUser.Id)
+	innerExists, _, _ := db.GetUserById(ctx, innerUser.Id)
+	assert.Nil(t, err)
+	assert.True(t, outerExists, "should commit the changes of the outer transaction")
+	assert.False(t, innerExists, "should roll back the changes of the failed nested transaction")
+}
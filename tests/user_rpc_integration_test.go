@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pjmessi/golang-practice/internal/errorcode"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/internal/service/user"
+	"github.com/pjmessi/golang-practice/pkg/exception"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupUserRpcClient starts the responders of the user service and returns a client using its own connection, like
+// another service would. The in process event bus is only seen by its own service, so the client shares it then
+func setupUserRpcClient(t *testing.T) user.RpcClient {
+	logService := logger.NewService()
+	for _, responder := range user.NewRpcResponders(appConfig, logService, validationHandler, userService) {
+		assert.Nil(t, natsService.Respond(responder))
+	}
+
+	if appConfig.NATS_DRIVER == "memory" {
+		return user.NewRpcClient(appConfig, natsService)
+	}
+
+	clientNatsService, err := nats.NewService(appConfig, logService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(clientNatsService.Close)
+
+	return user.NewRpcClient(appConfig, clientNatsService)
+}
+
+func TestIntegrationUserRpcShouldGetRegisteredUsers(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	client := setupUserRpcClient(t)
+	loginRes := testutil.SetupTestUser(testServer.URL)
+	unknownId := testutil.Fake.UUID().V4()
+
+	// ACT
+	userRes, getErr := client.GetUser(context.Background(), loginRes.User.Id)
+	batchRes, batchErr := client.GetUsers(context.Background(), []string{unknownId, loginRes.User.Id})
+	_, notFoundErr := client.GetUser(context.Background(), unknownId)
+
+	// ASSERT
+	assert.Nil(t, getErr)
+	assert.Equal(t, loginRes.User.Email, userRes.Email, "should return the user of the id")
+	assert.Nil(t, batchErr)
+	if assert.Len(t, batchRes.Users, 1) {
+		assert.Equal(t, loginRes.User.Id, batchRes.Users[0].Id)
+	}
+	assert.Equal(t, []string{unknownId}, batchRes.MissingIds, "should list the ids without a user")
+
+	var notFound exception.NotFound
+	if assert.ErrorAs(t, notFoundErr, &notFound, "should return the exception of the responder") {
+		assert.Equal(t, errorcode.UserNotFound, notFound.Type)
+	}
+}
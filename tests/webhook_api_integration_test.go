@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pjmessi/golang-practice/internal/model"
+	"github.com/pjmessi/golang-practice/internal/pkg/testutil"
+	"github.com/pjmessi/golang-practice/internal/service/webhook"
+	"github.com/pjmessi/golang-practice/pkg/logger"
+	"github.com/pjmessi/golang-practice/pkg/nats"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestOrgOwner registers a user owning a new organization and returns the login into it
+func setupTestOrgOwner() model.LoginApiRes {
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	loginRes := registerAndLoginTestUser(email, "")
+	_, responseBody := sendTestReq("POST", "/organizations", loginRes.Jwt, `{"name": "Acme"}`)
+	createOrgRes := model.CreateOrgApiRes{}
+	_ = json.Unmarshal(responseBody, &createOrgRes)
+	return loginTestUser(email, "Password123!", createOrgRes.Organization.Id)
+}
+
+func TestIntegrationWebhookValidationAndPermissions(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	loginRes := setupTestOrgOwner()
+
+	// ACT
+	invalidUrlResp, _ := sendTestReq("POST", "/organizations/current/webhooks", loginRes.Jwt, `{"url": "example", "eventTypes": ["*"]}`)
+	invalidTypeResp, invalidTypeBody := sendTestReq("POST", "/organizations/current/webhooks", loginRes.Jwt, `{"url": "https://example.com", "eventTypes": ["user.unknown"]}`)
+	deleteResp, deleteBody := sendTestReq("DELETE", "/organizations/current/webhooks?id=unknown", loginRes.Jwt, "")
+
+	// ASSERT
+	assert.Equal(t, http.StatusUnprocessableEntity, invalidUrlResp.StatusCode, "should reject urls which are not absolute")
+	assert.Equal(t, http.StatusUnprocessableEntity, invalidTypeResp.StatusCode, "should reject unknown event types")
+	assert.Contains(t, string(invalidTypeBody), `"type":"WEBHOOK.INVALID_EVENT_TYPE"`)
+	assert.Equal(t, http.StatusNotFound, deleteResp.StatusCode, "should return 404 for an unknown webhook")
+	assert.Contains(t, string(deleteBody), `"type":"WEBHOOK.NOT_FOUND"`)
+}
+
+func TestIntegrationRegistrationEventShouldBeDeliveredToWebhook(t *testing.T) {
+	// ARRANGE
+	setupIntegrationTest()
+	defer teardownIntegrationTest()
+
+	// the endpoint verifies every delivery with the secret returned when the webhook is created
+	var mu sync.Mutex
+	var secret string
+	var payloads []string
+	var verifyErrs []error
+	var otherOrgPayloads []string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		payloads = append(payloads, string(body))
+		verifyErrs = append(verifyErrs, webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), r.Header.Get(webhook.TimestampHeader), body, time.Now(), 5*time.Minute))
+	}))
+	defer endpoint.Close()
+	otherOrgEndpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		otherOrgPayloads = append(otherOrgPayloads, string(body))
+	}))
+	defer otherOrgEndpoint.Close()
+
+	loginRes := setupTestOrgOwner()
+	createResp, createBody := sendTestReq("POST", "/organizations/current/webhooks", loginRes.Jwt, fmt.Sprintf(`{"url": "%s", "eventTypes": ["user.registered"]}`, endpoint.URL))
+	createRes := model.CreateWebhookApiRes{}
+	_ = json.Unmarshal(createBody, &createRes)
+	assert.Equal(t, http.StatusOK, createResp.StatusCode)
+	assert.NotEmpty(t, createRes.Secret, "should return the secret of the webhook")
+	mu.Lock()
+	secret = createRes.Secret
+	mu.Unlock()
+
+	// the webhook of another organization subscribes to every event, it only receives the events of its own members
+	otherOrgLoginRes := setupTestOrgOwner()
+	otherCreateResp, otherCreateBody := sendTestReq("POST", "/organizations/current/webhooks", otherOrgLoginRes.Jwt, fmt.Sprintf(`{"url": "%s", "eventTypes": ["*"]}`, otherOrgEndpoint.URL))
+	otherCreateRes := model.CreateWebhookApiRes{}
+	_ = json.Unmarshal(otherCreateBody, &otherCreateRes)
+	assert.Equal(t, http.StatusOK, otherCreateResp.StatusCode)
+
+	// the registered user joins the organization through an invite of its owner
+	email := strings.ToLower(testutil.Fake.Internet().Email())
+	_, inviteBody := sendTestReq("POST", "/organizations/current/invitations", loginRes.Jwt, fmt.Sprintf(`{"email": "%s","role": "member"}`, email))
+	inviteRes := model.InviteOrgMemberApiRes{}
+	_ = json.Unmarshal(inviteBody, &inviteRes)
+	assert.NotEmpty(t, inviteRes.Invite.Code)
+
+	// the handlers of the app are durable, the test consumes with its own durable
+	durable := "integration_" + strings.ReplaceAll(testutil.Fake.UUID().V4(), "-", "")
+	handler := webhook.NewEventHandlers(appConfig, eventRegistry, webhookService)[0]
+	handler.Durable = durable
+	registry := nats.NewRegistry()
+	assert.Nil(t, registry.Register(handler))
+	assert.Nil(t, natsService.Subscribe(appConfig.NATS_STREAM, registry))
+	defer deleteTestConsumer(t, durable)
+
+	worker, err := webhook.NewWorker(appConfig, logger.NewService(), db)
+	assert.Nil(t, err)
+
+	// ACT
+	registerResp, _ := sendTestReq("POST", "/users/registration", "", fmt.Sprintf(`{"email": "%s","password": "Password123!","inviteCode": "%s"}`, email, inviteRes.Invite.Code))
+	_, errRelayRes := outboxRelay.RelayPending(context.Background())
+
+	// ASSERT
+	assert.Equal(t, http.StatusOK, registerResp.StatusCode)
+	assert.Nil(t, errRelayRes)
+
+	// the durable starts from the beginning of the stream, so events of previous runs are consumed too, they belong to
+	// users outside both organizations
+	timeout := time.After(10 * time.Second)
+	for isDelivered := false; !isDelivered; {
+		_, err := worker.DeliverPending(context.Background())
+		assert.Nil(t, err)
+
+		mu.Lock()
+		for _, payload := range payloads {
+			isDelivered = isDelivered || strings.Contains(payload, email)
+		}
+		mu.Unlock()
+
+		select {
+		case <-timeout:
+			t.Fatal("the registration event should be delivered to the webhook")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	mu.Lock()
+	for _, verifyErr := range verifyErrs {
+		assert.Nil(t, verifyErr, "every delivery should be signed with the secret of the webhook")
+	}
+	// the deliveries of an event are queued together, so the other organization would have received it by now
+	for _, payload := range otherOrgPayloads {
+		assert.NotContains(t, payload, email, "the webhook of another organization should not receive the events of the members of the first one")
+	}
+	mu.Unlock()
+
+	listResp, listBody := sendTestReq("GET", fmt.Sprintf("/organizations/current/webhooks/deliveries?webhookId=%s&limit=100", createRes.Webhook.Id), loginRes.Jwt, "")
+	listRes := model.ListWebhookDeliveriesApiRes{}
+	_ = json.Unmarshal(listBody, &listRes)
+	assert.Equal(t, http.StatusOK, listResp.StatusCode)
+	assert.NotEmpty(t, listRes.Deliveries)
+	delivery := listRes.Deliveries[len(listRes.Deliveries)-1]
+	assert.Equal(t, model.WebhookDeliverySucceeded, delivery.Status)
+
+	attemptsResp, attemptsBody := sendTestReq("GET", fmt.Sprintf("/organizations/current/webhooks/deliveries/attempts?deliveryId=%s", delivery.Id), loginRes.Jwt, "")
+	attemptsRes := model.ListWebhookDeliveryAttemptsApiRes{}
+	_ = json.Unmarshal(attemptsBody, &attemptsRes)
+	assert.Equal(t, http.StatusOK, attemptsResp.StatusCode)
+	assert.Len(t, attemptsRes.Attempts, 1)
+	assert.Equal(t, http.StatusOK, *attemptsRes.Attempts[0].StatusCode)
+
+	redeliverResp, redeliverBody := sendTestReq("POST", "/organizations/current/webhooks/deliveries/redeliver", loginRes.Jwt, fmt.Sprintf(`{"deliveryId": "%s"}`, delivery.Id))
+	redeliverRes := model.RedeliverWebhookApiRes{}
+	_ = json.Unmarshal(redeliverBody, &redeliverRes)
+	assert.Equal(t, http.StatusOK, redeliverResp.StatusCode)
+	assert.Equal(t, model.WebhookDeliveryPending, redeliverRes.Delivery.Status)
+
+	deliveredRes, err := worker.DeliverPending(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deliveredRes, "should deliver the event again")
+
+	deleteResp, _ := sendTestReq("DELETE", fmt.Sprintf("/organizations/current/webhooks?id=%s", createRes.Webhook.Id), loginRes.Jwt, "")
+	webhooksResp, webhooksBody := sendTestReq("GET", "/organizations/current/webhooks", loginRes.Jwt, "")
+	assert.Equal(t, http.StatusOK, deleteResp.StatusCode)
+	assert.Equal(t, http.StatusOK, webhooksResp.StatusCode)
+	assert.Equal(t, `{"webhooks":[]}`, string(webhooksBody))
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, natsService.Drain(drainCtx))
+}